@@ -99,6 +99,9 @@ func statReporter(statSink *websocket.ManagedConnection, statChan <-chan []asmet
 type config struct {
 	PodName string `split_words:"true" required:"true"`
 	PodIP   string `split_words:"true" required:"true"`
+
+	// NodeName is the node of the activator, used to find its zone.
+	NodeName string `split_words:"true"`
 }
 
 func main() {
@@ -166,7 +169,7 @@ func main() {
 	defer close(statCh)
 
 	// Start throttler.
-	throttler := activatornet.NewThrottler(ctx, env.PodIP)
+	throttler := activatornet.NewThrottler(ctx, env.PodIP, env.NodeName)
 	go throttler.Run(ctx)
 
 	oct := tracing.NewOpenCensusTracer(tracing.WithExporterFull(networking.ActivatorServiceName, env.PodIP, logger))
@@ -179,18 +182,6 @@ func main() {
 		}
 	})
 
-	// Set up our config store
-	configMapWatcher := configmap.NewInformedWatcher(kubeClient, system.Namespace())
-	configStore := activatorconfig.NewStore(logger, tracerUpdater)
-	configStore.WatchConfigs(configMapWatcher)
-
-	// Open a WebSocket connection to the autoscaler.
-	autoscalerEndpoint := fmt.Sprintf("ws://%s.%s.svc.%s%s", "autoscaler", system.Namespace(), pkgnet.GetClusterDomainName(), autoscalerPort)
-	logger.Info("Connecting to Autoscaler at ", autoscalerEndpoint)
-	statSink := websocket.NewDurableSendingConnection(autoscalerEndpoint, logger)
-	defer statSink.Shutdown()
-	go statReporter(statSink, statCh, logger)
-
 	// Create and run our concurrency reporter
 	concurrencyReporter := activatorhandler.NewConcurrencyReporter(ctx, env.PodName, statCh)
 	go concurrencyReporter.Run(ctx.Done())
@@ -201,12 +192,42 @@ func main() {
 	maxIdleProxyConnsPerHost := intFromEnv(logger, "MAX_IDLE_PROXY_CONNS_PER_HOST", 100)
 	logger.Debugf("MaxIdleProxyConns: %d, MaxIdleProxyConnsPerHost: %d", maxIdleProxyConns, maxIdleProxyConnsPerHost)
 
-	proxyTransport := pkgnet.NewAutoTransport(maxIdleProxyConns, maxIdleProxyConnsPerHost)
+	proxyTransport := activatornet.NewUpstreamTransport(logger,
+		pkgnet.NewAutoTransport(maxIdleProxyConns, maxIdleProxyConnsPerHost),
+		maxIdleProxyConns, maxIdleProxyConnsPerHost)
+	go proxyTransport.Run(ctx.Done())
+
+	reporterUpdater := configmap.TypeFilter(&activatorconfig.Activator{})(func(name string, value interface{}) {
+		concurrencyReporter.ApplyConfig(value.(*activatorconfig.Activator))
+		throttler.ApplyConfig(value.(*activatorconfig.Activator))
+		proxyTransport.ApplyConfig(value.(*activatorconfig.Activator))
+	})
+
+	// Set up our config store
+	configMapWatcher := configmap.NewInformedWatcher(kubeClient, system.Namespace())
+	configStore := activatorconfig.NewStore(logger, tracerUpdater, reporterUpdater)
+	configStore.WatchConfigs(configMapWatcher)
+
+	// Open a WebSocket connection to the autoscaler.
+	autoscalerEndpoint := fmt.Sprintf("ws://%s.%s.svc.%s%s", "autoscaler", system.Namespace(), pkgnet.GetClusterDomainName(), autoscalerPort)
+	logger.Info("Connecting to Autoscaler at ", autoscalerEndpoint)
+	statSink := websocket.NewDurableSendingConnection(autoscalerEndpoint, logger)
+	defer statSink.Shutdown()
+	go statReporter(statSink, statCh, logger)
 
 	// Create activation handler chain
 	// Note: innermost handlers are specified first, ie. the last handler in the chain will be executed first
 	var ah http.Handler = activatorhandler.New(ctx, throttler, proxyTransport)
+	ah = activatorhandler.NewDedupHandler(ah)
+	// Within the concurrency reporter, so that the shed requests still
+	// signal the scale-up of their revision.
+	ah = activatorhandler.NewMemoryShedHandler(ctx, throttler, ah)
 	ah = concurrencyReporter.Handler(ah)
+	// Outside of the concurrency reporter, so that the requests over the
+	// limit of their client don't signal a scale-up.
+	ah = activatorhandler.NewClientConcurrencyHandler(ah)
+	ah = &activatorhandler.MaintenanceHandler{NextHandler: ah}
+	ah = &activatorhandler.CompressionHandler{NextHandler: ah}
 	ah = tracing.HTTPSpanMiddleware(ah)
 	ah = configStore.HTTPMiddleware(ah)
 	reqLogHandler, err := pkghttp.NewRequestLogHandler(ah, logging.NewSyncFileWriter(os.Stdout), "",
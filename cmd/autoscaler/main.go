@@ -19,6 +19,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -29,13 +30,16 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	podinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod"
 	"knative.dev/pkg/injection"
+	"knative.dev/pkg/injection/clients/dynamicclient"
 	"knative.dev/pkg/injection/sharedmain"
 
 	"knative.dev/pkg/configmap"
@@ -48,9 +52,12 @@ import (
 	"knative.dev/pkg/version"
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
+	asconfig "knative.dev/serving/pkg/autoscaler/config"
+	"knative.dev/serving/pkg/autoscaler/custommetrics"
 	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
 	"knative.dev/serving/pkg/autoscaler/scaling"
 	"knative.dev/serving/pkg/autoscaler/statserver"
+	metricinformer "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/metric"
 	smetrics "knative.dev/serving/pkg/metrics"
 	"knative.dev/serving/pkg/reconciler/autoscaling/kpa"
 	"knative.dev/serving/pkg/reconciler/metric"
@@ -58,10 +65,12 @@ import (
 )
 
 const (
-	statsServerAddr = ":8080"
-	statsBufferLen  = 1000
-	component       = "autoscaler"
-	controllerNum   = 2
+	statsServerAddr         = ":8080"
+	customMetricsServerAddr = ":8081"
+	debugServerAddr         = "127.0.0.1:8082"
+	statsBufferLen          = 1000
+	component               = "autoscaler"
+	controllerNum           = 2
 )
 
 var (
@@ -135,6 +144,7 @@ func main() {
 
 	collector := asmetrics.NewMetricCollector(
 		statsScraperFactoryFunc(podLister), logger)
+	cmw.Watch(asconfig.ConfigName, updateScrapeConcurrencyFromConfigMap(logger, collector))
 
 	// Set up scalers.
 	// uniScalerFactory depends endpointsInformer to be set.
@@ -142,13 +152,30 @@ func main() {
 		uniScalerFactoryFunc(podLister, collector), logger)
 
 	controllers := []*controller.Impl{
-		kpa.NewController(ctx, cmw, multiScaler),
+		kpa.NewController(ctx, cmw, multiScaler, collector),
 		metric.NewController(ctx, cmw, collector),
 	}
 
 	// Set up a statserver.
 	statsServer := statserver.New(statsServerAddr, statsCh, logger)
 
+	// Serve the observed concurrency through the custom metrics API, when
+	// its APIService is installed.
+	customMetricsServer, err := newCustomMetricsServer(ctx, kubeClient,
+		custommetrics.NewHandler(collector, metricinformer.Get(ctx).Lister(), logger))
+	if errors.Is(err, custommetrics.ErrNotRegistered) {
+		logger.Infow("Not serving the custom metrics API", zap.Error(err))
+	} else if err != nil {
+		logger.Errorw("Not serving the custom metrics API", zap.Error(err))
+	}
+
+	// Serve the decision history of the revisions, for debugging. It isn't
+	// authenticated, so it only listens on the loopback interface.
+	debugServer := &http.Server{
+		Addr:    debugServerAddr,
+		Handler: scaling.NewDecisionHistoryHandler(multiScaler, logger),
+	}
+
 	// Start watching the configs.
 	if err := cmw.Start(ctx.Done()); err != nil {
 		logger.Fatalw("Failed to start watching configs", zap.Error(err))
@@ -173,6 +200,12 @@ func main() {
 	eg, egCtx := errgroup.WithContext(ctx)
 	eg.Go(statsServer.ListenAndServe)
 	eg.Go(profilingServer.ListenAndServe)
+	if customMetricsServer != nil {
+		eg.Go(func() error {
+			return customMetricsServer.ListenAndServeTLS("", "")
+		})
+	}
+	eg.Go(debugServer.ListenAndServe)
 
 	// This will block until either a signal arrives or one of the grouped functions
 	// returns an error.
@@ -180,12 +213,32 @@ func main() {
 
 	statsServer.Shutdown(5 * time.Second)
 	profilingServer.Shutdown(context.Background())
+	if customMetricsServer != nil {
+		customMetricsServer.Shutdown(context.Background())
+	}
+	debugServer.Shutdown(context.Background())
 	// Don't forward ErrServerClosed as that indicates we're already shutting down.
 	if err := eg.Wait(); err != nil && err != http.ErrServerClosed {
 		logger.Errorw("Error while running server", zap.Error(err))
 	}
 }
 
+// newCustomMetricsServer returns the server of the custom metrics API, which
+// authenticates and authorizes the requests through the API server.
+func newCustomMetricsServer(ctx context.Context, kubeClient kubernetes.Interface, handler http.Handler) (*http.Server, error) {
+	cert, err := custommetrics.ServingCertificate(ctx, kubeClient, dynamicclient.Get(ctx), component)
+	if err != nil {
+		return nil, err
+	}
+	requestHeader, err := custommetrics.LoadRequestHeaderConfig(kubeClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the front proxy configuration: %w", err)
+	}
+	return custommetrics.NewServer(customMetricsServerAddr, cert,
+		custommetrics.WithDelegatedAuth(handler, kubeClient, requestHeader, logging.FromContext(ctx)),
+		requestHeader), nil
+}
+
 func uniScalerFactoryFunc(podLister corev1listers.PodLister,
 	metricClient asmetrics.MetricClient) scaling.UniScalerFactory {
 	return func(decider *scaling.Decider) (scaling.UniScaler, error) {
@@ -227,6 +280,17 @@ func statsScraperFactoryFunc(podLister corev1listers.PodLister) asmetrics.StatsS
 	}
 }
 
+func updateScrapeConcurrencyFromConfigMap(logger *zap.SugaredLogger, collector *asmetrics.MetricCollector) configmap.Observer {
+	return func(cm *corev1.ConfigMap) {
+		cfg, err := asconfig.NewConfigFromConfigMap(cm)
+		if err != nil {
+			logger.Errorw("Failed to parse the autoscaler config", zap.Error(err))
+			return
+		}
+		collector.SetScrapeConcurrency(cfg.ScrapeConcurrency)
+	}
+}
+
 func flush(logger *zap.SugaredLogger) {
 	logger.Sync()
 	metrics.FlushExporter()
@@ -71,12 +71,17 @@ var (
 )
 
 type config struct {
-	ContainerConcurrency   int    `split_words:"true" required:"true"`
-	QueueServingPort       int    `split_words:"true" required:"true"`
-	UserPort               int    `split_words:"true" required:"true"`
-	RevisionTimeoutSeconds int    `split_words:"true" required:"true"`
-	ServingReadinessProbe  string `split_words:"true" required:"true"`
-	EnableProfiling        bool   `split_words:"true"` // optional
+	ContainerConcurrency   int           `split_words:"true" required:"true"`
+	QueueServingPort       int           `split_words:"true" required:"true"`
+	UserPort               int           `split_words:"true" required:"true"`
+	RevisionTimeoutSeconds int           `split_words:"true" required:"true"`
+	ServingReadinessProbe  string        `split_words:"true" required:"true"`
+	EnableProfiling        bool          `split_words:"true"` // optional
+	PreStopDelay           time.Duration `split_words:"true"` // optional
+	ConcurrencyWarmup      time.Duration `split_words:"true"` // optional
+	ImmediateContinue      bool          `split_words:"true"` // optional
+	SamePodRetries         int           `split_words:"true"` // optional
+	SamePodRetryBackoff    time.Duration `split_words:"true" default:"10ms"`
 
 	// Logging configuration
 	ServingLoggingConfig         string `split_words:"true" required:"true"`
@@ -252,7 +257,7 @@ func main() {
 	healthState := &health.State{}
 
 	server := buildServer(env, healthState, probe, stats, logger)
-	adminServer := buildAdminServer(healthState, logger)
+	adminServer := buildAdminServer(healthState, env.PreStopDelay, logger)
 	metricsServer := buildMetricsServer(promStatReporter, protoStatReporter)
 
 	servers := map[string]*http.Server{
@@ -331,13 +336,26 @@ func buildServer(env config, healthState *health.State, rp *readiness.Probe, sta
 	}
 
 	httpProxy := httputil.NewSingleHostReverseProxy(target)
-	httpProxy.Transport = buildTransport(env, logger, maxIdleConns)
+	httpProxy.Transport = queue.NewSamePodRetryRoundTripper(buildTransport(env, logger, maxIdleConns),
+		env.SamePodRetries, env.SamePodRetryBackoff)
 	httpProxy.ErrorHandler = pkgnet.ErrorHandler(logger)
 	httpProxy.BufferPool = network.NewBufferPool()
 	httpProxy.FlushInterval = network.FlushInterval
 	activatorutil.SetupHeaderPruning(httpProxy)
 
 	breaker := buildBreaker(env)
+	prober := rp.ProbeContainer
+	if breaker != nil && env.ConcurrencyWarmup > 0 {
+		// The warmup starts once the container is first found ready.
+		warmup := queue.NewConcurrencyWarmup(breaker, env.ContainerConcurrency, env.ConcurrencyWarmup)
+		prober = func() bool {
+			if !rp.ProbeContainer() {
+				return false
+			}
+			warmup.Start()
+			return true
+		}
+	}
 	metricsSupported := supportsMetrics(env, logger)
 	tracingEnabled := env.TracingConfigBackend != tracingconfig.None
 	timeout := time.Duration(env.RevisionTimeoutSeconds) * time.Second
@@ -349,6 +367,9 @@ func buildServer(env config, healthState *health.State, rp *readiness.Probe, sta
 		composedHandler = requestAppMetricsHandler(composedHandler, breaker, env)
 	}
 	composedHandler = proxyHandler(breaker, stats, tracingEnabled, composedHandler)
+	if env.ImmediateContinue {
+		composedHandler = queue.ImmediateContinueHandler(composedHandler)
+	}
 	composedHandler = queue.ForwardedShimHandler(composedHandler)
 	composedHandler = handler.NewTimeToFirstByteTimeoutHandler(composedHandler, "request timeout", handler.StaticTimeoutFunc(timeout))
 
@@ -357,7 +378,7 @@ func buildServer(env config, healthState *health.State, rp *readiness.Probe, sta
 	}
 	composedHandler = tracing.HTTPSpanMiddleware(composedHandler)
 
-	composedHandler = knativeProbeHandler(healthState, rp.ProbeContainer, rp.IsAggressive(), tracingEnabled, composedHandler, logger)
+	composedHandler = knativeProbeHandler(healthState, prober, rp.IsAggressive(), tracingEnabled, composedHandler, logger)
 	composedHandler = network.NewProbeHandler(composedHandler)
 	// We might want sometimes capture the probes/healthchecks in the request
 	// logs. Hence we need to have RequestLogHandler to be the first one.
@@ -398,6 +419,10 @@ func buildBreaker(env config) *queue.Breaker {
 	// allow the autoscaler time to react.
 	queueDepth := env.ContainerConcurrency * 10
 	params := queue.BreakerParams{QueueDepth: queueDepth, MaxConcurrency: env.ContainerConcurrency, InitialCapacity: env.ContainerConcurrency}
+	if env.ConcurrencyWarmup > 0 {
+		// The capacity is raised by the warmup once the container is ready.
+		params.InitialCapacity = 1
+	}
 	logger.Infof("Queue container is starting with %#v", params)
 
 	return queue.NewBreaker(params)
@@ -417,13 +442,17 @@ func supportsMetrics(env config, logger *zap.SugaredLogger) bool {
 	return true
 }
 
-func buildAdminServer(healthState *health.State, logger *zap.SugaredLogger) *http.Server {
+func buildAdminServer(healthState *health.State, preStopDelay time.Duration, logger *zap.SugaredLogger) *http.Server {
 	adminMux := http.NewServeMux()
 	drainHandler := healthState.DrainHandlerFunc()
 	adminMux.HandleFunc(queue.RequestQueueDrainPath, func(w http.ResponseWriter, r *http.Request) {
 		logger.Info("Attached drain handler from user-container")
 		drainHandler(w, r)
 	})
+	adminMux.HandleFunc(queue.RequestQueuePreStopPath, func(w http.ResponseWriter, r *http.Request) {
+		logger.Infof("Sleeping %v before TERM signal to allow K8s propagation of endpoint removal", preStopDelay)
+		time.Sleep(preStopDelay)
+	})
 
 	return &http.Server{
 		Addr:    ":" + strconv.Itoa(networking.QueueAdminPort),
@@ -18,8 +18,10 @@ package main
 
 import (
 	"context"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubeinformerfactory "knative.dev/pkg/client/injection/kube/informers/factory"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection/sharedmain"
@@ -118,8 +120,21 @@ func newDefaultingAdmissionController(ctx context.Context, cmw configmap.Watcher
 }
 
 func newValidationAdmissionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	// Cache the PriorityClasses, to check that the ones referenced by
+	// revisions exist, once that validation is enabled.
+	priorityClasses := kubeinformerfactory.Get(ctx).Scheduling().V1().PriorityClasses()
+	var startPriorityClasses sync.Once
+	startPriorityClassesIfEnabled := func(_ string, value interface{}) {
+		if features, ok := value.(*defaultconfig.Features); ok &&
+			features.PodSpecPriorityClassValidation == defaultconfig.Enabled {
+			startPriorityClasses.Do(func() {
+				go priorityClasses.Informer().Run(ctx.Done())
+			})
+		}
+	}
+
 	// Decorate contexts with the current state of the config.
-	store := defaultconfig.NewStore(logging.FromContext(ctx).Named("config-store"))
+	store := defaultconfig.NewStore(logging.FromContext(ctx).Named("config-store"), startPriorityClassesIfEnabled)
 	store.WatchConfigs(cmw)
 
 	return validation.NewAdmissionController(ctx,
@@ -135,6 +150,11 @@ func newValidationAdmissionController(ctx context.Context, cmw configmap.Watcher
 
 		// A function that infuses the context passed to Validate/SetDefaults with custom metadata.
 		func(ctx context.Context) context.Context {
+			// Until the PriorityClasses are cached, they aren't checked
+			// rather than reported missing.
+			if priorityClasses.Informer().HasSynced() {
+				ctx = extravalidation.WithPriorityClassLister(ctx, priorityClasses.Lister())
+			}
 			return servingv1.WithUpgradeViaDefaulting(store.ToContext(ctx))
 		},
 
@@ -54,6 +54,7 @@ readonly SERVING_STORAGE_VERSION_MIGRATE_YAML=${YAML_OUTPUT_DIR}/serving-storage
 readonly SERVING_HPA_YAML=${YAML_OUTPUT_DIR}/serving-hpa.yaml
 readonly SERVING_CRD_YAML=${YAML_OUTPUT_DIR}/serving-crds.yaml
 readonly SERVING_NSCERT_YAML=${YAML_OUTPUT_DIR}/serving-nscert.yaml
+readonly SERVING_CUSTOM_METRICS_YAML=${YAML_OUTPUT_DIR}/serving-custom-metrics.yaml
 readonly SERVING_POST_INSTALL_JOBS_YAML=${YAML_OUTPUT_DIR}/serving-post-install-jobs.yaml
 
 readonly MONITORING_YAML=${YAML_OUTPUT_DIR}/monitoring.yaml
@@ -104,6 +105,9 @@ ko resolve ${KO_YAML_FLAGS} -f config/hpa-autoscaling/ | "${LABEL_YAML_CMD[@]}"
 # Create nscert related yaml
 ko resolve ${KO_YAML_FLAGS} -f config/namespace-wildcard-certs | "${LABEL_YAML_CMD[@]}" > "${SERVING_NSCERT_YAML}"
 
+# Create custom metrics API related yaml
+ko resolve ${KO_YAML_FLAGS} -f config/custom-metrics/ | "${LABEL_YAML_CMD[@]}" > "${SERVING_CUSTOM_METRICS_YAML}"
+
 # Generate the core monitoring file - basically just the namespace
 ko resolve ${KO_YAML_FLAGS} -R -f config/monitoring/100-namespace.yaml \
     | "${LABEL_YAML_CMD[@]}" > "${MONITORING_CORE_YAML}"
@@ -157,6 +161,7 @@ ${SERVING_POST_INSTALL_JOBS_YAML}
 ${SERVING_HPA_YAML}
 ${SERVING_CRD_YAML}
 ${SERVING_NSCERT_YAML}
+${SERVING_CUSTOM_METRICS_YAML}
 ${MONITORING_YAML}
 ${MONITORING_CORE_YAML}
 ${MONITORING_METRIC_PROMETHEUS_YAML}
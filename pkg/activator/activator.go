@@ -23,4 +23,7 @@ const (
 	RevisionHeaderName = "Knative-Serving-Revision"
 	// RevisionHeaderNamespace is the header key for revision's namespace.
 	RevisionHeaderNamespace = "Knative-Serving-Namespace"
+	// MaintenanceHeaderName is the header key set by the Ingress on the requests
+	// for a Route in maintenance mode.
+	MaintenanceHeaderName = "Knative-Serving-Maintenance"
 )
@@ -0,0 +1,220 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	cm "knative.dev/pkg/configmap"
+)
+
+const (
+	// ActivatorConfigName is the name of the config map for the activator.
+	ActivatorConfigName = "config-activator"
+
+	connectionErrorRetriesKey = "connection-error-retries"
+	serverErrorRetriesKey     = "server-error-retries"
+	idempotencyKeyHeaderKey   = "idempotency-key-header"
+	idempotencyWindowKey      = "idempotency-window"
+
+	scaleUpBufferingWindowKey      = "scale-up-buffering-window"
+	scaleUpBufferingMaxRequestsKey = "scale-up-buffering-max-requests"
+
+	maintenanceResponseKey = "maintenance-response"
+
+	enforceRevisionTimeoutKey = "enforce-revision-timeout"
+
+	preferLocalZoneKey = "prefer-local-zone"
+
+	upstreamTLSKey           = "upstream-tls"
+	upstreamTLSCAFileKey     = "upstream-tls-ca-file"
+	upstreamTLSCertFileKey   = "upstream-tls-cert-file"
+	upstreamTLSKeyFileKey    = "upstream-tls-key-file"
+	upstreamTLSServerNameKey = "upstream-tls-server-name"
+
+	memorySheddingThresholdKey = "memory-shedding-threshold"
+
+	traceBaggageKey = "trace-baggage"
+
+	compressResponsesKey = "compress-responses"
+
+	clientConcurrencyHeaderKey = "client-concurrency-header"
+	clientConcurrencyLimitKey  = "client-concurrency-limit"
+)
+
+// Activator contains the knobs that control how the activator proxies
+// requests to the revision pods.
+type Activator struct {
+	// ConnectionErrorRetries is the number of times a request is retried
+	// when the connection to the upstream fails (refused or reset).
+	ConnectionErrorRetries int32
+
+	// ServerErrorRetries is the number of times a request is retried
+	// when the upstream responds with a 5xx status code.
+	ServerErrorRetries int32
+
+	// IdempotencyKeyHeader is the request header carrying the key used to
+	// deduplicate requests. Empty disables deduplication.
+	IdempotencyKeyHeader string
+
+	// IdempotencyWindow is how long the response to a request carrying an
+	// idempotency key is kept to answer its duplicates.
+	IdempotencyWindow time.Duration
+
+	// ScaleUpBufferingWindow is how long the requests for a revision without
+	// recent traffic are batched before signaling the scale-up. Zero signals
+	// the scale-up on the first request.
+	ScaleUpBufferingWindow time.Duration
+
+	// ScaleUpBufferingMaxRequests is the number of batched requests that
+	// signals the scale-up before the end of ScaleUpBufferingWindow. Zero
+	// means no limit.
+	ScaleUpBufferingMaxRequests int32
+
+	// MaintenanceResponse is the body of the 503 response served for the
+	// Routes in maintenance mode.
+	MaintenanceResponse string
+
+	// EnforceRevisionTimeout makes the activator bound the requests it
+	// handles by the timeoutSeconds of their revision, including the time
+	// spent waiting for capacity. Otherwise only the queue-proxy enforces it.
+	EnforceRevisionTimeout bool
+
+	// PreferLocalZone makes the activator send the requests to the pods in
+	// its own zone while they have capacity, and to all the pods when they
+	// don't. It doesn't apply to the requests sent through the clusterIP.
+	PreferLocalZone bool
+
+	// UpstreamTLS makes the activator speak TLS to the revision pods instead
+	// of plaintext, for both the requests and the probes.
+	UpstreamTLS bool
+
+	// UpstreamTLSCAFile is the PEM bundle of the CAs verifying the
+	// certificates of the revision pods. Empty uses the system roots.
+	UpstreamTLSCAFile string
+
+	// UpstreamTLSCertFile and UpstreamTLSKeyFile are the PEM client
+	// certificate and key the activator presents to the revision pods.
+	// Empty presents no client certificate.
+	UpstreamTLSCertFile string
+	UpstreamTLSKeyFile  string
+
+	// UpstreamTLSServerName is the name verified in the certificates of the
+	// revision pods, which are addressed by IP. Empty verifies the IP.
+	UpstreamTLSServerName string
+	// MemorySheddingThreshold is the memory in bytes held by the activator
+	// above which the requests that would wait for their revision to scale
+	// from zero are answered with a 503 instead. Zero disables the shedding.
+	MemorySheddingThreshold int64
+
+	// TraceBaggage makes the activator add to the W3C baggage of the
+	// requests it proxies how long it buffered them, so that the traces
+	// of the revisions tell the cold starts apart.
+	TraceBaggage bool
+	// CompressResponses makes the activator gzip the responses to the
+	// clients accepting it, unless the revision already encoded them or
+	// they aren't worth compressing.
+	CompressResponses bool
+
+	// ClientConcurrencyHeader is the request header identifying the client
+	// of a request, e.g. by its API key. Empty disables the per-client
+	// concurrency limit.
+	ClientConcurrencyHeader string
+
+	// ClientConcurrencyLimit is the number of requests a client may have in
+	// flight to a revision through an activator. Zero means no limit.
+	ClientConcurrencyLimit int32
+}
+
+func defaultActivatorConfig() *Activator {
+	return &Activator{
+		IdempotencyWindow:   10 * time.Second,
+		MaintenanceResponse: "Service is under maintenance, please try again later.\n",
+	}
+}
+
+// NewActivatorConfigFromMap creates an Activator config from the supplied map.
+func NewActivatorConfigFromMap(data map[string]string) (*Activator, error) {
+	ac := defaultActivatorConfig()
+	var memorySheddingThreshold *resource.Quantity
+
+	if err := cm.Parse(data,
+		cm.AsInt32(connectionErrorRetriesKey, &ac.ConnectionErrorRetries),
+		cm.AsInt32(serverErrorRetriesKey, &ac.ServerErrorRetries),
+		cm.AsString(idempotencyKeyHeaderKey, &ac.IdempotencyKeyHeader),
+		cm.AsDuration(idempotencyWindowKey, &ac.IdempotencyWindow),
+		cm.AsDuration(scaleUpBufferingWindowKey, &ac.ScaleUpBufferingWindow),
+		cm.AsInt32(scaleUpBufferingMaxRequestsKey, &ac.ScaleUpBufferingMaxRequests),
+		cm.AsString(maintenanceResponseKey, &ac.MaintenanceResponse),
+		cm.AsBool(enforceRevisionTimeoutKey, &ac.EnforceRevisionTimeout),
+		cm.AsBool(preferLocalZoneKey, &ac.PreferLocalZone),
+		cm.AsBool(upstreamTLSKey, &ac.UpstreamTLS),
+		cm.AsString(upstreamTLSCAFileKey, &ac.UpstreamTLSCAFile),
+		cm.AsString(upstreamTLSCertFileKey, &ac.UpstreamTLSCertFile),
+		cm.AsString(upstreamTLSKeyFileKey, &ac.UpstreamTLSKeyFile),
+		cm.AsString(upstreamTLSServerNameKey, &ac.UpstreamTLSServerName),
+		cm.AsQuantity(memorySheddingThresholdKey, &memorySheddingThreshold),
+		cm.AsBool(traceBaggageKey, &ac.TraceBaggage),
+		cm.AsBool(compressResponsesKey, &ac.CompressResponses),
+		cm.AsString(clientConcurrencyHeaderKey, &ac.ClientConcurrencyHeader),
+		cm.AsInt32(clientConcurrencyLimitKey, &ac.ClientConcurrencyLimit),
+	); err != nil {
+		return nil, fmt.Errorf("failed to parse data: %w", err)
+	}
+	if memorySheddingThreshold != nil {
+		ac.MemorySheddingThreshold = memorySheddingThreshold.Value()
+	}
+
+	if ac.ConnectionErrorRetries < 0 {
+		return nil, fmt.Errorf("%s must be non-negative, was: %d", connectionErrorRetriesKey, ac.ConnectionErrorRetries)
+	}
+	if ac.ServerErrorRetries < 0 {
+		return nil, fmt.Errorf("%s must be non-negative, was: %d", serverErrorRetriesKey, ac.ServerErrorRetries)
+	}
+
+	if ac.IdempotencyWindow <= 0 {
+		return nil, fmt.Errorf("%s must be positive, was: %v", idempotencyWindowKey, ac.IdempotencyWindow)
+	}
+
+	if ac.ScaleUpBufferingWindow < 0 {
+		return nil, fmt.Errorf("%s must be non-negative, was: %v", scaleUpBufferingWindowKey, ac.ScaleUpBufferingWindow)
+	}
+	if ac.ScaleUpBufferingMaxRequests < 0 {
+		return nil, fmt.Errorf("%s must be non-negative, was: %d", scaleUpBufferingMaxRequestsKey, ac.ScaleUpBufferingMaxRequests)
+	}
+
+	if ac.MemorySheddingThreshold < 0 {
+		return nil, fmt.Errorf("%s must be non-negative, was: %v", memorySheddingThresholdKey, memorySheddingThreshold)
+	}
+	if ac.ClientConcurrencyLimit < 0 {
+		return nil, fmt.Errorf("%s must be non-negative, was: %d", clientConcurrencyLimitKey, ac.ClientConcurrencyLimit)
+	}
+	if (ac.UpstreamTLSCertFile == "") != (ac.UpstreamTLSKeyFile == "") {
+		return nil, fmt.Errorf("%s and %s must be set together", upstreamTLSCertFileKey, upstreamTLSKeyFileKey)
+	}
+
+	return ac, nil
+}
+
+// NewActivatorConfigFromConfigMap creates an Activator config from the supplied ConfigMap.
+func NewActivatorConfigFromConfigMap(config *corev1.ConfigMap) (*Activator, error) {
+	return NewActivatorConfigFromMap(config.Data)
+}
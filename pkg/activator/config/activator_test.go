@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	. "knative.dev/pkg/configmap/testing"
+)
+
+func TestActivatorConfig(t *testing.T) {
+	actual, example := ConfigMapsFromTestFile(t, ActivatorConfigName)
+	for _, tt := range []struct {
+		name    string
+		wantErr bool
+		want    *Activator
+		data    map[string]string
+	}{{
+		name: "actual config",
+		want: defaultActivatorConfig(),
+		data: actual.Data,
+	}, {
+		name: "example config",
+		want: defaultActivatorConfig(),
+		data: example.Data,
+	}, {
+		name: "with value overrides",
+		want: &Activator{
+			ConnectionErrorRetries:      3,
+			ServerErrorRetries:          1,
+			IdempotencyKeyHeader:        "Idempotency-Key",
+			IdempotencyWindow:           time.Minute,
+			ScaleUpBufferingWindow:      200 * time.Millisecond,
+			ScaleUpBufferingMaxRequests: 10,
+			MaintenanceResponse:         "<h1>Back soon</h1>",
+			EnforceRevisionTimeout:      true,
+			PreferLocalZone:             true,
+			UpstreamTLS:                 true,
+			UpstreamTLSCAFile:           "/etc/upstream/ca.crt",
+			UpstreamTLSCertFile:         "/etc/upstream/tls.crt",
+			UpstreamTLSKeyFile:          "/etc/upstream/tls.key",
+			UpstreamTLSServerName:       "revision.knative.internal",
+			MemorySheddingThreshold:     800 << 20,
+			TraceBaggage:                true,
+			CompressResponses:           true,
+			ClientConcurrencyHeader:     "X-Api-Key",
+			ClientConcurrencyLimit:      5,
+		},
+		data: map[string]string{
+			connectionErrorRetriesKey:      "3",
+			serverErrorRetriesKey:          "1",
+			idempotencyKeyHeaderKey:        "Idempotency-Key",
+			idempotencyWindowKey:           "1m",
+			scaleUpBufferingWindowKey:      "200ms",
+			scaleUpBufferingMaxRequestsKey: "10",
+			maintenanceResponseKey:         "<h1>Back soon</h1>",
+			enforceRevisionTimeoutKey:      "true",
+			preferLocalZoneKey:             "true",
+			upstreamTLSKey:                 "true",
+			upstreamTLSCAFileKey:           "/etc/upstream/ca.crt",
+			upstreamTLSCertFileKey:         "/etc/upstream/tls.crt",
+			upstreamTLSKeyFileKey:          "/etc/upstream/tls.key",
+			upstreamTLSServerNameKey:       "revision.knative.internal",
+			memorySheddingThresholdKey:     "800Mi",
+			traceBaggageKey:                "true",
+			compressResponsesKey:           "true",
+			clientConcurrencyHeaderKey:     "X-Api-Key",
+			clientConcurrencyLimitKey:      "5",
+		},
+	}, {
+		name:    "invalid connection error retries",
+		wantErr: true,
+		data: map[string]string{
+			connectionErrorRetriesKey: "many",
+		},
+	}, {
+		name:    "negative connection error retries",
+		wantErr: true,
+		data: map[string]string{
+			connectionErrorRetriesKey: "-1",
+		},
+	}, {
+		name:    "negative server error retries",
+		wantErr: true,
+		data: map[string]string{
+			serverErrorRetriesKey: "-1",
+		},
+	}, {
+		name:    "invalid idempotency window",
+		wantErr: true,
+		data: map[string]string{
+			idempotencyWindowKey: "soon",
+		},
+	}, {
+		name:    "non-positive idempotency window",
+		wantErr: true,
+		data: map[string]string{
+			idempotencyWindowKey: "0s",
+		},
+	}, {
+		name:    "negative scale-up buffering window",
+		wantErr: true,
+		data: map[string]string{
+			scaleUpBufferingWindowKey: "-1s",
+		},
+	}, {
+		name:    "negative scale-up buffering max requests",
+		wantErr: true,
+		data: map[string]string{
+			scaleUpBufferingMaxRequestsKey: "-1",
+		},
+	}, {
+		name:    "invalid memory shedding threshold",
+		wantErr: true,
+		data: map[string]string{
+			memorySheddingThresholdKey: "lots",
+		},
+	}, {
+		name:    "negative memory shedding threshold",
+		wantErr: true,
+		data: map[string]string{
+			memorySheddingThresholdKey: "-1Gi",
+		},
+	}, {
+		name:    "negative client concurrency limit",
+		wantErr: true,
+		data: map[string]string{
+			clientConcurrencyLimitKey: "-1",
+		},
+	}, {
+		name:    "upstream TLS certificate without key",
+		wantErr: true,
+		data: map[string]string{
+			upstreamTLSKey:         "true",
+			upstreamTLSCertFileKey: "/etc/upstream/tls.crt",
+		},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewActivatorConfigFromMap(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewActivatorConfigFromMap() = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Error("NewActivatorConfigFromMap (-want, +got):", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
@@ -28,7 +28,8 @@ type cfgKey struct{}
 
 // Config is the configuration for the activator.
 type Config struct {
-	Tracing *tracingconfig.Config
+	Activator *Activator
+	Tracing   *tracingconfig.Config
 }
 
 // FromContext obtains a Config injected into the passed context.
@@ -53,6 +54,7 @@ func NewStore(logger configmap.Logger, onAfterStore ...func(name string, value i
 			"activator",
 			logger,
 			configmap.Constructors{
+				ActivatorConfigName:      NewActivatorConfigFromConfigMap,
 				tracingconfig.ConfigName: tracingconfig.NewTracingConfigFromConfigMap,
 			},
 			onAfterStore...,
@@ -68,7 +70,8 @@ func (s *Store) ToContext(ctx context.Context) context.Context {
 // Load creates a Config for this store.
 func (s *Store) Load() *Config {
 	return &Config{
-		Tracing: s.UntypedLoad(tracingconfig.ConfigName).(*tracingconfig.Config).DeepCopy(),
+		Activator: s.UntypedLoad(ActivatorConfigName).(*Activator).DeepCopy(),
+		Tracing:   s.UntypedLoad(tracingconfig.ConfigName).(*tracingconfig.Config).DeepCopy(),
 	}
 }
 
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+	"sync"
+
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	"knative.dev/serving/pkg/activator/util"
+)
+
+// ClientConcurrencyHandler answers with a 429 the requests of a client that
+// already has as many requests in flight to their revision as the configured
+// limit, so that a single client can't take all of the capacity of a
+// revision. Clients are told apart by the configured header.
+type ClientConcurrencyHandler struct {
+	nextHandler http.Handler
+
+	mu       sync.Mutex
+	inFlight map[string]int32
+}
+
+// NewClientConcurrencyHandler creates a handler that limits the concurrency
+// of each client.
+func NewClientConcurrencyHandler(next http.Handler) *ClientConcurrencyHandler {
+	return &ClientConcurrencyHandler{
+		nextHandler: next,
+		inFlight:    make(map[string]int32),
+	}
+}
+
+func (h *ClientConcurrencyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cfg := activatorconfig.FromContext(r.Context()).Activator
+	if cfg.ClientConcurrencyHeader == "" || cfg.ClientConcurrencyLimit == 0 {
+		h.nextHandler.ServeHTTP(w, r)
+		return
+	}
+	client := r.Header.Get(cfg.ClientConcurrencyHeader)
+	if client == "" {
+		h.nextHandler.ServeHTTP(w, r)
+		return
+	}
+	// Clients are limited per revision, so that a client busy with one app
+	// can still reach the others.
+	key := util.RevIDFrom(r.Context()).String() + "/" + client
+
+	if !h.acquire(key, cfg.ClientConcurrencyLimit) {
+		http.Error(w, "client concurrency limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	defer h.release(key)
+	h.nextHandler.ServeHTTP(w, r)
+}
+
+// acquire counts a request of the client in, unless it is at the limit.
+func (h *ClientConcurrencyHandler) acquire(key string, limit int32) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.inFlight[key] >= limit {
+		return false
+	}
+	h.inFlight[key]++
+	return true
+}
+
+// release counts a request of the client out, forgetting the idle clients.
+func (h *ClientConcurrencyHandler) release(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.inFlight[key] <= 1 {
+		delete(h.inFlight, key)
+	} else {
+		h.inFlight[key]--
+	}
+}
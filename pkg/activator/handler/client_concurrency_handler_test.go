@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	logtesting "knative.dev/pkg/logging/testing"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	"knative.dev/serving/pkg/activator/util"
+)
+
+const testClientHeader = "X-Api-Key"
+
+func TestClientConcurrencyHandler(t *testing.T) {
+	rev1 := types.NamespacedName{Namespace: "ns", Name: "rev1"}
+	rev2 := types.NamespacedName{Namespace: "ns", Name: "rev2"}
+
+	type request struct {
+		rev    types.NamespacedName
+		client string
+	}
+	tests := []struct {
+		name   string
+		header string
+		limit  string
+		// inFlight are held by the next handler while request is served.
+		inFlight []request
+		request  request
+		wantCode int
+	}{{
+		name:     "limit disabled",
+		limit:    "1",
+		inFlight: []request{{rev: rev1, client: "a"}},
+		request:  request{rev: rev1, client: "a"},
+		wantCode: http.StatusOK,
+	}, {
+		name:     "below the limit",
+		header:   testClientHeader,
+		limit:    "2",
+		inFlight: []request{{rev: rev1, client: "a"}},
+		request:  request{rev: rev1, client: "a"},
+		wantCode: http.StatusOK,
+	}, {
+		name:     "at the limit",
+		header:   testClientHeader,
+		limit:    "2",
+		inFlight: []request{{rev: rev1, client: "a"}, {rev: rev1, client: "a"}},
+		request:  request{rev: rev1, client: "a"},
+		wantCode: http.StatusTooManyRequests,
+	}, {
+		name:     "other client",
+		header:   testClientHeader,
+		limit:    "1",
+		inFlight: []request{{rev: rev1, client: "a"}},
+		request:  request{rev: rev1, client: "b"},
+		wantCode: http.StatusOK,
+	}, {
+		name:     "same client for a different revision",
+		header:   testClientHeader,
+		limit:    "1",
+		inFlight: []request{{rev: rev1, client: "a"}},
+		request:  request{rev: rev2, client: "a"},
+		wantCode: http.StatusOK,
+	}, {
+		name:     "requests without the header",
+		header:   testClientHeader,
+		limit:    "1",
+		inFlight: []request{{rev: rev1}},
+		request:  request{rev: rev1},
+		wantCode: http.StatusOK,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			release := make(chan struct{})
+			var started sync.WaitGroup
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Hold") != "" {
+					started.Done()
+					<-release
+				}
+			})
+			handler := NewClientConcurrencyHandler(next)
+			ctx := clientConcurrencyTestContext(t, test.header, test.limit)
+
+			var done sync.WaitGroup
+			for _, req := range test.inFlight {
+				started.Add(1)
+				done.Add(1)
+				go func(req request) {
+					defer done.Done()
+					serveClient(ctx, handler, req.rev, req.client, true)
+				}(req)
+			}
+			started.Wait()
+
+			resp := serveClient(ctx, handler, test.request.rev, test.request.client, false)
+			if resp.Code != test.wantCode {
+				t.Errorf("StatusCode = %d, want: %d", resp.Code, test.wantCode)
+			}
+
+			close(release)
+			done.Wait()
+			// The slots are given back once the requests complete.
+			if resp := serveClient(ctx, handler, test.request.rev, test.request.client, false); resp.Code != http.StatusOK {
+				t.Errorf("StatusCode after release = %d, want: %d", resp.Code, http.StatusOK)
+			}
+			if len(handler.inFlight) != 0 {
+				t.Errorf("inFlight = %v, want empty", handler.inFlight)
+			}
+		})
+	}
+}
+
+func clientConcurrencyTestContext(t *testing.T, header, limit string) context.Context {
+	store := setupConfigStore(t, logtesting.TestLogger(t))
+	data := map[string]string{
+		"client-concurrency-limit": limit,
+	}
+	if header != "" {
+		data["client-concurrency-header"] = header
+	}
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: activatorconfig.ActivatorConfigName,
+		},
+		Data: data,
+	})
+	return store.ToContext(context.Background())
+}
+
+func serveClient(ctx context.Context, handler http.Handler, rev types.NamespacedName, client string, hold bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	if client != "" {
+		req.Header.Set(testClientHeader, client)
+	}
+	if hold {
+		req.Header.Set("X-Hold", "true")
+	}
+	req = req.WithContext(util.WithRevID(ctx, rev))
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	return resp
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+)
+
+// gzipMinSize is the size of the smallest body that is compressed. The
+// smaller ones fit in a single packet anyway.
+const gzipMinSize = 1400
+
+// CompressionHandler gzips the responses to the clients accepting it, when
+// enabled in the activator config. Responses the upstream already encoded,
+// partial and gRPC responses, and small bodies are passed through untouched.
+type CompressionHandler struct {
+	NextHandler http.Handler
+}
+
+func (h *CompressionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Upgraded connections and bodiless responses have nothing to compress,
+	// and the ranges of the body refer to its uncompressed bytes.
+	if !activatorconfig.FromContext(r.Context()).Activator.CompressResponses ||
+		r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" ||
+		r.Header.Get("Range") != "" || isGRPC(r.Header) || !acceptsGzip(r.Header) {
+		h.NextHandler.ServeHTTP(w, r)
+		return
+	}
+
+	gw := &gzipResponseWriter{ResponseWriter: w}
+	defer gw.close()
+	h.NextHandler.ServeHTTP(gw, r)
+}
+
+// acceptsGzip returns whether the Accept-Encoding header of the request
+// allows a gzip response.
+func acceptsGzip(h http.Header) bool {
+	for _, v := range h.Values("Accept-Encoding") {
+		for _, enc := range strings.Split(v, ",") {
+			parts := strings.Split(enc, ";")
+			if name := strings.TrimSpace(parts[0]); name != "gzip" && name != "*" {
+				continue
+			}
+			// Only an explicit zero quality refuses the encoding.
+			if len(parts) > 1 && strings.ReplaceAll(parts[1], " ", "") == "q=0" {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// isGRPC returns whether the message is gRPC, which has a compression of its
+// own and whose clients don't expect the HTTP one.
+func isGRPC(h http.Header) bool {
+	return strings.HasPrefix(h.Get("Content-Type"), "application/grpc")
+}
+
+// gzipResponseWriter decides whether to compress the response once its
+// header is written, or once the first gzipMinSize bytes of its body are
+// when its length isn't known, and then compresses its body.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+
+	// While pending, the header isn't written yet and the body is buffered
+	// until it is known whether it is large enough to be compressed.
+	pending bool
+	code    int
+	buf     []byte
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || isGRPC(h) || code < http.StatusOK ||
+		code == http.StatusNoContent || code == http.StatusPartialContent || code == http.StatusNotModified {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if cl := h.Get("Content-Length"); cl != "" {
+		if n, err := strconv.Atoi(cl); err != nil || n < gzipMinSize {
+			w.ResponseWriter.WriteHeader(code)
+			return
+		}
+		w.startGzip(code)
+		return
+	}
+	w.pending, w.code = true, code
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.pending {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < gzipMinSize {
+			return len(b), nil
+		}
+		w.pending = false
+		w.startGzip(w.code)
+		if _, err := w.gz.Write(w.buf); err != nil {
+			return 0, err
+		}
+		w.buf = nil
+		return len(b), nil
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.gz.Write(b)
+}
+
+// startGzip writes the header of the compressed response.
+func (w *gzipResponseWriter) startGzip(code int) {
+	h := w.Header()
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	h.Add("Vary", "Accept-Encoding")
+	w.ResponseWriter.WriteHeader(code)
+	w.gz = gzip.NewWriter(w.ResponseWriter)
+}
+
+// passThrough writes out the pending response uncompressed.
+func (w *gzipResponseWriter) passThrough() {
+	w.pending = false
+	w.ResponseWriter.WriteHeader(w.code)
+	if len(w.buf) > 0 {
+		w.ResponseWriter.Write(w.buf)
+		w.buf = nil
+	}
+}
+
+// Flush writes out what was compressed so far, so that streamed responses
+// keep flowing. The streams too small to be compressed yet are passed
+// through uncompressed.
+func (w *gzipResponseWriter) Flush() {
+	if w.pending {
+		w.passThrough()
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *gzipResponseWriter) close() {
+	if w.pending {
+		w.passThrough()
+	}
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
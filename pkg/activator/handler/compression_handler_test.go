@@ -0,0 +1,237 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	logtesting "knative.dev/pkg/logging/testing"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+)
+
+func TestCompressionHandler(t *testing.T) {
+	const smallBody = "the quick brown fox jumps over the lazy dog"
+	largeBody := strings.Repeat(smallBody+"\n", 50)
+	tests := []struct {
+		name           string
+		disabled       bool
+		method         string
+		header         http.Header
+		acceptEncoding string
+		upstreamEnc    string
+		upstreamType   string
+		code           int
+		small          bool
+		unknownLength  bool
+		wantCompressed bool
+	}{{
+		name:           "compressed",
+		acceptEncoding: "gzip, deflate",
+		wantCompressed: true,
+	}, {
+		name:           "compressed with quality",
+		acceptEncoding: "br;q=1.0, gzip;q=0.8",
+		wantCompressed: true,
+	}, {
+		name:           "compressed for any encoding",
+		acceptEncoding: "*",
+		wantCompressed: true,
+	}, {
+		name:           "compressed error",
+		acceptEncoding: "gzip",
+		code:           http.StatusBadGateway,
+		wantCompressed: true,
+	}, {
+		name:           "disabled",
+		disabled:       true,
+		acceptEncoding: "gzip",
+	}, {
+		name: "client doesn't accept gzip",
+	}, {
+		name:           "client refuses gzip",
+		acceptEncoding: "gzip;q=0, deflate",
+	}, {
+		name:           "upstream already encoded",
+		acceptEncoding: "gzip",
+		upstreamEnc:    "br",
+	}, {
+		name:           "head request",
+		method:         http.MethodHead,
+		acceptEncoding: "gzip",
+	}, {
+		name:           "not modified",
+		acceptEncoding: "gzip",
+		code:           http.StatusNotModified,
+	}, {
+		name:           "partial content",
+		acceptEncoding: "gzip",
+		code:           http.StatusPartialContent,
+	}, {
+		name:           "range request",
+		header:         http.Header{"Range": {"bytes=0-"}},
+		acceptEncoding: "gzip",
+	}, {
+		name:           "grpc request",
+		header:         http.Header{"Content-Type": {"application/grpc"}},
+		acceptEncoding: "gzip",
+	}, {
+		name:           "grpc response",
+		acceptEncoding: "gzip",
+		upstreamType:   "application/grpc+proto",
+	}, {
+		name:           "small body",
+		acceptEncoding: "gzip",
+		small:          true,
+	}, {
+		name:           "unknown length",
+		acceptEncoding: "gzip",
+		unknownLength:  true,
+		wantCompressed: true,
+	}, {
+		name:           "small body of unknown length",
+		acceptEncoding: "gzip",
+		small:          true,
+		unknownLength:  true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := test.code
+			if code == 0 {
+				code = http.StatusOK
+			}
+			body := largeBody
+			if test.small {
+				body = smallBody
+			}
+			handler := &CompressionHandler{
+				NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if !test.unknownLength {
+						w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+					}
+					if test.upstreamEnc != "" {
+						w.Header().Set("Content-Encoding", test.upstreamEnc)
+					}
+					if test.upstreamType != "" {
+						w.Header().Set("Content-Type", test.upstreamType)
+					}
+					w.WriteHeader(code)
+					if code != http.StatusNotModified {
+						w.Write([]byte(body))
+					}
+				}),
+			}
+
+			store := setupConfigStore(t, logtesting.TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: activatorconfig.ActivatorConfigName,
+				},
+				Data: map[string]string{
+					"compress-responses": strconv.FormatBool(!test.disabled),
+				},
+			})
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "http://example.com", nil)
+			for k, v := range test.header {
+				req.Header[k] = v
+			}
+			if test.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", test.acceptEncoding)
+			}
+			req = req.WithContext(store.ToContext(context.Background()))
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != code {
+				t.Errorf("StatusCode = %d, want: %d", resp.Code, code)
+			}
+			if !test.wantCompressed {
+				if got, want := resp.Header().Get("Content-Encoding"), test.upstreamEnc; got != want {
+					t.Errorf("Content-Encoding = %q, want: %q", got, want)
+				}
+				if code != http.StatusNotModified && resp.Body.String() != body {
+					t.Errorf("Body = %q, want: %q", resp.Body.String(), body)
+				}
+				return
+			}
+
+			if got, want := resp.Header().Get("Content-Encoding"), "gzip"; got != want {
+				t.Errorf("Content-Encoding = %q, want: %q", got, want)
+			}
+			if got := resp.Header().Get("Content-Length"); got != "" {
+				t.Errorf("Content-Length = %q, want it removed", got)
+			}
+			if got, want := resp.Header().Get("Vary"), "Accept-Encoding"; got != want {
+				t.Errorf("Vary = %q, want: %q", got, want)
+			}
+			gr, err := gzip.NewReader(resp.Body)
+			if err != nil {
+				t.Fatal("Failed to read the gzip response:", err)
+			}
+			got, err := ioutil.ReadAll(gr)
+			if err != nil {
+				t.Fatal("Failed to decompress the response:", err)
+			}
+			if string(got) != body {
+				t.Errorf("Decompressed body = %q, want: %q", got, body)
+			}
+		})
+	}
+}
+
+func TestCompressionHandlerFlush(t *testing.T) {
+	store := setupConfigStore(t, logtesting.TestLogger(t))
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: activatorconfig.ActivatorConfigName,
+		},
+		Data: map[string]string{
+			"compress-responses": "true",
+		},
+	})
+
+	resp := httptest.NewRecorder()
+	handler := &CompressionHandler{
+		NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("event: first\n\n"))
+			w.(http.Flusher).Flush()
+			// What was written so far must have reached the client.
+			if !resp.Flushed || resp.Body.Len() == 0 {
+				t.Error("The response wasn't flushed")
+			}
+		}),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	handler.ServeHTTP(resp, req.WithContext(store.ToContext(context.Background())))
+}
@@ -26,11 +26,13 @@ import (
 	"go.uber.org/zap"
 
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	network "knative.dev/networking/pkg"
 	"knative.dev/pkg/logging"
 	pkgmetrics "knative.dev/pkg/metrics"
 	"knative.dev/serving/pkg/activator"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
 	"knative.dev/serving/pkg/activator/util"
 	"knative.dev/serving/pkg/apis/serving"
 	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
@@ -49,7 +51,8 @@ type ConcurrencyReporter struct {
 	// Stat reporting channel
 	statCh chan []asmetrics.StatMessage
 
-	rl servinglisters.RevisionLister
+	rl    servinglisters.RevisionLister
+	clock clock.Clock
 
 	mux sync.RWMutex
 	// This map holds the concurrency and request count accounting across revisions.
@@ -61,6 +64,23 @@ type ConcurrencyReporter struct {
 	// This is important because for small concurrencies, e.g. 1, autoscaler might cause
 	// noticeable overprovisioning.
 	reportedFirstRequest map[types.NamespacedName]float64
+
+	// The settings for batching the first requests for a revision before
+	// signaling the scale-up, see ApplyConfig.
+	bufferingWindow      time.Duration
+	bufferingMaxRequests int32
+	// This map holds the requests batched for the revisions whose scale-up
+	// hasn't been signaled yet.
+	buffers map[types.NamespacedName]*scaleUpBuffer
+}
+
+// scaleUpBuffer batches the first requests for a revision until either the
+// buffering window elapses or the max number of requests is reached.
+type scaleUpBuffer struct {
+	requests float64
+	timer    clock.Timer
+	// done is closed once the buffer is flushed or dropped.
+	done chan struct{}
 }
 
 // NewConcurrencyReporter creates a ConcurrencyReporter which listens to incoming
@@ -71,12 +91,23 @@ func NewConcurrencyReporter(ctx context.Context, podName string, statCh chan []a
 		podName: podName,
 		statCh:  statCh,
 		rl:      revisioninformer.Get(ctx).Lister(),
+		clock:   clock.RealClock{},
 
 		stats:                make(map[types.NamespacedName]*network.RequestStats),
 		reportedFirstRequest: make(map[types.NamespacedName]float64),
+		buffers:              make(map[types.NamespacedName]*scaleUpBuffer),
 	}
 }
 
+// ApplyConfig updates the scale-up buffering settings of the reporter. It
+// applies to the revisions seen after the update.
+func (cr *ConcurrencyReporter) ApplyConfig(cfg *activatorconfig.Activator) {
+	cr.mux.Lock()
+	defer cr.mux.Unlock()
+	cr.bufferingWindow = cfg.ScaleUpBufferingWindow
+	cr.bufferingMaxRequests = cfg.ScaleUpBufferingMaxRequests
+}
+
 // handleEvent handles request events (in, out) and updates the respective stats.
 func (cr *ConcurrencyReporter) handleEvent(event network.ReqEvent) {
 	stats, msg := cr.getOrCreateStat(event)
@@ -90,12 +121,12 @@ func (cr *ConcurrencyReporter) handleEvent(event network.ReqEvent) {
 // If absent it creates a new one and returns it, potentially returning a StatMessage too
 // to trigger an immediate scale-from-0.
 func (cr *ConcurrencyReporter) getOrCreateStat(event network.ReqEvent) (*network.RequestStats, *asmetrics.StatMessage) {
-	stat := func() *network.RequestStats {
+	stat, buffered := func() (*network.RequestStats, bool) {
 		cr.mux.RLock()
 		defer cr.mux.RUnlock()
-		return cr.stats[event.Key]
+		return cr.stats[event.Key], cr.buffers[event.Key] != nil
 	}()
-	if stat != nil {
+	if stat != nil && (!buffered || event.Type != network.ReqIn) {
 		return stat, nil
 	}
 
@@ -105,12 +136,30 @@ func (cr *ConcurrencyReporter) getOrCreateStat(event network.ReqEvent) (*network
 
 	stat = cr.stats[event.Key]
 	if stat != nil {
+		// The scale-up of the revision might not have been signaled yet.
+		if buf := cr.buffers[event.Key]; buf != nil && event.Type == network.ReqIn {
+			buf.requests++
+			if cr.bufferingMaxRequests > 0 && buf.requests >= float64(cr.bufferingMaxRequests) {
+				return stat, cr.flushBuffer(event.Key, buf)
+			}
+		}
 		return stat, nil
 	}
 
 	stat = network.NewRequestStats(event.Time)
 	cr.stats[event.Key] = stat
 
+	if cr.bufferingWindow > 0 && cr.bufferingMaxRequests != 1 {
+		buf := &scaleUpBuffer{
+			requests: 1,
+			timer:    cr.clock.NewTimer(cr.bufferingWindow),
+			done:     make(chan struct{}),
+		}
+		cr.buffers[event.Key] = buf
+		go cr.awaitBuffer(event.Key, buf)
+		return stat, nil
+	}
+
 	cr.reportedFirstRequest[event.Key] = 1
 	return stat, &asmetrics.StatMessage{
 		Key: event.Key,
@@ -126,6 +175,50 @@ func (cr *ConcurrencyReporter) getOrCreateStat(event network.ReqEvent) (*network
 	}
 }
 
+// awaitBuffer signals the scale-up of the revision once the buffering window
+// elapses, unless the buffer was flushed or dropped before.
+func (cr *ConcurrencyReporter) awaitBuffer(key types.NamespacedName, buf *scaleUpBuffer) {
+	select {
+	case <-buf.timer.C():
+	case <-buf.done:
+		return
+	}
+
+	msg := func() *asmetrics.StatMessage {
+		cr.mux.Lock()
+		defer cr.mux.Unlock()
+		if cr.buffers[key] != buf {
+			return nil
+		}
+		return cr.flushBuffer(key, buf)
+	}()
+	if msg != nil {
+		cr.statCh <- []asmetrics.StatMessage{*msg}
+	}
+}
+
+// flushBuffer returns the StatMessage signaling the scale-up of the revision
+// for all the batched requests. cr.mux must be held.
+func (cr *ConcurrencyReporter) flushBuffer(key types.NamespacedName, buf *scaleUpBuffer) *asmetrics.StatMessage {
+	cr.dropBuffer(key, buf)
+	cr.reportedFirstRequest[key] = buf.requests
+	return &asmetrics.StatMessage{
+		Key: key,
+		Stat: asmetrics.Stat{
+			PodName:                   cr.podName,
+			AverageConcurrentRequests: buf.requests,
+			RequestCount:              buf.requests,
+		},
+	}
+}
+
+// dropBuffer stops batching the requests for the revision. cr.mux must be held.
+func (cr *ConcurrencyReporter) dropBuffer(key types.NamespacedName, buf *scaleUpBuffer) {
+	buf.timer.Stop()
+	close(buf.done)
+	delete(cr.buffers, key)
+}
+
 // report cuts a report from all collected statistics and sends the respective messages
 // via the statsCh and reports the concurrency metrics to prometheus.
 func (cr *ConcurrencyReporter) report(now time.Time) []asmetrics.StatMessage {
@@ -137,6 +230,12 @@ func (cr *ConcurrencyReporter) report(now time.Time) []asmetrics.StatMessage {
 		report := stat.Report(now)
 		firstAdj := cr.reportedFirstRequest[key]
 
+		// The requests still being batched are part of this report, which
+		// signals the scale-up in place of the buffer.
+		if buf := cr.buffers[key]; buf != nil {
+			cr.dropBuffer(key, buf)
+		}
+
 		// This is only 0 if we have seen no activity for the entire reporting
 		// period at all.
 		if report.AverageConcurrency == 0 {
@@ -29,11 +29,13 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"go.opencensus.io/resource"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	network "knative.dev/networking/pkg"
 	"knative.dev/pkg/metrics/metricskey"
 	"knative.dev/pkg/metrics/metricstest"
 	_ "knative.dev/pkg/metrics/testing"
 	rtesting "knative.dev/pkg/reconciler/testing"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
 	"knative.dev/serving/pkg/activator/util"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
@@ -460,6 +462,141 @@ func TestConcurrencyReporterHandler(t *testing.T) {
 	}
 }
 
+func TestConcurrencyReporterScaleUpBuffering(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxRequests int32
+		burst       int
+		// advance is how much the clock is advanced after the burst.
+		advance time.Duration
+		want    *asmetrics.StatMessage
+	}{{
+		name:    "burst within window",
+		burst:   5,
+		advance: 100 * time.Millisecond,
+		want: &asmetrics.StatMessage{
+			Key: rev1,
+			Stat: asmetrics.Stat{
+				AverageConcurrentRequests: 5,
+				RequestCount:              5,
+				PodName:                   activatorPodName,
+			},
+		},
+	}, {
+		// The signal doesn't wait for the window to elapse.
+		name:        "burst reaching max requests",
+		burst:       5,
+		maxRequests: 3,
+		want: &asmetrics.StatMessage{
+			Key: rev1,
+			Stat: asmetrics.Stat{
+				AverageConcurrentRequests: 3,
+				RequestCount:              3,
+				PodName:                   activatorPodName,
+			},
+		},
+	}, {
+		name:        "max requests of one signals immediately",
+		burst:       1,
+		maxRequests: 1,
+		want: &asmetrics.StatMessage{
+			Key: rev1,
+			Stat: asmetrics.Stat{
+				AverageConcurrentRequests: 1,
+				RequestCount:              1,
+				PodName:                   activatorPodName,
+			},
+		},
+	}, {
+		name:    "window not elapsed",
+		burst:   5,
+		advance: 50 * time.Millisecond,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr, _, cancel := newTestReporter(t)
+			defer cancel()
+			fakeClock := clock.NewFakeClock(time.Now())
+			cr.clock = fakeClock
+			cr.ApplyConfig(&activatorconfig.Activator{
+				ScaleUpBufferingWindow:      100 * time.Millisecond,
+				ScaleUpBufferingMaxRequests: test.maxRequests,
+			})
+
+			for i := 0; i < test.burst; i++ {
+				cr.handleEvent(network.ReqEvent{Key: rev1, Type: network.ReqIn, Time: fakeClock.Now()})
+			}
+			fakeClock.Step(test.advance)
+
+			if test.want == nil {
+				select {
+				case msgs := <-cr.statCh:
+					t.Fatal("Unexpected scale-up signal:", msgs)
+				case <-time.After(50 * time.Millisecond):
+				}
+				return
+			}
+
+			select {
+			case got := <-cr.statCh:
+				if want := []asmetrics.StatMessage{*test.want}; !cmp.Equal(got, want) {
+					t.Errorf("Unexpected stats (-want +got): %s", cmp.Diff(want, got))
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Timed out waiting for the scale-up signal")
+			}
+			// A single signal is sent for the whole burst.
+			select {
+			case msgs := <-cr.statCh:
+				t.Error("Unexpected second scale-up signal:", msgs)
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			// The periodic report discounts the signaled requests.
+			msgs := cr.report(fakeClock.Now())
+			if got, want := msgs[0].Stat.RequestCount, float64(test.burst)-test.want.Stat.RequestCount; got != want {
+				t.Errorf("Reported RequestCount = %v, want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestConcurrencyReporterScaleUpBufferingReportedFirst(t *testing.T) {
+	cr, _, cancel := newTestReporter(t)
+	defer cancel()
+	fakeClock := clock.NewFakeClock(time.Now())
+	cr.clock = fakeClock
+	cr.ApplyConfig(&activatorconfig.Activator{
+		ScaleUpBufferingWindow: 100 * time.Millisecond,
+	})
+
+	for i := 0; i < 3; i++ {
+		cr.handleEvent(network.ReqEvent{Key: rev1, Type: network.ReqIn, Time: fakeClock.Now()})
+	}
+
+	// The periodic report comes before the end of the window and signals the
+	// scale-up in place of the buffer.
+	want := []asmetrics.StatMessage{{
+		Key: rev1,
+		Stat: asmetrics.Stat{
+			AverageConcurrentRequests: 3,
+			RequestCount:              3,
+			PodName:                   activatorPodName,
+		},
+	}}
+	if got := cr.report(fakeClock.Now().Add(reportInterval)); !cmp.Equal(got, want) {
+		t.Errorf("Unexpected stats (-want +got): %s", cmp.Diff(want, got))
+	}
+
+	fakeClock.Step(time.Second)
+	select {
+	case msgs := <-cr.statCh:
+		t.Error("Unexpected scale-up signal:", msgs)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
 func TestMetricsReported(t *testing.T) {
 	reset()
 	cr, ctx, cancel := newTestReporter(t)
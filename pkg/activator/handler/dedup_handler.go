@@ -0,0 +1,306 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"bytes"
+	"container/heap"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	"knative.dev/serving/pkg/activator/util"
+)
+
+const (
+	// dedupShards is the number of shards the entries are spread over, so
+	// that the requests with different keys rarely contend for a lock.
+	dedupShards = 32
+
+	// maxDedupBodyBytes is the size of the largest response body kept for
+	// the duplicates. Larger responses are passed through to the client.
+	maxDedupBodyBytes = 256 * 1024
+)
+
+// DedupHandler answers the duplicates of a request carrying an idempotency key
+// with the response of the first request, as long as they arrive within the
+// configured window. Duplicates arriving while the first request is in flight
+// wait for its response. Only the buffered requests, i.e. those whose body has
+// a known length, are deduplicated, and only their responses of at most
+// maxDedupBodyBytes are kept: streamed or larger responses are passed through
+// to the client, and their duplicates are rejected with a 409 Conflict.
+type DedupHandler struct {
+	nextHandler http.Handler
+	clock       clock.PassiveClock
+
+	shards [dedupShards]dedupShard
+}
+
+// dedupShard holds the entries of the keys hashing to it, along with their
+// expiries in the order they are due.
+type dedupShard struct {
+	mu       sync.Mutex
+	entries  map[string]*dedupEntry
+	expiries expiryHeap
+}
+
+// dedupEntry is the response to a request carrying an idempotency key.
+// done is closed once the response has been recorded, or the request passed
+// through.
+type dedupEntry struct {
+	key     string
+	done    chan struct{}
+	expires time.Time
+
+	passthrough bool
+	code        int
+	header      http.Header
+	body        bytes.Buffer
+
+	// replayHeader is the header the duplicates are answered with, i.e.
+	// header without the perClientHeaders.
+	replayHeader http.Header
+}
+
+// perClientHeaders are the response headers meant for the client of the
+// first request only, which aren't replayed to the duplicates.
+var perClientHeaders = []string{
+	"Set-Cookie",
+	"Set-Cookie2",
+	"Authentication-Info",
+	"Proxy-Authentication-Info",
+}
+
+// NewDedupHandler creates a handler that deduplicates requests carrying an
+// idempotency key.
+func NewDedupHandler(next http.Handler) *DedupHandler {
+	h := &DedupHandler{
+		nextHandler: next,
+		clock:       clock.RealClock{},
+	}
+	for i := range h.shards {
+		h.shards[i].entries = make(map[string]*dedupEntry)
+	}
+	return h
+}
+
+func (h *DedupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cfg := activatorconfig.FromContext(r.Context()).Activator
+	if cfg.IdempotencyKeyHeader == "" {
+		h.nextHandler.ServeHTTP(w, r)
+		return
+	}
+	idempotencyKey := r.Header.Get(cfg.IdempotencyKeyHeader)
+	// Streamed requests and upgraded connections aren't buffered.
+	if idempotencyKey == "" || r.ContentLength < 0 || r.Header.Get("Upgrade") != "" {
+		h.nextHandler.ServeHTTP(w, r)
+		return
+	}
+	// Keys are scoped to the revision, so that different apps can't see each
+	// other's responses.
+	key := util.RevIDFrom(r.Context()).String() + "/" + idempotencyKey
+	shard := h.shard(key)
+
+	entry, duplicate := shard.lookup(key, h.clock.Now())
+	if duplicate {
+		select {
+		case <-entry.done:
+			if entry.passthrough {
+				// The response wasn't kept, and the request mustn't be
+				// processed twice.
+				http.Error(w, "the response to a request with the same idempotency key was not kept", http.StatusConflict)
+			} else {
+				entry.writeTo(w, entry.replayHeader)
+			}
+		case <-r.Context().Done():
+			http.Error(w, r.Context().Err().Error(), http.StatusServiceUnavailable)
+		}
+		return
+	}
+
+	bw := &bufferedResponseWriter{w: w, entry: entry}
+	completed := false
+	defer func() {
+		// Don't leave the duplicates waiting if the next handler panics.
+		if !completed {
+			if !entry.passthrough {
+				entry.code = http.StatusInternalServerError
+			}
+			shard.complete(entry, h.clock.Now().Add(cfg.IdempotencyWindow))
+		}
+	}()
+
+	h.nextHandler.ServeHTTP(bw, r)
+	if entry.code == 0 {
+		entry.code = http.StatusOK
+	}
+	shard.complete(entry, h.clock.Now().Add(cfg.IdempotencyWindow))
+	completed = true
+	if !entry.passthrough {
+		entry.writeTo(w, entry.header)
+	}
+}
+
+func (h *DedupHandler) shard(key string) *dedupShard {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return &h.shards[hash.Sum32()%dedupShards]
+}
+
+// lookup returns the entry for the key, and whether it belongs to a previous
+// request. If there is none, a new pending entry is registered.
+func (s *dedupShard) lookup(key string, now time.Time) (*dedupEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.expiries) > 0 && now.After(s.expiries[0].expires) {
+		e := heap.Pop(&s.expiries).(*dedupEntry)
+		if s.entries[e.key] == e {
+			delete(s.entries, e.key)
+		}
+	}
+
+	if e, ok := s.entries[key]; ok {
+		return e, true
+	}
+	e := &dedupEntry{
+		key:    key,
+		done:   make(chan struct{}),
+		header: make(http.Header),
+	}
+	s.entries[key] = e
+	return e, false
+}
+
+// complete marks the entry as recorded, to expire at the given time. Server
+// errors aren't kept, so that the request can be retried. The responses that
+// were passed through are kept as such, for their duplicates to be rejected.
+func (s *dedupShard) complete(e *dedupEntry, expires time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !e.passthrough {
+		e.replayHeader = e.header.Clone()
+		for _, k := range perClientHeaders {
+			e.replayHeader.Del(k)
+		}
+	}
+	if e.code >= http.StatusInternalServerError {
+		delete(s.entries, e.key)
+	} else {
+		e.expires = expires
+		heap.Push(&s.expiries, e)
+	}
+	close(e.done)
+}
+
+func (e *dedupEntry) writeTo(w http.ResponseWriter, header http.Header) {
+	for k, v := range header {
+		w.Header()[k] = append([]string(nil), v...)
+	}
+	w.WriteHeader(e.code)
+	w.Write(e.body.Bytes())
+}
+
+// expiryHeap orders the recorded entries by their expiry.
+type expiryHeap []*dedupEntry
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expires.Before(h[j].expires) }
+func (h expiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *expiryHeap) Push(x interface{}) {
+	*h = append(*h, x.(*dedupEntry))
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
+
+// bufferedResponseWriter records a response into a dedupEntry, until it turns
+// out to be streamed or too large to keep. From then on, the response is
+// passed through to the underlying writer.
+type bufferedResponseWriter struct {
+	w     http.ResponseWriter
+	entry *dedupEntry
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	if w.entry.passthrough {
+		return w.w.Header()
+	}
+	return w.entry.header
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if w.entry.code == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.entry.passthrough && w.entry.body.Len()+len(b) > maxDedupBodyBytes {
+		w.passThrough()
+	}
+	if w.entry.passthrough {
+		return w.w.Write(b)
+	}
+	return w.entry.body.Write(b)
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if w.entry.code != 0 {
+		return
+	}
+	w.entry.code = code
+	if n, err := strconv.ParseInt(w.entry.header.Get("Content-Length"), 10, 64); err == nil && n > maxDedupBodyBytes {
+		w.passThrough()
+	}
+}
+
+// Flush implements http.Flusher. Flushing a response of unknown length means
+// that it is streamed, so it is passed through. Flushing a response of known
+// length is deferred until it is recorded.
+func (w *bufferedResponseWriter) Flush() {
+	if !w.entry.passthrough {
+		if w.entry.header.Get("Content-Length") != "" {
+			return
+		}
+		if w.entry.code == 0 {
+			w.WriteHeader(http.StatusOK)
+		}
+		w.passThrough()
+	}
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// passThrough writes what was recorded so far to the underlying writer, and
+// makes the writes go straight to it from then on.
+func (w *bufferedResponseWriter) passThrough() {
+	w.entry.passthrough = true
+	w.entry.writeTo(w.w, w.entry.header)
+	w.entry.body.Reset()
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	logtesting "knative.dev/pkg/logging/testing"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	"knative.dev/serving/pkg/activator/util"
+)
+
+const testIdempotencyHeader = "Idempotency-Key"
+
+func TestDedupHandler(t *testing.T) {
+	rev1 := types.NamespacedName{Namespace: "ns", Name: "rev1"}
+	rev2 := types.NamespacedName{Namespace: "ns", Name: "rev2"}
+
+	type request struct {
+		rev      types.NamespacedName
+		key      string
+		streamed bool          // the request body has an unknown length.
+		advance  time.Duration // advance the clock before the request.
+	}
+	tests := []struct {
+		name      string
+		header    string
+		code      int
+		bodySize  int  // pad the response body to this size.
+		sized     bool // set the Content-Length of the response.
+		flush     bool // flush the response.
+		requests  []request
+		wantCalls int32
+		// the duplicates are rejected with a 409, as the response wasn't kept.
+		wantConflict bool
+	}{{
+		name:      "deduplication disabled",
+		requests:  []request{{rev: rev1, key: "a"}, {rev: rev1, key: "a"}},
+		wantCalls: 2,
+	}, {
+		name:      "duplicate within window",
+		header:    testIdempotencyHeader,
+		requests:  []request{{rev: rev1, key: "a"}, {rev: rev1, key: "a", advance: 5 * time.Second}},
+		wantCalls: 1,
+	}, {
+		name:      "duplicate after window",
+		header:    testIdempotencyHeader,
+		requests:  []request{{rev: rev1, key: "a"}, {rev: rev1, key: "a", advance: 11 * time.Second}},
+		wantCalls: 2,
+	}, {
+		name:      "different keys",
+		header:    testIdempotencyHeader,
+		requests:  []request{{rev: rev1, key: "a"}, {rev: rev1, key: "b"}},
+		wantCalls: 2,
+	}, {
+		name:      "same key for different revisions",
+		header:    testIdempotencyHeader,
+		requests:  []request{{rev: rev1, key: "a"}, {rev: rev2, key: "a"}},
+		wantCalls: 2,
+	}, {
+		name:      "requests without key",
+		header:    testIdempotencyHeader,
+		requests:  []request{{rev: rev1}, {rev: rev1}},
+		wantCalls: 2,
+	}, {
+		name:      "server errors are not kept",
+		header:    testIdempotencyHeader,
+		code:      http.StatusBadGateway,
+		requests:  []request{{rev: rev1, key: "a"}, {rev: rev1, key: "a"}},
+		wantCalls: 2,
+	}, {
+		name:      "streamed requests",
+		header:    testIdempotencyHeader,
+		requests:  []request{{rev: rev1, key: "a", streamed: true}, {rev: rev1, key: "a", streamed: true}},
+		wantCalls: 2,
+	}, {
+		name:         "large responses are not kept",
+		header:       testIdempotencyHeader,
+		bodySize:     maxDedupBodyBytes + 1,
+		requests:     []request{{rev: rev1, key: "a"}, {rev: rev1, key: "a"}},
+		wantCalls:    1,
+		wantConflict: true,
+	}, {
+		name:         "large responses of known length are not kept",
+		header:       testIdempotencyHeader,
+		bodySize:     maxDedupBodyBytes + 1,
+		sized:        true,
+		requests:     []request{{rev: rev1, key: "a"}, {rev: rev1, key: "a"}},
+		wantCalls:    1,
+		wantConflict: true,
+	}, {
+		name:         "streamed responses are not kept",
+		header:       testIdempotencyHeader,
+		flush:        true,
+		requests:     []request{{rev: rev1, key: "a"}, {rev: rev1, key: "a"}},
+		wantCalls:    1,
+		wantConflict: true,
+	}, {
+		name:      "flushed responses of known length are kept",
+		header:    testIdempotencyHeader,
+		sized:     true,
+		flush:     true,
+		requests:  []request{{rev: rev1, key: "a"}, {rev: rev1, key: "a"}},
+		wantCalls: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := test.code
+			if code == 0 {
+				code = http.StatusCreated
+			}
+			var calls int32
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				body := []byte("call " + strconv.Itoa(int(n)))
+				if len(body) < test.bodySize {
+					body = append(body, bytes.Repeat([]byte{'.'}, test.bodySize-len(body))...)
+				}
+				w.Header().Set("X-Call", strconv.Itoa(int(n)))
+				if test.sized {
+					w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				}
+				w.WriteHeader(code)
+				if test.flush {
+					w.(http.Flusher).Flush()
+				}
+				w.Write(body)
+			})
+
+			fakeClock := clock.NewFakeClock(time.Now())
+			handler := NewDedupHandler(next)
+			handler.clock = fakeClock
+			ctx := dedupTestContext(t, test.header)
+
+			var first *httptest.ResponseRecorder
+			for i, req := range test.requests {
+				fakeClock.Step(req.advance)
+				resp := serveDedup(ctx, handler, req.rev, req.key, req.streamed)
+				if first != nil && test.wantConflict {
+					if resp.Code != http.StatusConflict {
+						t.Errorf("Request %d: StatusCode = %d, want: %d", i, resp.Code, http.StatusConflict)
+					}
+					continue
+				}
+				if resp.Code != code {
+					t.Errorf("Request %d: StatusCode = %d, want: %d", i, resp.Code, code)
+				}
+				if first == nil {
+					first = resp
+					continue
+				}
+				// Deduplicated requests get the exact same response.
+				if dedup := test.wantCalls == 1; dedup != (resp.Body.String() == first.Body.String()) {
+					t.Errorf("Request %d: Body = %q, first body = %q, want same: %v", i, resp.Body, first.Body, dedup)
+				}
+				if dedup := test.wantCalls == 1; dedup != (resp.Header().Get("X-Call") == first.Header().Get("X-Call")) {
+					t.Errorf("Request %d: X-Call = %q, first X-Call = %q, want same: %v", i,
+						resp.Header().Get("X-Call"), first.Header().Get("X-Call"), dedup)
+				}
+			}
+			if got := atomic.LoadInt32(&calls); got != test.wantCalls {
+				t.Errorf("Calls = %d, want: %d", got, test.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDedupHandlerConcurrentDuplicate(t *testing.T) {
+	rev := types.NamespacedName{Namespace: "ns", Name: "rev"}
+	release := make(chan struct{})
+	var calls int32
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Write([]byte("done"))
+	})
+	handler := NewDedupHandler(next)
+	ctx := dedupTestContext(t, testIdempotencyHeader)
+
+	firstDone := make(chan *httptest.ResponseRecorder)
+	go func() {
+		firstDone <- serveDedup(ctx, handler, rev, "a", false)
+	}()
+	// Wait for the first request to be in flight.
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	dupDone := make(chan *httptest.ResponseRecorder)
+	go func() {
+		dupDone <- serveDedup(ctx, handler, rev, "a", false)
+	}()
+
+	select {
+	case <-dupDone:
+		t.Fatal("Duplicate returned before the first request completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	for _, ch := range []chan *httptest.ResponseRecorder{firstDone, dupDone} {
+		if resp := <-ch; resp.Body.String() != "done" {
+			t.Errorf("Body = %q, want: %q", resp.Body, "done")
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Calls = %d, want: 1", got)
+	}
+}
+
+func TestDedupHandlerConcurrentPassthroughDuplicate(t *testing.T) {
+	rev := types.NamespacedName{Namespace: "ns", Name: "rev"}
+	release := make(chan struct{})
+	var calls int32
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Write(bytes.Repeat([]byte{'.'}, maxDedupBodyBytes+1))
+	})
+	handler := NewDedupHandler(next)
+	ctx := dedupTestContext(t, testIdempotencyHeader)
+
+	firstDone := make(chan *httptest.ResponseRecorder)
+	go func() {
+		firstDone <- serveDedup(ctx, handler, rev, "a", false)
+	}()
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	dupDone := make(chan *httptest.ResponseRecorder)
+	go func() {
+		dupDone <- serveDedup(ctx, handler, rev, "a", false)
+	}()
+	close(release)
+
+	if resp := <-firstDone; resp.Code != http.StatusOK || resp.Body.Len() != maxDedupBodyBytes+1 {
+		t.Errorf("StatusCode = %d, body length = %d, want: %d, %d", resp.Code, resp.Body.Len(), http.StatusOK, maxDedupBodyBytes+1)
+	}
+	if resp := <-dupDone; resp.Code != http.StatusConflict {
+		t.Errorf("Duplicate StatusCode = %d, want: %d", resp.Code, http.StatusConflict)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Calls = %d, want: 1", got)
+	}
+}
+
+func TestDedupHandlerPerClientHeaders(t *testing.T) {
+	rev := types.NamespacedName{Namespace: "ns", Name: "rev"}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=first")
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("done"))
+	})
+	handler := NewDedupHandler(next)
+	ctx := dedupTestContext(t, testIdempotencyHeader)
+
+	first := serveDedup(ctx, handler, rev, "a", false)
+	if got, want := first.Header().Get("Set-Cookie"), "session=first"; got != want {
+		t.Errorf("Set-Cookie = %q, want: %q", got, want)
+	}
+	dup := serveDedup(ctx, handler, rev, "a", false)
+	if got := dup.Header().Get("Set-Cookie"); got != "" {
+		t.Errorf("Duplicate Set-Cookie = %q, want it stripped", got)
+	}
+	if got, want := dup.Header().Get("Content-Type"), "text/plain"; got != want {
+		t.Errorf("Duplicate Content-Type = %q, want: %q", got, want)
+	}
+	if got, want := dup.Body.String(), "done"; got != want {
+		t.Errorf("Duplicate Body = %q, want: %q", got, want)
+	}
+}
+
+func TestDedupHandlerFlushesStreamedResponses(t *testing.T) {
+	rev := types.NamespacedName{Namespace: "ns", Name: "rev"}
+	flushed := make(chan struct{})
+	release := make(chan struct{})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("event"))
+		w.(http.Flusher).Flush()
+		close(flushed)
+		<-release
+	})
+	handler := NewDedupHandler(next)
+	ctx := dedupTestContext(t, testIdempotencyHeader)
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+	req.Header.Set(testIdempotencyHeader, "a")
+	req = req.WithContext(util.WithRevID(ctx, rev))
+	resp := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(resp, req)
+	}()
+
+	<-flushed
+	// The recorder isn't synchronized, so only look at it while the handler
+	// is blocked.
+	if !resp.Flushed || resp.Body.String() != "event" {
+		t.Errorf("Flushed = %v, Body = %q, want the flushed event", resp.Flushed, resp.Body)
+	}
+	close(release)
+	<-done
+}
+
+func dedupTestContext(t *testing.T, header string) context.Context {
+	store := setupConfigStore(t, logtesting.TestLogger(t))
+	data := map[string]string{}
+	if header != "" {
+		data["idempotency-key-header"] = header
+	}
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: activatorconfig.ActivatorConfigName,
+		},
+		Data: data,
+	})
+	return store.ToContext(context.Background())
+}
+
+func serveDedup(ctx context.Context, handler http.Handler, rev types.NamespacedName, key string, streamed bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+	if streamed {
+		req.ContentLength = -1
+	}
+	if key != "" {
+		req.Header.Set(testIdempotencyHeader, key)
+	}
+	req = req.WithContext(util.WithRevID(ctx, rev))
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	return resp
+}
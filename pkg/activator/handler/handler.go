@@ -21,6 +21,9 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.opencensus.io/plugin/ochttp"
 	"go.opencensus.io/trace"
@@ -34,9 +37,24 @@ import (
 	"knative.dev/serving/pkg/activator"
 	activatorconfig "knative.dev/serving/pkg/activator/config"
 	"knative.dev/serving/pkg/activator/util"
+	servinghandler "knative.dev/serving/pkg/http/handler"
 	"knative.dev/serving/pkg/queue"
 )
 
+// revisionTimeoutBody is the body of the response to the requests exceeding
+// the timeoutSeconds of their revision. It matches the queue-proxy's, so that
+// the client sees the same error whichever of the two times the request out.
+const revisionTimeoutBody = "request timeout"
+
+const (
+	// baggageHeaderName is the W3C header propagating the baggage of a trace.
+	baggageHeaderName = "baggage"
+
+	// bufferedBaggageKey is the key of the baggage entry carrying how long
+	// the activator buffered the request, in milliseconds.
+	bufferedBaggageKey = "knative-activator-buffered-ms"
+)
+
 // Throttler is the interface that Handler calls to Try to proxy the user request.
 type Throttler interface {
 	Try(context.Context, func(string) error) error
@@ -53,6 +71,7 @@ type activationHandler struct {
 
 // New constructs a new http.Handler that deals with revision activation.
 func New(ctx context.Context, t Throttler, transport http.RoundTripper) http.Handler {
+	transport = newRetryRoundTripper(transport)
 	return &activationHandler{
 		transport: transport,
 		tracingTransport: &ochttp.Transport{
@@ -65,20 +84,47 @@ func New(ctx context.Context, t Throttler, transport http.RoundTripper) http.Han
 }
 
 func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	config := activatorconfig.FromContext(r.Context())
+	if config.Activator.EnforceRevisionTimeout {
+		if rev := util.RevisionFrom(r.Context()); rev != nil && rev.Spec.TimeoutSeconds != nil {
+			// The timeout starts before the queue-proxy's, so it always expires
+			// first and the request is only ever timed out once.
+			timeout := time.Duration(*rev.Spec.TimeoutSeconds) * time.Second
+			servinghandler.NewTimeToFirstByteTimeoutHandler(http.HandlerFunc(a.serveHTTP),
+				revisionTimeoutBody, servinghandler.StaticTimeoutFunc(timeout)).ServeHTTP(w, r)
+			return
+		}
+	}
+	a.serveHTTP(w, r)
+}
+
+func (a *activationHandler) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	logger := logging.FromContext(r.Context())
-	tracingEnabled := activatorconfig.FromContext(r.Context()).Tracing.Backend != tracingconfig.None
+	config := activatorconfig.FromContext(r.Context())
+	tracingEnabled := config.Tracing.Backend != tracingconfig.None
 
 	tryContext, trySpan := r.Context(), (*trace.Span)(nil)
 	if tracingEnabled {
 		tryContext, trySpan = trace.StartSpan(r.Context(), "throttler_try")
 	}
 
+	// The baggage of the client is read once, so that each try of the
+	// throttler replaces the entry of the activator rather than adding one.
+	baggage := r.Header.Values(baggageHeaderName)
+
+	start := time.Now()
 	if err := a.throttler.Try(tryContext, func(dest string) error {
 		trySpan.End()
 
+		buffered := time.Since(start).Milliseconds()
+		if config.Activator.TraceBaggage {
+			r.Header.Set(baggageHeaderName, withBaggage(baggage, bufferedBaggageKey, strconv.FormatInt(buffered, 10)))
+		}
+
 		proxyCtx, proxySpan := r.Context(), (*trace.Span)(nil)
 		if tracingEnabled {
 			proxyCtx, proxySpan = trace.StartSpan(r.Context(), "activator_proxy")
+			proxySpan.AddAttributes(trace.Int64Attribute("activator.buffered_ms", buffered))
 		}
 		a.proxyRequest(logger, w, r.WithContext(proxyCtx), &url.URL{
 			Scheme: "http",
@@ -92,17 +138,30 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		trySpan.Annotate([]trace.Attribute{trace.StringAttribute("activator.throttler.error", err.Error())}, "ThrottlerTry")
 		trySpan.End()
 
-		logger.Errorw("Throttler try error", zap.Error(err))
-
 		switch err {
+		case context.Canceled:
+			// The client went away while the request was buffered, nobody is
+			// left to read the response.
+			logger.Debugw("Request canceled while waiting for capacity", zap.Error(err))
+			w.WriteHeader(http.StatusServiceUnavailable)
 		case context.DeadlineExceeded, queue.ErrRequestQueueFull:
+			logger.Errorw("Throttler try error", zap.Error(err))
 			http.Error(w, err.Error(), http.StatusServiceUnavailable)
 		default:
+			logger.Errorw("Throttler try error", zap.Error(err))
 			w.WriteHeader(http.StatusInternalServerError)
 		}
 	}
 }
 
+// withBaggage returns the baggage of the request, which is propagated along
+// with its trace context, with the entry appended. The given baggage is left
+// unchanged.
+func withBaggage(baggage []string, key, value string) string {
+	entries := append(baggage[:len(baggage):len(baggage)], key+"="+value)
+	return strings.Join(entries, ",")
+}
+
 func (a *activationHandler) proxyRequest(logger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, target *url.URL, tracingEnabled bool) {
 	network.RewriteHostIn(r)
 	r.Header.Set(network.ProxyHeaderName, activator.Name)
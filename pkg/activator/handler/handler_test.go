@@ -25,9 +25,12 @@ import (
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"go.opencensus.io/plugin/ochttp/propagation/b3"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
@@ -105,6 +108,12 @@ func TestActivationHandler(t *testing.T) {
 		wantCode:  http.StatusServiceUnavailable,
 		wantErr:   nil,
 		throttler: fakeThrottler{err: queue.ErrRequestQueueFull},
+	}, {
+		name:      "client went away while buffered",
+		wantBody:  "",
+		wantCode:  http.StatusServiceUnavailable,
+		wantErr:   nil,
+		throttler: fakeThrottler{err: context.Canceled},
 	}}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -190,6 +199,92 @@ func TestActivationHandlerProxyHeader(t *testing.T) {
 	}
 }
 
+type blockingThrottler struct{}
+
+func (blockingThrottler) Try(ctx context.Context, f func(string) error) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestActivationHandlerRevisionTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		enforce   string
+		throttler Throttler
+		upstream  *activatortest.FakeResponse
+		wantCode  int
+		wantBody  string
+	}{{
+		name:      "enforced, waiting for capacity",
+		enforce:   "true",
+		throttler: blockingThrottler{},
+		wantCode:  http.StatusGatewayTimeout,
+		wantBody:  revisionTimeoutBody,
+	}, {
+		name:      "enforced, proxied in time",
+		enforce:   "true",
+		throttler: fakeThrottler{},
+		upstream:  &activatortest.FakeResponse{Code: http.StatusOK, Body: wantBody},
+		wantCode:  http.StatusOK,
+		wantBody:  wantBody,
+	}, {
+		name:      "enforced, timed out by the queue-proxy",
+		enforce:   "true",
+		throttler: fakeThrottler{},
+		upstream:  &activatortest.FakeResponse{Code: http.StatusGatewayTimeout, Body: revisionTimeoutBody},
+		wantCode:  http.StatusGatewayTimeout,
+		wantBody:  revisionTimeoutBody,
+	}, {
+		name:      "not enforced, waiting for capacity",
+		enforce:   "false",
+		throttler: blockingThrottler{},
+		wantCode:  http.StatusServiceUnavailable,
+		wantBody:  context.DeadlineExceeded.Error() + "\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fakeRT := activatortest.FakeRoundTripper{RequestResponse: test.upstream}
+			rt := pkgnet.RoundTripperFunc(fakeRT.RT)
+
+			ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
+			defer cancel()
+			handler := New(ctx, test.throttler, rt)
+
+			configStore := setupConfigStore(t, logging.FromContext(ctx))
+			configStore.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: activatorconfig.ActivatorConfigName,
+				},
+				Data: map[string]string{
+					"enforce-revision-timeout": test.enforce,
+				},
+			})
+
+			rev := revision(testNamespace, testRevName)
+			rev.Spec.TimeoutSeconds = ptr.Int64(1)
+
+			// The client gives up after the revision timeout.
+			reqCtx, reqCancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+			defer reqCancel()
+			reqCtx = configStore.ToContext(reqCtx)
+			reqCtx = util.WithRevision(reqCtx, rev)
+			reqCtx = util.WithRevID(reqCtx, types.NamespacedName{Namespace: testNamespace, Name: testRevName})
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+			handler.ServeHTTP(resp, req.WithContext(reqCtx))
+
+			if resp.Code != test.wantCode {
+				t.Errorf("Unexpected response status. Want %d, got %d", test.wantCode, resp.Code)
+			}
+			if got := resp.Body.String(); got != test.wantBody {
+				t.Errorf("Unexpected response body. Response body %q, want %q", got, test.wantBody)
+			}
+		})
+	}
+}
+
 func TestActivationHandlerTraceSpans(t *testing.T) {
 	testcases := []struct {
 		name         string
@@ -269,6 +364,159 @@ func TestActivationHandlerTraceSpans(t *testing.T) {
 	}
 }
 
+// bufferingThrottler buffers the requests for delay before proxying them.
+type bufferingThrottler struct {
+	delay time.Duration
+}
+
+func (bt bufferingThrottler) Try(ctx context.Context, f func(string) error) error {
+	time.Sleep(bt.delay)
+	return f("10.10.10.10:1234")
+}
+
+func TestActivationHandlerTraceBaggage(t *testing.T) {
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+		delay    = 50 * time.Millisecond
+	)
+	tests := []struct {
+		name        string
+		baggage     string
+		wantBaggage bool
+	}{{
+		name: "baggage disabled",
+	}, {
+		name:        "baggage enabled",
+		baggage:     "true",
+		wantBaggage: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			interceptCh := make(chan *http.Request, 1)
+			rt := pkgnet.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				interceptCh <- r
+				return httptest.NewRecorder().Result(), nil
+			})
+
+			reporter, co := tracetesting.FakeZipkinExporter()
+			oct := tracing.NewOpenCensusTracer(co)
+			tracingCM := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: tracingconfig.ConfigName,
+				},
+				Data: map[string]string{
+					"zipkin-endpoint": "localhost:1234",
+					"backend":         string(tracingconfig.Zipkin),
+					"debug":           "true",
+				},
+			}
+			cfg, err := tracingconfig.NewTracingConfigFromConfigMap(tracingCM)
+			if err != nil {
+				t.Fatal("Failed to generate config:", err)
+			}
+			if err := oct.ApplyConfig(cfg); err != nil {
+				t.Fatal("Failed to apply tracer config:", err)
+			}
+
+			ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
+			defer func() {
+				cancel()
+				reporter.Close()
+				oct.Finish()
+			}()
+
+			configStore := setupConfigStore(t, logging.FromContext(ctx))
+			configStore.OnConfigChanged(tracingCM)
+			configStore.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: activatorconfig.ActivatorConfigName,
+				},
+				Data: map[string]string{
+					"trace-baggage": test.baggage,
+				},
+			})
+
+			// The activator's handler chain extracts the client's trace
+			// context before the request is buffered.
+			handler := tracing.HTTPSpanMiddleware(New(ctx, bufferingThrottler{delay: delay}, rt))
+			req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+			req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+			req.Header.Set(baggageHeaderName, "user=alice")
+			reqCtx := configStore.ToContext(req.Context())
+			reqCtx = util.WithRevID(reqCtx, types.NamespacedName{Namespace: testNamespace, Name: testRevName})
+			handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(reqCtx))
+
+			var upstream *http.Request
+			select {
+			case upstream = <-interceptCh:
+			case <-time.After(time.Second):
+				t.Fatal("Timed out waiting for a request to be intercepted")
+			}
+
+			if got := upstream.Header.Get(b3.TraceIDHeader); got != traceID {
+				t.Errorf("%s = %q, want the client's trace: %q", b3.TraceIDHeader, got, traceID)
+			}
+			if got := upstream.Header.Get(b3.SpanIDHeader); got == "" || got == parentID {
+				t.Errorf("%s = %q, want a span of the activator", b3.SpanIDHeader, got)
+			}
+
+			// The span of the proxying, after the buffering, is in the
+			// client's trace.
+			found := false
+			for _, span := range reporter.Flush() {
+				if span.Name != "activator_proxy" {
+					continue
+				}
+				found = true
+				if got := span.TraceID.String(); got != traceID {
+					t.Errorf("activator_proxy span in trace %q, want: %q", got, traceID)
+				}
+				if _, ok := span.Tags["activator.buffered_ms"]; !ok {
+					t.Errorf("activator_proxy span tags = %v, want the buffered time", span.Tags)
+				}
+			}
+			if !found {
+				t.Error("No activator_proxy span reported")
+			}
+
+			baggage := upstream.Header.Get(baggageHeaderName)
+			if !test.wantBaggage {
+				if baggage != "user=alice" {
+					t.Errorf("Baggage = %q, want the client's unchanged", baggage)
+				}
+				return
+			}
+			prefix := "user=alice," + bufferedBaggageKey + "="
+			if !strings.HasPrefix(baggage, prefix) {
+				t.Fatalf("Baggage = %q, want prefix %q", baggage, prefix)
+			}
+			if ms, err := strconv.Atoi(strings.TrimPrefix(baggage, prefix)); err != nil {
+				t.Errorf("Baggage = %q, want a number of milliseconds: %v", baggage, err)
+			} else if buffered := time.Duration(ms) * time.Millisecond; buffered < delay {
+				t.Errorf("Buffered for %v, want at least %v", buffered, delay)
+			}
+		})
+	}
+}
+
+func TestWithBaggage(t *testing.T) {
+	baggage := make([]string, 1, 2)
+	baggage[0] = "user=alice"
+
+	// Each try of the throttler replaces the entry of the previous one.
+	for _, value := range []string{"10", "20"} {
+		if got, want := withBaggage(baggage, bufferedBaggageKey, value),
+			"user=alice,"+bufferedBaggageKey+"="+value; got != want {
+			t.Errorf("withBaggage = %q, want: %q", got, want)
+		}
+	}
+	if got, want := strings.Join(baggage[:cap(baggage)], ","), "user=alice,"; got != want {
+		t.Errorf("baggage = %q, want unchanged: %q", got, want)
+	}
+}
+
 func sendRequest(namespace, revName string, handler http.Handler, store *activatorconfig.Store) *httptest.ResponseRecorder {
 	resp := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
@@ -296,6 +544,8 @@ func revision(namespace, name string) *v1.Revision {
 
 func setupConfigStore(t *testing.T, logger *zap.SugaredLogger) *activatorconfig.Store {
 	configStore := activatorconfig.NewStore(logger)
+	activatorConfig := ConfigMapFromTestFile(t, activatorconfig.ActivatorConfigName)
+	configStore.OnConfigChanged(activatorConfig)
 	tracingConfig := ConfigMapFromTestFile(t, tracingconfig.ConfigName)
 	configStore.OnConfigChanged(tracingConfig)
 	return configStore
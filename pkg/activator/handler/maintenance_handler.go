@@ -0,0 +1,42 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+
+	"knative.dev/serving/pkg/activator"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+)
+
+// MaintenanceHandler answers the requests for a Route in maintenance mode with
+// the configured maintenance response, without scaling up the revision.
+type MaintenanceHandler struct {
+	NextHandler http.Handler
+}
+
+func (h *MaintenanceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(activator.MaintenanceHeaderName) == "" {
+		h.NextHandler.ServeHTTP(w, r)
+		return
+	}
+
+	cfg := activatorconfig.FromContext(r.Context()).Activator
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	w.Write([]byte(cfg.MaintenanceResponse))
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/activator"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+)
+
+func TestMaintenanceHandler(t *testing.T) {
+	const page = "<h1>Back soon</h1>"
+	tests := []struct {
+		name        string
+		maintenance bool
+		wantCode    int
+		wantBody    string
+	}{{
+		name:     "not in maintenance",
+		wantCode: http.StatusOK,
+		wantBody: "served",
+	}, {
+		name:        "in maintenance",
+		maintenance: true,
+		wantCode:    http.StatusServiceUnavailable,
+		wantBody:    page,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			served := false
+			handler := &MaintenanceHandler{
+				NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					served = true
+					w.Write([]byte("served"))
+				}),
+			}
+
+			store := setupConfigStore(t, logtesting.TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: activatorconfig.ActivatorConfigName,
+				},
+				Data: map[string]string{
+					"maintenance-response": page,
+				},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			if test.maintenance {
+				req.Header.Set(activator.MaintenanceHeaderName, "true")
+			}
+			req = req.WithContext(store.ToContext(context.Background()))
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != test.wantCode {
+				t.Errorf("StatusCode = %d, want: %d", resp.Code, test.wantCode)
+			}
+			if got := resp.Body.String(); got != test.wantBody {
+				t.Errorf("Body = %q, want: %q", got, test.wantBody)
+			}
+			if served == test.maintenance {
+				t.Errorf("Next handler called = %v, want: %v", served, !test.maintenance)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"time"
+
+	"go.uber.org/atomic"
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/logging"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	"knative.dev/serving/pkg/activator/util"
+)
+
+// memorySampleInterval is how often the memory held by the activator is
+// sampled. Reading it stops the world, so it isn't done per request.
+const memorySampleInterval = time.Second
+
+// memoryShedBody is the body of the responses to the shed requests.
+const memoryShedBody = "activator is under memory pressure"
+
+// RevisionCapacity is the interface that MemoryShedHandler calls to know
+// whether the requests for a revision would be buffered.
+type RevisionCapacity interface {
+	Capacity(types.NamespacedName) int
+}
+
+// MemoryShedHandler answers with a 503 the requests for the revisions without
+// capacity, which would be buffered until they scale from zero, while the
+// memory held by the activator is over the configured threshold.
+type MemoryShedHandler struct {
+	nextHandler http.Handler
+	capacity    RevisionCapacity
+	readMemory  func() uint64
+	inUse       atomic.Uint64
+}
+
+// NewMemoryShedHandler creates a MemoryShedHandler, which samples the memory
+// held by the activator until the context is done.
+func NewMemoryShedHandler(ctx context.Context, capacity RevisionCapacity, next http.Handler) *MemoryShedHandler {
+	h := newMemoryShedHandler(capacity, next, readMemoryInUse)
+	go h.run(ctx.Done())
+	return h
+}
+
+func newMemoryShedHandler(capacity RevisionCapacity, next http.Handler, readMemory func() uint64) *MemoryShedHandler {
+	h := &MemoryShedHandler{
+		nextHandler: next,
+		capacity:    capacity,
+		readMemory:  readMemory,
+	}
+	h.sample()
+	return h
+}
+
+func (h *MemoryShedHandler) run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(memorySampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			h.sample()
+		}
+	}
+}
+
+func (h *MemoryShedHandler) sample() {
+	h.inUse.Store(h.readMemory())
+}
+
+func (h *MemoryShedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	threshold := activatorconfig.FromContext(r.Context()).Activator.MemorySheddingThreshold
+	if threshold == 0 || h.inUse.Load() <= uint64(threshold) {
+		h.nextHandler.ServeHTTP(w, r)
+		return
+	}
+
+	revID := util.RevIDFrom(r.Context())
+	if h.capacity.Capacity(revID) > 0 {
+		h.nextHandler.ServeHTTP(w, r)
+		return
+	}
+	logging.FromContext(r.Context()).Warnf("Shedding request for revision %s, memory in use %d is over %d",
+		revID, h.inUse.Load(), threshold)
+	http.Error(w, memoryShedBody, http.StatusServiceUnavailable)
+}
+
+// readMemoryInUse returns the memory the Go runtime holds from the OS.
+func readMemoryInUse() uint64 {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.Sys - ms.HeapReleased
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	logtesting "knative.dev/pkg/logging/testing"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	"knative.dev/serving/pkg/activator/util"
+)
+
+type fakeRevisionCapacity map[types.NamespacedName]int
+
+func (f fakeRevisionCapacity) Capacity(revID types.NamespacedName) int {
+	return f[revID]
+}
+
+func TestMemoryShedHandler(t *testing.T) {
+	const mi = 1 << 20
+	scaledToZero := types.NamespacedName{Namespace: testNamespace, Name: "zero"}
+	scaledUp := types.NamespacedName{Namespace: testNamespace, Name: "up"}
+	capacity := fakeRevisionCapacity{scaledUp: 10}
+
+	tests := []struct {
+		name      string
+		threshold string
+		inUse     uint64
+		revID     types.NamespacedName
+		wantCode  int
+	}{{
+		name:      "disabled",
+		threshold: "0",
+		inUse:     900 * mi,
+		revID:     scaledToZero,
+		wantCode:  http.StatusOK,
+	}, {
+		name:      "under threshold",
+		threshold: "800Mi",
+		inUse:     700 * mi,
+		revID:     scaledToZero,
+		wantCode:  http.StatusOK,
+	}, {
+		name:      "over threshold, revision with capacity",
+		threshold: "800Mi",
+		inUse:     900 * mi,
+		revID:     scaledUp,
+		wantCode:  http.StatusOK,
+	}, {
+		name:      "over threshold, revision scaled to zero",
+		threshold: "800Mi",
+		inUse:     900 * mi,
+		revID:     scaledToZero,
+		wantCode:  http.StatusServiceUnavailable,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			served := false
+			handler := newMemoryShedHandler(capacity, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served = true
+			}), func() uint64 { return test.inUse })
+
+			store := setupConfigStore(t, logtesting.TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: activatorconfig.ActivatorConfigName,
+				},
+				Data: map[string]string{
+					"memory-shedding-threshold": test.threshold,
+				},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			ctx := util.WithRevID(store.ToContext(context.Background()), test.revID)
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req.WithContext(ctx))
+
+			if resp.Code != test.wantCode {
+				t.Errorf("StatusCode = %d, want: %d", resp.Code, test.wantCode)
+			}
+			if shed := test.wantCode == http.StatusServiceUnavailable; served == shed {
+				t.Errorf("Next handler called = %v, want: %v", served, !shed)
+			}
+		})
+	}
+}
+
+func TestMemoryShedHandlerSamples(t *testing.T) {
+	inUse := uint64(100)
+	handler := newMemoryShedHandler(fakeRevisionCapacity{}, http.NotFoundHandler(), func() uint64 { return inUse })
+	if got, want := handler.inUse.Load(), uint64(100); got != want {
+		t.Errorf("inUse = %d, want: %d", got, want)
+	}
+
+	inUse = 200
+	handler.sample()
+	if got, want := handler.inUse.Load(), uint64(200); got != want {
+		t.Errorf("inUse = %d after sampling, want: %d", got, want)
+	}
+
+	if readMemoryInUse() == 0 {
+		t.Error("readMemoryInUse() = 0, want: the memory held by the runtime")
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"syscall"
+
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	"knative.dev/serving/pkg/queue"
+)
+
+// maxReplayBodyBytes is the size up to which the body of a request is
+// buffered so that it can be sent again. Larger requests aren't retried.
+const maxReplayBodyBytes = 64 * 1024
+
+// retryRoundTripper retries requests to the upstream according to the
+// policy configured in the activator config attached to the request context.
+// Connection errors and 5xx responses are treated as distinct error classes
+// so that each can have its own retry budget. Only the failures that happened
+// before the request was sent are retried for non-idempotent methods.
+type retryRoundTripper struct {
+	next http.RoundTripper
+}
+
+func newRetryRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return &retryRoundTripper{next: next}
+}
+
+func (rt *retryRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	cfg := activatorconfig.FromContext(r.Context()).Activator
+	connRetries, serverRetries := cfg.ConnectionErrorRetries, cfg.ServerErrorRetries
+	if connRetries <= 0 && serverRetries <= 0 {
+		return rt.next.RoundTrip(r)
+	}
+
+	// The body of a proxied request can only be read once, so keep a copy of
+	// it to be able to send it again.
+	body, replayable, err := bufferBody(r)
+	if err != nil {
+		return nil, err
+	}
+	if !replayable {
+		return rt.next.RoundTrip(r)
+	}
+	idempotent := queue.IsIdempotent(r.Method)
+
+	for {
+		resp, err := rt.next.RoundTrip(withBody(r, body))
+		switch {
+		case err != nil && connRetries > 0 && (isNotSentError(err) || (idempotent && isConnectionError(err))):
+			connRetries--
+		case err == nil && idempotent && resp.StatusCode >= http.StatusInternalServerError && serverRetries > 0:
+			serverRetries--
+			// Drain the body to allow the connection to be reused.
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		default:
+			return resp, err
+		}
+
+		// Once the client went away nobody is waiting for the response, so
+		// don't spend the retries dialing the backend on its behalf.
+		if err := r.Context().Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isConnectionError returns true if err indicates that we failed to establish
+// or keep a connection to the upstream, as opposed to the upstream responding
+// with an error.
+func isConnectionError(err error) bool {
+	return isNotSentError(err) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// isNotSentError returns true if err indicates that the connection to the
+// upstream couldn't be established, so the request was never sent and can be
+// sent again whatever its method.
+func isNotSentError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// bufferBody reads the body of the request, when it's small enough to be
+// sent again, and returns it along with whether the request can be replayed.
+// When it's too large, the body of the request is restored to be sent once.
+func bufferBody(r *http.Request) ([]byte, bool, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true, nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxReplayBodyBytes+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(body) > maxReplayBodyBytes {
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		return nil, false, nil
+	}
+	return body, true, nil
+}
+
+// withBody returns a copy of the request sending the buffered body.
+func withBody(r *http.Request, body []byte) *http.Request {
+	if r.Body == nil || r.Body == http.NoBody {
+		return r
+	}
+	r = r.Clone(r.Context())
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return r
+}
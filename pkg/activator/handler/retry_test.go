@@ -0,0 +1,277 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	logtesting "knative.dev/pkg/logging/testing"
+	pkgnet "knative.dev/pkg/network"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{{
+		name: "connection refused",
+		err:  &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+		want: true,
+	}, {
+		name: "connection reset",
+		err:  &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+		want: true,
+	}, {
+		name: "dial timeout",
+		err:  &net.OpError{Op: "dial", Err: errors.New("i/o timeout")},
+		want: true,
+	}, {
+		name: "other error",
+		err:  errors.New("something else"),
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isConnectionError(test.err); got != test.want {
+				t.Errorf("isConnectionError(%v) = %v, want: %v", test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRetryRoundTripper(t *testing.T) {
+	connRefused := &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	connReset := &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	largeBody := strings.Repeat("x", maxReplayBodyBytes+1)
+
+	tests := []struct {
+		name          string
+		method        string
+		connRetries   string
+		serverRetries string
+		body          string
+		responses     []error // nil means a 500 response, errOK a 200.
+		wantAttempts  int
+		wantErr       bool
+		wantCode      int
+	}{{
+		name:         "no retries configured, connection error",
+		responses:    []error{connRefused, errOK},
+		wantAttempts: 1,
+		wantErr:      true,
+	}, {
+		name:         "no retries configured, server error",
+		responses:    []error{nil, errOK},
+		wantAttempts: 1,
+		wantCode:     http.StatusInternalServerError,
+	}, {
+		name:         "retry connection errors but not server errors, connection error",
+		connRetries:  "2",
+		responses:    []error{connRefused, connRefused, errOK},
+		wantAttempts: 3,
+		wantCode:     http.StatusOK,
+	}, {
+		name:         "retry connection errors but not server errors, server error",
+		connRetries:  "2",
+		responses:    []error{nil, errOK},
+		wantAttempts: 1,
+		wantCode:     http.StatusInternalServerError,
+	}, {
+		name:         "connection retries exhausted",
+		connRetries:  "1",
+		responses:    []error{connRefused, connRefused, errOK},
+		wantAttempts: 2,
+		wantErr:      true,
+	}, {
+		name:          "retry server errors but not connection errors, server error",
+		serverRetries: "1",
+		responses:     []error{nil, errOK},
+		wantAttempts:  2,
+		wantCode:      http.StatusOK,
+	}, {
+		name:          "retry server errors but not connection errors, connection error",
+		serverRetries: "1",
+		responses:     []error{connRefused, errOK},
+		wantAttempts:  1,
+		wantErr:       true,
+	}, {
+		name:          "server retries exhausted",
+		serverRetries: "1",
+		responses:     []error{nil, nil, errOK},
+		wantAttempts:  2,
+		wantCode:      http.StatusInternalServerError,
+	}, {
+		name:          "body replayed",
+		method:        http.MethodPut,
+		serverRetries: "1",
+		body:          "payload",
+		responses:     []error{nil, errOK},
+		wantAttempts:  2,
+		wantCode:      http.StatusOK,
+	}, {
+		name:          "body too large to be replayed",
+		method:        http.MethodPut,
+		connRetries:   "1",
+		serverRetries: "1",
+		body:          largeBody,
+		responses:     []error{nil, errOK},
+		wantAttempts:  1,
+		wantCode:      http.StatusInternalServerError,
+	}, {
+		name:          "non-idempotent server error not retried",
+		method:        http.MethodPost,
+		serverRetries: "1",
+		body:          "payload",
+		responses:     []error{nil, errOK},
+		wantAttempts:  1,
+		wantCode:      http.StatusInternalServerError,
+	}, {
+		name:         "non-idempotent request never sent retried",
+		method:       http.MethodPost,
+		connRetries:  "1",
+		body:         "payload",
+		responses:    []error{connRefused, errOK},
+		wantAttempts: 2,
+		wantCode:     http.StatusOK,
+	}, {
+		name:         "non-idempotent connection reset not retried",
+		method:       http.MethodPost,
+		connRetries:  "1",
+		body:         "payload",
+		responses:    []error{connReset, errOK},
+		wantAttempts: 1,
+		wantErr:      true,
+	}, {
+		name:         "idempotent connection reset retried",
+		connRetries:  "1",
+		responses:    []error{connReset, errOK},
+		wantAttempts: 2,
+		wantCode:     http.StatusOK,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			rt := newRetryRoundTripper(pkgnet.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				resp := test.responses[attempts]
+				attempts++
+
+				if r.Body != nil {
+					if got, err := ioutil.ReadAll(r.Body); err != nil {
+						t.Fatal("Error reading body:", err)
+					} else if string(got) != test.body {
+						t.Errorf("Attempt %d body has %d bytes, want: %d", attempts, len(got), len(test.body))
+					}
+				}
+
+				rec := httptest.NewRecorder()
+				switch resp {
+				case errOK:
+					rec.WriteHeader(http.StatusOK)
+				case nil:
+					rec.WriteHeader(http.StatusInternalServerError)
+				default:
+					return nil, resp
+				}
+				return rec.Result(), nil
+			}))
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			// Like the proxied server requests, the request has no GetBody.
+			req := httptest.NewRequest(method, "http://example.com", strings.NewReader(test.body))
+			if test.body == "" {
+				req.Body = http.NoBody
+			}
+			data := map[string]string{}
+			if test.connRetries != "" {
+				data["connection-error-retries"] = test.connRetries
+			}
+			if test.serverRetries != "" {
+				data["server-error-retries"] = test.serverRetries
+			}
+			store := setupConfigStore(t, logtesting.TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: activatorconfig.ActivatorConfigName,
+				},
+				Data: data,
+			})
+			req = req.WithContext(store.ToContext(req.Context()))
+
+			resp, err := rt.RoundTrip(req)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("RoundTrip() = %v, wantErr = %v", err, test.wantErr)
+			}
+			if err == nil && resp.StatusCode != test.wantCode {
+				t.Errorf("StatusCode = %d, want: %d", resp.StatusCode, test.wantCode)
+			}
+			if attempts != test.wantAttempts {
+				t.Errorf("Attempts = %d, want: %d", attempts, test.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryRoundTripperClientCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	rt := newRetryRoundTripper(pkgnet.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		attempts++
+		// The client goes away while the backend is being dialed.
+		cancel()
+		return nil, &net.OpError{Op: "dial", Err: context.Canceled}
+	}))
+
+	store := setupConfigStore(t, logtesting.TestLogger(t))
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: activatorconfig.ActivatorConfigName,
+		},
+		Data: map[string]string{
+			"connection-error-retries": "3",
+			"server-error-retries":     "3",
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	req = req.WithContext(store.ToContext(ctx))
+
+	if _, err := rt.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("RoundTrip() = %v, want: %v", err, context.Canceled)
+	}
+	if attempts != 1 {
+		t.Errorf("Attempts = %d, want: 1", attempts)
+	}
+}
+
+// errOK is a sentinel used in the table above to signal a successful response.
+var errOK = errors.New("ok")
@@ -61,11 +61,14 @@ type revisionDestsUpdate struct {
 	Rev           types.NamespacedName
 	ClusterIPDest string
 	Dests         sets.String
+	// Zones are the zones of the Dests, when known.
+	Zones map[string]string
 }
 
 type dests struct {
 	ready    sets.String
 	notReady sets.String
+	zones    map[string]string
 }
 
 func (d dests) becameNonReady(prev dests) sets.String {
@@ -101,6 +104,8 @@ type revisionWatcher struct {
 	healthyPods sets.String
 	// Stores whether the service ClusterIP has been seen as healthy.
 	clusterIPHealthy bool
+	// Stores the zones of the pods, as of the last endpoints update.
+	zones map[string]string
 
 	transport     http.RoundTripper
 	destsCh       chan dests
@@ -244,7 +249,7 @@ func (rw *revisionWatcher) sendUpdate(clusterIP string, dests sets.String) {
 	case <-rw.stopCh:
 		return
 	default:
-		rw.updateCh <- revisionDestsUpdate{Rev: rw.rev, ClusterIPDest: clusterIP, Dests: dests}
+		rw.updateCh <- revisionDestsUpdate{Rev: rw.rev, ClusterIPDest: clusterIP, Dests: dests, Zones: rw.zones}
 	}
 }
 
@@ -359,6 +364,7 @@ func (rw *revisionWatcher) run(probeFrequency time.Duration) {
 			return
 		case x := <-rw.destsCh:
 			prevDests, curDests = curDests, x
+			rw.zones = x.zones
 		case <-tickCh:
 		}
 
@@ -380,17 +386,20 @@ type revisionBackendsManager struct {
 	transport      http.RoundTripper
 	logger         *zap.SugaredLogger
 	probeFrequency time.Duration
+
+	// zones resolves the zones of the pods. Nil when the zones aren't needed.
+	zones *zoneResolver
 }
 
 // NewRevisionBackendsManager returns a new RevisionBackendsManager with default
 // probe time out.
-func newRevisionBackendsManager(ctx context.Context, tr http.RoundTripper) *revisionBackendsManager {
-	return newRevisionBackendsManagerWithProbeFrequency(ctx, tr, defaultProbeFrequency)
+func newRevisionBackendsManager(ctx context.Context, tr http.RoundTripper, zones *zoneResolver) *revisionBackendsManager {
+	return newRevisionBackendsManagerWithProbeFrequency(ctx, tr, zones, defaultProbeFrequency)
 }
 
 // newRevisionBackendsManagerWithProbeFrequency creates a fully spec'd RevisionBackendsManager.
 func newRevisionBackendsManagerWithProbeFrequency(ctx context.Context, tr http.RoundTripper,
-	probeFreq time.Duration) *revisionBackendsManager {
+	zones *zoneResolver, probeFreq time.Duration) *revisionBackendsManager {
 	rbm := &revisionBackendsManager{
 		ctx:              ctx,
 		revisionLister:   revisioninformer.Get(ctx).Lister(),
@@ -400,6 +409,7 @@ func newRevisionBackendsManagerWithProbeFrequency(ctx context.Context, tr http.R
 		transport:        tr,
 		logger:           logging.FromContext(ctx),
 		probeFrequency:   probeFreq,
+		zones:            zones,
 	}
 	endpointsInformer := endpointsinformer.Get(ctx)
 	endpointsInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
@@ -488,12 +498,13 @@ func (rbm *revisionBackendsManager) endpointsUpdated(newObj interface{}) {
 		logger.Errorw("Failed to get revision watcher", zap.Error(err))
 		return
 	}
-	ready, notReady := endpointsToDests(endpoints, networking.ServicePortName(rw.protocol))
+	portName := networking.ServicePortName(rw.protocol)
+	ready, notReady := endpointsToDests(endpoints, portName)
 	logger.Debugf("Updating Endpoints: ready backends: %d, not-ready backends: %d", len(ready), len(notReady))
 	select {
 	case <-rbm.ctx.Done():
 		return
-	case rw.destsCh <- dests{ready: ready, notReady: notReady}:
+	case rw.destsCh <- dests{ready: ready, notReady: notReady, zones: rbm.zones.destZones(endpoints, portName)}:
 	}
 }
 
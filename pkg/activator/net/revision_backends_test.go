@@ -710,7 +710,7 @@ func TestRevisionBackendManagerAddEndpoint(t *testing.T) {
 				t.Fatal("Failed to start informers:", err)
 			}
 
-			rbm := newRevisionBackendsManagerWithProbeFrequency(ctx, rt, probeFreq)
+			rbm := newRevisionBackendsManagerWithProbeFrequency(ctx, rt, nil /*zones*/, probeFreq)
 			defer func() {
 				cancel()
 				waitInformers()
@@ -1168,7 +1168,7 @@ func TestRevisionDeleted(t *testing.T) {
 	ri.Informer().GetIndexer().Add(rev)
 
 	fakeRT := activatortest.FakeRoundTripper{}
-	rbm := newRevisionBackendsManagerWithProbeFrequency(ctx, network.RoundTripperFunc(fakeRT.RT), probeFreq)
+	rbm := newRevisionBackendsManagerWithProbeFrequency(ctx, network.RoundTripperFunc(fakeRT.RT), nil /*zones*/, probeFreq)
 	defer func() {
 		cancel()
 		waitInformers()
@@ -1224,7 +1224,7 @@ func TestServiceDoesNotExist(t *testing.T) {
 			}},
 		},
 	}
-	rbm := newRevisionBackendsManagerWithProbeFrequency(ctx, network.RoundTripperFunc(fakeRT.RT), probeFreq)
+	rbm := newRevisionBackendsManagerWithProbeFrequency(ctx, network.RoundTripperFunc(fakeRT.RT), nil /*zones*/, probeFreq)
 	defer func() {
 		cancel()
 		waitInformers()
@@ -1288,7 +1288,7 @@ func TestServiceMoreThanOne(t *testing.T) {
 			}},
 		},
 	}
-	rbm := newRevisionBackendsManagerWithProbeFrequency(ctx, network.RoundTripperFunc(fakeRT.RT), probeFreq)
+	rbm := newRevisionBackendsManagerWithProbeFrequency(ctx, network.RoundTripperFunc(fakeRT.RT), nil /*zones*/, probeFreq)
 	defer func() {
 		cancel()
 		waitInformers()
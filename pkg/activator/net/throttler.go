@@ -22,6 +22,7 @@ import (
 	"math/rand"
 	"sort"
 	"sync"
+	"time"
 
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
@@ -29,17 +30,21 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1informers "k8s.io/client-go/informers/core/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	"knative.dev/networking/pkg/apis/networking"
 	endpointsinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/endpoints"
 	serviceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/service"
+	kubeinformerfactory "knative.dev/pkg/client/injection/kube/informers/factory"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/logging/logkey"
 	"knative.dev/pkg/network"
 	"knative.dev/pkg/reconciler"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
 	"knative.dev/serving/pkg/activator/util"
 	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
@@ -62,9 +67,15 @@ const (
 	revisionMaxConcurrency = queue.MaxBreakerCapacity
 )
 
+// zoneRetryInterval is how often the zone of the activator is looked up until
+// it is known, e.g. while its node isn't labeled yet. A var for testing.
+var zoneRetryInterval = 5 * time.Second
+
 type podTracker struct {
 	dest string
 	b    breaker
+	// zone is the zone of the pod, empty if unknown.
+	zone string
 	// weight is used for LB policy implementations.
 	weight atomic.Int32
 }
@@ -142,6 +153,17 @@ type revisionThrottler struct {
 	// This is a subset of podIPTrackers.
 	assignedTrackers []*podTracker
 
+	// The assigned trackers in the zone of this Activator.
+	// This is a subset of assignedTrackers.
+	localTrackers []*podTracker
+
+	// zone is shared with the Throttler and holds the zone of this
+	// Activator, empty until it is known. Nil means it is never known.
+	zone *atomic.String
+	// preferLocalZone is shared with the Throttler and reflects whether the
+	// localTrackers are preferred. Nil means they aren't.
+	preferLocalZone *atomic.Bool
+
 	// If we don't have a healthy clusterIPTracker this is set to nil, otherwise
 	// it is the l4dest for this revision's private clusterIP.
 	clusterIPTracker *podTracker
@@ -194,12 +216,33 @@ func (rt *revisionThrottler) acquireDest(ctx context.Context) (func(), *podTrack
 	rt.mux.RLock()
 	defer rt.mux.RUnlock()
 
+	// The clusterIP spreads the requests over all the zones, so the pods
+	// in our zone are only preferred once they are addressed directly.
 	if rt.clusterIPTracker != nil {
 		return noop, rt.clusterIPTracker
 	}
+	// Prefer the pods in our zone, and fall back to all of them when the
+	// pods in our zone are saturated.
+	if len(rt.localTrackers) > 0 && rt.preferLocalZone != nil && rt.preferLocalZone.Load() {
+		if cb, tracker := rt.lbPolicy(ctx, rt.localTrackers); tracker != nil {
+			if rt.containerConcurrency != 0 || !rt.busierThanAnother(tracker) {
+				return cb, tracker
+			}
+			cb()
+		}
+	}
 	return rt.lbPolicy(ctx, rt.assignedTrackers)
 }
 
+// busierThanAnother returns whether the pod just picked has more requests in
+// flight than another random pod. With an infinite concurrency the pods never
+// run out of capacity, so this is what saturates the pods in our zone.
+func (rt *revisionThrottler) busierThanAnother(picked *podTracker) bool {
+	other := rt.assignedTrackers[rand.Intn(len(rt.assignedTrackers))]
+	// The weight of the picked pod includes the request being routed.
+	return picked.getWeight()-1 > other.getWeight()
+}
+
 func (rt *revisionThrottler) try(ctx context.Context, function func(string) error) error {
 	var ret error
 
@@ -210,6 +253,12 @@ func (rt *revisionThrottler) try(ctx context.Context, function func(string) erro
 	for reenqueue {
 		reenqueue = false
 		if err := rt.breaker.Maybe(ctx, func() {
+			// The client may have gone away while we were waiting for a slot,
+			// in which case there's no point in reaching out to the backend.
+			if ctx.Err() != nil {
+				ret = ctx.Err()
+				return
+			}
 			cb, tracker := rt.acquireDest(ctx)
 			if tracker == nil {
 				// This can happen if individual requests raced each other or if pod
@@ -244,6 +293,15 @@ func (rt *revisionThrottler) calculateCapacity(size, activatorCount int) int {
 	return targetCapacity
 }
 
+// localZone returns the zone of this Activator, or an empty string if it isn't
+// known.
+func (rt *revisionThrottler) localZone() string {
+	if rt.zone == nil {
+		return ""
+	}
+	return rt.zone.Load()
+}
+
 // This makes sure we reset the capacity to the CC, since the pod
 // might be reassigned to be exclusively used.
 func (rt *revisionThrottler) resetTrackers() {
@@ -282,11 +340,20 @@ func (rt *revisionThrottler) updateCapacity(backendCount int) {
 			assigned = assignSlice(rt.podTrackers,
 				ai, ac, rt.containerConcurrency)
 		}
-		rt.logger.Debugf("Trackers %d/%d:  %v", ai, ac, rt.assignedTrackers)
+		var local []*podTracker
+		if zone := rt.localZone(); zone != "" {
+			for _, t := range assigned {
+				if t.zone == zone {
+					local = append(local, t)
+				}
+			}
+		}
+		rt.logger.Debugf("Trackers %d/%d:  %v (local: %v)", ai, ac, assigned, local)
 		// The actual write out of the assigned trackers has to be under lock.
 		rt.mux.Lock()
 		defer rt.mux.Unlock()
 		rt.assignedTrackers = assigned
+		rt.localTrackers = local
 		return len(assigned)
 	}()
 
@@ -424,6 +491,7 @@ func (rt *revisionThrottler) handleUpdate(throttler *Throttler, update revisionD
 					}
 				}
 			}
+			tracker.zone = update.Zones[newDest]
 			trackers = append(trackers, tracker)
 		}
 
@@ -446,10 +514,29 @@ type Throttler struct {
 	ipAddress               string // The IP address of this activator.
 	logger                  *zap.SugaredLogger
 	epsUpdateCh             chan *corev1.Endpoints
+
+	// nodeName is the node of this activator, empty if unknown. Its zone,
+	// and those of the pods, are only resolved once the pods in the zone
+	// of the activator are preferred.
+	nodeName         string
+	nodeInformer     corev1informers.NodeInformer
+	localZoneEnabled chan struct{}
+	enableLocalZone  sync.Once
+
+	// zone is the zone of this activator, empty until it is known. The zones
+	// of the pods are only resolved once it is known.
+	zone            atomic.String
+	zones           *zoneResolver
+	preferLocalZone atomic.Bool
+
+	// probeTransport probes the revision pods, over TLS when the
+	// activator speaks TLS to them.
+	probeTransport *UpstreamTransport
 }
 
-// NewThrottler creates a new Throttler
-func NewThrottler(ctx context.Context, ipAddr string) *Throttler {
+// NewThrottler creates a new Throttler. The zone of the activator is
+// that of the node named nodeName, if any.
+func NewThrottler(ctx context.Context, ipAddr, nodeName string) *Throttler {
 	revisionInformer := revisioninformer.Get(ctx)
 	t := &Throttler{
 		revisionThrottlers: make(map[types.NamespacedName]*revisionThrottler),
@@ -458,6 +545,16 @@ func NewThrottler(ctx context.Context, ipAddr string) *Throttler {
 		ipAddress:          ipAddr,
 		logger:             logging.FromContext(ctx),
 		epsUpdateCh:        make(chan *corev1.Endpoints),
+		localZoneEnabled:   make(chan struct{}),
+	}
+	// Pooled like network.AutoTransport, which it wraps.
+	t.probeTransport = NewUpstreamTransport(t.logger, network.AutoTransport, 1000, 100)
+	if nodeName != "" {
+		// The nodes are only watched once their zones are needed, so the
+		// informer isn't started along with the others.
+		t.nodeName = nodeName
+		t.nodeInformer = kubeinformerfactory.Get(ctx).Core().V1().Nodes()
+		t.zones = newZoneResolver(nil /*nodeLister*/, &t.preferLocalZone)
 	}
 
 	// Watch revisions to create throttler with backlog immediately and delete
@@ -485,7 +582,11 @@ func NewThrottler(ctx context.Context, ipAddr string) *Throttler {
 
 // Run starts the throttler and blocks until the context is done.
 func (t *Throttler) Run(ctx context.Context) {
-	rbm := newRevisionBackendsManager(ctx, network.AutoTransport)
+	go t.probeTransport.Run(ctx.Done())
+	if t.nodeName != "" {
+		go t.resolveZone(ctx)
+	}
+	rbm := newRevisionBackendsManager(ctx, t.probeTransport, t.zones)
 	// Update channel is closed when ctx is done.
 	t.run(rbm.updates())
 }
@@ -505,6 +606,45 @@ func (t *Throttler) run(updateCh <-chan revisionDestsUpdate) {
 	}
 }
 
+// resolveZone waits for the pods in the zone of the activator to be preferred,
+// then starts watching the nodes and resolves the zone of the activator,
+// retrying until it is known.
+func (t *Throttler) resolveZone(ctx context.Context) {
+	select {
+	case <-t.localZoneEnabled:
+	case <-ctx.Done():
+		return
+	}
+	if err := controller.StartInformers(ctx.Done(), t.nodeInformer.Informer()); err != nil {
+		t.logger.Errorw("Failed to start the node informer", zap.Error(err))
+		return
+	}
+	nodeLister := t.nodeInformer.Lister()
+	wait.PollImmediateUntil(zoneRetryInterval, func() (bool, error) {
+		zone := nodeZone(nodeLister, t.nodeName)
+		if zone == "" {
+			t.logger.Debugf("The zone of node %q isn't known yet", t.nodeName)
+			return false, nil
+		}
+		t.zone.Store(zone)
+		t.zones.setNodeLister(nodeLister)
+		t.logger.Infof("The zone of this activator is %q", zone)
+		return true, nil
+	}, ctx.Done())
+}
+
+// ApplyConfig updates whether the pods in the zone of the activator are
+// preferred over the others, and how they are probed.
+func (t *Throttler) ApplyConfig(cfg *activatorconfig.Activator) {
+	t.preferLocalZone.Store(cfg.PreferLocalZone)
+	if cfg.PreferLocalZone {
+		t.enableLocalZone.Do(func() {
+			close(t.localZoneEnabled)
+		})
+	}
+	t.probeTransport.ApplyConfig(cfg)
+}
+
 // Try waits for capacity and then executes function, passing in a l4 dest to send a request
 func (t *Throttler) Try(ctx context.Context, function func(string) error) error {
 	rt, err := t.getOrCreateRevisionThrottler(util.RevIDFrom(ctx))
@@ -514,6 +654,18 @@ func (t *Throttler) Try(ctx context.Context, function func(string) error) error
 	return rt.try(ctx, function)
 }
 
+// Capacity returns the number of requests the revision can take right away,
+// which is zero for the revisions that haven't been seen yet.
+func (t *Throttler) Capacity(revID types.NamespacedName) int {
+	t.revisionThrottlersMutex.RLock()
+	rt, ok := t.revisionThrottlers[revID]
+	t.revisionThrottlersMutex.RUnlock()
+	if !ok {
+		return 0
+	}
+	return rt.breaker.Capacity()
+}
+
 func (t *Throttler) getOrCreateRevisionThrottler(revID types.NamespacedName) (*revisionThrottler, error) {
 	// First, see if we can succeed with just an RLock. This is in the request path so optimizing
 	// for this case is important
@@ -540,6 +692,8 @@ func (t *Throttler) getOrCreateRevisionThrottler(revID types.NamespacedName) (*r
 			queue.BreakerParams{QueueDepth: breakerQueueDepth, MaxConcurrency: revisionMaxConcurrency},
 			t.logger,
 		)
+		revThrottler.zone = &t.zone
+		revThrottler.preferLocalZone = &t.preferLocalZone
 		t.revisionThrottlers[revID] = revThrottler
 	}
 	return revThrottler, nil
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"go.uber.org/atomic"
 	"golang.org/x/sync/errgroup"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,6 +41,7 @@ import (
 	. "knative.dev/pkg/logging/testing"
 	rtesting "knative.dev/pkg/reconciler/testing"
 	_ "knative.dev/pkg/system/testing"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
 	"knative.dev/serving/pkg/activator/util"
 	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
@@ -61,7 +63,52 @@ type tryResult struct {
 }
 
 func newTestThrottler(ctx context.Context) *Throttler {
-	return NewThrottler(ctx, "10.10.10.10")
+	return NewThrottler(ctx, "10.10.10.10", "" /*nodeName*/)
+}
+
+func TestThrottlerResolveZone(t *testing.T) {
+	defer func(interval time.Duration) { zoneRetryInterval = interval }(zoneRetryInterval)
+	zoneRetryInterval = 10 * time.Millisecond
+
+	ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
+	defer cancel()
+	nodes := fakekubeclient.Get(ctx).CoreV1().Nodes()
+	if _, err := nodes.Create(node("node-a", nil)); err != nil {
+		t.Fatal("Failed to create node:", err)
+	}
+
+	throttler := NewThrottler(ctx, "130.0.0.2", "node-a")
+	go throttler.resolveZone(ctx)
+
+	// The nodes aren't watched until the local zone is preferred.
+	throttler.ApplyConfig(&activatorconfig.Activator{})
+	time.Sleep(50 * time.Millisecond)
+	if throttler.nodeInformer.Informer().HasSynced() {
+		t.Error("The node informer was started while the local zone isn't preferred")
+	}
+
+	throttler.ApplyConfig(&activatorconfig.Activator{PreferLocalZone: true})
+	if err := wait.PollImmediate(10*time.Millisecond, 3*time.Second, func() (bool, error) {
+		return throttler.nodeInformer.Informer().HasSynced(), nil
+	}); err != nil {
+		t.Fatal("The node informer wasn't started:", err)
+	}
+	// The zone is retried until the node is labeled.
+	time.Sleep(50 * time.Millisecond)
+	if got := throttler.zone.Load(); got != "" {
+		t.Errorf("zone = %q, want it unknown", got)
+	}
+	if _, err := nodes.Update(node("node-a", map[string]string{corev1.LabelZoneFailureDomainStable: "zone-a"})); err != nil {
+		t.Fatal("Failed to update node:", err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 3*time.Second, func() (bool, error) {
+		return throttler.zone.Load() == "zone-a", nil
+	}); err != nil {
+		t.Errorf("zone = %q, want: %q", throttler.zone.Load(), "zone-a")
+	}
+	if throttler.zones.lister() == nil {
+		t.Error("The zones of the pods aren't resolved once the zone is known")
+	}
 }
 
 func TestThrottlerUpdateCapacity(t *testing.T) {
@@ -308,6 +355,84 @@ func TestThrottlerErrorOneTimesOut(t *testing.T) {
 	}
 }
 
+func TestThrottlerClientCanceled(t *testing.T) {
+	ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
+	servfake := fakeservingclient.Get(ctx)
+	revisions := fakerevisioninformer.Get(ctx)
+	waitInformers, err := controller.RunInformers(ctx.Done(), revisions.Informer())
+	if err != nil {
+		t.Fatal("Failed to start informers:", err)
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	revID := types.NamespacedName{Namespace: testNamespace, Name: testRevision}
+	revision := revisionCC1(revID, networking.ProtocolHTTP1)
+	servfake.ServingV1().Revisions(revision.Namespace).Create(revision)
+	revisions.Informer().GetIndexer().Add(revision)
+
+	throttler := newTestThrottler(ctx)
+	throttler.handleUpdate(revisionDestsUpdate{
+		Rev:   revID,
+		Dests: sets.NewString("128.0.0.1:1234"),
+	})
+
+	var calls atomic.Int32
+	// A request of a client that is already gone never reaches the backend,
+	// even when there's capacity for it.
+	canceledCtx, cancelRequest := context.WithCancel(context.Background())
+	cancelRequest()
+	for i := 0; i < 10; i++ {
+		if result := <-throttler.try(canceledCtx, 1, func(string) error {
+			calls.Inc()
+			return nil
+		}); result.err != context.Canceled {
+			t.Fatalf("err = %v, want %v", result.err, context.Canceled)
+		}
+	}
+	if got := calls.Load(); got != 0 {
+		t.Fatalf("Backend calls = %d, want 0", got)
+	}
+
+	// Hold the only slot, so that the next request is buffered.
+	release := make(chan struct{})
+	heldChan := throttler.try(context.Background(), 1, func(string) error {
+		calls.Inc()
+		<-release
+		return nil
+	})
+	if err := wait.PollImmediate(time.Millisecond, 3*time.Second, func() (bool, error) {
+		return calls.Load() == 1, nil
+	}); err != nil {
+		t.Fatal("The first request never reached the backend:", err)
+	}
+
+	reqCtx, cancelRequest := context.WithCancel(context.Background())
+	bufferedChan := throttler.try(reqCtx, 1, func(string) error {
+		calls.Inc()
+		return nil
+	})
+	cancelRequest()
+	select {
+	case result := <-bufferedChan:
+		if result.err != context.Canceled {
+			t.Errorf("err = %v, want %v", result.err, context.Canceled)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("The buffered request wasn't aborted when its client went away")
+	}
+
+	close(release)
+	if result := <-heldChan; result.err != nil {
+		t.Fatalf("err = %v, want no error", result.err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Backend calls = %d, want 1", got)
+	}
+}
+
 func TestThrottlerSuccesses(t *testing.T) {
 	for _, tc := range []struct {
 		name        string
@@ -409,7 +534,7 @@ func TestThrottlerSuccesses(t *testing.T) {
 
 			updateCh := make(chan revisionDestsUpdate)
 
-			throttler := NewThrottler(ctx, "130.0.0.2")
+			throttler := NewThrottler(ctx, "130.0.0.2", "" /*nodeName*/)
 			var grp errgroup.Group
 			grp.Go(func() error { throttler.run(updateCh); return nil })
 			// Ensure the throttler stopped before we leave the test, so that
@@ -598,6 +723,130 @@ func TestPodAssignmentInfinite(t *testing.T) {
 	}
 }
 
+func TestThrottlerPreferLocalZone(t *testing.T) {
+	revName := types.NamespacedName{Namespace: testNamespace, Name: testRevision}
+	update := revisionDestsUpdate{
+		Rev:   revName,
+		Dests: sets.NewString("ip0", "ip1", "ip2"),
+		Zones: map[string]string{
+			"ip0": "zone-b",
+			"ip1": "zone-a",
+			"ip2": "zone-a",
+		},
+	}
+	local := sets.NewString("ip1", "ip2")
+
+	tests := []struct {
+		name       string
+		cc         int
+		zone       string
+		preferred  bool
+		wantLocal  sets.String
+		acquire    int
+		hold       bool
+		wantRemote bool
+	}{{
+		name:      "infinite cc, preferred",
+		cc:        0,
+		zone:      "zone-a",
+		preferred: true,
+		wantLocal: local,
+		acquire:   100,
+	}, {
+		name:       "infinite cc, local pods busy",
+		cc:         0,
+		zone:       "zone-a",
+		preferred:  true,
+		wantLocal:  local,
+		acquire:    50,
+		hold:       true,
+		wantRemote: true,
+	}, {
+		name:       "infinite cc, not preferred",
+		cc:         0,
+		zone:       "zone-a",
+		wantLocal:  local,
+		acquire:    100,
+		wantRemote: true,
+	}, {
+		name:       "infinite cc, unknown zone",
+		cc:         0,
+		preferred:  true,
+		wantLocal:  sets.NewString(),
+		acquire:    100,
+		wantRemote: true,
+	}, {
+		name:      "first available, preferred",
+		cc:        1,
+		zone:      "zone-a",
+		preferred: true,
+		wantLocal: local,
+		acquire:   2,
+	}, {
+		name:       "first available, local pods full",
+		cc:         1,
+		zone:       "zone-a",
+		preferred:  true,
+		wantLocal:  local,
+		acquire:    3,
+		wantRemote: true,
+	}, {
+		name:      "round robin, preferred",
+		cc:        5,
+		zone:      "zone-a",
+		preferred: true,
+		wantLocal: local,
+		acquire:   10,
+	}, {
+		name:       "round robin, local pods full",
+		cc:         5,
+		zone:       "zone-a",
+		preferred:  true,
+		wantLocal:  local,
+		acquire:    11,
+		wantRemote: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
+			defer cancel()
+
+			throttler := newTestThrottler(ctx)
+			throttler.ApplyConfig(&activatorconfig.Activator{PreferLocalZone: test.preferred})
+			rt := newRevisionThrottler(revName, test.cc, networking.ServicePortNameHTTP1, testBreakerParams, TestLogger(t))
+			rt.zone = atomic.NewString(test.zone)
+			rt.preferLocalZone = &throttler.preferLocalZone
+			throttler.revisionThrottlers[revName] = rt
+			throttler.handleUpdate(update)
+
+			if got := trackerDestSet(rt.localTrackers); !got.Equal(test.wantLocal) {
+				t.Errorf("Local trackers = %v, want: %v", got, test.wantLocal)
+			}
+
+			// With a finite cc the reservations are held, so that the local
+			// pods fill up. With an infinite cc they are released right away,
+			// unless the local pods are meant to get busy.
+			gotRemote := false
+			for i := 0; i < test.acquire; i++ {
+				cb, tracker := rt.acquireDest(ctx)
+				if tracker == nil {
+					t.Fatal("acquireDest() returned no tracker")
+				}
+				if test.cc == 0 && !test.hold {
+					cb()
+				} else {
+					defer cb()
+				}
+				gotRemote = gotRemote || !local.Has(tracker.dest)
+			}
+			if gotRemote != test.wantRemote {
+				t.Errorf("Acquired a remote pod = %v, want: %v", gotRemote, test.wantRemote)
+			}
+		})
+	}
+}
+
 func TestActivatorsIndexUpdate(t *testing.T) {
 	ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
 
@@ -619,7 +868,7 @@ func TestActivatorsIndexUpdate(t *testing.T) {
 
 	updateCh := make(chan revisionDestsUpdate)
 
-	throttler := NewThrottler(ctx, "130.0.0.2")
+	throttler := NewThrottler(ctx, "130.0.0.2", "" /*nodeName*/)
 	var grp errgroup.Group
 	grp.Go(func() error { throttler.run(updateCh); return nil })
 	// Ensure the throttler stopped before we leave the test, so that
@@ -667,6 +916,12 @@ func TestActivatorsIndexUpdate(t *testing.T) {
 	}); err != nil {
 		t.Fatal("Timed out waiting for the capacity to be updated")
 	}
+	if got, want := throttler.Capacity(revID), 1; got != want {
+		t.Errorf("Capacity() = %d, want: %d", got, want)
+	}
+	if got, want := throttler.Capacity(types.NamespacedName{Namespace: testNamespace, Name: "unseen"}), 0; got != want {
+		t.Errorf("Capacity(unseen) = %d, want: %d", got, want)
+	}
 
 	if got, want := rt.numActivators.Load(), int32(2); got != want {
 		t.Fatalf("numActivators = %d, want %d", got, want)
@@ -714,7 +969,7 @@ func TestMultipleActivators(t *testing.T) {
 
 	updateCh := make(chan revisionDestsUpdate)
 
-	throttler := NewThrottler(ctx, "130.0.0.2")
+	throttler := NewThrottler(ctx, "130.0.0.2", "" /*nodeName*/)
 	var grp errgroup.Group
 	grp.Go(func() error { throttler.run(updateCh); return nil })
 	// Ensure the throttler stopped before we leave the test, so that
@@ -0,0 +1,238 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	pkgnet "knative.dev/pkg/network"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+)
+
+// tlsReloadPeriod is how often the certificates are checked for rotation.
+const tlsReloadPeriod = time.Minute
+
+// upstreamTLS configures how the activator speaks TLS to the revision pods.
+type upstreamTLS struct {
+	enabled    bool
+	caFile     string
+	certFile   string
+	keyFile    string
+	serverName string
+}
+
+func upstreamTLSFromConfig(cfg *activatorconfig.Activator) upstreamTLS {
+	return upstreamTLS{
+		enabled:    cfg.UpstreamTLS,
+		caFile:     cfg.UpstreamTLSCAFile,
+		certFile:   cfg.UpstreamTLSCertFile,
+		keyFile:    cfg.UpstreamTLSKeyFile,
+		serverName: cfg.UpstreamTLSServerName,
+	}
+}
+
+// modTimes returns the modification times of the configured files, which
+// change when the certificates are rotated.
+func (ut upstreamTLS) modTimes() [3]time.Time {
+	var times [3]time.Time
+	for i, f := range []string{ut.caFile, ut.certFile, ut.keyFile} {
+		if f == "" {
+			continue
+		}
+		if fi, err := os.Stat(f); err == nil {
+			times[i] = fi.ModTime()
+		}
+	}
+	return times
+}
+
+// tlsState is the transport built for a configuration.
+type tlsState struct {
+	tls       upstreamTLS
+	modTimes  [3]time.Time
+	transport *http.Transport
+	err       error
+}
+
+// UpstreamTransport sends the requests to the revision pods over TLS when
+// the activator is configured to, and through the plaintext transport
+// otherwise. The certificates are reloaded when they are rotated.
+type UpstreamTransport struct {
+	logger         *zap.SugaredLogger
+	plaintext      http.RoundTripper
+	maxIdle        int
+	maxIdlePerHost int
+
+	// mux serializes the rebuilds of the transport, which is read without
+	// locking on every request.
+	mux   sync.Mutex
+	state atomic.Value
+}
+
+// NewUpstreamTransport creates an UpstreamTransport sending the plaintext
+// requests through the given transport. The TLS connections are pooled
+// within the given limits, like those of pkgnet.NewAutoTransport.
+func NewUpstreamTransport(logger *zap.SugaredLogger, plaintext http.RoundTripper, maxIdle, maxIdlePerHost int) *UpstreamTransport {
+	t := &UpstreamTransport{
+		logger:         logger,
+		plaintext:      plaintext,
+		maxIdle:        maxIdle,
+		maxIdlePerHost: maxIdlePerHost,
+	}
+	t.state.Store(&tlsState{})
+	return t
+}
+
+// RoundTrip implements http.RoundTripper. When TLS is enabled, the http
+// requests are sent as https.
+func (t *UpstreamTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	s := t.state.Load().(*tlsState)
+	if !s.tls.enabled {
+		return t.plaintext.RoundTrip(r)
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	if r.URL.Scheme == "http" {
+		u := *r.URL
+		u.Scheme = "https"
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = &u
+		r = r2
+	}
+	return s.transport.RoundTrip(r)
+}
+
+// ApplyConfig rebuilds the TLS transport when the upstream TLS configuration
+// changes.
+func (t *UpstreamTransport) ApplyConfig(cfg *activatorconfig.Activator) {
+	ut := upstreamTLSFromConfig(cfg)
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	cur := t.state.Load().(*tlsState)
+	if cur.tls == ut {
+		return
+	}
+	next := &tlsState{tls: ut}
+	if ut.enabled {
+		next.modTimes = ut.modTimes()
+		if next.transport, next.err = t.newTLSTransport(ut); next.err != nil {
+			t.logger.Errorw("Failed to set up upstream TLS", zap.Error(next.err))
+		}
+	}
+	t.swap(cur, next)
+}
+
+// Run reloads the certificates when they are rotated, until stopCh is closed.
+func (t *UpstreamTransport) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(tlsReloadPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			t.reload()
+		}
+	}
+}
+
+func (t *UpstreamTransport) reload() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	cur := t.state.Load().(*tlsState)
+	if !cur.tls.enabled {
+		return
+	}
+	modTimes := cur.tls.modTimes()
+	if modTimes == cur.modTimes && cur.err == nil {
+		return
+	}
+	transport, err := t.newTLSTransport(cur.tls)
+	if err != nil {
+		// The files may be mid-rotation, so keep the current transport and
+		// retry on the next tick.
+		t.logger.Errorw("Failed to reload upstream TLS", zap.Error(err))
+		return
+	}
+	t.logger.Info("Reloaded the upstream TLS certificates")
+	t.swap(cur, &tlsState{tls: cur.tls, modTimes: modTimes, transport: transport})
+}
+
+func (t *UpstreamTransport) swap(cur, next *tlsState) {
+	t.state.Store(next)
+	if cur.transport != nil {
+		cur.transport.CloseIdleConnections()
+	}
+}
+
+// newTLSTransport returns a transport speaking TLS to the revision pods,
+// verifying them with the configured CAs and presenting the configured
+// client certificate.
+func (t *UpstreamTransport) newTLSTransport(ut upstreamTLS) (*http.Transport, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: ut.serverName,
+	}
+
+	if ut.caFile != "" {
+		pem, err := ioutil.ReadFile(ut.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", ut.caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if ut.certFile != "" || ut.keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(ut.certFile, ut.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Transport{
+		// Those match the transports of pkgnet.NewAutoTransport.
+		Proxy:                 http.ProxyFromEnvironment,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		DialContext:           pkgnet.DialWithBackOff,
+		MaxIdleConns:          t.maxIdle,
+		MaxIdleConnsPerHost:   t.maxIdlePerHost,
+
+		// Negotiate HTTP/2 over ALPN, which replaces h2c.
+		ForceAttemptHTTP2: true,
+		TLSClientConfig:   cfg,
+	}, nil
+}
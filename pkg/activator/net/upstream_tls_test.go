@@ -0,0 +1,303 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	logtesting "knative.dev/pkg/logging/testing"
+	pkgnet "knative.dev/pkg/network"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+)
+
+const upstreamBody = "upstream"
+
+// upstreamServer starts a TLS server requiring a client certificate, and
+// writes its CA and a client certificate accepted by it to dir.
+func upstreamServer(t *testing.T, dir string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(upstreamBody))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+
+	// The test certificate is self-signed, so it serves as the CA as well.
+	cert := server.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal("Failed to marshal the key:", err)
+	}
+	for name, block := range map[string]*pem.Block{
+		"ca.crt":  {Type: "CERTIFICATE", Bytes: cert.Certificate[0]},
+		"tls.crt": {Type: "CERTIFICATE", Bytes: cert.Certificate[0]},
+		"tls.key": {Type: "PRIVATE KEY", Bytes: key},
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatal("Failed to write the certificates:", err)
+		}
+	}
+	return server
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "upstream-tls")
+	if err != nil {
+		t.Fatal("Failed to create a temporary directory:", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func upstreamTLSConfig(dir string) *activatorconfig.Activator {
+	return &activatorconfig.Activator{
+		UpstreamTLS:         true,
+		UpstreamTLSCAFile:   filepath.Join(dir, "ca.crt"),
+		UpstreamTLSCertFile: filepath.Join(dir, "tls.crt"),
+		UpstreamTLSKeyFile:  filepath.Join(dir, "tls.key"),
+	}
+}
+
+func TestNewTLSTransport(t *testing.T) {
+	dir := tempDir(t)
+	server := upstreamServer(t, dir)
+	defer server.Close()
+	if err := ioutil.WriteFile(filepath.Join(dir, "empty.crt"), []byte("nope"), 0600); err != nil {
+		t.Fatal("Failed to write the certificates:", err)
+	}
+
+	// The test certificate is valid for example.com and 127.0.0.1.
+	full := upstreamTLSFromConfig(upstreamTLSConfig(dir))
+	withServerName := func(name string) upstreamTLS {
+		ut := full
+		ut.serverName = name
+		return ut
+	}
+
+	tests := []struct {
+		name       string
+		tls        upstreamTLS
+		wantErr    bool
+		wantReqErr bool
+	}{{
+		name: "mutual TLS",
+		tls:  full,
+	}, {
+		name: "matching server name",
+		tls:  withServerName("example.com"),
+	}, {
+		name:       "mismatching server name",
+		tls:        withServerName("example.org"),
+		wantReqErr: true,
+	}, {
+		name: "system roots",
+		tls: upstreamTLS{
+			certFile: full.certFile,
+			keyFile:  full.keyFile,
+		},
+		wantReqErr: true,
+	}, {
+		name: "no client certificate",
+		tls: upstreamTLS{
+			caFile: full.caFile,
+		},
+		wantReqErr: true,
+	}, {
+		name: "missing CA bundle",
+		tls: upstreamTLS{
+			caFile: filepath.Join(dir, "missing.crt"),
+		},
+		wantErr: true,
+	}, {
+		name: "empty CA bundle",
+		tls: upstreamTLS{
+			caFile: filepath.Join(dir, "empty.crt"),
+		},
+		wantErr: true,
+	}, {
+		name: "mismatching client certificate",
+		tls: upstreamTLS{
+			certFile: full.certFile,
+			keyFile:  full.caFile,
+		},
+		wantErr: true,
+	}}
+
+	ut := NewUpstreamTransport(logtesting.TestLogger(t), nil, 10, 5)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport, err := ut.newTLSTransport(test.tls)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("newTLSTransport() = %v, wantErr = %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer transport.CloseIdleConnections()
+
+			if transport.MaxIdleConns != 10 || transport.MaxIdleConnsPerHost != 5 {
+				t.Errorf("Idle connections = %d/%d, want: 10/5", transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+			}
+			if transport.DialContext == nil {
+				t.Error("The transport doesn't dial with back off")
+			}
+
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if (err != nil) != test.wantReqErr {
+				t.Fatalf("Get() = %v, wantErr = %v", err, test.wantReqErr)
+			}
+			if err == nil {
+				resp.Body.Close()
+			}
+		})
+	}
+}
+
+func TestUpstreamTransport(t *testing.T) {
+	dir := tempDir(t)
+	server := upstreamServer(t, dir)
+	defer server.Close()
+
+	var plaintext int
+	ut := NewUpstreamTransport(logtesting.TestLogger(t), pkgnet.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		plaintext++
+		return httptest.NewRecorder().Result(), nil
+	}), 10, 10)
+
+	send := func() error {
+		t.Helper()
+		// The requests are addressed over http, like those to the pods.
+		req := httptest.NewRequest(http.MethodGet, "http://"+server.Listener.Addr().String(), nil)
+		req.RequestURI = ""
+		resp, err := ut.RoundTrip(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	if err := send(); err != nil {
+		t.Fatal("RoundTrip() =", err)
+	}
+	if plaintext != 1 {
+		t.Errorf("Plaintext requests = %d, want: 1", plaintext)
+	}
+
+	ut.ApplyConfig(upstreamTLSConfig(dir))
+	if err := send(); err != nil {
+		t.Fatal("RoundTrip() =", err)
+	}
+	if plaintext != 1 {
+		t.Errorf("Plaintext requests = %d, want: 1", plaintext)
+	}
+	transport := ut.state.Load().(*tlsState).transport
+
+	// The transport is reused as long as the configuration doesn't change.
+	ut.ApplyConfig(upstreamTLSConfig(dir))
+	if ut.state.Load().(*tlsState).transport != transport {
+		t.Error("The TLS transport was rebuilt for the same configuration")
+	}
+
+	cfg := upstreamTLSConfig(dir)
+	cfg.UpstreamTLSServerName = "example.com"
+	ut.ApplyConfig(cfg)
+	if ut.state.Load().(*tlsState).transport == transport {
+		t.Error("The TLS transport was not rebuilt for a new configuration")
+	}
+	if err := send(); err != nil {
+		t.Fatal("RoundTrip() =", err)
+	}
+
+	cfg = upstreamTLSConfig(dir)
+	cfg.UpstreamTLSCAFile = filepath.Join(dir, "missing.crt")
+	ut.ApplyConfig(cfg)
+	if err := send(); err == nil {
+		t.Error("RoundTrip() succeeded with an invalid configuration")
+	}
+
+	ut.ApplyConfig(&activatorconfig.Activator{})
+	if err := send(); err != nil {
+		t.Fatal("RoundTrip() =", err)
+	}
+	if plaintext != 2 {
+		t.Errorf("Plaintext requests = %d, want: 2", plaintext)
+	}
+}
+
+func TestUpstreamTransportReload(t *testing.T) {
+	dir := tempDir(t)
+	cfg := upstreamTLSConfig(dir)
+
+	// The certificates are not there yet.
+	ut := NewUpstreamTransport(logtesting.TestLogger(t), nil, 10, 10)
+	ut.ApplyConfig(cfg)
+	if err := ut.state.Load().(*tlsState).err; err == nil {
+		t.Fatal("ApplyConfig() succeeded without certificates")
+	}
+
+	server := upstreamServer(t, dir)
+	defer server.Close()
+	ut.reload()
+	state := ut.state.Load().(*tlsState)
+	if state.err != nil {
+		t.Fatal("reload() =", state.err)
+	}
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req.RequestURI = ""
+	resp, err := ut.RoundTrip(req)
+	if err != nil {
+		t.Fatal("RoundTrip() =", err)
+	}
+	resp.Body.Close()
+
+	// Unchanged certificates are not reloaded.
+	ut.reload()
+	if ut.state.Load().(*tlsState) != state {
+		t.Error("The TLS transport was rebuilt for unchanged certificates")
+	}
+
+	// Rotated certificates are.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(cfg.UpstreamTLSCertFile, later, later); err != nil {
+		t.Fatal("Failed to touch the certificate:", err)
+	}
+	ut.reload()
+	if ut.state.Load().(*tlsState) == state {
+		t.Error("The TLS transport was not rebuilt for rotated certificates")
+	}
+
+	// Certificates that fail to load keep the current transport.
+	state = ut.state.Load().(*tlsState)
+	if err := ioutil.WriteFile(cfg.UpstreamTLSKeyFile, []byte("nope"), 0600); err != nil {
+		t.Fatal("Failed to write the key:", err)
+	}
+	ut.reload()
+	if ut.state.Load().(*tlsState) != state {
+		t.Error("The TLS transport was replaced by one failing to load")
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"net"
+	"strconv"
+	"sync"
+
+	"go.uber.org/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// zoneResolver maps node names to the zone of the node.
+type zoneResolver struct {
+	// nodeLister is nil until the nodes are watched, which is only once
+	// the zones are needed.
+	mu         sync.RWMutex
+	nodeLister corev1listers.NodeLister
+	// enabled reflects whether the zones of the pods are needed, i.e.
+	// whether the pods in the zone of the activator are preferred.
+	enabled *atomic.Bool
+}
+
+func newZoneResolver(nodeLister corev1listers.NodeLister, enabled *atomic.Bool) *zoneResolver {
+	return &zoneResolver{
+		nodeLister: nodeLister,
+		enabled:    enabled,
+	}
+}
+
+// setNodeLister sets the lister the zones of the nodes are read from.
+func (z *zoneResolver) setNodeLister(nodeLister corev1listers.NodeLister) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.nodeLister = nodeLister
+}
+
+func (z *zoneResolver) lister() corev1listers.NodeLister {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	return z.nodeLister
+}
+
+// zoneOf returns the zone of the node, or an empty string if it isn't known.
+func (z *zoneResolver) zoneOf(nodeName string) string {
+	nodeLister := z.lister()
+	if nodeLister == nil {
+		return ""
+	}
+	return nodeZone(nodeLister, nodeName)
+}
+
+// nodeZone returns the zone of the node listed by nodeLister, or an empty
+// string if it isn't known.
+func nodeZone(nodeLister corev1listers.NodeLister, nodeName string) string {
+	node, err := nodeLister.Get(nodeName)
+	if err != nil {
+		return ""
+	}
+	if zone := node.Labels[corev1.LabelZoneFailureDomainStable]; zone != "" {
+		return zone
+	}
+	return node.Labels[corev1.LabelZoneFailureDomain]
+}
+
+// destZones returns the zones of the dests of the endpoints for the port
+// named portName, keyed by dest. It returns nil when z is nil or the zones
+// aren't needed or known yet.
+func (z *zoneResolver) destZones(endpoints *corev1.Endpoints, portName string) map[string]string {
+	if z == nil || !z.enabled.Load() || z.lister() == nil {
+		return nil
+	}
+	zones := make(map[string]string)
+	for _, es := range endpoints.Subsets {
+		for _, port := range es.Ports {
+			if port.Name == portName {
+				portStr := strconv.Itoa(int(port.Port))
+				for _, addrs := range [][]corev1.EndpointAddress{es.Addresses, es.NotReadyAddresses} {
+					for _, addr := range addrs {
+						if addr.NodeName == nil || *addr.NodeName == "" {
+							continue
+						}
+						if zone := z.zoneOf(*addr.NodeName); zone != "" {
+							zones[net.JoinHostPort(addr.IP, portStr)] = zone
+						}
+					}
+				}
+				break
+			}
+		}
+	}
+	return zones
+}
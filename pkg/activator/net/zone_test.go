@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/atomic"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/ptr"
+)
+
+func nodeLister(nodes ...*corev1.Node) corev1listers.NodeLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, n := range nodes {
+		indexer.Add(n)
+	}
+	return corev1listers.NewNodeLister(indexer)
+}
+
+func node(name string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+func TestZoneResolver(t *testing.T) {
+	zones := newZoneResolver(nodeLister(
+		node("stable", map[string]string{corev1.LabelZoneFailureDomainStable: "zone-a"}),
+		node("beta", map[string]string{corev1.LabelZoneFailureDomain: "zone-b"}),
+		node("both", map[string]string{
+			corev1.LabelZoneFailureDomainStable: "zone-a",
+			corev1.LabelZoneFailureDomain:       "zone-b",
+		}),
+		node("none", nil),
+	), atomic.NewBool(true))
+
+	for name, want := range map[string]string{
+		"stable":  "zone-a",
+		"beta":    "zone-b",
+		"both":    "zone-a",
+		"none":    "",
+		"missing": "",
+	} {
+		if got := zones.zoneOf(name); got != want {
+			t.Errorf("zoneOf(%q) = %q, want: %q", name, got, want)
+		}
+	}
+}
+
+func TestDestZones(t *testing.T) {
+	lister := nodeLister(
+		node("node-a", map[string]string{corev1.LabelZoneFailureDomainStable: "zone-a"}),
+		node("node-b", map[string]string{corev1.LabelZoneFailureDomainStable: "zone-b"}),
+	)
+	eps := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{{
+			Ports: []corev1.EndpointPort{{Name: "http", Port: 8012}},
+			Addresses: []corev1.EndpointAddress{
+				{IP: "128.0.0.1", NodeName: ptr.String("node-a")},
+				{IP: "128.0.0.2", NodeName: ptr.String("node-b")},
+				{IP: "128.0.0.3"},
+				{IP: "128.0.0.4", NodeName: ptr.String("node-c")},
+			},
+			NotReadyAddresses: []corev1.EndpointAddress{
+				{IP: "128.0.0.5", NodeName: ptr.String("node-a")},
+			},
+		}, {
+			Ports: []corev1.EndpointPort{{Name: "http2", Port: 8013}},
+			Addresses: []corev1.EndpointAddress{
+				{IP: "128.0.0.6", NodeName: ptr.String("node-a")},
+			},
+		}},
+	}
+
+	enabled := atomic.NewBool(true)
+	zones := newZoneResolver(lister, enabled)
+	got := zones.destZones(eps, "http")
+	want := map[string]string{
+		"128.0.0.1:8012": "zone-a",
+		"128.0.0.2:8012": "zone-b",
+		"128.0.0.5:8012": "zone-a",
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("destZones() = %v, want: %v, diff(-want,+got): %s", got, want, cmp.Diff(want, got))
+	}
+
+	// The zones aren't resolved while the local zone isn't preferred.
+	enabled.Store(false)
+	if got := zones.destZones(eps, "http"); got != nil {
+		t.Errorf("destZones() while disabled = %v, want: nil", got)
+	}
+
+	zones = nil
+	if got := zones.destZones(eps, "http"); got != nil {
+		t.Errorf("destZones() of a nil resolver = %v, want: nil", got)
+	}
+}
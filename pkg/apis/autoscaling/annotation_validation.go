@@ -52,7 +52,8 @@ func ValidateAnnotations(allowInitScaleZero bool, anns map[string]string) *apis.
 	}
 	return validateClass(anns).Also(validateMinMaxScale(anns)).Also(validateFloats(anns)).
 		Also(validateWindow(anns).Also(validateLastPodRetention(anns)).
-			Also(validateMetric(anns).Also(validateInitialScale(allowInitScaleZero, anns))))
+			Also(validateMetric(anns).Also(validateAlgorithm(anns)).
+				Also(validateInitialScale(allowInitScaleZero, anns))))
 }
 
 func validateClass(annotations map[string]string) *apis.FieldError {
@@ -174,6 +175,22 @@ func validateMetric(annotations map[string]string) *apis.FieldError {
 	return nil
 }
 
+func validateAlgorithm(annotations map[string]string) *apis.FieldError {
+	if algorithm, ok := annotations[AlgorithmAnnotationKey]; ok {
+		switch annotations[ClassAnnotationKey] {
+		case "", KPA:
+			switch algorithm {
+			case AlgorithmRatio, AlgorithmPID:
+				return nil
+			}
+			return apis.ErrInvalidValue(algorithm, AlgorithmAnnotationKey)
+		case HPA:
+			return apis.ErrInvalidKeyName(AlgorithmAnnotationKey, HPA)
+		}
+	}
+	return nil
+}
+
 func validateInitialScale(allowInitScaleZero bool, annotations map[string]string) *apis.FieldError {
 	if initialScale, ok := annotations[InitialScaleAnnotationKey]; ok {
 		initScaleInt, err := strconv.Atoi(initialScale)
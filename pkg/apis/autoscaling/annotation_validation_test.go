@@ -251,6 +251,23 @@ func TestValidateScaleBoundAnnotations(t *testing.T) {
 	}, {
 		name:        "other than HPA and KPA class",
 		annotations: map[string]string{ClassAnnotationKey: "other", MetricAnnotationKey: RPS},
+	}, {
+		name:        "valid algorithm ratio",
+		annotations: map[string]string{AlgorithmAnnotationKey: AlgorithmRatio},
+	}, {
+		name:        "valid class KPA with algorithm pid",
+		annotations: map[string]string{ClassAnnotationKey: KPA, AlgorithmAnnotationKey: AlgorithmPID},
+	}, {
+		name:        "invalid algorithm",
+		annotations: map[string]string{AlgorithmAnnotationKey: "fuzzy"},
+		expectErr:   "invalid value: fuzzy: " + AlgorithmAnnotationKey,
+	}, {
+		name:        "algorithm with class HPA",
+		annotations: map[string]string{ClassAnnotationKey: HPA, AlgorithmAnnotationKey: AlgorithmPID},
+		expectErr:   "invalid key name \"" + AlgorithmAnnotationKey + "\": " + HPA,
+	}, {
+		name:        "algorithm with other class",
+		annotations: map[string]string{ClassAnnotationKey: "other", AlgorithmAnnotationKey: "fuzzy"},
 	}, {
 		name:               "initial scale is zero but cluster doesn't allow",
 		allowInitScaleZero: false,
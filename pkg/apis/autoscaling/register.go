@@ -62,6 +62,20 @@ const (
 	// RPS is the requests per second reaching the Pod.
 	RPS = "rps"
 
+	// AlgorithmAnnotationKey is the annotation to specify the algorithm the
+	// autoscaler uses to compute the desired scale from the observed stable
+	// metric. For example,
+	//   autoscaling.knative.dev/algorithm: pid
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the algorithm annotation.
+	AlgorithmAnnotationKey = GroupName + "/algorithm"
+	// AlgorithmRatio scales to the observed value divided by the target,
+	// this is the default.
+	AlgorithmRatio = "ratio"
+	// AlgorithmPID scales with a proportional-integral-derivative controller,
+	// which converges on the ratio more smoothly.
+	AlgorithmPID = "pid"
+
 	// TargetAnnotationKey is the annotation to specify what metric value the
 	// PodAutoscaler should attempt to maintain. For example,
 	//   autoscaling.knative.dev/metric: cpu
@@ -173,4 +187,13 @@ const (
 	// KPALabelKey is the label key attached to a K8s Service to hint to the KPA
 	// which services/endpoints should trigger reconciles.
 	KPALabelKey = GroupName + "/kpa"
+
+	// ConcurrencyUtilizationAnnotationKey is the status annotation reporting
+	// the observed concurrency of a revision as a percentage of the concurrency
+	// its ready pods can maintain at the per-pod target. For example,
+	//   autoscaling.knative.dev/concurrencyUtilization: "85"
+	// means the revision runs at 85% of its target, while values above 100
+	// indicate saturation. The value is refreshed when the PodAutoscaler is
+	// reconciled and is reported in steps of 5 percentage points.
+	ConcurrencyUtilizationAnnotationKey = GroupName + "/concurrencyUtilization"
 )
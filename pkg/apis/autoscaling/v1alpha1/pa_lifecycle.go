@@ -63,6 +63,15 @@ func (pa *PodAutoscaler) Metric() string {
 	return defaultMetric(pa.Class())
 }
 
+// Algorithm returns the contents of the algorithm annotation or the default.
+func (pa *PodAutoscaler) Algorithm() string {
+	// The value is validated in the webhook.
+	if a, ok := pa.Annotations[autoscaling.AlgorithmAnnotationKey]; ok {
+		return a
+	}
+	return autoscaling.AlgorithmRatio
+}
+
 func (pa *PodAutoscaler) annotationInt32(key string) (int32, bool) {
 	if s, ok := pa.Annotations[key]; ok {
 		i, err := strconv.ParseInt(s, 10, 32)
@@ -720,6 +720,32 @@ func TestMetric(t *testing.T) {
 	}
 }
 
+func TestAlgorithm(t *testing.T) {
+	cases := []struct {
+		name string
+		pa   *PodAutoscaler
+		want string
+	}{{
+		name: "default",
+		pa:   pa(map[string]string{}),
+		want: autoscaling.AlgorithmRatio,
+	}, {
+		name: "annotation set",
+		pa: pa(map[string]string{
+			autoscaling.AlgorithmAnnotationKey: autoscaling.AlgorithmPID,
+		}),
+		want: autoscaling.AlgorithmPID,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pa.Algorithm(); got != tc.want {
+				t.Errorf("Algorithm() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestWindowAnnotation(t *testing.T) {
 	cases := []struct {
 		name       string
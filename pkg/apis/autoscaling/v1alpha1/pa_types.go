@@ -138,6 +138,16 @@ type PodAutoscalerStatus struct {
 
 	// ActualScale shows the actual number of replicas for the revision.
 	ActualScale *int32 `json:"actualScale,omitempty"`
+
+	// MinScale shows the effective minimum number of replicas for the revision,
+	// after the defaults are applied to its annotations.
+	// +optional
+	MinScale *int32 `json:"minScale,omitempty"`
+
+	// MaxScale shows the effective maximum number of replicas for the revision,
+	// after the defaults are applied to its annotations. 0 means no maximum.
+	// +optional
+	MaxScale *int32 `json:"maxScale,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -24,11 +24,13 @@ import (
 	"math"
 	"strings"
 	"text/template"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"knative.dev/pkg/apis"
 	cm "knative.dev/pkg/configmap"
@@ -105,14 +107,19 @@ func NewDefaultsConfigFromMap(data map[string]string) (*Defaults, error) {
 
 	if err := cm.Parse(data,
 		cm.AsString("container-name-template", &nc.UserContainerNameTemplate),
+		cm.AsString("service-account-name-template", &nc.ServiceAccountNameTemplate),
 
 		cm.AsBool("allow-container-concurrency-zero", &nc.AllowContainerConcurrencyZero),
 		asTriState("enable-service-links", &nc.EnableServiceLinks),
+		asTriState("automount-service-account-token", &nc.AutomountServiceAccountToken),
 
 		cm.AsInt64("revision-timeout-seconds", &nc.RevisionTimeoutSeconds),
 		cm.AsInt64("max-revision-timeout-seconds", &nc.MaxRevisionTimeoutSeconds),
 		cm.AsInt64("container-concurrency", &nc.ContainerConcurrency),
 		cm.AsInt64("container-concurrency-max-limit", &nc.ContainerConcurrencyMaxLimit),
+		cm.AsInt64("max-containers", &nc.MaxContainers),
+		cm.AsInt64("traffic-targets-warning-threshold", &nc.TrafficTargetsWarningThreshold),
+		cm.AsInt64("container-concurrency-warning-threshold", &nc.ContainerConcurrencyWarningThreshold),
 
 		cm.AsQuantity("revision-cpu-request", &nc.RevisionCPURequest),
 		cm.AsQuantity("revision-memory-request", &nc.RevisionMemoryRequest),
@@ -120,6 +127,10 @@ func NewDefaultsConfigFromMap(data map[string]string) (*Defaults, error) {
 		cm.AsQuantity("revision-cpu-limit", &nc.RevisionCPULimit),
 		cm.AsQuantity("revision-memory-limit", &nc.RevisionMemoryLimit),
 		cm.AsQuantity("revision-ephemeral-storage-limit", &nc.RevisionEphemeralStorageLimit),
+		cm.AsQuantity("emptydir-size-limit", &nc.EmptyDirSizeLimit),
+		cm.AsQuantity("max-emptydir-size-limit", &nc.MaxEmptyDirSizeLimit),
+
+		cm.AsDuration("certificate-expiry-warning-threshold", &nc.CertificateExpiryWarningThreshold),
 	); err != nil {
 		return nil, err
 	}
@@ -131,20 +142,58 @@ func NewDefaultsConfigFromMap(data map[string]string) (*Defaults, error) {
 		return nil, apis.ErrOutOfBoundsValue(
 			nc.ContainerConcurrencyMaxLimit, 1, math.MaxInt32, "container-concurrency-max-limit")
 	}
+	if nc.MaxContainers < 0 {
+		return nil, apis.ErrOutOfBoundsValue(nc.MaxContainers, 0, math.MaxInt32, "max-containers")
+	}
+	if nc.TrafficTargetsWarningThreshold < 0 {
+		return nil, apis.ErrOutOfBoundsValue(
+			nc.TrafficTargetsWarningThreshold, 0, math.MaxInt32, "traffic-targets-warning-threshold")
+	}
+	if nc.ContainerConcurrencyWarningThreshold < 0 {
+		return nil, apis.ErrOutOfBoundsValue(
+			nc.ContainerConcurrencyWarningThreshold, 0, math.MaxInt32, "container-concurrency-warning-threshold")
+	}
+	if nc.CertificateExpiryWarningThreshold < 0 {
+		return nil, fmt.Errorf("certificate-expiry-warning-threshold cannot be a negative duration, was %v",
+			nc.CertificateExpiryWarningThreshold)
+	}
 	if nc.ContainerConcurrency < 0 || nc.ContainerConcurrency > nc.ContainerConcurrencyMaxLimit {
 		return nil, apis.ErrOutOfBoundsValue(
 			nc.ContainerConcurrency, 0, nc.ContainerConcurrencyMaxLimit, "container-concurrency")
 	}
 
-	tmpl, err := template.New("user-container").Parse(nc.UserContainerNameTemplate)
-	if err != nil {
-		return nil, err
+	if nc.EmptyDirSizeLimit != nil && nc.EmptyDirSizeLimit.Sign() <= 0 {
+		return nil, fmt.Errorf("emptydir-size-limit (%s) must be positive", nc.EmptyDirSizeLimit)
+	}
+	if nc.MaxEmptyDirSizeLimit != nil && nc.MaxEmptyDirSizeLimit.Sign() <= 0 {
+		return nil, fmt.Errorf("max-emptydir-size-limit (%s) must be positive", nc.MaxEmptyDirSizeLimit)
+	}
+	if nc.EmptyDirSizeLimit != nil && nc.MaxEmptyDirSizeLimit != nil && nc.EmptyDirSizeLimit.Cmp(*nc.MaxEmptyDirSizeLimit) > 0 {
+		return nil, fmt.Errorf("emptydir-size-limit (%s) cannot be greater than max-emptydir-size-limit (%s)", nc.EmptyDirSizeLimit, nc.MaxEmptyDirSizeLimit)
+	}
+
+	for _, text := range []string{nc.UserContainerNameTemplate, nc.ServiceAccountNameTemplate} {
+		tmpl, err := template.New("defaults").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		// Check that the template properly applies to ObjectMeta.
+		if err := tmpl.Execute(ioutil.Discard, metav1.ObjectMeta{}); err != nil {
+			return nil, fmt.Errorf("error executing template: %w", err)
+		}
+		templateCache.Add(text, tmpl)
 	}
-	// Check that the template properly applies to ObjectMeta.
-	if err := tmpl.Execute(ioutil.Discard, metav1.ObjectMeta{}); err != nil {
-		return nil, fmt.Errorf("error executing template: %w", err)
+	if nc.ServiceAccountNameTemplate != "" {
+		// Check that the template renders a valid name, for a representative parent.
+		name := executeTemplate(apis.WithinParent(context.Background(), metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		}), nc.ServiceAccountNameTemplate)
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return nil, fmt.Errorf("service-account-name-template renders an invalid name %q: %s",
+				name, strings.Join(errs, ", "))
+		}
 	}
-	templateCache.Add(nc.UserContainerNameTemplate, tmpl)
 
 	return nc, nil
 }
@@ -163,6 +212,11 @@ type Defaults struct {
 
 	UserContainerNameTemplate string
 
+	// ServiceAccountNameTemplate is the template of the serviceAccountName
+	// given to the revisions that don't specify one. When empty, they run
+	// as the "default" ServiceAccount of their namespace.
+	ServiceAccountNameTemplate string
+
 	ContainerConcurrency int64
 
 	// ContainerConcurrencyMaxLimit is the maximum permitted container concurrency
@@ -173,27 +227,68 @@ type Defaults struct {
 	// a containerConcurrency of 0 (i.e. unbounded).
 	AllowContainerConcurrencyZero bool
 
+	// MaxContainers is the maximum number of containers, the serving one
+	// and its sidecars, a revision may have. Zero means no maximum.
+	MaxContainers int64
+
 	// Permits defaulting of `enableServiceLinks` pod spec field.
 	// See: https://github.com/knative/serving/issues/8498 for details.
 	EnableServiceLinks *bool
 
+	// Permits defaulting of `automountServiceAccountToken` pod spec field, so
+	// that revisions which don't talk to the API server don't get a token.
+	AutomountServiceAccountToken *bool
+
+	// TrafficTargetsWarningThreshold is the number of traffic targets above
+	// which Routes are warned about the size of their Ingress. Zero disables
+	// the warning.
+	TrafficTargetsWarningThreshold int64
+
+	// ContainerConcurrencyWarningThreshold is the containerConcurrency above
+	// which Revisions are warned about a likely misconfiguration. Zero
+	// disables the warning.
+	ContainerConcurrencyWarningThreshold int64
+
+	// CertificateExpiryWarningThreshold is how long before the expiry of the
+	// certificates serving a Route it is warned about it. Zero disables the
+	// warning.
+	CertificateExpiryWarningThreshold time.Duration
+
 	RevisionCPURequest              *resource.Quantity
 	RevisionCPULimit                *resource.Quantity
 	RevisionMemoryRequest           *resource.Quantity
 	RevisionMemoryLimit             *resource.Quantity
 	RevisionEphemeralStorageRequest *resource.Quantity
 	RevisionEphemeralStorageLimit   *resource.Quantity
+
+	// EmptyDirSizeLimit is the sizeLimit given to the emptyDir volumes that
+	// don't specify one.
+	EmptyDirSizeLimit *resource.Quantity
+	// MaxEmptyDirSizeLimit is the largest sizeLimit an emptyDir volume may
+	// have. EmptyDirSizeLimit must not be greater than this value.
+	MaxEmptyDirSizeLimit *resource.Quantity
 }
 
 // UserContainerName returns the name of the user container based on the context.
 func (d *Defaults) UserContainerName(ctx context.Context) string {
+	return executeTemplate(ctx, d.UserContainerNameTemplate)
+}
+
+// ServiceAccountName returns the name of the default ServiceAccount of the
+// revisions based on the context.
+func (d *Defaults) ServiceAccountName(ctx context.Context) string {
+	return executeTemplate(ctx, d.ServiceAccountNameTemplate)
+}
+
+// executeTemplate applies the template to the ObjectMeta of the parent in
+// the context.
+func executeTemplate(ctx context.Context, text string) string {
 	var tmpl *template.Template
-	if tt, ok := templateCache.Get(d.UserContainerNameTemplate); ok {
+	if tt, ok := templateCache.Get(text); ok {
 		tmpl = tt.(*template.Template)
 	} else {
 		// Fallback for unit tests.
-		tmpl = template.Must(
-			template.New("user-container").Parse(d.UserContainerNameTemplate))
+		tmpl = template.Must(template.New("defaults").Parse(text))
 	}
 	buf := &bytes.Buffer{}
 	if err := tmpl.Execute(buf, apis.ParentMeta(ctx)); err != nil {
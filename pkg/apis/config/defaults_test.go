@@ -19,6 +19,7 @@ package config
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
@@ -50,6 +51,7 @@ func TestDefaultsConfigurationFromFile(t *testing.T) {
 	got.RevisionCPULimit, got.RevisionCPURequest = nil, nil
 	got.RevisionMemoryLimit, got.RevisionMemoryRequest = nil, nil
 	got.RevisionEphemeralStorageLimit, got.RevisionEphemeralStorageRequest = nil, nil
+	got.EmptyDirSizeLimit, got.MaxEmptyDirSizeLimit = nil, nil
 	want := defaultDefaultsConfig()
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Error("Example does not represent default config: diff(-want,+got)\n", diff)
@@ -58,6 +60,7 @@ func TestDefaultsConfigurationFromFile(t *testing.T) {
 
 func TestDefaultsConfiguration(t *testing.T) {
 	oneTwoThree := resource.MustParse("123m")
+	fiveHundredMi, oneGi := resource.MustParse("500Mi"), resource.MustParse("1Gi")
 
 	configTests := []struct {
 		name         string
@@ -73,21 +76,33 @@ func TestDefaultsConfiguration(t *testing.T) {
 		name:    "specified values",
 		wantErr: false,
 		wantDefaults: &Defaults{
-			RevisionTimeoutSeconds:       123,
-			MaxRevisionTimeoutSeconds:    456,
-			ContainerConcurrencyMaxLimit: 1984,
-			RevisionCPURequest:           &oneTwoThree,
-			UserContainerNameTemplate:    "{{.Name}}",
-			EnableServiceLinks:           ptr.Bool(true),
+			RevisionTimeoutSeconds:               123,
+			MaxRevisionTimeoutSeconds:            456,
+			ContainerConcurrencyMaxLimit:         1984,
+			RevisionCPURequest:                   &oneTwoThree,
+			UserContainerNameTemplate:            "{{.Name}}",
+			ServiceAccountNameTemplate:           "{{.Namespace}}-runner",
+			EnableServiceLinks:                   ptr.Bool(true),
+			AutomountServiceAccountToken:         ptr.Bool(false),
+			TrafficTargetsWarningThreshold:       50,
+			ContainerConcurrencyWarningThreshold: 500,
+			MaxContainers:                        3,
+			CertificateExpiryWarningThreshold:    720 * time.Hour,
 		},
 		data: map[string]string{
-			"revision-timeout-seconds":         "123",
-			"max-revision-timeout-seconds":     "456",
-			"revision-cpu-request":             "123m",
-			"container-concurrency-max-limit":  "1984",
-			"container-name-template":          "{{.Name}}",
-			"allow-container-concurrency-zero": "false",
-			"enable-service-links":             "true",
+			"revision-timeout-seconds":                "123",
+			"max-revision-timeout-seconds":            "456",
+			"revision-cpu-request":                    "123m",
+			"container-concurrency-max-limit":         "1984",
+			"container-name-template":                 "{{.Name}}",
+			"service-account-name-template":           "{{.Namespace}}-runner",
+			"allow-container-concurrency-zero":        "false",
+			"enable-service-links":                    "true",
+			"automount-service-account-token":         "false",
+			"traffic-targets-warning-threshold":       "50",
+			"container-concurrency-warning-threshold": "500",
+			"max-containers":                          "3",
+			"certificate-expiry-warning-threshold":    "720h",
 		},
 	}, {
 		name:    "service links false",
@@ -134,6 +149,18 @@ func TestDefaultsConfiguration(t *testing.T) {
 		data: map[string]string{
 			"container-name-template": "{{.NAme}}",
 		},
+	}, {
+		name:    "bad service account name template",
+		wantErr: true,
+		data: map[string]string{
+			"service-account-name-template": "{{.NAmespace}}",
+		},
+	}, {
+		name:    "service account name template renders an invalid name",
+		wantErr: true,
+		data: map[string]string{
+			"service-account-name-template": "{{.Namespace}}_runner",
+		},
 	}, {
 		name:    "bad resource",
 		wantErr: true,
@@ -167,6 +194,62 @@ func TestDefaultsConfiguration(t *testing.T) {
 		data: map[string]string{
 			"container-concurrency-max-limit": "0",
 		},
+	}, {
+		name:    "emptyDir size limits",
+		wantErr: false,
+		wantDefaults: func() *Defaults {
+			d := defaultDefaultsConfig()
+			d.EmptyDirSizeLimit = &fiveHundredMi
+			d.MaxEmptyDirSizeLimit = &oneGi
+			return d
+		}(),
+		data: map[string]string{
+			"emptydir-size-limit":     "500Mi",
+			"max-emptydir-size-limit": "1Gi",
+		},
+	}, {
+		name:    "emptydir-size-limit bigger than max-emptydir-size-limit",
+		wantErr: true,
+		data: map[string]string{
+			"emptydir-size-limit":     "2Gi",
+			"max-emptydir-size-limit": "1Gi",
+		},
+	}, {
+		name:    "emptydir-size-limit is zero",
+		wantErr: true,
+		data: map[string]string{
+			"emptydir-size-limit": "0",
+		},
+	}, {
+		name:    "max-emptydir-size-limit is negative",
+		wantErr: true,
+		data: map[string]string{
+			"max-emptydir-size-limit": "-1Gi",
+		},
+	}, {
+		name:    "max-containers is negative",
+		wantErr: true,
+		data: map[string]string{
+			"max-containers": "-1",
+		},
+	}, {
+		name:    "traffic-targets-warning-threshold is negative",
+		wantErr: true,
+		data: map[string]string{
+			"traffic-targets-warning-threshold": "-1",
+		},
+	}, {
+		name:    "container-concurrency-warning-threshold is negative",
+		wantErr: true,
+		data: map[string]string{
+			"container-concurrency-warning-threshold": "-1",
+		},
+	}, {
+		name:    "certificate-expiry-warning-threshold is negative",
+		wantErr: true,
+		data: map[string]string{
+			"certificate-expiry-warning-threshold": "-1h",
+		},
 	}}
 
 	for _, tt := range configTests {
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	cm "knative.dev/pkg/configmap"
 )
 
@@ -38,16 +39,45 @@ const (
 	Allowed Flag = "Allowed"
 )
 
+// DefaultAllowedSysctls is the set of sysctls Kubernetes considers safe, i.e.
+// namespaced and isolated between the pods of a node.
+var DefaultAllowedSysctls = sets.NewString(
+	"kernel.shm_rmid_forced",
+	"net.ipv4.ip_local_port_range",
+	"net.ipv4.tcp_syncookies",
+	"net.ipv4.ping_group_range",
+)
+
 func defaultFeaturesConfig() *Features {
 	return &Features{
-		MultiContainer:         Enabled,
-		PodSpecAffinity:        Disabled,
-		PodSpecFieldRef:        Disabled,
-		PodSpecDryRun:          Allowed,
-		PodSpecNodeSelector:    Disabled,
-		PodSpecSecurityContext: Disabled,
-		PodSpecTolerations:     Disabled,
-		ResponsiveRevisionGC:   Disabled,
+		ConfigurationRevisionSummary:   Disabled,
+		DuplicateVolumeMounts:          Enabled,
+		MultiContainer:                 Enabled,
+		NestedVolumeMounts:             Enabled,
+		PodSpecAffinity:                Disabled,
+		PodSpecCommandValidation:       Disabled,
+		PodSpecFieldRef:                Disabled,
+		PodSpecHostAliases:             Disabled,
+		PodSpecDryRun:                  Allowed,
+		PodSpecEnvFromValidation:       Disabled,
+		PodSpecNodeSelector:            Disabled,
+		PodSpecPostStart:               Disabled,
+		PodSpecPriorityClassName:       Disabled,
+		PodSpecPriorityClassValidation: Disabled,
+		PodSpecRestartPolicy:           Disabled,
+		PodSpecRuntimeClassName:        Disabled,
+		PodSpecSecurityContext:         Disabled,
+		PodSpecShareProcessNamespace:   Disabled,
+		PodSpecSysctls:                 Disabled,
+		PodSpecTolerations:             Disabled,
+		PodSpecVolumesEmptyDir:         Disabled,
+		PerTagIngress:                  Disabled,
+		ResponsiveRevisionGC:           Disabled,
+		ResponsiveRouteReadiness:       Disabled,
+		RouteDNSReadiness:              Disabled,
+		RouteSortedTraffic:             Disabled,
+		TolerateMissingRevisions:       Disabled,
+		AllowedSysctls:                 sets.NewString(DefaultAllowedSysctls.UnsortedList()...),
 	}
 }
 
@@ -56,16 +86,46 @@ func NewFeaturesConfigFromMap(data map[string]string) (*Features, error) {
 	nc := defaultFeaturesConfig()
 
 	if err := cm.Parse(data,
+		asFlag("configuration-revision-summary", &nc.ConfigurationRevisionSummary),
+		asFlag("duplicate-volume-mounts", &nc.DuplicateVolumeMounts),
 		asFlag("multi-container", &nc.MultiContainer),
+		asFlag("nested-volume-mounts", &nc.NestedVolumeMounts),
 		asFlag("kubernetes.podspec-affinity", &nc.PodSpecAffinity),
+		asFlag("kubernetes.podspec-command-validation", &nc.PodSpecCommandValidation),
 		asFlag("kubernetes.podspec-fieldref", &nc.PodSpecFieldRef),
+		asFlag("kubernetes.podspec-hostaliases", &nc.PodSpecHostAliases),
 		asFlag("kubernetes.podspec-dryrun", &nc.PodSpecDryRun),
+		asFlag("kubernetes.podspec-envfrom-validation", &nc.PodSpecEnvFromValidation),
 		asFlag("kubernetes.podspec-nodeselector", &nc.PodSpecNodeSelector),
+		asFlag("kubernetes.podspec-poststart", &nc.PodSpecPostStart),
+		asFlag("kubernetes.podspec-priorityclassname", &nc.PodSpecPriorityClassName),
+		asFlag("kubernetes.podspec-priorityclassname-validation", &nc.PodSpecPriorityClassValidation),
+		asFlag("kubernetes.podspec-restartpolicy", &nc.PodSpecRestartPolicy),
+		asFlag("kubernetes.podspec-runtimeclassname", &nc.PodSpecRuntimeClassName),
 		asFlag("kubernetes.podspec-securitycontext", &nc.PodSpecSecurityContext),
+		asFlag("kubernetes.podspec-shareprocessnamespace", &nc.PodSpecShareProcessNamespace),
+		asFlag("kubernetes.podspec-sysctls", &nc.PodSpecSysctls),
+		cm.AsStringSet("kubernetes.podspec-sysctls.allowed", &nc.AllowedSysctls),
 		asFlag("kubernetes.podspec-tolerations", &nc.PodSpecTolerations),
-		asFlag("responsive-revision-gc", &nc.ResponsiveRevisionGC)); err != nil {
+		asFlag("kubernetes.podspec-volumes-emptydir", &nc.PodSpecVolumesEmptyDir),
+		asFlag("per-tag-ingress", &nc.PerTagIngress),
+		asFlag("responsive-revision-gc", &nc.ResponsiveRevisionGC),
+		asFlag("responsive-route-readiness", &nc.ResponsiveRouteReadiness),
+		asFlag("route-dns-readiness", &nc.RouteDNSReadiness),
+		cm.AsString("route-dns-readiness.resolver", &nc.RouteDNSResolver),
+		asFlag("route-sorted-traffic", &nc.RouteSortedTraffic),
+		asFlag("tolerate-missing-revisions", &nc.TolerateMissingRevisions)); err != nil {
 		return nil, err
 	}
+
+	allowed := sets.NewString()
+	for _, name := range nc.AllowedSysctls.UnsortedList() {
+		if name = strings.TrimSpace(name); name != "" {
+			allowed.Insert(name)
+		}
+	}
+	nc.AllowedSysctls = allowed
+
 	return nc, nil
 }
 
@@ -76,14 +136,42 @@ func NewFeaturesConfigFromConfigMap(config *corev1.ConfigMap) (*Features, error)
 
 // Features specifies which features are allowed by the webhook.
 type Features struct {
-	MultiContainer         Flag
-	PodSpecAffinity        Flag
-	PodSpecFieldRef        Flag
-	PodSpecDryRun          Flag
-	PodSpecNodeSelector    Flag
-	PodSpecTolerations     Flag
-	PodSpecSecurityContext Flag
-	ResponsiveRevisionGC   Flag
+	ConfigurationRevisionSummary   Flag
+	DuplicateVolumeMounts          Flag
+	MultiContainer                 Flag
+	NestedVolumeMounts             Flag
+	PodSpecAffinity                Flag
+	PodSpecCommandValidation       Flag
+	PodSpecFieldRef                Flag
+	PodSpecHostAliases             Flag
+	PodSpecDryRun                  Flag
+	PodSpecEnvFromValidation       Flag
+	PodSpecNodeSelector            Flag
+	PodSpecPostStart               Flag
+	PodSpecPriorityClassName       Flag
+	PodSpecPriorityClassValidation Flag
+	PodSpecRestartPolicy           Flag
+	PodSpecRuntimeClassName        Flag
+	PodSpecTolerations             Flag
+	PodSpecVolumesEmptyDir         Flag
+	PodSpecSecurityContext         Flag
+	PodSpecShareProcessNamespace   Flag
+	PodSpecSysctls                 Flag
+	PerTagIngress                  Flag
+	ResponsiveRevisionGC           Flag
+	ResponsiveRouteReadiness       Flag
+	RouteDNSReadiness              Flag
+	RouteSortedTraffic             Flag
+	TolerateMissingRevisions       Flag
+
+	// AllowedSysctls is the set of sysctls the pods may set when
+	// PodSpecSysctls is not Disabled.
+	AllowedSysctls sets.String
+
+	// RouteDNSResolver is the address of the DNS server checking the hosts
+	// of the Routes when RouteDNSReadiness is Enabled, the system resolver
+	// is used when it's empty.
+	RouteDNSResolver string
 }
 
 // asFlag parses the value at key as a Flag into the target, if it exists.
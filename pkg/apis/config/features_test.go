@@ -22,6 +22,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	. "knative.dev/pkg/configmap/testing"
 	_ "knative.dev/pkg/system/testing"
 )
@@ -59,22 +60,78 @@ func TestFeaturesConfiguration(t *testing.T) {
 		name:    "features Enabled",
 		wantErr: false,
 		wantFeatures: defaultWith(&Features{
-			MultiContainer:         Enabled,
-			PodSpecAffinity:        Enabled,
-			PodSpecDryRun:          Enabled,
-			PodSpecNodeSelector:    Enabled,
-			PodSpecSecurityContext: Enabled,
-			PodSpecTolerations:     Enabled,
-			ResponsiveRevisionGC:   Enabled,
+			ConfigurationRevisionSummary:   Enabled,
+			DuplicateVolumeMounts:          Enabled,
+			MultiContainer:                 Enabled,
+			NestedVolumeMounts:             Enabled,
+			PodSpecAffinity:                Enabled,
+			PodSpecCommandValidation:       Enabled,
+			PodSpecDryRun:                  Enabled,
+			PodSpecEnvFromValidation:       Enabled,
+			PodSpecHostAliases:             Enabled,
+			PodSpecNodeSelector:            Enabled,
+			PodSpecPostStart:               Enabled,
+			PodSpecPriorityClassName:       Enabled,
+			PodSpecPriorityClassValidation: Enabled,
+			PodSpecRestartPolicy:           Enabled,
+			PodSpecRuntimeClassName:        Enabled,
+			PodSpecSecurityContext:         Enabled,
+			PodSpecShareProcessNamespace:   Enabled,
+			PodSpecSysctls:                 Enabled,
+			PodSpecTolerations:             Enabled,
+			PodSpecVolumesEmptyDir:         Enabled,
+			PerTagIngress:                  Enabled,
+			ResponsiveRevisionGC:           Enabled,
+			ResponsiveRouteReadiness:       Enabled,
+			RouteDNSReadiness:              Enabled,
+			RouteSortedTraffic:             Enabled,
+			TolerateMissingRevisions:       Enabled,
 		}),
 		data: map[string]string{
-			"multi-container":                    "Enabled",
-			"kubernetes.podspec-affinity":        "Enabled",
-			"kubernetes.podspec-dryrun":          "Enabled",
-			"kubernetes.podspec-nodeselector":    "Enabled",
-			"kubernetes.podspec-securitycontext": "Enabled",
-			"kubernetes.podspec-tolerations":     "Enabled",
-			"responsive-revision-gc":             "Enabled",
+			"configuration-revision-summary":                  "Enabled",
+			"duplicate-volume-mounts":                         "Enabled",
+			"multi-container":                                 "Enabled",
+			"nested-volume-mounts":                            "Enabled",
+			"kubernetes.podspec-affinity":                     "Enabled",
+			"kubernetes.podspec-command-validation":           "Enabled",
+			"kubernetes.podspec-dryrun":                       "Enabled",
+			"kubernetes.podspec-envfrom-validation":           "Enabled",
+			"kubernetes.podspec-hostaliases":                  "Enabled",
+			"kubernetes.podspec-nodeselector":                 "Enabled",
+			"kubernetes.podspec-poststart":                    "Enabled",
+			"kubernetes.podspec-priorityclassname":            "Enabled",
+			"kubernetes.podspec-priorityclassname-validation": "Enabled",
+			"kubernetes.podspec-restartpolicy":                "Enabled",
+			"kubernetes.podspec-runtimeclassname":             "Enabled",
+			"kubernetes.podspec-securitycontext":              "Enabled",
+			"kubernetes.podspec-shareprocessnamespace":        "Enabled",
+			"kubernetes.podspec-sysctls":                      "Enabled",
+			"kubernetes.podspec-tolerations":                  "Enabled",
+			"kubernetes.podspec-volumes-emptydir":             "Enabled",
+			"per-tag-ingress":                                 "Enabled",
+			"responsive-revision-gc":                          "Enabled",
+			"responsive-route-readiness":                      "Enabled",
+			"route-dns-readiness":                             "Enabled",
+			"route-sorted-traffic":                            "Enabled",
+			"tolerate-missing-revisions":                      "Enabled",
+		},
+	}, {
+		name:    "duplicate-volume-mounts Disabled",
+		wantErr: false,
+		wantFeatures: defaultWith(&Features{
+			DuplicateVolumeMounts: Disabled,
+		}),
+		data: map[string]string{
+			"duplicate-volume-mounts": "Disabled",
+		},
+	}, {
+		name:    "nested-volume-mounts Disabled",
+		wantErr: false,
+		wantFeatures: defaultWith(&Features{
+			NestedVolumeMounts: Disabled,
+		}),
+		data: map[string]string{
+			"nested-volume-mounts": "Disabled",
 		},
 	}, {
 		name:    "multi-container Allowed",
@@ -247,6 +304,69 @@ func TestFeaturesConfiguration(t *testing.T) {
 		data: map[string]string{
 			"kubernetes.podspec-securitycontext": "Disabled",
 		},
+	}, {
+		name:    "envfrom validation Allowed",
+		wantErr: false,
+		wantFeatures: defaultWith(&Features{
+			PodSpecEnvFromValidation: Allowed,
+		}),
+		data: map[string]string{
+			"kubernetes.podspec-envfrom-validation": "Allowed",
+		},
+	}, {
+		name:    "host aliases Allowed",
+		wantErr: false,
+		wantFeatures: defaultWith(&Features{
+			PodSpecHostAliases: Allowed,
+		}),
+		data: map[string]string{
+			"kubernetes.podspec-hostaliases": "Allowed",
+		},
+	}, {
+		name:    "priority class name Allowed",
+		wantErr: false,
+		wantFeatures: defaultWith(&Features{
+			PodSpecPriorityClassName: Allowed,
+		}),
+		data: map[string]string{
+			"kubernetes.podspec-priorityclassname": "Allowed",
+		},
+	}, {
+		name:    "sysctls Allowed",
+		wantErr: false,
+		wantFeatures: defaultWith(&Features{
+			PodSpecSysctls: Allowed,
+		}),
+		data: map[string]string{
+			"kubernetes.podspec-sysctls": "Allowed",
+		},
+	}, {
+		name:    "allowed sysctls",
+		wantErr: false,
+		wantFeatures: defaultWith(&Features{
+			AllowedSysctls: sets.NewString("net.core.somaxconn", "net.ipv4.tcp_syncookies"),
+		}),
+		data: map[string]string{
+			"kubernetes.podspec-sysctls.allowed": "net.core.somaxconn, net.ipv4.tcp_syncookies,",
+		},
+	}, {
+		name:    "no allowed sysctls",
+		wantErr: false,
+		wantFeatures: defaultWith(&Features{
+			AllowedSysctls: sets.NewString(),
+		}),
+		data: map[string]string{
+			"kubernetes.podspec-sysctls.allowed": "",
+		},
+	}, {
+		name:    "route dns resolver",
+		wantErr: false,
+		wantFeatures: defaultWith(&Features{
+			RouteDNSResolver: "10.0.0.53:53",
+		}),
+		data: map[string]string{
+			"route-dns-readiness.resolver": "10.0.0.53:53",
+		},
 	}}
 
 	for _, tt := range configTests {
@@ -273,7 +393,7 @@ func defaultWith(p *Features) *Features {
 	pType := reflect.ValueOf(p).Elem()
 	fType := reflect.ValueOf(f).Elem()
 	for i := 0; i < pType.NumField(); i++ {
-		if pType.Field(i).Interface().(Flag) != "" {
+		if !pType.Field(i).IsZero() {
 			fType.Field(i).Set(pType.Field(i))
 		}
 	}
@@ -20,6 +20,10 @@ limitations under the License.
 
 package config
 
+import (
+	sets "k8s.io/apimachinery/pkg/util/sets"
+)
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Defaults) DeepCopyInto(out *Defaults) {
 	*out = *in
@@ -28,6 +32,11 @@ func (in *Defaults) DeepCopyInto(out *Defaults) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.AutomountServiceAccountToken != nil {
+		in, out := &in.AutomountServiceAccountToken, &out.AutomountServiceAccountToken
+		*out = new(bool)
+		**out = **in
+	}
 	if in.RevisionCPURequest != nil {
 		in, out := &in.RevisionCPURequest, &out.RevisionCPURequest
 		x := (*in).DeepCopy()
@@ -74,6 +83,13 @@ func (in *Defaults) DeepCopy() *Defaults {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Features) DeepCopyInto(out *Features) {
 	*out = *in
+	if in.AllowedSysctls != nil {
+		in, out := &in.AllowedSysctls, &out.AllowedSysctls
+		*out = make(sets.String, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -43,11 +43,12 @@ func VolumeMask(in *corev1.Volume) *corev1.Volume {
 // VolumeSourceMask performs a _shallow_ copy of the Kubernetes VolumeSource object to a new
 // Kubernetes VolumeSource object bringing over only the fields allowed in the Knative API. This
 // does not validate the contents or the bounds of the provided fields.
-func VolumeSourceMask(in *corev1.VolumeSource) *corev1.VolumeSource {
+func VolumeSourceMask(ctx context.Context, in *corev1.VolumeSource) *corev1.VolumeSource {
 	if in == nil {
 		return nil
 	}
 
+	cfg := config.FromContextOrDefaults(ctx)
 	out := new(corev1.VolumeSource)
 
 	// Allowed fields
@@ -55,6 +56,11 @@ func VolumeSourceMask(in *corev1.VolumeSource) *corev1.VolumeSource {
 	out.ConfigMap = in.ConfigMap
 	out.Projected = in.Projected
 
+	// Feature fields
+	if cfg.Features.PodSpecVolumesEmptyDir != config.Disabled {
+		out.EmptyDir = in.EmptyDir
+	}
+
 	// Too many disallowed fields to list
 
 	return out
@@ -153,6 +159,7 @@ func PodSpecMask(ctx context.Context, in *corev1.PodSpec) *corev1.PodSpec {
 	out.Volumes = in.Volumes
 	out.ImagePullSecrets = in.ImagePullSecrets
 	out.EnableServiceLinks = in.EnableServiceLinks
+	out.AutomountServiceAccountToken = in.AutomountServiceAccountToken
 
 	// Feature fields
 	if cfg.Features.PodSpecAffinity != config.Disabled {
@@ -164,32 +171,41 @@ func PodSpecMask(ctx context.Context, in *corev1.PodSpec) *corev1.PodSpec {
 	if cfg.Features.PodSpecTolerations != config.Disabled {
 		out.Tolerations = in.Tolerations
 	}
-	if cfg.Features.PodSpecSecurityContext != config.Disabled {
+	if cfg.Features.PodSpecHostAliases != config.Disabled {
+		out.HostAliases = in.HostAliases
+	}
+	if cfg.Features.PodSpecPriorityClassName != config.Disabled {
+		out.PriorityClassName = in.PriorityClassName
+	}
+	if cfg.Features.PodSpecSecurityContext != config.Disabled || cfg.Features.PodSpecSysctls != config.Disabled {
 		out.SecurityContext = in.SecurityContext
 	}
+	if cfg.Features.PodSpecShareProcessNamespace != config.Disabled {
+		out.ShareProcessNamespace = in.ShareProcessNamespace
+	}
+	if cfg.Features.PodSpecRestartPolicy != config.Disabled {
+		out.RestartPolicy = in.RestartPolicy
+	}
+	if cfg.Features.PodSpecRuntimeClassName != config.Disabled {
+		out.RuntimeClassName = in.RuntimeClassName
+	}
 
 	// Disallowed fields
 	// This list is unnecessary, but added here for clarity
 	out.InitContainers = nil
-	out.RestartPolicy = ""
 	out.TerminationGracePeriodSeconds = nil
 	out.ActiveDeadlineSeconds = nil
 	out.DNSPolicy = ""
-	out.AutomountServiceAccountToken = nil
 	out.NodeName = ""
 	out.HostNetwork = false
 	out.HostPID = false
 	out.HostIPC = false
-	out.ShareProcessNamespace = nil
 	out.Hostname = ""
 	out.Subdomain = ""
 	out.SchedulerName = ""
-	out.HostAliases = nil
-	out.PriorityClassName = ""
 	out.Priority = nil
 	out.DNSConfig = nil
 	out.ReadinessGates = nil
-	out.RuntimeClassName = nil
 
 	return out
 }
@@ -197,7 +213,7 @@ func PodSpecMask(ctx context.Context, in *corev1.PodSpec) *corev1.PodSpec {
 // ContainerMask performs a _shallow_ copy of the Kubernetes Container object to a new
 // Kubernetes Container object bringing over only the fields allowed in the Knative API. This
 // does not validate the contents or the bounds of the provided fields.
-func ContainerMask(in *corev1.Container) *corev1.Container {
+func ContainerMask(ctx context.Context, in *corev1.Container) *corev1.Container {
 	if in == nil {
 		return nil
 	}
@@ -222,9 +238,12 @@ func ContainerMask(in *corev1.Container) *corev1.Container {
 	out.TerminationMessagePolicy = in.TerminationMessagePolicy
 	out.VolumeMounts = in.VolumeMounts
 
+	if config.FromContextOrDefaults(ctx).Features.PodSpecPostStart != config.Disabled {
+		out.Lifecycle = in.Lifecycle
+	}
+
 	// Disallowed fields
 	// This list is unnecessary, but added here for clarity
-	out.Lifecycle = nil
 	out.Stdin = false
 	out.StdinOnce = false
 	out.TTY = false
@@ -233,6 +252,26 @@ func ContainerMask(in *corev1.Container) *corev1.Container {
 	return out
 }
 
+// LifecycleMask performs a _shallow_ copy of the Kubernetes Lifecycle object to a new
+// Kubernetes Lifecycle object bringing over only the fields allowed in the Knative API. This
+// does not validate the contents or the bounds of the provided fields.
+func LifecycleMask(in *corev1.Lifecycle) *corev1.Lifecycle {
+	if in == nil {
+		return nil
+	}
+
+	out := new(corev1.Lifecycle)
+
+	// Allowed fields
+	out.PostStart = in.PostStart
+
+	// Disallowed fields
+	// This list is unnecessary, but added here for clarity
+	out.PreStop = nil
+
+	return out
+}
+
 // VolumeMountMask performs a _shallow_ copy of the Kubernetes VolumeMount object to a new
 // Kubernetes VolumeMount object bringing over only the fields allowed in the Knative API. This
 // does not validate the contents or the bounds of the provided fields.
@@ -539,8 +578,13 @@ func PodSecurityContextMask(ctx context.Context, in *corev1.PodSecurityContext)
 	}
 
 	out := new(corev1.PodSecurityContext)
+	features := config.FromContextOrDefaults(ctx).Features
+
+	if features.PodSpecSysctls != config.Disabled {
+		out.Sysctls = in.Sysctls
+	}
 
-	if config.FromContextOrDefaults(ctx).Features.PodSpecSecurityContext == config.Disabled {
+	if features.PodSpecSecurityContext == config.Disabled {
 		return out
 	}
 
@@ -554,7 +598,6 @@ func PodSecurityContextMask(ctx context.Context, in *corev1.PodSecurityContext)
 	// This list is unnecessary, but added here for clarity
 	out.SELinuxOptions = nil
 	out.WindowsOptions = nil
-	out.Sysctls = nil
 
 	return out
 }
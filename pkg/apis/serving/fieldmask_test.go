@@ -51,6 +51,23 @@ func TestVolumeMask(t *testing.T) {
 	}
 }
 
+func TestVolumeSourceMaskEmptyDir(t *testing.T) {
+	in := &corev1.VolumeSource{
+		EmptyDir: &corev1.EmptyDirVolumeSource{},
+	}
+
+	if got := VolumeSourceMask(context.Background(), in); got.EmptyDir != nil {
+		t.Errorf("EmptyDir = %v, want: nil when the feature is disabled", got.EmptyDir)
+	}
+
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.Features.PodSpecVolumesEmptyDir = config.Enabled
+	ctx := config.ToContext(context.Background(), cfg)
+	if got := VolumeSourceMask(ctx, in); got.EmptyDir != in.EmptyDir {
+		t.Errorf("EmptyDir = %v, want: %v", got.EmptyDir, in.EmptyDir)
+	}
+}
+
 func TestVolumeSourceMask(t *testing.T) {
 	want := &corev1.VolumeSource{
 		Secret:    &corev1.SecretVolumeSource{},
@@ -62,7 +79,7 @@ func TestVolumeSourceMask(t *testing.T) {
 		NFS:       &corev1.NFSVolumeSource{},
 	}
 
-	got := VolumeSourceMask(in)
+	got := VolumeSourceMask(context.Background(), in)
 
 	if &want == &got {
 		t.Error("Input and output share addresses. Want different addresses")
@@ -74,7 +91,7 @@ func TestVolumeSourceMask(t *testing.T) {
 		t.Errorf("VolumeSourceMask (-want, +got): %s", diff)
 	}
 
-	if got = VolumeSourceMask(nil); got != nil {
+	if got = VolumeSourceMask(context.Background(), nil); got != nil {
 		t.Errorf("VolumeSourceMask(nil) = %v, want: nil", got)
 	}
 }
@@ -96,6 +113,7 @@ func TestPodSpecMask(t *testing.T) {
 				},
 			},
 		}},
+		AutomountServiceAccountToken: ptr.Bool(false),
 	}
 	in := &corev1.PodSpec{
 		ServiceAccountName: "default",
@@ -113,10 +131,13 @@ func TestPodSpecMask(t *testing.T) {
 				},
 			},
 		}},
+		AutomountServiceAccountToken: ptr.Bool(false),
 		// Stripped out.
 		InitContainers: []corev1.Container{{
 			Image: "busybox",
 		}},
+		ShareProcessNamespace: ptr.Bool(true),
+		RestartPolicy:         corev1.RestartPolicyOnFailure,
 	}
 
 	ctx := context.Background()
@@ -174,9 +195,10 @@ func TestContainerMask(t *testing.T) {
 		Stdin:                    true,
 		StdinOnce:                true,
 		TTY:                      true,
+		Lifecycle:                &corev1.Lifecycle{},
 	}
 
-	got := ContainerMask(in)
+	got := ContainerMask(context.Background(), in)
 
 	if &want == &got {
 		t.Error("Input and output share addresses. Want different addresses")
@@ -188,11 +210,70 @@ func TestContainerMask(t *testing.T) {
 		t.Errorf("ContainerMask (-want, +got): %s", diff)
 	}
 
-	if got = ContainerMask(nil); got != nil {
+	if got = ContainerMask(context.Background(), nil); got != nil {
 		t.Errorf("ContainerMask(nil) = %v, want: nil", got)
 	}
 }
 
+func TestContainerMask_PostStartEnabled(t *testing.T) {
+	lifecycle := &corev1.Lifecycle{
+		PostStart: &corev1.Handler{
+			Exec: &corev1.ExecAction{Command: []string{"/signal"}},
+		},
+	}
+	want := &corev1.Container{
+		Image:     "python",
+		Lifecycle: lifecycle,
+	}
+	in := &corev1.Container{
+		Image:     "python",
+		Lifecycle: lifecycle,
+		TTY:       true,
+	}
+
+	ctx := config.ToContext(context.Background(),
+		&config.Config{
+			Features: &config.Features{
+				PodSpecPostStart: config.Enabled,
+			},
+		},
+	)
+
+	got := ContainerMask(ctx, in)
+
+	if diff, err := kmp.SafeDiff(want, got); err != nil {
+		t.Errorf("Got error comparing output, err = %v", err)
+	} else if diff != "" {
+		t.Errorf("ContainerMask (-want, +got): %s", diff)
+	}
+}
+
+func TestLifecycleMask(t *testing.T) {
+	want := &corev1.Lifecycle{
+		PostStart: &corev1.Handler{},
+	}
+	in := &corev1.Lifecycle{
+		PostStart: &corev1.Handler{},
+		PreStop:   &corev1.Handler{},
+	}
+
+	got := LifecycleMask(in)
+
+	if &want == &got {
+		t.Error("Input and output share addresses. Want different addresses")
+	}
+
+	if diff, err := kmp.SafeDiff(want, got); err != nil {
+		t.Errorf("Got error comparing output, err = %v", err)
+	} else if diff != "" {
+		t.Errorf("LifecycleMask (-want, +got): %s", diff)
+	}
+
+	if got = LifecycleMask(nil); got != nil {
+		t.Errorf("LifecycleMask(nil) = %v, want: nil", got)
+	}
+}
+
 func TestVolumeMountMask(t *testing.T) {
 	mode := corev1.MountPropagationBidirectional
 
@@ -700,6 +781,7 @@ func TestPodSecurityContextMask_FeatureEnabled(t *testing.T) {
 		&config.Config{
 			Features: &config.Features{
 				PodSpecSecurityContext: config.Enabled,
+				PodSpecSysctls:         config.Disabled,
 			},
 		},
 	)
@@ -717,6 +799,40 @@ func TestPodSecurityContextMask_FeatureEnabled(t *testing.T) {
 	}
 }
 
+func TestPodSecurityContextMask_SysctlsEnabled(t *testing.T) {
+	in := &corev1.PodSecurityContext{
+		Sysctls: []corev1.Sysctl{{
+			Name:  "net.ipv4.tcp_syncookies",
+			Value: "1",
+		}},
+		RunAsUser: ptr.Int64(1),
+	}
+
+	want := &corev1.PodSecurityContext{
+		Sysctls: []corev1.Sysctl{{
+			Name:  "net.ipv4.tcp_syncookies",
+			Value: "1",
+		}},
+	}
+
+	ctx := config.ToContext(context.Background(),
+		&config.Config{
+			Features: &config.Features{
+				PodSpecSecurityContext: config.Disabled,
+				PodSpecSysctls:         config.Enabled,
+			},
+		},
+	)
+
+	got := PodSecurityContextMask(ctx, in)
+
+	if diff, err := kmp.SafeDiff(want, got); err != nil {
+		t.Errorf("Got error comparing output, err = %v", err)
+	} else if diff != "" {
+		t.Errorf("PostSecurityContextMask (-want, +got): %s", diff)
+	}
+}
+
 func TestSecurityContextMask(t *testing.T) {
 	mtype := corev1.UnmaskedProcMount
 	want := &corev1.SecurityContext{
@@ -20,12 +20,15 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net"
 	"path/filepath"
 	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/networking/pkg/apis/networking"
@@ -81,11 +84,11 @@ var (
 	)
 )
 
-func ValidateVolumes(vs []corev1.Volume, mountedVolumes sets.String) (sets.String, *apis.FieldError) {
-	volumes := make(sets.String, len(vs))
+func ValidateVolumes(ctx context.Context, vs []corev1.Volume, mountedVolumes sets.String) (map[string]corev1.Volume, *apis.FieldError) {
+	volumes := make(map[string]corev1.Volume, len(vs))
 	var errs *apis.FieldError
 	for i, volume := range vs {
-		if volumes.Has(volume.Name) {
+		if _, ok := volumes[volume.Name]; ok {
 			errs = errs.Also((&apis.FieldError{
 				Message: fmt.Sprintf("duplicate volume name %q", volume.Name),
 				Paths:   []string{"name"},
@@ -97,13 +100,13 @@ func ValidateVolumes(vs []corev1.Volume, mountedVolumes sets.String) (sets.Strin
 				Paths:   []string{"name"},
 			}).ViaIndex(i))
 		}
-		errs = errs.Also(validateVolume(volume).ViaIndex(i))
-		volumes.Insert(volume.Name)
+		errs = errs.Also(validateVolume(ctx, volume).ViaIndex(i))
+		volumes[volume.Name] = volume
 	}
 	return volumes, errs
 }
 
-func validateVolume(volume corev1.Volume) *apis.FieldError {
+func validateVolume(ctx context.Context, volume corev1.Volume) *apis.FieldError {
 	errs := apis.CheckDisallowedFields(volume, *VolumeMask(&volume))
 	if volume.Name == "" {
 		errs = apis.ErrMissingField("name")
@@ -112,7 +115,7 @@ func validateVolume(volume corev1.Volume) *apis.FieldError {
 	}
 
 	vs := volume.VolumeSource
-	errs = errs.Also(apis.CheckDisallowedFields(vs, *VolumeSourceMask(&vs)))
+	errs = errs.Also(apis.CheckDisallowedFields(vs, *VolumeSourceMask(ctx, &vs)))
 	specified := []string{}
 	if vs.Secret != nil {
 		specified = append(specified, "secret")
@@ -132,8 +135,16 @@ func validateVolume(volume corev1.Volume) *apis.FieldError {
 			errs = errs.Also(validateProjectedVolumeSource(proj).ViaFieldIndex("projected", i))
 		}
 	}
+	oneOf := []string{"secret", "configMap", "projected"}
+	if cfg := config.FromContextOrDefaults(ctx); cfg.Features.PodSpecVolumesEmptyDir != config.Disabled {
+		oneOf = append(oneOf, "emptyDir")
+		if vs.EmptyDir != nil {
+			specified = append(specified, "emptyDir")
+			errs = errs.Also(validateEmptyDirVolumeSource(vs.EmptyDir, cfg.Defaults.MaxEmptyDirSizeLimit).ViaField("emptyDir"))
+		}
+	}
 	if len(specified) == 0 {
-		errs = errs.Also(apis.ErrMissingOneOf("secret", "configMap", "projected"))
+		errs = errs.Also(apis.ErrMissingOneOf(oneOf...))
 	} else if len(specified) > 1 {
 		errs = errs.Also(apis.ErrMultipleOneOf(specified...))
 	}
@@ -141,6 +152,27 @@ func validateVolume(volume corev1.Volume) *apis.FieldError {
 	return errs
 }
 
+// validateEmptyDirVolumeSource validates the medium of the emptyDir and its
+// sizeLimit against maxSizeLimit, if any.
+func validateEmptyDirVolumeSource(ed *corev1.EmptyDirVolumeSource, maxSizeLimit *resource.Quantity) *apis.FieldError {
+	var errs *apis.FieldError
+	if ed.Medium != corev1.StorageMediumDefault && ed.Medium != corev1.StorageMediumMemory {
+		errs = errs.Also(apis.ErrInvalidValue(ed.Medium, "medium"))
+	}
+	if ed.SizeLimit != nil {
+		if ed.SizeLimit.Sign() <= 0 {
+			errs = errs.Also(apis.ErrInvalidValue(ed.SizeLimit.String(), "sizeLimit"))
+		} else if maxSizeLimit != nil && ed.SizeLimit.Cmp(*maxSizeLimit) > 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("invalid value: %s", ed.SizeLimit),
+				Paths:   []string{"sizeLimit"},
+				Details: fmt.Sprintf("sizeLimit must be at most %s", maxSizeLimit),
+			})
+		}
+	}
+	return errs
+}
+
 func validateProjectedVolumeSource(vp corev1.VolumeProjection) *apis.FieldError {
 	errs := apis.CheckDisallowedFields(vp, *VolumeProjectionMask(&vp))
 	specified := []string{}
@@ -275,11 +307,37 @@ func ValidatePodSpec(ctx context.Context, ps corev1.PodSpec) *apis.FieldError {
 
 	errs = errs.Also(ValidatePodSecurityContext(ctx, ps.SecurityContext).ViaField("securityContext"))
 
-	volumes, err := ValidateVolumes(ps.Volumes, AllMountedVolumes(ps.Containers))
+	for i, alias := range ps.HostAliases {
+		errs = errs.Also(validateHostAlias(alias).ViaFieldIndex("hostAliases", i))
+	}
+
+	if ps.PriorityClassName != "" {
+		if verrs := validation.IsDNS1123Subdomain(ps.PriorityClassName); len(verrs) != 0 {
+			errs = errs.Also(apis.ErrInvalidValue(ps.PriorityClassName, "priorityClassName"))
+		}
+	}
+	if ps.RuntimeClassName != nil {
+		if verrs := validation.IsDNS1123Subdomain(*ps.RuntimeClassName); len(verrs) != 0 {
+			errs = errs.Also(apis.ErrInvalidValue(*ps.RuntimeClassName, "runtimeClassName"))
+		}
+	}
+
+	if config.FromContextOrDefaults(ctx).Features.PodSpecRestartPolicy != config.Disabled {
+		errs = errs.Also(validateRestartPolicy(ps.RestartPolicy))
+	}
+
+	volumes, err := ValidateVolumes(ctx, ps.Volumes, AllMountedVolumes(ps.Containers))
 	if err != nil {
 		errs = errs.Also(err.ViaField("volumes"))
 	}
 
+	if limit := config.FromContextOrDefaults(ctx).Defaults.MaxContainers; limit > 0 && int64(len(ps.Containers)) > limit {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("found %d containers, but at most %d are allowed", len(ps.Containers), limit),
+			Paths:   []string{"containers"},
+		})
+	}
+
 	switch len(ps.Containers) {
 	case 0:
 		errs = errs.Also(apis.ErrMissingField("containers"))
@@ -297,7 +355,41 @@ func ValidatePodSpec(ctx context.Context, ps corev1.PodSpec) *apis.FieldError {
 	return errs
 }
 
-func validateContainers(ctx context.Context, containers []corev1.Container, volumes sets.String) *apis.FieldError {
+// validateRestartPolicy rejects the restart policies the Revisions don't
+// support. Their pods always restart, so Never is not supported, and
+// OnFailure only changes how the exits of their containers are reported.
+func validateRestartPolicy(policy corev1.RestartPolicy) *apis.FieldError {
+	switch policy {
+	case "", corev1.RestartPolicyAlways, corev1.RestartPolicyOnFailure:
+		return nil
+	default:
+		return &apis.FieldError{
+			Message: fmt.Sprint("invalid value: ", policy),
+			Paths:   []string{"restartPolicy"},
+			Details: fmt.Sprintf("restartPolicy must be one of %q or %q",
+				corev1.RestartPolicyAlways, corev1.RestartPolicyOnFailure),
+		}
+	}
+}
+
+func validateHostAlias(alias corev1.HostAlias) (errs *apis.FieldError) {
+	if alias.IP == "" {
+		errs = errs.Also(apis.ErrMissingField("ip"))
+	} else if net.ParseIP(alias.IP) == nil {
+		errs = errs.Also(apis.ErrInvalidValue(alias.IP, "ip"))
+	}
+	if len(alias.Hostnames) == 0 {
+		errs = errs.Also(apis.ErrMissingField("hostnames"))
+	}
+	for i, hostname := range alias.Hostnames {
+		if verrs := validation.IsDNS1123Subdomain(hostname); len(verrs) != 0 {
+			errs = errs.Also(apis.ErrInvalidArrayValue(hostname, "hostnames", i))
+		}
+	}
+	return errs
+}
+
+func validateContainers(ctx context.Context, containers []corev1.Container, volumes map[string]corev1.Volume) *apis.FieldError {
 	var errs *apis.FieldError
 	features := config.FromContextOrDefaults(ctx).Features
 	if features.MultiContainer != config.Enabled {
@@ -305,6 +397,9 @@ func validateContainers(ctx context.Context, containers []corev1.Container, volu
 			"but found %d containers", len(containers))})
 	} else {
 		errs = errs.Also(validateContainersPorts(containers).ViaField("containers"))
+		if features.DuplicateVolumeMounts == config.Disabled {
+			errs = errs.Also(validateConflictingVolumeMounts(containers))
+		}
 		for i := range containers {
 			// Probes are not allowed on other than serving container,
 			// ref: http://bit.ly/probes-condition
@@ -318,6 +413,34 @@ func validateContainers(ctx context.Context, containers []corev1.Container, volu
 	return errs
 }
 
+// validateConflictingVolumeMounts checks that the containers mounting
+// something at the same path mount the same volume (and subPath) there.
+func validateConflictingVolumeMounts(containers []corev1.Container) *apis.FieldError {
+	var errs *apis.FieldError
+	type mount struct {
+		key       string
+		container int
+	}
+	seen := make(map[string]mount)
+	for i := range containers {
+		for j, vm := range containers[i].VolumeMounts {
+			path, key := filepath.Clean(vm.MountPath), vm.Name+"/"+vm.SubPath
+			other, ok := seen[path]
+			if !ok {
+				seen[path] = mount{key: key, container: i}
+				continue
+			}
+			if other.container != i && other.key != key {
+				errs = errs.Also((&apis.FieldError{
+					Message: fmt.Sprintf("mountPath %q is mounted from another volume by containers[%d]", path, other.container),
+					Paths:   []string{"mountPath"},
+				}).ViaFieldIndex("volumeMounts", j).ViaFieldIndex("containers", i))
+			}
+		}
+	}
+	return errs
+}
+
 // AllMountedVolumes returns all the mounted volumes in all the containers.
 func AllMountedVolumes(containers []corev1.Container) sets.String {
 	volumeNames := sets.NewString()
@@ -347,7 +470,7 @@ func validateContainersPorts(containers []corev1.Container) *apis.FieldError {
 }
 
 // validateSidecarContainer validate fields for non serving containers
-func validateSidecarContainer(ctx context.Context, container corev1.Container, volumes sets.String) *apis.FieldError {
+func validateSidecarContainer(ctx context.Context, container corev1.Container, volumes map[string]corev1.Volume) *apis.FieldError {
 	var errs *apis.FieldError
 	if container.LivenessProbe != nil {
 		errs = errs.Also(apis.CheckDisallowedFields(*container.LivenessProbe,
@@ -361,7 +484,7 @@ func validateSidecarContainer(ctx context.Context, container corev1.Container, v
 }
 
 // ValidateContainer validate fields for serving containers
-func ValidateContainer(ctx context.Context, container corev1.Container, volumes sets.String) *apis.FieldError {
+func ValidateContainer(ctx context.Context, container corev1.Container, volumes map[string]corev1.Volume) *apis.FieldError {
 	var errs *apis.FieldError
 	// Single container cannot have multiple ports
 	errs = errs.Also(portValidation(container.Ports).ViaField("ports"))
@@ -383,12 +506,12 @@ func portValidation(containerPorts []corev1.ContainerPort) *apis.FieldError {
 	return nil
 }
 
-func validate(ctx context.Context, container corev1.Container, volumes sets.String) *apis.FieldError {
+func validate(ctx context.Context, container corev1.Container, volumes map[string]corev1.Volume) *apis.FieldError {
 	if equality.Semantic.DeepEqual(container, corev1.Container{}) {
 		return apis.ErrMissingField(apis.CurrentField)
 	}
 
-	errs := apis.CheckDisallowedFields(container, *ContainerMask(&container))
+	errs := apis.CheckDisallowedFields(container, *ContainerMask(ctx, &container))
 
 	if reservedContainerNames.Has(container.Name) {
 		errs = errs.Also(&apis.FieldError{
@@ -412,12 +535,28 @@ func validate(ctx context.Context, container corev1.Container, volumes sets.Stri
 		}
 		errs = errs.Also(fe)
 	}
+	// Command and Args
+	if config.FromContextOrDefaults(ctx).Features.PodSpecCommandValidation == config.Enabled {
+		errs = errs.Also(validateCommandArgs(container.Command, container.Args))
+	}
 	// Ports
 	errs = errs.Also(validateContainerPorts(container.Ports).ViaField("ports"))
 	// Resources
 	errs = errs.Also(validateResources(&container.Resources).ViaField("resources"))
 	// SecurityContext
 	errs = errs.Also(validateSecurityContext(ctx, container.SecurityContext).ViaField("securityContext"))
+	// TerminationMessagePath
+	if container.TerminationMessagePath != "" && !filepath.IsAbs(container.TerminationMessagePath) {
+		errs = errs.Also(apis.ErrInvalidValue(container.TerminationMessagePath, "terminationMessagePath"))
+	}
+	// WorkingDir
+	if container.WorkingDir != "" && !filepath.IsAbs(container.WorkingDir) {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("invalid value: %s", container.WorkingDir),
+			Paths:   []string{"workingDir"},
+			Details: "workingDir must be an absolute path",
+		})
+	}
 	// TerminationMessagePolicy
 	switch container.TerminationMessagePolicy {
 	case corev1.TerminationMessageReadFile, corev1.TerminationMessageFallbackToLogsOnError, "":
@@ -425,11 +564,88 @@ func validate(ctx context.Context, container corev1.Container, volumes sets.Stri
 		errs = errs.Also(apis.ErrInvalidValue(container.TerminationMessagePolicy, "terminationMessagePolicy"))
 	}
 	// VolumeMounts
-	errs = errs.Also(validateVolumeMounts(container.VolumeMounts, volumes).ViaField("volumeMounts"))
+	errs = errs.Also(validateVolumeMounts(ctx, container.VolumeMounts, volumes).ViaField("volumeMounts"))
+	// Lifecycle
+	if config.FromContextOrDefaults(ctx).Features.PodSpecPostStart != config.Disabled {
+		errs = errs.Also(validateLifecycle(container.Lifecycle).ViaField("lifecycle"))
+	}
 
 	return errs
 }
 
+// validateHookPort validates the port of a lifecycle hook action. The hooks
+// are run against the pod, so they must not reach the queue-proxy.
+func validateHookPort(port intstr.IntOrString) *apis.FieldError {
+	switch {
+	case port.String() == "0":
+		return apis.ErrMissingField("port")
+	case reservedPorts.Has(int32(port.IntValue())):
+		return apis.ErrInvalidValue(port.String(), "port")
+	}
+	return nil
+}
+
+// validateLifecycle validates the lifecycle hooks of a container, of which
+// only postStart may be set, the preStop hook being reserved for draining
+// through the queue-proxy.
+func validateLifecycle(lc *corev1.Lifecycle) *apis.FieldError {
+	if lc == nil {
+		return nil
+	}
+	errs := apis.CheckDisallowedFields(*lc, *LifecycleMask(lc))
+
+	if h := lc.PostStart; h != nil {
+		herrs := validateHandler(*h, true /*withPort*/)
+		if h.HTTPGet != nil {
+			herrs = herrs.Also(validateHookPort(h.HTTPGet.Port).ViaField("httpGet"))
+		}
+		if h.TCPSocket != nil {
+			herrs = herrs.Also(validateHookPort(h.TCPSocket.Port).ViaField("tcpSocket"))
+		}
+		errs = errs.Also(herrs.ViaField("postStart"))
+	}
+	return errs
+}
+
+// validateCommandArgs rejects the command and args that are likely mistakes,
+// i.e. empty entries and a whole command line in the first entry of the
+// command. The entries are passed to the process as is, without being split
+// by a shell, so such an executable is never found.
+func validateCommandArgs(command, args []string) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, c := range command {
+		errs = errs.Also(validateCommandArgsEntry(c, "command", i))
+	}
+	if len(command) > 0 && strings.ContainsAny(strings.TrimSpace(command[0]), " \t\n") {
+		errs = errs.Also(notSplitError(command[0], "command", 0))
+	}
+	for i, a := range args {
+		errs = errs.Also(validateCommandArgsEntry(a, "args", i))
+	}
+	return errs
+}
+
+func validateCommandArgsEntry(value, field string, index int) *apis.FieldError {
+	if strings.TrimSpace(value) != "" {
+		return nil
+	}
+	fe := apis.ErrInvalidArrayValue(fmt.Sprintf("%q", value), field, index)
+	fe.Details = field + " entries must not be empty"
+	return fe
+}
+
+func notSplitError(value, field string, index int) *apis.FieldError {
+	fields := strings.Fields(value)
+	quoted := make([]string, len(fields))
+	for i, f := range fields {
+		quoted[i] = fmt.Sprintf("%q", f)
+	}
+	fe := apis.ErrInvalidArrayValue(fmt.Sprintf("%q", value), field, index)
+	fe.Details = fmt.Sprintf("%s entries are not split by a shell, use one entry per argument: [%s]",
+		field, strings.Join(quoted, ", "))
+	return fe
+}
+
 func validateResources(resources *corev1.ResourceRequirements) *apis.FieldError {
 	if resources == nil {
 		return nil
@@ -459,23 +675,23 @@ func validateSecurityContext(ctx context.Context, sc *corev1.SecurityContext) *a
 	return errs
 }
 
-func validateVolumeMounts(mounts []corev1.VolumeMount, volumes sets.String) *apis.FieldError {
+func validateVolumeMounts(ctx context.Context, mounts []corev1.VolumeMount, volumes map[string]corev1.Volume) *apis.FieldError {
 	var errs *apis.FieldError
-	// Check that volume mounts match names in "volumes", that "volumes" has 100%
-	// coverage, and the field restrictions.
-	seenName := make(sets.String, len(mounts))
+	features := config.FromContextOrDefaults(ctx).Features
+	allowNested := features.NestedVolumeMounts != config.Disabled
+	// Check that volume mounts match names in "volumes" and the field
+	// restrictions. The unused volumes are caught by ValidateVolumes.
 	seenMountPath := make(sets.String, len(mounts))
 	for i, vm := range mounts {
 		errs = errs.Also(apis.CheckDisallowedFields(vm, *VolumeMountMask(&vm)).ViaIndex(i))
 		// This effectively checks that Name is non-empty because Volume name must be non-empty.
-		if !volumes.Has(vm.Name) {
+		volume, ok := volumes[vm.Name]
+		if !ok {
 			errs = errs.Also((&apis.FieldError{
 				Message: "volumeMount has no matching volume",
 				Paths:   []string{"name"},
 			}).ViaIndex(i))
 		}
-		seenName.Insert(vm.Name)
-
 		if vm.MountPath == "" {
 			errs = errs.Also(apis.ErrMissingField("mountPath").ViaIndex(i))
 		} else if reservedPaths.Has(filepath.Clean(vm.MountPath)) {
@@ -488,10 +704,23 @@ func validateVolumeMounts(mounts []corev1.VolumeMount, volumes sets.String) *api
 		} else if seenMountPath.Has(filepath.Clean(vm.MountPath)) {
 			errs = errs.Also(apis.ErrInvalidValue(
 				fmt.Sprintf("%q must be unique", vm.MountPath), "mountPath").ViaIndex(i))
+		} else if !allowNested {
+			// The volumes are read-only, so the kubelet can't create the
+			// mount point of a volume mounted within another one.
+			for j, other := range mounts[:i] {
+				if p, ok := nestedMountPath(vm.MountPath, other.MountPath); ok {
+					errs = errs.Also((&apis.FieldError{
+						Message: fmt.Sprintf("mountPath %q conflicts with the mountPath %q of volumeMounts[%d]", vm.MountPath, other.MountPath, j),
+						Details: fmt.Sprintf("%q is nested within the other mount", p),
+						Paths:   []string{"mountPath"},
+					}).ViaIndex(i))
+				}
+			}
 		}
 		seenMountPath.Insert(filepath.Clean(vm.MountPath))
 
-		if !vm.ReadOnly {
+		// The emptyDir volumes are scratch space, all the others are read-only.
+		if !vm.ReadOnly && volume.EmptyDir == nil {
 			errs = errs.Also(apis.ErrMissingField("readOnly").ViaIndex(i))
 		}
 
@@ -499,6 +728,22 @@ func validateVolumeMounts(mounts []corev1.VolumeMount, volumes sets.String) *api
 	return errs
 }
 
+// nestedMountPath returns the one of the two absolute mount paths nested
+// within the other, if any.
+func nestedMountPath(a, b string) (string, bool) {
+	if b == "" || !filepath.IsAbs(b) {
+		return "", false
+	}
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	switch {
+	case strings.HasPrefix(a, b+"/"):
+		return a, true
+	case strings.HasPrefix(b, a+"/"):
+		return b, true
+	}
+	return "", false
+}
+
 func validateContainerPorts(ports []corev1.ContainerPort) *apis.FieldError {
 	if len(ports) == 0 {
 		return nil
@@ -591,19 +836,33 @@ func validateProbe(p *corev1.Probe) *apis.FieldError {
 		return nil
 	}
 	errs := apis.CheckDisallowedFields(*p, *ProbeMask(p))
+	return errs.Also(validateHandler(p.Handler, false /*withPort*/))
+}
 
-	h := p.Handler
-	errs = errs.Also(apis.CheckDisallowedFields(h, *HandlerMask(&h)))
+// validateHandler validates the action of a probe or lifecycle hook, of
+// which exactly one must be set. The port of the probes is the user port,
+// while the hooks name theirs, when withPort is true.
+func validateHandler(h corev1.Handler, withPort bool) *apis.FieldError {
+	errs := apis.CheckDisallowedFields(h, *HandlerMask(&h))
 
 	var handlers []string
 
 	if h.HTTPGet != nil {
 		handlers = append(handlers, "httpGet")
-		errs = errs.Also(apis.CheckDisallowedFields(*h.HTTPGet, *HTTPGetActionMask(h.HTTPGet))).ViaField("httpGet")
+		mask := HTTPGetActionMask(h.HTTPGet)
+		if withPort {
+			mask.Port = h.HTTPGet.Port
+		}
+		errs = errs.Also(apis.CheckDisallowedFields(*h.HTTPGet, *mask),
+			validateURIScheme(h.HTTPGet.Scheme)).ViaField("httpGet")
 	}
 	if h.TCPSocket != nil {
 		handlers = append(handlers, "tcpSocket")
-		errs = errs.Also(apis.CheckDisallowedFields(*h.TCPSocket, *TCPSocketActionMask(h.TCPSocket))).ViaField("tcpSocket")
+		mask := TCPSocketActionMask(h.TCPSocket)
+		if withPort {
+			mask.Port = h.TCPSocket.Port
+		}
+		errs = errs.Also(apis.CheckDisallowedFields(*h.TCPSocket, *mask)).ViaField("tcpSocket")
 	}
 	if h.Exec != nil {
 		handlers = append(handlers, "exec")
@@ -618,6 +877,15 @@ func validateProbe(p *corev1.Probe) *apis.FieldError {
 	return errs
 }
 
+func validateURIScheme(scheme corev1.URIScheme) *apis.FieldError {
+	switch scheme {
+	case "", corev1.URISchemeHTTP, corev1.URISchemeHTTPS:
+		return nil
+	default:
+		return apis.ErrInvalidValue(scheme, "scheme")
+	}
+}
+
 func ValidateNamespacedObjectReference(p *corev1.ObjectReference) *apis.FieldError {
 	if p == nil {
 		return nil
@@ -682,6 +950,18 @@ func ValidatePodSecurityContext(ctx context.Context, sc *corev1.PodSecurityConte
 		}
 	}
 
+	if features := config.FromContextOrDefaults(ctx).Features; features.PodSpecSysctls != config.Disabled {
+		for i, sysctl := range sc.Sysctls {
+			if !features.AllowedSysctls.Has(sysctl.Name) {
+				errs = errs.Also((&apis.FieldError{
+					Message: fmt.Sprintf("sysctl %q is not allowed", sysctl.Name),
+					Paths:   []string{"name"},
+					Details: "allowed sysctls: " + strings.Join(features.AllowedSysctls.List(), ", "),
+				}).ViaFieldIndex("sysctls", i))
+			}
+		}
+	}
+
 	return errs
 }
 
@@ -41,6 +41,20 @@ func withMultiContainerDisabled() configOption {
 	}
 }
 
+func withDuplicateVolumeMountsDisabled() configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Features.DuplicateVolumeMounts = config.Disabled
+		return cfg
+	}
+}
+
+func withNestedVolumeMountsDisabled() configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Features.NestedVolumeMounts = config.Disabled
+		return cfg
+	}
+}
+
 func withPodSpecFieldRefEnabled() configOption {
 	return func(cfg *config.Config) *config.Config {
 		cfg.Features.PodSpecFieldRef = config.Enabled
@@ -69,6 +83,96 @@ func withPodSpecTolerationsEnabled() configOption {
 	}
 }
 
+func withPodSpecHostAliasesEnabled() configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Features.PodSpecHostAliases = config.Enabled
+		return cfg
+	}
+}
+
+func withPodSpecPriorityClassNameEnabled() configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Features.PodSpecPriorityClassName = config.Enabled
+		return cfg
+	}
+}
+
+func withPodSpecPostStartEnabled() configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Features.PodSpecPostStart = config.Enabled
+		return cfg
+	}
+}
+
+func withPodSpecRuntimeClassNameEnabled() configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Features.PodSpecRuntimeClassName = config.Enabled
+		return cfg
+	}
+}
+
+func withPodSpecShareProcessNamespaceEnabled() configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Features.PodSpecShareProcessNamespace = config.Enabled
+		return cfg
+	}
+}
+
+func withPodSpecRestartPolicyEnabled() configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Features.PodSpecRestartPolicy = config.Enabled
+		return cfg
+	}
+}
+
+func withPodSpecVolumesEmptyDirEnabled() configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Features.PodSpecVolumesEmptyDir = config.Enabled
+		return cfg
+	}
+}
+
+func withMaxEmptyDirSizeLimit(limit string) configOption {
+	return func(cfg *config.Config) *config.Config {
+		q := resource.MustParse(limit)
+		cfg.Defaults.MaxEmptyDirSizeLimit = &q
+		return cfg
+	}
+}
+
+func withMaxContainers(max int64) configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Defaults.MaxContainers = max
+		return cfg
+	}
+}
+
+func resourceQuantity(q string) *resource.Quantity {
+	v := resource.MustParse(q)
+	return &v
+}
+
+// secretVolumes returns the named secret volumes, by name.
+func secretVolumes(names ...string) map[string]corev1.Volume {
+	volumes := make(map[string]corev1.Volume, len(names))
+	for _, name := range names {
+		volumes[name] = corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: name},
+			},
+		}
+	}
+	return volumes
+}
+
+func withPodSpecCommandValidationEnabled() configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Features.PodSpecCommandValidation = config.Enabled
+		return cfg
+	}
+}
+
 func withPodSpecSecurityContextEnabled() configOption {
 	return func(cfg *config.Config) *config.Config {
 		cfg.Features.PodSpecSecurityContext = config.Enabled
@@ -76,6 +180,13 @@ func withPodSpecSecurityContextEnabled() configOption {
 	}
 }
 
+func withPodSpecSysctlsEnabled() configOption {
+	return func(cfg *config.Config) *config.Config {
+		cfg.Features.PodSpecSysctls = config.Enabled
+		return cfg
+	}
+}
+
 func TestPodSpecValidation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -267,6 +378,38 @@ func TestPodSpecMultiContainerValidation(t *testing.T) {
 		},
 		cfgOpts: []configOption{withMultiContainerDisabled()},
 		want:    &apis.FieldError{Message: "multi-container is off, but found 2 containers"},
+	}, {
+		name: "at the maximum number of containers",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+			}, {
+				Image: "helloworld",
+			}},
+		},
+		cfgOpts: []configOption{withMaxContainers(2)},
+	}, {
+		name: "above the maximum number of containers",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+			}, {
+				Image: "helloworld",
+			}, {
+				Image: "logshipper",
+			}},
+		},
+		cfgOpts: []configOption{withMaxContainers(2)},
+		want: &apis.FieldError{
+			Message: "found 3 containers, but at most 2 are allowed",
+			Paths:   []string{"containers"},
+		},
 	}, {
 		name: "flag enabled: more than one container with one container port",
 		ps: corev1.PodSpec{
@@ -280,6 +423,116 @@ func TestPodSpecMultiContainerValidation(t *testing.T) {
 			}},
 		},
 		want: nil,
+	}, {
+		name: "volume shared across containers at the same path",
+		ps: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				Name:         "the-name",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}},
+			Containers: []corev1.Container{{
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+				VolumeMounts: []corev1.VolumeMount{{
+					MountPath: "/shared",
+					Name:      "the-name",
+				}},
+			}, {
+				Image: "helloworld",
+				VolumeMounts: []corev1.VolumeMount{{
+					MountPath: "/shared/",
+					Name:      "the-name",
+				}},
+			}},
+		},
+		cfgOpts: []configOption{withPodSpecVolumesEmptyDirEnabled()},
+	}, {
+		name: "volume shared across containers at different paths",
+		ps: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				Name:         "the-name",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}},
+			Containers: []corev1.Container{{
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+				VolumeMounts: []corev1.VolumeMount{{
+					MountPath: "/shared",
+					Name:      "the-name",
+				}},
+			}, {
+				Image: "helloworld",
+				VolumeMounts: []corev1.VolumeMount{{
+					MountPath: "/elsewhere",
+					Name:      "the-name",
+				}},
+			}},
+		},
+		cfgOpts: []configOption{withPodSpecVolumesEmptyDirEnabled(), withDuplicateVolumeMountsDisabled()},
+	}, {
+		name: "different volumes at the same path across containers",
+		ps: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				Name:         "the-name",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}, {
+				Name:         "the-other-name",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}},
+			Containers: []corev1.Container{{
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+				VolumeMounts: []corev1.VolumeMount{{
+					MountPath: "/shared",
+					Name:      "the-name",
+				}},
+			}, {
+				Image: "helloworld",
+				VolumeMounts: []corev1.VolumeMount{{
+					MountPath: "/shared",
+					Name:      "the-other-name",
+				}},
+			}},
+		},
+		cfgOpts: []configOption{withPodSpecVolumesEmptyDirEnabled()},
+	}, {
+		name: "different volumes at the same path across containers, duplicates disabled",
+		ps: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				Name:         "the-name",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}, {
+				Name:         "the-other-name",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}},
+			Containers: []corev1.Container{{
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+				VolumeMounts: []corev1.VolumeMount{{
+					MountPath: "/shared",
+					Name:      "the-name",
+				}},
+			}, {
+				Image: "helloworld",
+				VolumeMounts: []corev1.VolumeMount{{
+					MountPath: "/shared/",
+					Name:      "the-other-name",
+				}},
+			}},
+		},
+		cfgOpts: []configOption{withPodSpecVolumesEmptyDirEnabled(), withDuplicateVolumeMountsDisabled()},
+		want: &apis.FieldError{
+			Message: `mountPath "/shared" is mounted from another volume by containers[0]`,
+			Paths:   []string{"containers[1].volumeMounts[0].mountPath"},
+		},
 	}, {
 		name: "flag enabled: probes are not allowed for non serving containers",
 		ps: corev1.PodSpec{
@@ -597,6 +850,59 @@ func TestPodSpecFeatureValidation(t *testing.T) {
 			Paths:   []string{"tolerations"},
 		},
 		cfgOpts: []configOption{withPodSpecTolerationsEnabled()},
+	}, {
+		name: "HostAliases",
+		featureSpec: corev1.PodSpec{
+			HostAliases: []corev1.HostAlias{{
+				IP:        "10.0.0.1",
+				Hostnames: []string{"legacy.example.com"},
+			}},
+		},
+		err: &apis.FieldError{
+			Message: "must not set the field(s)",
+			Paths:   []string{"hostAliases"},
+		},
+		cfgOpts: []configOption{withPodSpecHostAliasesEnabled()},
+	}, {
+		name: "PriorityClassName",
+		featureSpec: corev1.PodSpec{
+			PriorityClassName: "critical",
+		},
+		err: &apis.FieldError{
+			Message: "must not set the field(s)",
+			Paths:   []string{"priorityClassName"},
+		},
+		cfgOpts: []configOption{withPodSpecPriorityClassNameEnabled()},
+	}, {
+		name: "RuntimeClassName",
+		featureSpec: corev1.PodSpec{
+			RuntimeClassName: ptr.String("gvisor"),
+		},
+		err: &apis.FieldError{
+			Message: "must not set the field(s)",
+			Paths:   []string{"runtimeClassName"},
+		},
+		cfgOpts: []configOption{withPodSpecRuntimeClassNameEnabled()},
+	}, {
+		name: "ShareProcessNamespace",
+		featureSpec: corev1.PodSpec{
+			ShareProcessNamespace: ptr.Bool(true),
+		},
+		err: &apis.FieldError{
+			Message: "must not set the field(s)",
+			Paths:   []string{"shareProcessNamespace"},
+		},
+		cfgOpts: []configOption{withPodSpecShareProcessNamespaceEnabled()},
+	}, {
+		name: "RestartPolicy",
+		featureSpec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyOnFailure,
+		},
+		err: &apis.FieldError{
+			Message: "must not set the field(s)",
+			Paths:   []string{"restartPolicy"},
+		},
+		cfgOpts: []configOption{withPodSpecRestartPolicyEnabled()},
 	}, {
 		name: "PodSpecSecurityContext",
 		featureSpec: corev1.PodSpec{
@@ -607,6 +913,21 @@ func TestPodSpecFeatureValidation(t *testing.T) {
 			Paths:   []string{"securityContext"},
 		},
 		cfgOpts: []configOption{withPodSpecSecurityContextEnabled()},
+	}, {
+		name: "PodSpecSysctls",
+		featureSpec: corev1.PodSpec{
+			SecurityContext: &corev1.PodSecurityContext{
+				Sysctls: []corev1.Sysctl{{
+					Name:  "net.ipv4.tcp_syncookies",
+					Value: "1",
+				}},
+			},
+		},
+		err: &apis.FieldError{
+			Message: "must not set the field(s)",
+			Paths:   []string{"securityContext", "securityContext.sysctls"},
+		},
+		cfgOpts: []configOption{withPodSpecSysctlsEnabled()},
 	}}
 
 	featureTests := []struct {
@@ -791,7 +1112,7 @@ func TestContainerValidation(t *testing.T) {
 		name    string
 		c       corev1.Container
 		want    *apis.FieldError
-		volumes sets.String
+		volumes map[string]corev1.Volume
 		cfgOpts []configOption
 	}{{
 		name: "empty container",
@@ -821,6 +1142,70 @@ func TestContainerValidation(t *testing.T) {
 			Lifecycle: &corev1.Lifecycle{},
 		},
 		want: apis.ErrDisallowedFields("lifecycle"),
+	}, {
+		name: "has a postStart hook",
+		c: corev1.Container{
+			Image: "foo",
+			Lifecycle: &corev1.Lifecycle{
+				PostStart: &corev1.Handler{
+					HTTPGet: &corev1.HTTPGetAction{
+						Host: "registry.example.com",
+						Port: intstr.FromInt(443),
+					},
+				},
+			},
+		},
+		cfgOpts: []configOption{withPodSpecPostStartEnabled()},
+	}, {
+		name: "has a preStop hook",
+		c: corev1.Container{
+			Image: "foo",
+			Lifecycle: &corev1.Lifecycle{
+				PreStop: &corev1.Handler{
+					Exec: &corev1.ExecAction{Command: []string{"/bye"}},
+				},
+			},
+		},
+		cfgOpts: []configOption{withPodSpecPostStartEnabled()},
+		want:    apis.ErrDisallowedFields("lifecycle.preStop"),
+	}, {
+		name: "has a postStart hook without action",
+		c: corev1.Container{
+			Image: "foo",
+			Lifecycle: &corev1.Lifecycle{
+				PostStart: &corev1.Handler{},
+			},
+		},
+		cfgOpts: []configOption{withPodSpecPostStartEnabled()},
+		want:    apis.ErrMissingOneOf("httpGet", "tcpSocket", "exec").ViaField("lifecycle.postStart"),
+	}, {
+		name: "has a postStart hook to the queue-proxy",
+		c: corev1.Container{
+			Image: "foo",
+			Lifecycle: &corev1.Lifecycle{
+				PostStart: &corev1.Handler{
+					TCPSocket: &corev1.TCPSocketAction{
+						Port: intstr.FromInt(8012),
+					},
+				},
+			},
+		},
+		cfgOpts: []configOption{withPodSpecPostStartEnabled()},
+		want:    apis.ErrInvalidValue("8012", "lifecycle.postStart.tcpSocket.port"),
+	}, {
+		name: "has a postStart hook without port",
+		c: corev1.Container{
+			Image: "foo",
+			Lifecycle: &corev1.Lifecycle{
+				PostStart: &corev1.Handler{
+					HTTPGet: &corev1.HTTPGetAction{
+						Path: "/warm",
+					},
+				},
+			},
+		},
+		cfgOpts: []configOption{withPodSpecPostStartEnabled()},
+		want:    apis.ErrMissingField("lifecycle.postStart.httpGet.port"),
 	}, {
 		name: "has resources",
 		c: corev1.Container{
@@ -1027,20 +1412,37 @@ func TestContainerValidation(t *testing.T) {
 				ReadOnly:  true,
 			}},
 		},
-		volumes: sets.NewString("the-name"),
+		volumes: secretVolumes("the-name"),
 	}, {
-		name: "has known volumeMounts, but at reserved path",
+		name: "has writable volumeMounts of an emptyDir",
 		c: corev1.Container{
 			Image: "foo",
 			VolumeMounts: []corev1.VolumeMount{{
-				MountPath: "//var//log//",
-				Name:      "the-name",
-				ReadOnly:  true,
+				MountPath: "/scratch",
+				Name:      "scratch",
 			}},
 		},
-		volumes: sets.NewString("the-name"),
-		want: (&apis.FieldError{
-			Message: `mountPath "/var/log" is a reserved path`,
+		volumes: map[string]corev1.Volume{
+			"scratch": {
+				Name: "scratch",
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			},
+		},
+	}, {
+		name: "has known volumeMounts, but at reserved path",
+		c: corev1.Container{
+			Image: "foo",
+			VolumeMounts: []corev1.VolumeMount{{
+				MountPath: "//var//log//",
+				Name:      "the-name",
+				ReadOnly:  true,
+			}},
+		},
+		volumes: secretVolumes("the-name"),
+		want: (&apis.FieldError{
+			Message: `mountPath "/var/log" is a reserved path`,
 			Paths:   []string{"mountPath"},
 		}).ViaFieldIndex("volumeMounts", 0),
 	}, {
@@ -1053,7 +1455,7 @@ func TestContainerValidation(t *testing.T) {
 				ReadOnly:  true,
 			}},
 		},
-		volumes: sets.NewString("the-name"),
+		volumes: secretVolumes("the-name"),
 		want:    apis.ErrInvalidValue("not/absolute", "volumeMounts[0].mountPath"),
 	}, {
 		name: "has lifecycle",
@@ -1076,7 +1478,80 @@ func TestContainerValidation(t *testing.T) {
 				ReadOnly:  true,
 			}},
 		},
-		volumes: sets.NewString("the-name"),
+		volumes: secretVolumes("the-name"),
+	}, {
+		name: "has known volumeMount twice, duplicates disabled",
+		c: corev1.Container{
+			Image: "foo",
+			VolumeMounts: []corev1.VolumeMount{{
+				MountPath: "/mount/path",
+				Name:      "the-name",
+				ReadOnly:  true,
+			}, {
+				MountPath: "/another/mount/path",
+				Name:      "the-name",
+				ReadOnly:  true,
+			}},
+		},
+		volumes: secretVolumes("the-name"),
+		cfgOpts: []configOption{withDuplicateVolumeMountsDisabled()},
+	}, {
+		name: "has known volumeMount twice with distinct subPaths",
+		c: corev1.Container{
+			Image: "foo",
+			VolumeMounts: []corev1.VolumeMount{{
+				MountPath: "/mount/path",
+				SubPath:   "a",
+				Name:      "the-name",
+				ReadOnly:  true,
+			}, {
+				MountPath: "/another/mount/path",
+				SubPath:   "b",
+				Name:      "the-name",
+				ReadOnly:  true,
+			}},
+		},
+		volumes: secretVolumes("the-name"),
+	}, {
+		name: "has volumeMount nested within another",
+		c: corev1.Container{
+			Image: "foo",
+			VolumeMounts: []corev1.VolumeMount{{
+				MountPath: "/mount/path/nested/",
+				Name:      "the-name",
+				ReadOnly:  true,
+			}, {
+				MountPath: "/mount/path",
+				Name:      "the-other-name",
+				ReadOnly:  true,
+			}},
+		},
+		volumes: secretVolumes("the-name", "the-other-name"),
+	}, {
+		name: "has volumeMount nested within another, nested mounts disabled",
+		c: corev1.Container{
+			Image: "foo",
+			VolumeMounts: []corev1.VolumeMount{{
+				MountPath: "/mount/path/nested/",
+				Name:      "the-name",
+				ReadOnly:  true,
+			}, {
+				MountPath: "/mount/path",
+				Name:      "the-other-name",
+				ReadOnly:  true,
+			}, {
+				MountPath: "/mount/pathological",
+				Name:      "the-third-name",
+				ReadOnly:  true,
+			}},
+		},
+		volumes: secretVolumes("the-name", "the-other-name", "the-third-name"),
+		cfgOpts: []configOption{withNestedVolumeMountsDisabled()},
+		want: &apis.FieldError{
+			Message: `mountPath "/mount/path" conflicts with the mountPath "/mount/path/nested/" of volumeMounts[0]`,
+			Details: `"/mount/path/nested" is nested within the other mount`,
+			Paths:   []string{"volumeMounts[1].mountPath"},
+		},
 	}, {
 		name: "valid with probes (no port)",
 		c: corev1.Container{
@@ -1179,6 +1654,35 @@ func TestContainerValidation(t *testing.T) {
 			},
 		},
 		want: apis.ErrDisallowedFields("readinessProbe.httpGet.port"),
+	}, {
+		name: "valid readiness http probe with HTTPS scheme",
+		c: corev1.Container{
+			Image: "foo",
+			ReadinessProbe: &corev1.Probe{
+				SuccessThreshold: 1,
+				Handler: corev1.Handler{
+					HTTPGet: &corev1.HTTPGetAction{
+						Path:   "/",
+						Scheme: corev1.URISchemeHTTPS,
+					},
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid liveness http probe (bad scheme)",
+		c: corev1.Container{
+			Image: "foo",
+			LivenessProbe: &corev1.Probe{
+				Handler: corev1.Handler{
+					HTTPGet: &corev1.HTTPGetAction{
+						Path:   "/",
+						Scheme: "GOPHER",
+					},
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("GOPHER", "livenessProbe.httpGet.scheme"),
 	}, {
 		name: "invalid readiness probe (has failureThreshold while using special probe)",
 		c: corev1.Container{
@@ -1342,6 +1846,39 @@ func TestContainerValidation(t *testing.T) {
 			TerminationMessagePolicy: corev1.TerminationMessagePolicy("Not a Policy"),
 		},
 		want: apis.ErrInvalidValue(corev1.TerminationMessagePolicy("Not a Policy"), "terminationMessagePolicy"),
+	}, {
+		name: "termination message fallback to logs",
+		c: corev1.Container{
+			Image:                    "foo",
+			TerminationMessagePath:   "/dev/termination-log",
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		},
+		want: nil,
+	}, {
+		name: "relative termination message path",
+		c: corev1.Container{
+			Image:                  "foo",
+			TerminationMessagePath: "termination-log",
+		},
+		want: apis.ErrInvalidValue("termination-log", "terminationMessagePath"),
+	}, {
+		name: "absolute working directory",
+		c: corev1.Container{
+			Image:      "foo",
+			WorkingDir: "/srv/app",
+		},
+		want: nil,
+	}, {
+		name: "relative working directory",
+		c: corev1.Container{
+			Image:      "foo",
+			WorkingDir: "srv/app",
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: srv/app",
+			Paths:   []string{"workingDir"},
+			Details: "workingDir must be an absolute path",
+		},
 	}, {
 		name: "empty env var name",
 		c: corev1.Container{
@@ -1443,9 +1980,10 @@ func TestContainerValidation(t *testing.T) {
 
 func TestVolumeValidation(t *testing.T) {
 	tests := []struct {
-		name string
-		v    corev1.Volume
-		want *apis.FieldError
+		name    string
+		v       corev1.Volume
+		cfgOpts []configOption
+		want    *apis.FieldError
 	}{{
 		name: "just name",
 		v: corev1.Volume{
@@ -1661,11 +2199,110 @@ func TestVolumeValidation(t *testing.T) {
 			apis.ErrMissingField("projected[0].secret.items[0].path")).Also(
 			apis.ErrMissingField("projected[1].configMap.items[0].key")).Also(
 			apis.ErrMissingField("projected[1].configMap.items[0].path")),
+	}, {
+		name: "emptyDir feature disabled",
+		v: corev1.Volume{
+			Name: "foo",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		want: apis.ErrDisallowedFields("emptyDir").Also(
+			apis.ErrMissingOneOf("secret", "configMap", "projected")),
+	}, {
+		name:    "just name, emptyDir feature enabled",
+		cfgOpts: []configOption{withPodSpecVolumesEmptyDirEnabled()},
+		v: corev1.Volume{
+			Name: "foo",
+		},
+		want: apis.ErrMissingOneOf("secret", "configMap", "projected", "emptyDir"),
+	}, {
+		name:    "emptyDir volume",
+		cfgOpts: []configOption{withPodSpecVolumesEmptyDirEnabled()},
+		v: corev1.Volume{
+			Name: "foo",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}, {
+		name:    "emptyDir volume in memory within the max size limit",
+		cfgOpts: []configOption{withPodSpecVolumesEmptyDirEnabled(), withMaxEmptyDirSizeLimit("1Gi")},
+		v: corev1.Volume{
+			Name: "foo",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium:    corev1.StorageMediumMemory,
+					SizeLimit: resourceQuantity("1Gi"),
+				},
+			},
+		},
+	}, {
+		name:    "emptyDir volume over the max size limit",
+		cfgOpts: []configOption{withPodSpecVolumesEmptyDirEnabled(), withMaxEmptyDirSizeLimit("1Gi")},
+		v: corev1.Volume{
+			Name: "foo",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					SizeLimit: resourceQuantity("2Gi"),
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: 2Gi",
+			Paths:   []string{"emptyDir.sizeLimit"},
+			Details: "sizeLimit must be at most 1Gi",
+		},
+	}, {
+		name:    "emptyDir volume with a zero size limit",
+		cfgOpts: []configOption{withPodSpecVolumesEmptyDirEnabled()},
+		v: corev1.Volume{
+			Name: "foo",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					SizeLimit: resourceQuantity("0"),
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("0", "emptyDir.sizeLimit"),
+	}, {
+		name:    "emptyDir volume with an unsupported medium",
+		cfgOpts: []configOption{withPodSpecVolumesEmptyDirEnabled()},
+		v: corev1.Volume{
+			Name: "foo",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: corev1.StorageMediumHugePages,
+				},
+			},
+		},
+		want: apis.ErrInvalidValue(corev1.StorageMediumHugePages, "emptyDir.medium"),
+	}, {
+		name:    "emptyDir and secret volume",
+		cfgOpts: []configOption{withPodSpecVolumesEmptyDirEnabled()},
+		v: corev1.Volume{
+			Name: "foo",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: "foo",
+				},
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		want: apis.ErrMultipleOneOf("secret", "emptyDir"),
 	}}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			got := validateVolume(test.v)
+			ctx := context.Background()
+			if test.cfgOpts != nil {
+				cfg := config.FromContextOrDefaults(ctx)
+				for _, opt := range test.cfgOpts {
+					cfg = opt(cfg)
+				}
+				ctx = config.ToContext(ctx, cfg)
+			}
+			got := validateVolume(ctx, test.v)
 			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
 				t.Errorf("validateVolume (-want, +got): \n%s", diff)
 			}
@@ -1838,6 +2475,7 @@ func TestPodSpecSecurityContextValidation(t *testing.T) {
 			&config.Config{
 				Features: &config.Features{
 					PodSpecSecurityContext: config.Enabled,
+					PodSpecSysctls:         config.Disabled,
 				},
 			})
 
@@ -1849,3 +2487,350 @@ func TestPodSpecSecurityContextValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestPodSpecSysctlsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		sc      *corev1.PodSecurityContext
+		allowed sets.String
+		want    *apis.FieldError
+	}{{
+		name: "safe sysctls",
+		sc: &corev1.PodSecurityContext{
+			Sysctls: []corev1.Sysctl{{
+				Name:  "net.ipv4.tcp_syncookies",
+				Value: "1",
+			}, {
+				Name:  "net.ipv4.ip_local_port_range",
+				Value: "1024 65535",
+			}},
+		},
+	}, {
+		name: "unsafe sysctl",
+		sc: &corev1.PodSecurityContext{
+			Sysctls: []corev1.Sysctl{{
+				Name:  "net.ipv4.tcp_syncookies",
+				Value: "1",
+			}, {
+				Name:  "net.core.somaxconn",
+				Value: "1024",
+			}},
+		},
+		want: &apis.FieldError{
+			Message: `sysctl "net.core.somaxconn" is not allowed`,
+			Paths:   []string{"sysctls[1].name"},
+			Details: "allowed sysctls: kernel.shm_rmid_forced, net.ipv4.ip_local_port_range, net.ipv4.ping_group_range, net.ipv4.tcp_syncookies",
+		},
+	}, {
+		name: "configured sysctl",
+		sc: &corev1.PodSecurityContext{
+			Sysctls: []corev1.Sysctl{{
+				Name:  "net.core.somaxconn",
+				Value: "1024",
+			}},
+		},
+		allowed: sets.NewString("net.core.somaxconn"),
+	}, {
+		name: "safe sysctl not configured",
+		sc: &corev1.PodSecurityContext{
+			Sysctls: []corev1.Sysctl{{
+				Name:  "net.ipv4.tcp_syncookies",
+				Value: "1",
+			}},
+		},
+		allowed: sets.NewString("net.core.somaxconn"),
+		want: &apis.FieldError{
+			Message: `sysctl "net.ipv4.tcp_syncookies" is not allowed`,
+			Paths:   []string{"sysctls[0].name"},
+			Details: "allowed sysctls: net.core.somaxconn",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg = withPodSpecSysctlsEnabled()(cfg)
+			if test.allowed != nil {
+				cfg.Features.AllowedSysctls = test.allowed
+			}
+			ctx := config.ToContext(context.Background(), cfg)
+
+			got := ValidatePodSecurityContext(ctx, test.sc)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("ValidatePodSecurityContext(-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPodSpecHostAliasesValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		aliases []corev1.HostAlias
+		want    *apis.FieldError
+	}{{
+		name: "valid",
+		aliases: []corev1.HostAlias{{
+			IP:        "10.0.0.1",
+			Hostnames: []string{"legacy.example.com", "legacy"},
+		}, {
+			IP:        "fd00::1",
+			Hostnames: []string{"legacy-v6.example.com"},
+		}},
+	}, {
+		name: "missing fields",
+		aliases: []corev1.HostAlias{{
+			Hostnames: []string{"legacy.example.com"},
+		}, {
+			IP: "10.0.0.1",
+		}},
+		want: apis.ErrMissingField("hostAliases[0].ip", "hostAliases[1].hostnames"),
+	}, {
+		name: "invalid ip",
+		aliases: []corev1.HostAlias{{
+			IP:        "10.0.0.256",
+			Hostnames: []string{"legacy.example.com"},
+		}},
+		want: apis.ErrInvalidValue("10.0.0.256", "hostAliases[0].ip"),
+	}, {
+		name: "invalid hostname",
+		aliases: []corev1.HostAlias{{
+			IP:        "10.0.0.1",
+			Hostnames: []string{"legacy.example.com", "Legacy_Host"},
+		}},
+		want: apis.ErrInvalidArrayValue("Legacy_Host", "hostAliases[0].hostnames", 1),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg = withPodSpecHostAliasesEnabled()(cfg)
+			ctx := config.ToContext(context.Background(), cfg)
+
+			got := ValidatePodSpec(ctx, corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Image: "busybox",
+				}},
+				HostAliases: test.aliases,
+			})
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("ValidatePodSpec (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPodSpecPriorityClassNameValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		pcn  string
+		want *apis.FieldError
+	}{{
+		name: "valid",
+		pcn:  "critical-services",
+	}, {
+		name: "invalid",
+		pcn:  "Critical_Services",
+		want: apis.ErrInvalidValue("Critical_Services", "priorityClassName"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg = withPodSpecPriorityClassNameEnabled()(cfg)
+			ctx := config.ToContext(context.Background(), cfg)
+
+			got := ValidatePodSpec(ctx, corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Image: "busybox",
+				}},
+				PriorityClassName: test.pcn,
+			})
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("ValidatePodSpec (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPodSpecRuntimeClassNameValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		rcn  string
+		want *apis.FieldError
+	}{{
+		name: "valid",
+		rcn:  "gvisor",
+	}, {
+		name: "invalid",
+		rcn:  "G_Visor",
+		want: apis.ErrInvalidValue("G_Visor", "runtimeClassName"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg = withPodSpecRuntimeClassNameEnabled()(cfg)
+			ctx := config.ToContext(context.Background(), cfg)
+
+			got := ValidatePodSpec(ctx, corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Image: "busybox",
+				}},
+				RuntimeClassName: ptr.String(test.rcn),
+			})
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("ValidatePodSpec (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPodSpecRestartPolicyValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   corev1.RestartPolicy
+		disabled bool
+		want     *apis.FieldError
+	}{{
+		name:   "always",
+		policy: corev1.RestartPolicyAlways,
+	}, {
+		name:   "on failure",
+		policy: corev1.RestartPolicyOnFailure,
+	}, {
+		name:   "never",
+		policy: corev1.RestartPolicyNever,
+		want: &apis.FieldError{
+			Message: "invalid value: Never",
+			Paths:   []string{"restartPolicy"},
+			Details: `restartPolicy must be one of "Always" or "OnFailure"`,
+		},
+	}, {
+		name:   "unknown",
+		policy: "Sometimes",
+		want: &apis.FieldError{
+			Message: "invalid value: Sometimes",
+			Paths:   []string{"restartPolicy"},
+			Details: `restartPolicy must be one of "Always" or "OnFailure"`,
+		},
+	}, {
+		name:     "feature disabled",
+		policy:   corev1.RestartPolicyNever,
+		disabled: true,
+		want:     apis.ErrDisallowedFields("restartPolicy"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			if !test.disabled {
+				cfg = withPodSpecRestartPolicyEnabled()(cfg)
+			}
+			ctx := config.ToContext(context.Background(), cfg)
+
+			got := ValidatePodSpec(ctx, corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Image: "busybox",
+				}},
+				RestartPolicy: test.policy,
+			})
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("ValidatePodSpec (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestContainerCommandArgsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		args    []string
+		cfgOpts []configOption
+		want    *apis.FieldError
+	}{{
+		name:    "validation disabled",
+		command: []string{"/ko-app/server --port 8080"},
+		args:    []string{""},
+	}, {
+		name:    "no command or args",
+		cfgOpts: []configOption{withPodSpecCommandValidationEnabled()},
+	}, {
+		name:    "valid command and args",
+		command: []string{"/ko-app/server"},
+		args:    []string{"--port", "8080"},
+		cfgOpts: []configOption{withPodSpecCommandValidationEnabled()},
+	}, {
+		name:    "single arg without spaces",
+		args:    []string{"--verbose"},
+		cfgOpts: []configOption{withPodSpecCommandValidationEnabled()},
+	}, {
+		name:    "script run by a shell",
+		command: []string{"/bin/sh", "-c"},
+		args:    []string{"echo hello && sleep 10"},
+		cfgOpts: []configOption{withPodSpecCommandValidationEnabled()},
+	}, {
+		name:    "empty command entry",
+		command: []string{"/ko-app/server", ""},
+		cfgOpts: []configOption{withPodSpecCommandValidationEnabled()},
+		want: &apis.FieldError{
+			Message: `invalid value: ""`,
+			Paths:   []string{"command[1]"},
+			Details: "command entries must not be empty",
+		},
+	}, {
+		name:    "blank arg",
+		args:    []string{"--port", " "},
+		cfgOpts: []configOption{withPodSpecCommandValidationEnabled()},
+		want: &apis.FieldError{
+			Message: `invalid value: " "`,
+			Paths:   []string{"args[1]"},
+			Details: "args entries must not be empty",
+		},
+	}, {
+		name:    "command line in the command",
+		command: []string{"/ko-app/server --port 8080"},
+		cfgOpts: []configOption{withPodSpecCommandValidationEnabled()},
+		want: &apis.FieldError{
+			Message: `invalid value: "/ko-app/server --port 8080"`,
+			Paths:   []string{"command[0]"},
+			Details: `command entries are not split by a shell, use one entry per argument: ["/ko-app/server", "--port", "8080"]`,
+		},
+	}, {
+		name:    "command line in a later command entry",
+		command: []string{"/bin/sh", "-c", "echo hello && sleep 10"},
+		cfgOpts: []configOption{withPodSpecCommandValidationEnabled()},
+	}, {
+		name:    "single arg with spaces",
+		command: []string{"/ko-app/server"},
+		args:    []string{"--greeting=Hello World"},
+		cfgOpts: []configOption{withPodSpecCommandValidationEnabled()},
+	}, {
+		name:    "script for the shell entrypoint of the image",
+		args:    []string{"echo hello && sleep 10"},
+		cfgOpts: []configOption{withPodSpecCommandValidationEnabled()},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.cfgOpts != nil {
+				cfg := config.FromContextOrDefaults(ctx)
+				for _, opt := range test.cfgOpts {
+					cfg = opt(cfg)
+				}
+				ctx = config.ToContext(ctx, cfg)
+			}
+
+			got := ValidateContainer(ctx, corev1.Container{
+				Image:   "busybox",
+				Command: test.command,
+				Args:    test.args,
+			}, nil)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("ValidateContainer (-want, +got): \n%s", diff)
+			}
+		})
+	}
+}
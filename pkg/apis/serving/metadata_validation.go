@@ -19,13 +19,18 @@ package serving
 import (
 	"context"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+	"time"
 
+	"go.uber.org/zap/zapcore"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 
 	"knative.dev/pkg/apis"
 	"knative.dev/serving/pkg/apis/autoscaling"
@@ -40,8 +45,28 @@ var (
 		RoutingStateModifiedAnnotationKey,
 		GroupNamePrefix+"forceUpgrade",
 		RevisionPreservedAnnotationKey,
+		GCDisabledAnnotationKey,
 		RoutesAnnotationKey,
+		MaxPodLifetimeAnnotationKey,
+		MinTLSVersionAnnotationKey,
+		DefaultBackendAnnotationKey,
+		NodePoolAnnotationKey,
+		MaintenanceAnnotationKey,
+		DrainTimeoutAnnotationKey,
+		WorkloadRBACAnnotationKey,
+		RevisionHistoryLimitAnnotationKey,
+		ConcurrencyWarmupAnnotationKey,
+		TerminationGracePeriodAnnotationKey,
+		TopologyAwareHintsAnnotationKey,
+		GoRuntimeEnvAnnotationKey,
 	)
+
+	// supportedTLSVersions are the values accepted by MinTLSVersionAnnotationKey.
+	supportedTLSVersions = sets.NewString("1.0", "1.1", "1.2", "1.3")
+
+	// workloadRBACVerbs are the verbs accepted by WorkloadRBACAnnotationKey.
+	// Wildcards are deliberately not among them, to keep the Roles minimal.
+	workloadRBACVerbs = sets.NewString("get", "list", "watch", "create", "update", "patch", "delete")
 )
 
 // ValidateObjectMetadata validates that `metadata` stanza of the
@@ -64,24 +89,256 @@ func validateKnativeAnnotations(annotations map[string]string) (errs *apis.Field
 }
 
 // ValidateQueueSidecarAnnotation validates QueueSideCarResourcePercentageAnnotation
+// and QueueSideCarLogLevelAnnotation
 func ValidateQueueSidecarAnnotation(annotations map[string]string) *apis.FieldError {
 	if len(annotations) == 0 {
 		return nil
 	}
-	v, ok := annotations[QueueSideCarResourcePercentageAnnotation]
+	var errs *apis.FieldError
+	if v, ok := annotations[QueueSideCarResourcePercentageAnnotation]; ok {
+		value, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(QueueSideCarResourcePercentageAnnotation))
+		} else if value < 0.1 || value > 100 {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(value, 0.1, 100.0, apis.CurrentField).ViaKey(QueueSideCarResourcePercentageAnnotation))
+		}
+	}
+	if v, ok := annotations[QueueSideCarLogLevelAnnotation]; ok {
+		var level zapcore.Level
+		if err := level.UnmarshalText([]byte(v)); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(QueueSideCarLogLevelAnnotation))
+		}
+	}
+	if v, ok := annotations[QueueSideCarResourceProfileAnnotation]; ok {
+		if msgs := k8svalidation.IsDNS1123Label(v); len(msgs) != 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprint("invalid value: ", v),
+				Paths:   []string{apis.CurrentField},
+				Details: strings.Join(msgs, ", "),
+			}).ViaKey(QueueSideCarResourceProfileAnnotation)
+		}
+	}
+	if v, ok := annotations[QueueSideCarReadinessPassthroughAnnotation]; ok {
+		if _, err := strconv.ParseBool(v); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(QueueSideCarReadinessPassthroughAnnotation))
+		}
+	}
+	return errs
+}
+
+// ValidateMaxPodLifetimeAnnotation validates MaxPodLifetimeAnnotationKey
+func ValidateMaxPodLifetimeAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[MaxPodLifetimeAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(MaxPodLifetimeAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateConcurrencyWarmupAnnotation validates ConcurrencyWarmupAnnotationKey
+// against the containerConcurrency of the revision, which it ramps up to.
+func ValidateConcurrencyWarmupAnnotation(annotations map[string]string, containerConcurrency int64) *apis.FieldError {
+	v, ok := annotations[ConcurrencyWarmupAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(ConcurrencyWarmupAnnotationKey)
+	}
+	if containerConcurrency == 0 {
+		return (&apis.FieldError{
+			Message: "the concurrency warmup requires a containerConcurrency limit",
+			Paths:   []string{apis.CurrentField},
+		}).ViaKey(ConcurrencyWarmupAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateTerminationGracePeriodAnnotation validates TerminationGracePeriodAnnotationKey
+// against the timeoutSeconds of the revision, which its requests are drained for.
+func ValidateTerminationGracePeriodAnnotation(annotations map[string]string, timeoutSeconds int64) *apis.FieldError {
+	v, ok := annotations[TerminationGracePeriodAnnotationKey]
 	if !ok {
 		return nil
 	}
-	value, err := strconv.ParseFloat(v, 64)
+	seconds, err := strconv.ParseInt(v, 10, 64)
 	if err != nil {
-		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(QueueSideCarResourcePercentageAnnotation)
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(TerminationGracePeriodAnnotationKey)
+	}
+	if seconds < timeoutSeconds {
+		return apis.ErrOutOfBoundsValue(seconds, timeoutSeconds, math.MaxInt32, apis.CurrentField).
+			ViaKey(TerminationGracePeriodAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateTopologyAwareHintsAnnotation validates TopologyAwareHintsAnnotationKey
+func ValidateTopologyAwareHintsAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[TopologyAwareHintsAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if _, err := strconv.ParseBool(v); err != nil {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(TopologyAwareHintsAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateGoRuntimeEnvAnnotation validates GoRuntimeEnvAnnotationKey
+func ValidateGoRuntimeEnvAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[GoRuntimeEnvAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if _, err := strconv.ParseBool(v); err != nil {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(GoRuntimeEnvAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateRevisionHistoryLimitAnnotation validates RevisionHistoryLimitAnnotationKey
+func ValidateRevisionHistoryLimitAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[RevisionHistoryLimitAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if limit, err := strconv.ParseInt(v, 10, 32); err != nil || limit < 0 {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(RevisionHistoryLimitAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateNodePoolAnnotation validates NodePoolAnnotationKey
+func ValidateNodePoolAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[NodePoolAnnotationKey]
+	if !ok {
+		return nil
+	}
+	// The value ends up as a node label value in the pods' nodeSelector.
+	if v == "" || len(k8svalidation.IsValidLabelValue(v)) != 0 {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(NodePoolAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateMinTLSVersionAnnotation validates MinTLSVersionAnnotationKey
+func ValidateMinTLSVersionAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[MinTLSVersionAnnotationKey]
+	if !ok {
+		return nil
 	}
-	if value < 0.1 || value > 100 {
-		return apis.ErrOutOfBoundsValue(value, 0.1, 100.0, apis.CurrentField).ViaKey(QueueSideCarResourcePercentageAnnotation)
+	if !supportedTLSVersions.Has(v) {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(MinTLSVersionAnnotationKey)
 	}
 	return nil
 }
 
+// ValidateDefaultBackendAnnotation validates DefaultBackendAnnotationKey
+func ValidateDefaultBackendAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[DefaultBackendAnnotationKey]
+	if !ok {
+		return nil
+	}
+	// The value names a Revision.
+	if msgs := k8svalidation.IsDNS1123Subdomain(v); len(msgs) != 0 {
+		return (&apis.FieldError{
+			Message: fmt.Sprint("invalid value: ", v),
+			Paths:   []string{apis.CurrentField},
+			Details: strings.Join(msgs, ", "),
+		}).ViaKey(DefaultBackendAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateMaintenanceAnnotation validates MaintenanceAnnotationKey
+func ValidateMaintenanceAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[MaintenanceAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if _, err := strconv.ParseBool(v); err != nil {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(MaintenanceAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateGCDisabledAnnotation validates GCDisabledAnnotationKey
+func ValidateGCDisabledAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[GCDisabledAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if _, err := strconv.ParseBool(v); err != nil {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(GCDisabledAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateDrainTimeoutAnnotation validates DrainTimeoutAnnotationKey
+func ValidateDrainTimeoutAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[DrainTimeoutAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if d, err := time.ParseDuration(v); err != nil || d < 0 {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(DrainTimeoutAnnotationKey)
+	}
+	return nil
+}
+
+// ValidateWorkloadRBACAnnotation validates WorkloadRBACAnnotationKey
+func ValidateWorkloadRBACAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[WorkloadRBACAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if _, err := ParseWorkloadRBAC(v); err != nil {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(WorkloadRBACAnnotationKey)
+	}
+	return nil
+}
+
+// ParseWorkloadRBAC parses the value of WorkloadRBACAnnotationKey into the
+// rules of the Role granted to the workload.
+func ParseWorkloadRBAC(v string) ([]rbacv1.PolicyRule, error) {
+	var rules []rbacv1.PolicyRule
+	for _, r := range strings.Split(v, ";") {
+		parts := strings.Split(r, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("rule %q is not of the form verbs:resources", r)
+		}
+		verbs := strings.Split(parts[0], ",")
+		for _, verb := range verbs {
+			if !workloadRBACVerbs.Has(verb) {
+				return nil, fmt.Errorf("unsupported verb %q", verb)
+			}
+		}
+		// Each resource gets its own rule, since the API groups and the
+		// resources of a rule are combined with each other.
+		for _, res := range strings.Split(parts[1], ",") {
+			name, group := res, ""
+			if i := strings.Index(res, "."); i >= 0 {
+				name, group = res[:i], res[i+1:]
+				if errs := k8svalidation.IsDNS1123Subdomain(group); len(errs) != 0 {
+					return nil, fmt.Errorf("invalid API group %q: %s", group, strings.Join(errs, ", "))
+				}
+			}
+			if errs := k8svalidation.IsDNS1123Label(name); len(errs) != 0 {
+				return nil, fmt.Errorf("invalid resource %q: %s", name, strings.Join(errs, ", "))
+			}
+			rules = append(rules, rbacv1.PolicyRule{
+				Verbs:     verbs,
+				APIGroups: []string{group},
+				Resources: []string{name},
+			})
+		}
+	}
+	return rules, nil
+}
+
 // ValidateTimeoutSeconds validates timeout by comparing MaxRevisionTimeoutSeconds
 func ValidateTimeoutSeconds(ctx context.Context, timeoutSeconds int64) *apis.FieldError {
 	if timeoutSeconds != 0 {
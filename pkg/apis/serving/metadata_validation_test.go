@@ -19,13 +19,16 @@ package serving
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	authv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/ptr"
 	"knative.dev/serving/pkg/apis/autoscaling"
@@ -261,6 +264,59 @@ func TestValidateQueueSidecarAnnotation(t *testing.T) {
 		annotation: map[string]string{
 			QueueSideCarResourcePercentageAnnotation: "100",
 		},
+	}, {
+		name: "valid Queue sidecar log level annotation",
+		annotation: map[string]string{
+			QueueSideCarLogLevelAnnotation: "debug",
+		},
+	}, {
+		name: "invalid Queue sidecar log level annotation",
+		annotation: map[string]string{
+			QueueSideCarLogLevelAnnotation: "verbose",
+		},
+		expectErr: &apis.FieldError{
+			Message: "invalid value: verbose",
+			Paths:   []string{fmt.Sprintf("[%s]", QueueSideCarLogLevelAnnotation)},
+		},
+	}, {
+		name: "valid Queue sidecar resource profile annotation",
+		annotation: map[string]string{
+			QueueSideCarResourceProfileAnnotation: "large",
+		},
+	}, {
+		name: "invalid Queue sidecar resource profile annotation",
+		annotation: map[string]string{
+			QueueSideCarResourceProfileAnnotation: "Extra_Large",
+		},
+		expectErr: &apis.FieldError{
+			Message: "invalid value: Extra_Large",
+			Paths:   []string{fmt.Sprintf("[%s]", QueueSideCarResourceProfileAnnotation)},
+			Details: "a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')",
+		},
+	}, {
+		name: "valid Queue sidecar readiness passthrough annotation",
+		annotation: map[string]string{
+			QueueSideCarReadinessPassthroughAnnotation: "true",
+		},
+	}, {
+		name: "invalid Queue sidecar readiness passthrough annotation",
+		annotation: map[string]string{
+			QueueSideCarReadinessPassthroughAnnotation: "sometimes",
+		},
+		expectErr: apis.ErrInvalidValue("sometimes", apis.CurrentField).ViaKey(QueueSideCarReadinessPassthroughAnnotation),
+	}, {
+		name: "invalid Queue sidecar resource percentage and log level annotations",
+		annotation: map[string]string{
+			QueueSideCarResourcePercentageAnnotation: "200",
+			QueueSideCarLogLevelAnnotation:           "loud",
+		},
+		expectErr: (&apis.FieldError{
+			Message: "expected 0.1 <= 200 <= 100",
+			Paths:   []string{fmt.Sprintf("[%s]", QueueSideCarResourcePercentageAnnotation)},
+		}).Also(&apis.FieldError{
+			Message: "invalid value: loud",
+			Paths:   []string{fmt.Sprintf("[%s]", QueueSideCarLogLevelAnnotation)},
+		}),
 	}}
 
 	for _, c := range cases {
@@ -273,6 +329,584 @@ func TestValidateQueueSidecarAnnotation(t *testing.T) {
 	}
 }
 
+func TestValidateMaxPodLifetimeAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "valid duration",
+		annotation: map[string]string{
+			MaxPodLifetimeAnnotationKey: "24h",
+		},
+	}, {
+		name: "not a duration",
+		annotation: map[string]string{
+			MaxPodLifetimeAnnotationKey: "forever",
+		},
+		expectErr: apis.ErrInvalidValue("forever", apis.CurrentField).ViaKey(MaxPodLifetimeAnnotationKey),
+	}, {
+		name: "zero duration",
+		annotation: map[string]string{
+			MaxPodLifetimeAnnotationKey: "0s",
+		},
+		expectErr: apis.ErrInvalidValue("0s", apis.CurrentField).ViaKey(MaxPodLifetimeAnnotationKey),
+	}, {
+		name: "negative duration",
+		annotation: map[string]string{
+			MaxPodLifetimeAnnotationKey: "-1h",
+		},
+		expectErr: apis.ErrInvalidValue("-1h", apis.CurrentField).ViaKey(MaxPodLifetimeAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateMaxPodLifetimeAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateConcurrencyWarmupAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		noLimit    bool
+		expectErr  *apis.FieldError
+	}{{
+		name: "valid duration",
+		annotation: map[string]string{
+			ConcurrencyWarmupAnnotationKey: "30s",
+		},
+	}, {
+		name: "no container concurrency",
+		annotation: map[string]string{
+			ConcurrencyWarmupAnnotationKey: "30s",
+		},
+		noLimit: true,
+		expectErr: (&apis.FieldError{
+			Message: "the concurrency warmup requires a containerConcurrency limit",
+			Paths:   []string{apis.CurrentField},
+		}).ViaKey(ConcurrencyWarmupAnnotationKey),
+	}, {
+		name: "not a duration",
+		annotation: map[string]string{
+			ConcurrencyWarmupAnnotationKey: "slowly",
+		},
+		expectErr: apis.ErrInvalidValue("slowly", apis.CurrentField).ViaKey(ConcurrencyWarmupAnnotationKey),
+	}, {
+		name: "zero duration",
+		annotation: map[string]string{
+			ConcurrencyWarmupAnnotationKey: "0s",
+		},
+		expectErr: apis.ErrInvalidValue("0s", apis.CurrentField).ViaKey(ConcurrencyWarmupAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cc := int64(10)
+			if c.noLimit {
+				cc = 0
+			}
+			err := ValidateConcurrencyWarmupAnnotation(c.annotation, cc)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateTopologyAwareHintsAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "enabled",
+		annotation: map[string]string{
+			TopologyAwareHintsAnnotationKey: "true",
+		},
+	}, {
+		name: "disabled",
+		annotation: map[string]string{
+			TopologyAwareHintsAnnotationKey: "false",
+		},
+	}, {
+		name: "not a bool",
+		annotation: map[string]string{
+			TopologyAwareHintsAnnotationKey: "auto",
+		},
+		expectErr: apis.ErrInvalidValue("auto", apis.CurrentField).ViaKey(TopologyAwareHintsAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateTopologyAwareHintsAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateGoRuntimeEnvAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "enabled",
+		annotation: map[string]string{
+			GoRuntimeEnvAnnotationKey: "true",
+		},
+	}, {
+		name: "disabled",
+		annotation: map[string]string{
+			GoRuntimeEnvAnnotationKey: "false",
+		},
+	}, {
+		name: "not a bool",
+		annotation: map[string]string{
+			GoRuntimeEnvAnnotationKey: "go",
+		},
+		expectErr: apis.ErrInvalidValue("go", apis.CurrentField).ViaKey(GoRuntimeEnvAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateGoRuntimeEnvAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateRevisionHistoryLimitAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "valid limit",
+		annotation: map[string]string{
+			RevisionHistoryLimitAnnotationKey: "3",
+		},
+	}, {
+		name: "zero limit",
+		annotation: map[string]string{
+			RevisionHistoryLimitAnnotationKey: "0",
+		},
+	}, {
+		name: "negative limit",
+		annotation: map[string]string{
+			RevisionHistoryLimitAnnotationKey: "-1",
+		},
+		expectErr: apis.ErrInvalidValue("-1", apis.CurrentField).ViaKey(RevisionHistoryLimitAnnotationKey),
+	}, {
+		name: "not a number",
+		annotation: map[string]string{
+			RevisionHistoryLimitAnnotationKey: "few",
+		},
+		expectErr: apis.ErrInvalidValue("few", apis.CurrentField).ViaKey(RevisionHistoryLimitAnnotationKey),
+	}, {
+		name: "too large",
+		annotation: map[string]string{
+			RevisionHistoryLimitAnnotationKey: "4294967296",
+		},
+		expectErr: apis.ErrInvalidValue("4294967296", apis.CurrentField).ViaKey(RevisionHistoryLimitAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateRevisionHistoryLimitAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateTerminationGracePeriodAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "longer than the drain",
+		annotation: map[string]string{
+			TerminationGracePeriodAnnotationKey: "900",
+		},
+	}, {
+		name: "as long as the drain",
+		annotation: map[string]string{
+			TerminationGracePeriodAnnotationKey: "300",
+		},
+	}, {
+		name: "shorter than the drain",
+		annotation: map[string]string{
+			TerminationGracePeriodAnnotationKey: "60",
+		},
+		expectErr: apis.ErrOutOfBoundsValue(60, 300, math.MaxInt32, apis.CurrentField).ViaKey(TerminationGracePeriodAnnotationKey),
+	}, {
+		name: "not a number",
+		annotation: map[string]string{
+			TerminationGracePeriodAnnotationKey: "long",
+		},
+		expectErr: apis.ErrInvalidValue("long", apis.CurrentField).ViaKey(TerminationGracePeriodAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateTerminationGracePeriodAnnotation(c.annotation, 300)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateWorkloadRBACAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "single rule",
+		annotation: map[string]string{
+			WorkloadRBACAnnotationKey: "get,list,watch:configmaps",
+		},
+	}, {
+		name: "several rules and groups",
+		annotation: map[string]string{
+			WorkloadRBACAnnotationKey: "get:configmaps,deployments.apps;create:events",
+		},
+	}, {
+		name: "wildcard verb",
+		annotation: map[string]string{
+			WorkloadRBACAnnotationKey: "*:configmaps",
+		},
+		expectErr: apis.ErrInvalidValue("*:configmaps", apis.CurrentField).ViaKey(WorkloadRBACAnnotationKey),
+	}, {
+		name: "wildcard resource",
+		annotation: map[string]string{
+			WorkloadRBACAnnotationKey: "get:*",
+		},
+		expectErr: apis.ErrInvalidValue("get:*", apis.CurrentField).ViaKey(WorkloadRBACAnnotationKey),
+	}, {
+		name: "invalid group",
+		annotation: map[string]string{
+			WorkloadRBACAnnotationKey: "get:deployments.Apps",
+		},
+		expectErr: apis.ErrInvalidValue("get:deployments.Apps", apis.CurrentField).ViaKey(WorkloadRBACAnnotationKey),
+	}, {
+		name: "missing resources",
+		annotation: map[string]string{
+			WorkloadRBACAnnotationKey: "get,list",
+		},
+		expectErr: apis.ErrInvalidValue("get,list", apis.CurrentField).ViaKey(WorkloadRBACAnnotationKey),
+	}, {
+		name: "empty",
+		annotation: map[string]string{
+			WorkloadRBACAnnotationKey: "",
+		},
+		expectErr: apis.ErrInvalidValue("", apis.CurrentField).ViaKey(WorkloadRBACAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateWorkloadRBACAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseWorkloadRBAC(t *testing.T) {
+	got, err := ParseWorkloadRBAC("get,list:configmaps,deployments.apps;create:events")
+	if err != nil {
+		t.Fatal("ParseWorkloadRBAC() =", err)
+	}
+	want := []rbacv1.PolicyRule{{
+		Verbs:     []string{"get", "list"},
+		APIGroups: []string{""},
+		Resources: []string{"configmaps"},
+	}, {
+		Verbs:     []string{"get", "list"},
+		APIGroups: []string{"apps"},
+		Resources: []string{"deployments"},
+	}, {
+		Verbs:     []string{"create"},
+		APIGroups: []string{""},
+		Resources: []string{"events"},
+	}}
+	if !cmp.Equal(got, want) {
+		t.Error("ParseWorkloadRBAC (-want, +got) =", cmp.Diff(want, got))
+	}
+}
+
+func TestValidateNodePoolAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "valid pool",
+		annotation: map[string]string{
+			NodePoolAnnotationKey: "gpu-pool",
+		},
+	}, {
+		name: "empty pool",
+		annotation: map[string]string{
+			NodePoolAnnotationKey: "",
+		},
+		expectErr: apis.ErrInvalidValue("", apis.CurrentField).ViaKey(NodePoolAnnotationKey),
+	}, {
+		name: "invalid label value",
+		annotation: map[string]string{
+			NodePoolAnnotationKey: "gpu pool!",
+		},
+		expectErr: apis.ErrInvalidValue("gpu pool!", apis.CurrentField).ViaKey(NodePoolAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateNodePoolAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateMinTLSVersionAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "TLS 1.3",
+		annotation: map[string]string{
+			MinTLSVersionAnnotationKey: "1.3",
+		},
+	}, {
+		name: "TLS 1.0",
+		annotation: map[string]string{
+			MinTLSVersionAnnotationKey: "1.0",
+		},
+	}, {
+		name: "unknown version",
+		annotation: map[string]string{
+			MinTLSVersionAnnotationKey: "1.4",
+		},
+		expectErr: apis.ErrInvalidValue("1.4", apis.CurrentField).ViaKey(MinTLSVersionAnnotationKey),
+	}, {
+		name: "prefixed version",
+		annotation: map[string]string{
+			MinTLSVersionAnnotationKey: "TLSv1.2",
+		},
+		expectErr: apis.ErrInvalidValue("TLSv1.2", apis.CurrentField).ViaKey(MinTLSVersionAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateMinTLSVersionAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateDefaultBackendAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "revision name",
+		annotation: map[string]string{
+			DefaultBackendAnnotationKey: "hello-00001",
+		},
+	}, {
+		name: "empty",
+		annotation: map[string]string{
+			DefaultBackendAnnotationKey: "",
+		},
+		expectErr: (&apis.FieldError{
+			Message: "invalid value: ",
+			Paths:   []string{apis.CurrentField},
+			Details: strings.Join(validation.IsDNS1123Subdomain(""), ", "),
+		}).ViaKey(DefaultBackendAnnotationKey),
+	}, {
+		name: "not a name",
+		annotation: map[string]string{
+			DefaultBackendAnnotationKey: "Hello_World",
+		},
+		expectErr: (&apis.FieldError{
+			Message: "invalid value: Hello_World",
+			Paths:   []string{apis.CurrentField},
+			Details: strings.Join(validation.IsDNS1123Subdomain("Hello_World"), ", "),
+		}).ViaKey(DefaultBackendAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateDefaultBackendAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateMaintenanceAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "in maintenance",
+		annotation: map[string]string{
+			MaintenanceAnnotationKey: "true",
+		},
+	}, {
+		name: "not in maintenance",
+		annotation: map[string]string{
+			MaintenanceAnnotationKey: "false",
+		},
+	}, {
+		name: "not a bool",
+		annotation: map[string]string{
+			MaintenanceAnnotationKey: "soon",
+		},
+		expectErr: apis.ErrInvalidValue("soon", apis.CurrentField).ViaKey(MaintenanceAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateMaintenanceAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateGCDisabledAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "disabled",
+		annotation: map[string]string{
+			GCDisabledAnnotationKey: "true",
+		},
+	}, {
+		name: "not disabled",
+		annotation: map[string]string{
+			GCDisabledAnnotationKey: "false",
+		},
+	}, {
+		name: "not a bool",
+		annotation: map[string]string{
+			GCDisabledAnnotationKey: "until-friday",
+		},
+		expectErr: apis.ErrInvalidValue("until-friday", apis.CurrentField).ViaKey(GCDisabledAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateGCDisabledAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateDrainTimeoutAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name: "valid",
+		annotation: map[string]string{
+			DrainTimeoutAnnotationKey: "30s",
+		},
+	}, {
+		name: "zero",
+		annotation: map[string]string{
+			DrainTimeoutAnnotationKey: "0s",
+		},
+	}, {
+		name: "negative",
+		annotation: map[string]string{
+			DrainTimeoutAnnotationKey: "-1s",
+		},
+		expectErr: apis.ErrInvalidValue("-1s", apis.CurrentField).ViaKey(DrainTimeoutAnnotationKey),
+	}, {
+		name: "not a duration",
+		annotation: map[string]string{
+			DrainTimeoutAnnotationKey: "a while",
+		},
+		expectErr: apis.ErrInvalidValue("a while", apis.CurrentField).ViaKey(DrainTimeoutAnnotationKey),
+	}, {
+		name:       "no annotation",
+		annotation: map[string]string{},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateDrainTimeoutAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
 func TestValidateTimeoutSecond(t *testing.T) {
 	cases := []struct {
 		name      string
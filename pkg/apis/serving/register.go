@@ -37,6 +37,11 @@ const (
 	// from automatically deleting the revision.
 	RevisionPreservedAnnotationKey = GroupName + "/no-gc"
 
+	// GCDisabledAnnotationKey is the annotation key used on a Service or
+	// Configuration for preventing garbage collector from deleting any of
+	// its revisions while it is set to "true", e.g. to keep them for forensics.
+	GCDisabledAnnotationKey = GroupName + "/gc-disabled"
+
 	// RouteLabelKey is the label key attached to a Configuration indicating by
 	// which Route it is configured as traffic target.
 	// The key is also attached to Revision resources to indicate they are directly
@@ -47,6 +52,81 @@ const (
 	// triggered their creation.
 	RouteLabelKey = GroupName + "/route"
 
+	// MaxPodLifetimeAnnotationKey is the annotation key used to opt a Revision
+	// into having its pods recycled once they are older than the given duration.
+	MaxPodLifetimeAnnotationKey = GroupName + "/maxPodLifetime"
+
+	// MinTLSVersionAnnotationKey is the annotation key used on a Route or Service
+	// to request the minimum TLS version its Ingress should accept. The Ingresses
+	// terminating TLS for the Route carry it as the minTLSVersion annotation of
+	// the networking group, for the implementations that support it.
+	MinTLSVersionAnnotationKey = GroupName + "/minTLSVersion"
+
+	// DefaultBackendAnnotationKey is the annotation key used on a Route or
+	// Service to name the Revision, among its traffic targets, that serves
+	// the requests for hosts that match none of the Route's hosts.
+	DefaultBackendAnnotationKey = GroupName + "/defaultBackend"
+
+	// MaintenanceAnnotationKey is the annotation key used on a Route or Service
+	// to put it in maintenance mode: while "true", all its traffic is answered
+	// by the activator with the maintenance response from config-activator.
+	MaintenanceAnnotationKey = GroupName + "/maintenance"
+
+	// DrainTimeoutAnnotationKey is the annotation key used on a Route or
+	// Service to keep programming the Revisions that leave its traffic for
+	// the given duration, so that their in-flight requests complete while new
+	// requests go to the new targets.
+	DrainTimeoutAnnotationKey = GroupName + "/drainTimeout"
+
+	// NodePoolAnnotationKey is the annotation key used to pin the pods of a
+	// Revision to a node pool. The value is matched against the node label
+	// configured in config-deployment.
+	NodePoolAnnotationKey = GroupName + "/nodePool"
+
+	// WorkloadRBACAnnotationKey is the annotation key used to opt a Revision
+	// into a Role, bound to its service account, granting the listed access
+	// to the API in its namespace. The value is a semicolon separated list of
+	// rules of the form "verbs:resources", e.g. "get,list:configmaps;get:deployments.apps".
+	// Only the access allowed by the operator in config-deployment is granted.
+	WorkloadRBACAnnotationKey = GroupName + "/workloadRBAC"
+
+	// PodOverheadAnnotationKey is the status annotation reporting the pod
+	// overhead of the RuntimeClass a Revision runs with, which its pods are
+	// charged for on top of the resources of their containers. For example,
+	//   serving.knative.dev/podOverhead: "cpu=250m,memory=120Mi"
+	PodOverheadAnnotationKey = GroupName + "/podOverhead"
+
+	// RevisionHistoryLimitAnnotationKey is the annotation key used to set the
+	// number of old ReplicaSets kept by the Deployment of a Revision.
+	RevisionHistoryLimitAnnotationKey = GroupName + "/revisionHistoryLimit"
+
+	// TerminationGracePeriodAnnotationKey is the annotation key used to set
+	// the terminationGracePeriodSeconds of the pods of a Revision, which
+	// otherwise is its timeoutSeconds, e.g. for the long draining workloads.
+	// It can't be less than the timeoutSeconds the requests are drained for.
+	TerminationGracePeriodAnnotationKey = GroupName + "/terminationGracePeriodSeconds"
+
+	// ConcurrencyWarmupAnnotationKey is the annotation key used to have the
+	// queue-proxy of a Revision ramp its allowed concurrency up to the
+	// container concurrency over the given duration once the pod is ready.
+	// It requires a containerConcurrency limit.
+	ConcurrencyWarmupAnnotationKey = GroupName + "/concurrencyWarmup"
+
+	// TopologyAwareHintsAnnotationKey is the annotation key used to have the
+	// K8s Service routing to the pods of a Revision request topology aware
+	// hints, so that its traffic is kept within the zone it originates from
+	// when the endpoints are spread enough.
+	TopologyAwareHintsAnnotationKey = GroupName + "/topologyAwareHints"
+
+	// GoRuntimeEnvAnnotationKey is the annotation key used to have the
+	// GOMAXPROCS and GOMEMLIMIT environment variables of the containers of a
+	// Revision derived from their CPU and memory limits.
+	GoRuntimeEnvAnnotationKey = GroupName + "/goRuntimeEnv"
+
+	// RestartedAtAnnotationKey is the annotation key set on the pod template of
+	// a Revision's Deployment to trigger a rolling replacement of its pods.
+	RestartedAtAnnotationKey = GroupName + "/restartedAt"
+
 	// RoutesAnnotationKey is an annotation attached to a Revision to indicate that it is
 	// referenced by one or many routes. The value is a comma separated list of Route names.
 	RoutesAnnotationKey = GroupName + "/routes"
@@ -90,6 +170,22 @@ const (
 	// It has to be in [0.1,100]
 	QueueSideCarResourcePercentageAnnotation = "queue.sidecar." + GroupName + "/resourcePercentage"
 
+	// QueueSideCarLogLevelAnnotation is the log level of the queue-proxy of a
+	// revision, e.g. "debug" to troubleshoot it, overriding the one of the
+	// logging config.
+	QueueSideCarLogLevelAnnotation = "queue.sidecar." + GroupName + "/logLevel"
+
+	// QueueSideCarResourceProfileAnnotation is the name of the queue-proxy
+	// resource profile of the deployment config applied to a revision. The
+	// default resources are applied when the profile doesn't exist.
+	QueueSideCarResourceProfileAnnotation = "queue.sidecar." + GroupName + "/resourceProfile"
+
+	// QueueSideCarReadinessPassthroughAnnotation is the annotation key used
+	// to have the kubelet run the readiness probe of a revision against its
+	// container directly when set to "true", instead of the queue-proxy
+	// aggregating it into its own readiness.
+	QueueSideCarReadinessPassthroughAnnotation = "queue.sidecar." + GroupName + "/readinessPassthrough"
+
 	// VisibilityLabelKey is the label to indicate visibility of Route
 	// and KServices.  It can be an annotation too but since users are
 	// already using labels for domain, it probably best to keep this
@@ -99,6 +195,12 @@ const (
 	// that will result to the Route/KService getting a cluster local
 	// domain suffix.
 	VisibilityClusterLocal = "cluster-local"
+
+	// TagVisibilityLabelKeyPrefix prefixes the labels indicating the
+	// visibility of a single tag of a Route or KService, e.g. labelling with
+	// "visibility.serving.knative.dev/blue: cluster-local" makes the "blue"
+	// tag reachable only from within the cluster.
+	TagVisibilityLabelKeyPrefix = "visibility." + GroupNamePrefix
 )
 
 var (
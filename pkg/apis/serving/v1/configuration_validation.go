@@ -33,7 +33,8 @@ func (c *Configuration) Validate(ctx context.Context) (errs *apis.FieldError) {
 	// spec validation.
 	if !apis.IsInStatusUpdate(ctx) {
 		errs = errs.Also(serving.ValidateObjectMetadata(ctx, c.GetObjectMeta()).Also(
-			c.validateLabels().ViaField("labels")).ViaField("metadata"))
+			c.validateLabels().ViaField("labels")).Also(
+			serving.ValidateGCDisabledAnnotation(c.GetAnnotations()).ViaField("annotations")).ViaField("metadata"))
 		ctx = apis.WithinParent(ctx, c.ObjectMeta)
 		errs = errs.Also(c.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
 	}
@@ -53,6 +53,12 @@ func (rs *RevisionSpec) SetDefaults(ctx context.Context) {
 		rs.ContainerConcurrency = ptr.Int64(cfg.Defaults.ContainerConcurrency)
 	}
 
+	// Default the ServiceAccount based on our configmap, rather than running
+	// as the "default" one of the namespace.
+	if rs.PodSpec.ServiceAccountName == "" {
+		rs.PodSpec.ServiceAccountName = cfg.Defaults.ServiceAccountName(ctx)
+	}
+
 	// Avoid clashes with user-supplied names when generating defaults.
 	userContainerNames := make(sets.String, len(rs.PodSpec.Containers))
 	for idx := range rs.PodSpec.Containers {
@@ -84,6 +90,16 @@ func (rs *RevisionSpec) SetDefaults(ctx context.Context) {
 
 		rs.applyDefault(&rs.PodSpec.Containers[idx], cfg)
 	}
+
+	// Bound the emptyDir volumes, so that they can't fill up the nodes.
+	if cfg.Defaults.EmptyDirSizeLimit != nil {
+		for idx := range rs.PodSpec.Volumes {
+			if ed := rs.PodSpec.Volumes[idx].EmptyDir; ed != nil && ed.SizeLimit == nil {
+				limit := cfg.Defaults.EmptyDirSizeLimit.DeepCopy()
+				ed.SizeLimit = &limit
+			}
+		}
+	}
 }
 
 func (rs *RevisionSpec) applyDefault(container *corev1.Container, cfg *config.Config) {
@@ -130,10 +146,27 @@ func (rs *RevisionSpec) applyDefault(container *corev1.Container, cfg *config.Co
 		rs.PodSpec.EnableServiceLinks = cfg.Defaults.EnableServiceLinks
 	}
 
+	if rs.PodSpec.AutomountServiceAccountToken == nil {
+		rs.PodSpec.AutomountServiceAccountToken = cfg.Defaults.AutomountServiceAccountToken
+	}
+
 	vms := container.VolumeMounts
 	for i := range vms {
-		vms[i].ReadOnly = true
+		// The emptyDir volumes are scratch space, so they stay writable.
+		if !rs.isEmptyDirVolume(vms[i].Name) {
+			vms[i].ReadOnly = true
+		}
+	}
+}
+
+// isEmptyDirVolume returns true if the named volume is an emptyDir.
+func (rs *RevisionSpec) isEmptyDirVolume(name string) bool {
+	for _, v := range rs.PodSpec.Volumes {
+		if v.Name == name {
+			return v.EmptyDir != nil
+		}
 	}
+	return false
 }
 
 func (*RevisionSpec) applyProbes(container *corev1.Container) {
@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"knative.dev/pkg/apis"
 	logtesting "knative.dev/pkg/logging/testing"
 	"knative.dev/pkg/ptr"
 	"knative.dev/serving/pkg/apis/config"
@@ -47,6 +48,11 @@ var (
 	ignoreUnexportedResources = cmpopts.IgnoreUnexported(resource.Quantity{})
 )
 
+func quantity(q string) *resource.Quantity {
+	v := resource.MustParse(q)
+	return &v
+}
+
 func TestRevisionDefaulting(t *testing.T) {
 	logger := logtesting.TestLogger(t)
 	tests := []struct {
@@ -188,6 +194,71 @@ func TestRevisionDefaulting(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "with automount service account token `false`",
+		in:   &Revision{Spec: RevisionSpec{PodSpec: corev1.PodSpec{Containers: []corev1.Container{{}}}}},
+		wc: func(ctx context.Context) context.Context {
+			s := config.NewStore(logger)
+			s.OnConfigChanged(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: autoscalerconfig.ConfigName}})
+			s.OnConfigChanged(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: config.FeaturesConfigName}})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: config.DefaultsConfigName,
+				},
+				Data: map[string]string{
+					"automount-service-account-token": "false",
+				},
+			})
+			return s.ToContext(ctx)
+		},
+		want: &Revision{
+			Spec: RevisionSpec{
+				ContainerConcurrency: ptr.Int64(0),
+				TimeoutSeconds:       ptr.Int64(300),
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:           config.DefaultUserContainerName,
+						Resources:      defaultResources,
+						ReadinessProbe: defaultProbe,
+					}},
+					AutomountServiceAccountToken: ptr.Bool(false),
+				},
+			},
+		},
+	}, {
+		name: "with automount service account token set",
+		in: &Revision{Spec: RevisionSpec{PodSpec: corev1.PodSpec{
+			AutomountServiceAccountToken: ptr.Bool(true),
+			Containers:                   []corev1.Container{{}},
+		}}},
+		wc: func(ctx context.Context) context.Context {
+			s := config.NewStore(logger)
+			s.OnConfigChanged(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: autoscalerconfig.ConfigName}})
+			s.OnConfigChanged(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: config.FeaturesConfigName}})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: config.DefaultsConfigName,
+				},
+				Data: map[string]string{
+					"automount-service-account-token": "false", // this should be ignored.
+				},
+			})
+			return s.ToContext(ctx)
+		},
+		want: &Revision{
+			Spec: RevisionSpec{
+				ContainerConcurrency: ptr.Int64(0),
+				TimeoutSeconds:       ptr.Int64(300),
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:           config.DefaultUserContainerName,
+						Resources:      defaultResources,
+						ReadinessProbe: defaultProbe,
+					}},
+					AutomountServiceAccountToken: ptr.Bool(true),
+				},
+			},
+		},
 	}, {
 		name: "readonly volumes",
 		in: &Revision{
@@ -222,6 +293,139 @@ func TestRevisionDefaulting(t *testing.T) {
 				TimeoutSeconds:       ptr.Int64(99),
 			},
 		},
+	}, {
+		name: "emptyDir volumes",
+		in: &Revision{
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Image: "foo",
+						VolumeMounts: []corev1.VolumeMount{{
+							Name: "scratch",
+						}, {
+							Name: "bounded",
+						}, {
+							Name: "secret",
+						}},
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "scratch",
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{},
+						},
+					}, {
+						Name: "bounded",
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{
+								SizeLimit: quantity("100Mi"),
+							},
+						},
+					}, {
+						Name: "secret",
+						VolumeSource: corev1.VolumeSource{
+							Secret: &corev1.SecretVolumeSource{SecretName: "secret"},
+						},
+					}},
+				},
+				ContainerConcurrency: ptr.Int64(1),
+				TimeoutSeconds:       ptr.Int64(99),
+			},
+		},
+		wc: func(ctx context.Context) context.Context {
+			s := config.NewStore(logger)
+			s.OnConfigChanged(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: autoscalerconfig.ConfigName}})
+			s.OnConfigChanged(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: config.FeaturesConfigName}})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: config.DefaultsConfigName,
+				},
+				Data: map[string]string{
+					"emptydir-size-limit": "500Mi",
+				},
+			})
+			return s.ToContext(ctx)
+		},
+		want: &Revision{
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  config.DefaultUserContainerName,
+						Image: "foo",
+						VolumeMounts: []corev1.VolumeMount{{
+							Name: "scratch",
+						}, {
+							Name: "bounded",
+						}, {
+							Name:     "secret",
+							ReadOnly: true,
+						}},
+						Resources:      defaultResources,
+						ReadinessProbe: defaultProbe,
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "scratch",
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{
+								SizeLimit: quantity("500Mi"),
+							},
+						},
+					}, {
+						Name: "bounded",
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{
+								SizeLimit: quantity("100Mi"),
+							},
+						},
+					}, {
+						Name: "secret",
+						VolumeSource: corev1.VolumeSource{
+							Secret: &corev1.SecretVolumeSource{SecretName: "secret"},
+						},
+					}},
+				},
+				ContainerConcurrency: ptr.Int64(1),
+				TimeoutSeconds:       ptr.Int64(99),
+			},
+		},
+	}, {
+		name: "emptyDir volumes without a default size limit",
+		in: &Revision{
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Image: "foo",
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "scratch",
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{},
+						},
+					}},
+				},
+				ContainerConcurrency: ptr.Int64(1),
+				TimeoutSeconds:       ptr.Int64(99),
+			},
+		},
+		want: &Revision{
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:           config.DefaultUserContainerName,
+						Image:          "foo",
+						Resources:      defaultResources,
+						ReadinessProbe: defaultProbe,
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "scratch",
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{},
+						},
+					}},
+				},
+				ContainerConcurrency: ptr.Int64(1),
+				TimeoutSeconds:       ptr.Int64(99),
+			},
+		},
 	}, {
 		name: "timeout sets to default when 0 is specified",
 		in:   &Revision{Spec: RevisionSpec{PodSpec: corev1.PodSpec{Containers: []corev1.Container{{}}}, TimeoutSeconds: ptr.Int64(0)}},
@@ -519,6 +723,86 @@ func TestRevisionDefaulting(t *testing.T) {
 	}
 }
 
+func TestRevisionDefaultingEmptyDirSizeLimit(t *testing.T) {
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.Defaults.EmptyDirSizeLimit = quantity("500Mi")
+	ctx := config.ToContext(context.Background(), cfg)
+
+	rev := &Revision{
+		Spec: RevisionSpec{
+			PodSpec: corev1.PodSpec{
+				Containers: []corev1.Container{{}},
+				Volumes: []corev1.Volume{{
+					Name: "scratch",
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{},
+					},
+				}, {
+					Name: "bounded",
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: quantity("100Mi")},
+					},
+				}},
+			},
+		},
+	}
+	rev.SetDefaults(ctx)
+
+	for i, want := range []string{"500Mi", "100Mi"} {
+		if got := rev.Spec.Volumes[i].EmptyDir.SizeLimit; got == nil || got.Cmp(resource.MustParse(want)) != 0 {
+			t.Errorf("Volumes[%d].EmptyDir.SizeLimit = %v, want: %s", i, got, want)
+		}
+	}
+	if rev.Spec.Volumes[0].EmptyDir.SizeLimit == cfg.Defaults.EmptyDirSizeLimit {
+		t.Error("The default size limit is shared with the config")
+	}
+}
+
+func TestRevisionDefaultingServiceAccountName(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		in       string
+		want     string
+	}{{
+		name: "unset without template",
+	}, {
+		name:     "unset",
+		template: "{{.Namespace}}-runner",
+		want:     "guardians-runner",
+	}, {
+		name:     "set",
+		template: "{{.Namespace}}-runner",
+		in:       "groot",
+		want:     "groot",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg.Defaults.ServiceAccountNameTemplate = test.template
+			ctx := apis.WithinParent(config.ToContext(context.Background(), cfg), metav1.ObjectMeta{
+				Name:      "i-am-groot",
+				Namespace: "guardians",
+			})
+
+			rev := &Revision{
+				Spec: RevisionSpec{
+					PodSpec: corev1.PodSpec{
+						ServiceAccountName: test.in,
+						Containers:         []corev1.Container{{}},
+					},
+				},
+			}
+			rev.SetDefaults(ctx)
+
+			if got := rev.Spec.ServiceAccountName; got != test.want {
+				t.Errorf("ServiceAccountName = %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
+
 func TestRevisionDefaultingContainerName(t *testing.T) {
 	got := &Revision{
 		Spec: RevisionSpec{
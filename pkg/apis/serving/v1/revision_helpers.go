@@ -21,6 +21,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
 	net "knative.dev/networking/pkg/apis/networking"
 	"knative.dev/pkg/kmeta"
@@ -150,6 +151,80 @@ func (r *Revision) GetRoutingStateModified() time.Time {
 	return parsed
 }
 
+// GetMaxPodLifetime returns the maximum age of the revision's pods as requested
+// via annotation, and whether such a lifetime was requested at all.
+func (r *Revision) GetMaxPodLifetime() (time.Duration, bool) {
+	val, ok := r.Annotations[serving.MaxPodLifetimeAnnotationKey]
+	if !ok {
+		return 0, false
+	}
+	parsed, err := time.ParseDuration(val)
+	if err != nil || parsed <= 0 {
+		return 0, false
+	}
+	return parsed, true
+}
+
+// GetConcurrencyWarmup returns the window over which the queue-proxy ramps up
+// the allowed concurrency as requested via annotation, and whether such a
+// warmup was requested at all.
+func (r *Revision) GetConcurrencyWarmup() (time.Duration, bool) {
+	val, ok := r.Annotations[serving.ConcurrencyWarmupAnnotationKey]
+	if !ok {
+		return 0, false
+	}
+	parsed, err := time.ParseDuration(val)
+	if err != nil || parsed <= 0 {
+		return 0, false
+	}
+	return parsed, true
+}
+
+// GetRevisionHistoryLimit returns the revisionHistoryLimit of the revision's
+// Deployment as requested via annotation, and whether such a limit was
+// requested at all.
+func (r *Revision) GetRevisionHistoryLimit() (int32, bool) {
+	val, ok := r.Annotations[serving.RevisionHistoryLimitAnnotationKey]
+	if !ok {
+		return 0, false
+	}
+	parsed, err := strconv.ParseInt(val, 10, 32)
+	if err != nil || parsed < 0 {
+		return 0, false
+	}
+	return int32(parsed), true
+}
+
+// GetTerminationGracePeriodSeconds returns the terminationGracePeriodSeconds
+// of the revision's pods as requested via annotation, and whether such a
+// grace period was requested at all.
+func (r *Revision) GetTerminationGracePeriodSeconds() (int64, bool) {
+	val, ok := r.Annotations[serving.TerminationGracePeriodAnnotationKey]
+	if !ok {
+		return 0, false
+	}
+	parsed, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || parsed < 0 {
+		return 0, false
+	}
+	return parsed, true
+}
+
+// GetWorkloadRBACRules returns the rules of the Role requested for the
+// revision's service account via annotation, and whether such a Role was
+// requested at all.
+func (r *Revision) GetWorkloadRBACRules() ([]rbacv1.PolicyRule, bool) {
+	val, ok := r.Annotations[serving.WorkloadRBACAnnotationKey]
+	if !ok {
+		return nil, false
+	}
+	rules, err := serving.ParseWorkloadRBAC(val)
+	if err != nil {
+		return nil, false
+	}
+	return rules, true
+}
+
 // IsReachable returns whether or not the revision can be reached by a route.
 func (r *Revision) IsReachable() bool {
 	return r.Labels[serving.RouteLabelKey] != "" ||
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
@@ -211,6 +212,179 @@ func TestRevisionIsReachable(t *testing.T) {
 	}
 }
 
+func TestRevisionGetMaxPodLifetime(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        time.Duration
+		wantOK      bool
+	}{{
+		name:        "no annotation",
+		annotations: nil,
+	}, {
+		name:        "valid annotation",
+		annotations: map[string]string{serving.MaxPodLifetimeAnnotationKey: "12h"},
+		want:        12 * time.Hour,
+		wantOK:      true,
+	}, {
+		name:        "invalid annotation",
+		annotations: map[string]string{serving.MaxPodLifetimeAnnotationKey: "a while"},
+	}, {
+		name:        "non-positive annotation",
+		annotations: map[string]string{serving.MaxPodLifetimeAnnotationKey: "0s"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rev := Revision{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+
+			got, ok := rev.GetMaxPodLifetime()
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("GetMaxPodLifetime = (%v, %t), want: (%v, %t)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRevisionGetConcurrencyWarmup(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        time.Duration
+		wantOK      bool
+	}{{
+		name: "no annotation",
+	}, {
+		name:        "valid annotation",
+		annotations: map[string]string{serving.ConcurrencyWarmupAnnotationKey: "45s"},
+		want:        45 * time.Second,
+		wantOK:      true,
+	}, {
+		name:        "invalid annotation",
+		annotations: map[string]string{serving.ConcurrencyWarmupAnnotationKey: "a bit"},
+	}, {
+		name:        "non-positive annotation",
+		annotations: map[string]string{serving.ConcurrencyWarmupAnnotationKey: "-5s"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rev := Revision{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+
+			got, ok := rev.GetConcurrencyWarmup()
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("GetConcurrencyWarmup = (%v, %t), want: (%v, %t)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRevisionGetRevisionHistoryLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        int32
+		wantOK      bool
+	}{{
+		name: "no annotation",
+	}, {
+		name:        "valid annotation",
+		annotations: map[string]string{serving.RevisionHistoryLimitAnnotationKey: "5"},
+		want:        5,
+		wantOK:      true,
+	}, {
+		name:        "zero annotation",
+		annotations: map[string]string{serving.RevisionHistoryLimitAnnotationKey: "0"},
+		wantOK:      true,
+	}, {
+		name:        "negative annotation",
+		annotations: map[string]string{serving.RevisionHistoryLimitAnnotationKey: "-5"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rev := Revision{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+
+			got, ok := rev.GetRevisionHistoryLimit()
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("GetRevisionHistoryLimit = (%v, %t), want: (%v, %t)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRevisionGetTerminationGracePeriodSeconds(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        int64
+		wantOK      bool
+	}{{
+		name: "no annotation",
+	}, {
+		name:        "valid annotation",
+		annotations: map[string]string{serving.TerminationGracePeriodAnnotationKey: "900"},
+		want:        900,
+		wantOK:      true,
+	}, {
+		name:        "invalid annotation",
+		annotations: map[string]string{serving.TerminationGracePeriodAnnotationKey: "long"},
+	}, {
+		name:        "negative annotation",
+		annotations: map[string]string{serving.TerminationGracePeriodAnnotationKey: "-5"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rev := Revision{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+
+			got, ok := rev.GetTerminationGracePeriodSeconds()
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("GetTerminationGracePeriodSeconds = (%v, %t), want: (%v, %t)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRevisionGetWorkloadRBACRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []rbacv1.PolicyRule
+		wantOK      bool
+	}{{
+		name: "no annotation",
+	}, {
+		name:        "valid annotation",
+		annotations: map[string]string{serving.WorkloadRBACAnnotationKey: "get,list:configmaps"},
+		want: []rbacv1.PolicyRule{{
+			Verbs:     []string{"get", "list"},
+			APIGroups: []string{""},
+			Resources: []string{"configmaps"},
+		}},
+		wantOK: true,
+	}, {
+		name:        "invalid annotation",
+		annotations: map[string]string{serving.WorkloadRBACAnnotationKey: "*:*"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rev := Revision{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+
+			got, ok := rev.GetWorkloadRBACRules()
+
+			if !equality.Semantic.DeepEqual(got, tt.want) || ok != tt.wantOK {
+				t.Errorf("GetWorkloadRBACRules = (%v, %t), want: (%v, %t)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestRevisionGetProtocol(t *testing.T) {
 	containerWithPortName := func(name string) corev1.Container {
 		return corev1.Container{Ports: []corev1.ContainerPort{{Name: name}}}
@@ -18,12 +18,14 @@ package v1
 
 import (
 	"fmt"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/serving"
@@ -45,6 +47,30 @@ const (
 	// ReasonProgressDeadlineExceeded defines the reason for marking revision availability
 	// status as false if progress has exceeded the deadline.
 	ReasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+	// ReasonCompleted defines the reason for marking container healthiness status
+	// as true if the container of a revision with the OnFailure restart policy
+	// ran to completion.
+	ReasonCompleted = "Completed"
+
+	// ReasonOOMKilled defines the reason for marking container healthiness status
+	// as false if a container of the revision was killed for running out of
+	// memory. It matches the reason the kubelet terminates those containers with.
+	ReasonOOMKilled = "OOMKilled"
+
+	// ReasonImageFlagged defines the reason for marking revision availability
+	// status as false if the scan of its images flagged them.
+	ReasonImageFlagged = "ImageFlagged"
+
+	// ReasonWorkloadRBACNotAllowed defines the reason for marking revision
+	// availability status as false if it requests access to the API that
+	// the operator doesn't allow.
+	ReasonWorkloadRBACNotAllowed = "WorkloadRBACNotAllowed"
+
+	// ReasonImagePullSlow defines the reason for marking revision availability
+	// status as unknown if its pods have been pulling an image for longer
+	// than the configured deadline.
+	ReasonImagePullSlow = "ImagePullSlow"
 )
 
 var revisionCondSet = apis.NewLivingConditionSet(
@@ -117,6 +143,14 @@ func (rs *RevisionStatus) MarkContainerHealthyTrue() {
 	revisionCondSet.Manage(rs).MarkTrue(RevisionConditionContainerHealthy)
 }
 
+// MarkCompleted marks the revision completed, and its container healthy with
+// the reason that it ran to completion.
+func (rs *RevisionStatus) MarkCompleted() {
+	revisionCondSet.Manage(rs).MarkTrue(RevisionConditionCompleted)
+	revisionCondSet.Manage(rs).MarkTrueWithReason(RevisionConditionContainerHealthy, ReasonCompleted,
+		"Container ran to completion")
+}
+
 // MarkContainerHealthyFalse marks ContainerHealthy status on revision as False
 func (rs *RevisionStatus) MarkContainerHealthyFalse(reason, message string) {
 	revisionCondSet.Manage(rs).MarkFalse(RevisionConditionContainerHealthy, reason, message)
@@ -164,6 +198,16 @@ func (rs *RevisionStatus) PropagateAutoscalerStatus(ps *av1alpha1.PodAutoscalerS
 	// Propagate the service name from the PA.
 	rs.ServiceName = ps.ServiceName
 
+	// Propagate the concurrency utilization for the dashboards.
+	if u, ok := ps.Annotations[autoscaling.ConcurrencyUtilizationAnnotationKey]; ok {
+		if rs.Annotations == nil {
+			rs.Annotations = make(map[string]string, 1)
+		}
+		rs.Annotations[autoscaling.ConcurrencyUtilizationAnnotationKey] = u
+	} else {
+		delete(rs.Annotations, autoscaling.ConcurrencyUtilizationAnnotationKey)
+	}
+
 	// Reflect the PA status in our own.
 	cond := ps.GetCondition(av1alpha1.PodAutoscalerConditionReady)
 	if cond == nil {
@@ -230,6 +274,18 @@ func RevisionContainerExitingMessage(message string) string {
 	return fmt.Sprint("Container failed with: ", message)
 }
 
+// RevisionContainerOOMKilledMessage constructs the status message if a
+// container was killed for running out of memory.
+func RevisionContainerOOMKilledMessage(container string) string {
+	return fmt.Sprintf("Container %q was killed for exceeding its memory limit", container)
+}
+
+// RevisionImagePullSlowMessage constructs the status message if the pull of
+// an image is taking longer than the deadline.
+func RevisionImagePullSlowMessage(image string, deadline time.Duration) string {
+	return fmt.Sprintf("Pulling image %q is taking longer than %v", image, deadline)
+}
+
 // RevisionContainerMissingMessage constructs the status message if a given image
 // cannot be pulled correctly.
 func RevisionContainerMissingMessage(image string, message string) string {
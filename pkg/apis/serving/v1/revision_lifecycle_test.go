@@ -30,6 +30,7 @@ import (
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	apistest "knative.dev/pkg/apis/testing"
 	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/config"
 )
@@ -331,6 +332,26 @@ func TestTypicalFlowWithContainerMissing(t *testing.T) {
 	apistest.CheckConditionOngoing(r, RevisionConditionReady, t)
 }
 
+func TestTypicalFlowWithContainerCompleted(t *testing.T) {
+	r := &RevisionStatus{}
+	r.InitializeConditions()
+
+	r.MarkContainerHealthyFalse(ExitCodeReason(1), "failed")
+	apistest.CheckConditionFailed(r, RevisionConditionContainerHealthy, t)
+
+	r.MarkCompleted()
+	apistest.CheckConditionSucceeded(r, RevisionConditionCompleted, t)
+	apistest.CheckConditionSucceeded(r, RevisionConditionContainerHealthy, t)
+	if got := r.GetCondition(RevisionConditionContainerHealthy); got == nil || got.Reason != ReasonCompleted {
+		t.Errorf("MarkCompleted = %v, want reason %q", got, ReasonCompleted)
+	}
+	apistest.CheckConditionOngoing(r, RevisionConditionReady, t)
+
+	// The revision stays completed while its restarted container is healthy.
+	r.MarkContainerHealthyTrue()
+	apistest.CheckConditionSucceeded(r, RevisionConditionCompleted, t)
+}
+
 func TestTypicalFlowWithSuspendResume(t *testing.T) {
 	r := &RevisionStatus{}
 	r.InitializeConditions()
@@ -650,6 +671,28 @@ func TestPropagateAutoscalerStatusNoProgress(t *testing.T) {
 	}
 }
 
+func TestPropagateAutoscalerStatusUtilization(t *testing.T) {
+	r := &RevisionStatus{}
+	r.InitializeConditions()
+
+	r.PropagateAutoscalerStatus(&av1alpha1.PodAutoscalerStatus{
+		Status: duckv1.Status{
+			Annotations: map[string]string{
+				autoscaling.ConcurrencyUtilizationAnnotationKey: "85",
+			},
+		},
+	})
+	if got, want := r.Annotations[autoscaling.ConcurrencyUtilizationAnnotationKey], "85"; got != want {
+		t.Errorf("Utilization = %q, want: %q", got, want)
+	}
+
+	// The utilization is dropped when the PodAutoscaler stops reporting it.
+	r.PropagateAutoscalerStatus(&av1alpha1.PodAutoscalerStatus{})
+	if got, ok := r.Annotations[autoscaling.ConcurrencyUtilizationAnnotationKey]; ok {
+		t.Errorf("Utilization = %q, want: none", got)
+	}
+}
+
 func TestPropagateAutoscalerStatusRace(t *testing.T) {
 	r := &RevisionStatus{}
 	r.InitializeConditions()
@@ -103,6 +103,10 @@ const (
 
 	// RevisionConditionActive is set when the revision is receiving traffic.
 	RevisionConditionActive apis.ConditionType = "Active"
+
+	// RevisionConditionCompleted is set when the container of a revision with
+	// the OnFailure restart policy ran to completion.
+	RevisionConditionCompleted apis.ConditionType = "Completed"
 )
 
 // IsRevisionCondition returns true if the ConditionType is a revision condition type
@@ -112,7 +116,8 @@ func IsRevisionCondition(t apis.ConditionType) bool {
 		RevisionConditionReady,
 		RevisionConditionResourcesAvailable,
 		RevisionConditionContainerHealthy,
-		RevisionConditionActive:
+		RevisionConditionActive,
+		RevisionConditionCompleted:
 		return true
 	}
 	return false
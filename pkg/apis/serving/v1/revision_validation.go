@@ -65,6 +65,24 @@ func (rts *RevisionTemplateSpec) Validate(ctx context.Context) *apis.FieldError
 	// it follows the requirements on the name.
 	errs = errs.Also(serving.ValidateRevisionName(ctx, rts.Name, rts.GenerateName))
 	errs = errs.Also(serving.ValidateQueueSidecarAnnotation(rts.Annotations).ViaField("metadata.annotations"))
+	errs = errs.Also(serving.ValidateMaxPodLifetimeAnnotation(rts.Annotations).ViaField("metadata.annotations"))
+	errs = errs.Also(serving.ValidateNodePoolAnnotation(rts.Annotations).ViaField("metadata.annotations"))
+	errs = errs.Also(serving.ValidateWorkloadRBACAnnotation(rts.Annotations).ViaField("metadata.annotations"))
+	errs = errs.Also(serving.ValidateRevisionHistoryLimitAnnotation(rts.Annotations).ViaField("metadata.annotations"))
+	errs = errs.Also(serving.ValidateTopologyAwareHintsAnnotation(rts.Annotations).ViaField("metadata.annotations"))
+	errs = errs.Also(serving.ValidateGoRuntimeEnvAnnotation(rts.Annotations).ViaField("metadata.annotations"))
+
+	timeoutSeconds := apisconfig.FromContextOrDefaults(ctx).Defaults.RevisionTimeoutSeconds
+	if rts.Spec.TimeoutSeconds != nil {
+		timeoutSeconds = *rts.Spec.TimeoutSeconds
+	}
+	errs = errs.Also(serving.ValidateTerminationGracePeriodAnnotation(rts.Annotations, timeoutSeconds).ViaField("metadata.annotations"))
+
+	containerConcurrency := apisconfig.FromContextOrDefaults(ctx).Defaults.ContainerConcurrency
+	if rts.Spec.ContainerConcurrency != nil {
+		containerConcurrency = *rts.Spec.ContainerConcurrency
+	}
+	errs = errs.Also(serving.ValidateConcurrencyWarmupAnnotation(rts.Annotations, containerConcurrency).ViaField("metadata.annotations"))
 	return errs
 }
 
@@ -19,6 +19,7 @@ package v1
 import (
 	"context"
 	"fmt"
+	"math"
 	"strconv"
 	"testing"
 
@@ -961,6 +962,99 @@ func TestRevisionTemplateSpecValidation(t *testing.T) {
 			},
 		},
 		want: nil,
+	}, {
+		name: "termination grace period covers the drain",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.TerminationGracePeriodAnnotationKey: "900",
+				},
+			},
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Image: "helloworld",
+					}},
+				},
+				TimeoutSeconds: ptr.Int64(600),
+			},
+		},
+		want: nil,
+	}, {
+		name: "termination grace period shorter than the drain",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.TerminationGracePeriodAnnotationKey: "60",
+				},
+			},
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Image: "helloworld",
+					}},
+				},
+				TimeoutSeconds: ptr.Int64(600),
+			},
+		},
+		want: apis.ErrOutOfBoundsValue(60, 600, math.MaxInt32, apis.CurrentField).
+			ViaKey(serving.TerminationGracePeriodAnnotationKey).ViaField("metadata.annotations"),
+	}, {
+		name: "termination grace period shorter than the default drain",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.TerminationGracePeriodAnnotationKey: "60",
+				},
+			},
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Image: "helloworld",
+					}},
+				},
+			},
+		},
+		want: apis.ErrOutOfBoundsValue(60, config.DefaultRevisionTimeoutSeconds, math.MaxInt32, apis.CurrentField).
+			ViaKey(serving.TerminationGracePeriodAnnotationKey).ViaField("metadata.annotations"),
+	}, {
+		name: "concurrency warmup",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.ConcurrencyWarmupAnnotationKey: "30s",
+				},
+			},
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Image: "helloworld",
+					}},
+				},
+				ContainerConcurrency: ptr.Int64(10),
+			},
+		},
+		want: nil,
+	}, {
+		name: "concurrency warmup with the default container concurrency",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.ConcurrencyWarmupAnnotationKey: "30s",
+				},
+			},
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Image: "helloworld",
+					}},
+				},
+			},
+		},
+		want: (&apis.FieldError{
+			Message: "the concurrency warmup requires a containerConcurrency limit",
+			Paths:   []string{apis.CurrentField},
+		}).ViaKey(serving.ConcurrencyWarmupAnnotationKey).ViaField("metadata.annotations"),
 	}}
 
 	for _, test := range tests {
@@ -18,6 +18,9 @@ package v1
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -75,6 +78,13 @@ func (rs *RouteStatus) MarkServiceNotOwned(name string) {
 		fmt.Sprintf("There is an existing placeholder Service %q that we do not own.", name))
 }
 
+// MarkIngressNotOwned changes the IngressReady status to be false with the reason being that
+// there is a pre-existing Ingress with the name we wanted to use.
+func (rs *RouteStatus) MarkIngressNotOwned(name string) {
+	routeCondSet.Manage(rs).MarkFalse(RouteConditionIngressReady, "NotOwned",
+		fmt.Sprintf("There is an existing Ingress %q that we do not own.", name))
+}
+
 // MarkIngressNotConfigured changes the IngressReady condition to be unknown to reflect
 // that the Ingress does not yet have a Status
 func (rs *RouteStatus) MarkIngressNotConfigured() {
@@ -82,6 +92,13 @@ func (rs *RouteStatus) MarkIngressNotConfigured() {
 		"IngressNotConfigured", "Ingress has not yet been reconciled.")
 }
 
+// MarkDNSNotResolved changes the IngressReady condition to be unknown to reflect
+// that the host of the Route doesn't resolve to its Ingress yet.
+func (rs *RouteStatus) MarkDNSNotResolved(host string) {
+	routeCondSet.Manage(rs).MarkUnknown(RouteConditionIngressReady,
+		"DNSNotResolved", "Host %q does not resolve to the Ingress yet.", host)
+}
+
 func (rs *RouteStatus) MarkTrafficAssigned() {
 	routeCondSet.Manage(rs).MarkTrue(RouteConditionAllTrafficAssigned)
 }
@@ -120,6 +137,23 @@ func (rs *RouteStatus) MarkMissingTrafficTarget(kind, name string) {
 		"%s %q referenced in traffic not found.", kind, name)
 }
 
+// MarkTrafficTargetNotFound marks the traffic as partially assigned, since the
+// named Revisions referenced in traffic don't exist.
+func (rs *RouteStatus) MarkTrafficTargetNotFound(names ...string) {
+	kind := "Revision"
+	if len(names) > 1 {
+		kind = "Revisions"
+	}
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = strconv.Quote(name)
+	}
+	routeCondSet.Manage(rs).MarkFalse(RouteConditionAllTrafficAssigned,
+		"TrafficTargetNotFound",
+		"%s %s referenced in traffic not found, serving the other traffic targets.",
+		kind, strings.Join(quoted, ", "))
+}
+
 func (rs *RouteStatus) MarkCertificateProvisionFailed(name string) {
 	routeCondSet.Manage(rs).MarkFalse(RouteConditionCertificateProvisioned,
 		"CertificateProvisionFailed",
@@ -162,6 +196,56 @@ func (rs *RouteStatus) MarkHTTPDowngrade(name string) {
 		"Certificate %s is not ready downgrade HTTP.", name)
 }
 
+// MarkTrafficTargetsExceedThreshold warns that the Route has more traffic
+// targets than the threshold, without affecting its readiness.
+func (rs *RouteStatus) MarkTrafficTargetsExceedThreshold(count, threshold int) {
+	routeCondSet.Manage(rs).SetCondition(apis.Condition{
+		Type:     RouteConditionTrafficTargetsWithinThreshold,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   "TooManyTrafficTargets",
+		Message: fmt.Sprintf("The Route has %d traffic targets, more than the threshold of %d, "+
+			"which makes its Ingress large and slow to reconcile.", count, threshold),
+	})
+}
+
+// MarkTrafficTargetsWithinThreshold removes the warning about the number of
+// traffic targets of the Route.
+func (rs *RouteStatus) MarkTrafficTargetsWithinThreshold() {
+	routeCondSet.Manage(rs).ClearCondition(RouteConditionTrafficTargetsWithinThreshold)
+}
+
+// MarkCertificateExpiring warns that a certificate serving the Route expires
+// within the threshold, without affecting its readiness.
+func (rs *RouteStatus) MarkCertificateExpiring(secretName string, notAfter time.Time, threshold time.Duration) {
+	routeCondSet.Manage(rs).SetCondition(apis.Condition{
+		Type:     RouteConditionCertificateNotExpiring,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   "CertificateExpiring",
+		Message: fmt.Sprintf("The certificate in secret %s expires at %s, within the threshold of %v.",
+			secretName, notAfter.UTC().Format(time.RFC3339), threshold),
+	})
+}
+
+// MarkCertificateInvalid warns that a certificate serving the Route can't be
+// read, so its expiry is unknown.
+func (rs *RouteStatus) MarkCertificateInvalid(secretName, reason string) {
+	routeCondSet.Manage(rs).SetCondition(apis.Condition{
+		Type:     RouteConditionCertificateNotExpiring,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   "CertificateInvalid",
+		Message:  fmt.Sprintf("The certificate in secret %s can't be read: %s.", secretName, reason),
+	})
+}
+
+// MarkCertificatesNotExpiring removes the warning about the expiry of the
+// certificates serving the Route.
+func (rs *RouteStatus) MarkCertificatesNotExpiring() {
+	routeCondSet.Manage(rs).ClearCondition(RouteConditionCertificateNotExpiring)
+}
+
 // PropagateIngressStatus update RouteConditionIngressReady condition
 // in RouteStatus according to IngressStatus.
 func (rs *RouteStatus) PropagateIngressStatus(cs v1alpha1.IngressStatus) {
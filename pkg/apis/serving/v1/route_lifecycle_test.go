@@ -17,6 +17,7 @@ package v1
 
 import (
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -271,6 +272,28 @@ func TestTrafficNotAssignedFlow(t *testing.T) {
 	apistest.CheckConditionFailed(r, RouteConditionReady, t)
 }
 
+func TestTrafficTargetNotFoundFlow(t *testing.T) {
+	r := &RouteStatus{}
+	r.InitializeConditions()
+
+	r.MarkTrafficTargetNotFound("does-not-exist")
+	apistest.CheckConditionFailed(r, RouteConditionAllTrafficAssigned, t)
+	apistest.CheckConditionFailed(r, RouteConditionReady, t)
+	cond := r.GetCondition(RouteConditionAllTrafficAssigned)
+	if got, want := cond.Reason, "TrafficTargetNotFound"; got != want {
+		t.Errorf("Reason = %q, want: %q", got, want)
+	}
+	if got, want := cond.Message, `Revision "does-not-exist" referenced in traffic not found, serving the other traffic targets.`; got != want {
+		t.Errorf("Message = %q, want: %q", got, want)
+	}
+
+	r.MarkTrafficTargetNotFound("a", "b")
+	cond = r.GetCondition(RouteConditionAllTrafficAssigned)
+	if got, want := cond.Message, `Revisions "a", "b" referenced in traffic not found, serving the other traffic targets.`; got != want {
+		t.Errorf("Message = %q, want: %q", got, want)
+	}
+}
+
 func TestTargetConfigurationNotYetReadyFlow(t *testing.T) {
 	r := &RouteStatus{}
 	r.InitializeConditions()
@@ -416,6 +439,15 @@ func TestRouteNotOwnedStuff(t *testing.T) {
 	apistest.CheckConditionFailed(r, RouteConditionReady, t)
 }
 
+func TestIngressNotOwned(t *testing.T) {
+	r := &RouteStatus{}
+	r.InitializeConditions()
+	r.MarkIngressNotOwned("evan")
+	apistest.CheckConditionOngoing(r, RouteConditionAllTrafficAssigned, t)
+	apistest.CheckConditionFailed(r, RouteConditionIngressReady, t)
+	apistest.CheckConditionFailed(r, RouteConditionReady, t)
+}
+
 func TestCertificateReady(t *testing.T) {
 	r := &RouteStatus{}
 	r.InitializeConditions()
@@ -464,6 +496,50 @@ func TestRouteHTTPDowngrade(t *testing.T) {
 	apistest.CheckConditionSucceeded(r, RouteConditionCertificateProvisioned, t)
 }
 
+func TestRouteTrafficTargetsThreshold(t *testing.T) {
+	r := &RouteStatus{}
+	r.InitializeConditions()
+	r.MarkTrafficTargetsExceedThreshold(11, 10)
+
+	cond := r.GetCondition(RouteConditionTrafficTargetsWithinThreshold)
+	if cond == nil || !cond.IsFalse() || cond.Severity != apis.ConditionSeverityWarning {
+		t.Errorf("GetCondition() = %#v, want a False warning", cond)
+	}
+	// The warning doesn't affect the readiness of the Route.
+	apistest.CheckConditionOngoing(r, RouteConditionReady, t)
+
+	r.MarkTrafficTargetsWithinThreshold()
+	if cond := r.GetCondition(RouteConditionTrafficTargetsWithinThreshold); cond != nil {
+		t.Errorf("GetCondition() = %#v, want: nil", cond)
+	}
+}
+
+func TestRouteCertificateExpiry(t *testing.T) {
+	r := &RouteStatus{}
+	r.InitializeConditions()
+	r.MarkCertificateExpiring("cert", time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC), 24*time.Hour)
+
+	cond := r.GetCondition(RouteConditionCertificateNotExpiring)
+	if cond == nil || !cond.IsFalse() || cond.Severity != apis.ConditionSeverityWarning {
+		t.Fatalf("GetCondition() = %#v, want a False warning", cond)
+	}
+	if want := "The certificate in secret cert expires at 2020-07-01T00:00:00Z, within the threshold of 24h0m0s."; cond.Message != want {
+		t.Errorf("Message = %q, want: %q", cond.Message, want)
+	}
+	// The warning doesn't affect the readiness of the Route.
+	apistest.CheckConditionOngoing(r, RouteConditionReady, t)
+
+	r.MarkCertificateInvalid("cert", "no PEM data")
+	if cond := r.GetCondition(RouteConditionCertificateNotExpiring); cond == nil || cond.Reason != "CertificateInvalid" {
+		t.Errorf("GetCondition() = %#v, want reason CertificateInvalid", cond)
+	}
+
+	r.MarkCertificatesNotExpiring()
+	if cond := r.GetCondition(RouteConditionCertificateNotExpiring); cond != nil {
+		t.Errorf("GetCondition() = %#v, want: nil", cond)
+	}
+}
+
 func TestIngressNotConfigured(t *testing.T) {
 	r := &RouteStatus{}
 	r.InitializeConditions()
@@ -471,3 +547,14 @@ func TestIngressNotConfigured(t *testing.T) {
 
 	apistest.CheckConditionOngoing(r, RouteConditionIngressReady, t)
 }
+
+func TestDNSNotResolved(t *testing.T) {
+	r := &RouteStatus{}
+	r.InitializeConditions()
+	r.MarkDNSNotResolved("foo.example.com")
+
+	apistest.CheckConditionOngoing(r, RouteConditionIngressReady, t)
+	if got, want := r.GetCondition(RouteConditionIngressReady).Reason, "DNSNotResolved"; got != want {
+		t.Errorf("Reason = %q, want: %q", got, want)
+	}
+}
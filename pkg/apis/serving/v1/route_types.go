@@ -135,6 +135,17 @@ const (
 	// RouteConditionCertificateProvisioned is set to False when the
 	// Knative Certificates fail to be provisioned for the Route.
 	RouteConditionCertificateProvisioned apis.ConditionType = "CertificateProvisioned"
+
+	// RouteConditionTrafficTargetsWithinThreshold is set to False, with a
+	// Warning severity, when the Route has more traffic targets than the
+	// configured threshold. It doesn't affect the readiness of the Route.
+	RouteConditionTrafficTargetsWithinThreshold apis.ConditionType = "TrafficTargetsWithinThreshold"
+
+	// RouteConditionCertificateNotExpiring is set to False, with a Warning
+	// severity, when a certificate serving the Route expires within the
+	// configured threshold or can't be read. It doesn't affect the readiness
+	// of the Route.
+	RouteConditionCertificateNotExpiring apis.ConditionType = "CertificateNotExpiring"
 )
 
 // IsRouteCondition returns true if the ConditionType is a route condition type
@@ -144,7 +155,9 @@ func IsRouteCondition(t apis.ConditionType) bool {
 		RouteConditionReady,
 		RouteConditionAllTrafficAssigned,
 		RouteConditionIngressReady,
-		RouteConditionCertificateProvisioned:
+		RouteConditionCertificateProvisioned,
+		RouteConditionTrafficTargetsWithinThreshold,
+		RouteConditionCertificateNotExpiring:
 		return true
 	}
 	return false
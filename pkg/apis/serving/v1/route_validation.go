@@ -29,7 +29,11 @@ import (
 // Validate makes sure that Route is properly configured.
 func (r *Route) Validate(ctx context.Context) *apis.FieldError {
 	errs := serving.ValidateObjectMetadata(ctx, r.GetObjectMeta()).Also(
-		r.validateLabels().ViaField("labels")).ViaField("metadata")
+		r.validateLabels().ViaField("labels")).Also(
+		serving.ValidateMinTLSVersionAnnotation(r.GetAnnotations()).ViaField("annotations")).Also(
+		serving.ValidateDefaultBackendAnnotation(r.GetAnnotations()).ViaField("annotations")).Also(
+		serving.ValidateMaintenanceAnnotation(r.GetAnnotations()).ViaField("annotations")).Also(
+		serving.ValidateDrainTimeoutAnnotation(r.GetAnnotations()).ViaField("annotations")).ViaField("metadata")
 	errs = errs.Also(r.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
 	errs = errs.Also(r.Status.Validate(apis.WithinStatus(ctx)).ViaField("status"))
 
@@ -213,13 +217,23 @@ func validateClusterVisibilityLabel(label string) (errs *apis.FieldError) {
 	return
 }
 
+// validateTagVisibilityLabel validates the visibility label of a tag.
+func validateTagVisibilityLabel(key, label string) (errs *apis.FieldError) {
+	if label != serving.VisibilityClusterLocal {
+		errs = apis.ErrInvalidValue(label, key)
+	}
+	return
+}
+
 // validateLabels function validates route labels.
 func (r *Route) validateLabels() (errs *apis.FieldError) {
 	for key, val := range r.GetLabels() {
-		switch key {
-		case serving.VisibilityLabelKey:
+		switch {
+		case key == serving.VisibilityLabelKey:
 			errs = errs.Also(validateClusterVisibilityLabel(val))
-		case serving.ServiceLabelKey:
+		case strings.HasPrefix(key, serving.TagVisibilityLabelKeyPrefix):
+			errs = errs.Also(validateTagVisibilityLabel(key, val))
+		case key == serving.ServiceLabelKey:
 			errs = errs.Also(verifyLabelOwnerRef(val, serving.ServiceLabelKey, "Service", r.GetOwnerReferences()))
 		default:
 			if strings.HasPrefix(key, serving.GroupNamePrefix) {
@@ -557,6 +557,30 @@ func TestRouteLabelValidation(t *testing.T) {
 			Spec: validRouteSpec,
 		},
 		want: apis.ErrInvalidValue("bad-value", "metadata.labels.serving.knative.dev/visibility"),
+	}, {
+		name: "valid tag visibility",
+		r: &Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "byo-name",
+				Labels: map[string]string{
+					serving.TagVisibilityLabelKeyPrefix + "blue": "cluster-local",
+				},
+			},
+			Spec: validRouteSpec,
+		},
+		want: nil,
+	}, {
+		name: "invalid tag visibility",
+		r: &Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "byo-name",
+				Labels: map[string]string{
+					serving.TagVisibilityLabelKeyPrefix + "blue": "bad-value",
+				},
+			},
+			Spec: validRouteSpec,
+		},
+		want: apis.ErrInvalidValue("bad-value", "metadata.labels.visibility.serving.knative.dev/blue"),
 	}, {
 		name: "valid knative service name",
 		r: &Route{
@@ -664,6 +688,75 @@ func TestRouteLabelValidation(t *testing.T) {
 	}
 }
 
+func TestRouteMinTLSVersionValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    *apis.FieldError
+	}{{
+		name:    "valid version",
+		version: "1.3",
+	}, {
+		name:    "invalid version",
+		version: "1.9",
+		want: apis.ErrInvalidValue("1.9", apis.CurrentField).ViaKey(
+			serving.MinTLSVersionAnnotationKey).ViaField("metadata", "annotations"),
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &Route{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "byo-name",
+					Annotations: map[string]string{
+						serving.MinTLSVersionAnnotationKey: test.version,
+					},
+				},
+				Spec: getRouteSpec("config"),
+			}
+			got := r.Validate(context.Background())
+			if !cmp.Equal(test.want.Error(), got.Error()) {
+				t.Errorf("Validate (-want, +got) = %v",
+					cmp.Diff(test.want.Error(), got.Error()))
+			}
+		})
+	}
+}
+
+func TestRouteDefaultBackendValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		wantErr bool
+	}{{
+		name:    "valid revision name",
+		backend: "config-00001",
+	}, {
+		name:    "invalid revision name",
+		backend: "Config_00001",
+		wantErr: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &Route{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "byo-name",
+					Annotations: map[string]string{
+						serving.DefaultBackendAnnotationKey: test.backend,
+					},
+				},
+				Spec: getRouteSpec("config"),
+			}
+			got := r.Validate(context.Background())
+			if (got != nil) != test.wantErr {
+				t.Errorf("Validate() = %v, wantErr: %v", got, test.wantErr)
+			}
+			if got != nil && !strings.Contains(got.Error(), serving.DefaultBackendAnnotationKey) {
+				t.Errorf("Validate() = %v, want an error about %s", got, serving.DefaultBackendAnnotationKey)
+			}
+		})
+	}
+}
+
 func getRouteSpec(confName string) RouteSpec {
 	return RouteSpec{
 		Traffic: []TrafficTarget{{
@@ -39,4 +39,7 @@ func (s *Service) SetDefaults(ctx context.Context) {
 func (ss *ServiceSpec) SetDefaults(ctx context.Context) {
 	ss.ConfigurationSpec.SetDefaults(ctx)
 	ss.RouteSpec.SetDefaults(WithDefaultConfigurationName(ctx))
+	for i := range ss.Variants {
+		ss.Variants[i].ConfigurationSpec.SetDefaults(ctx)
+	}
 }
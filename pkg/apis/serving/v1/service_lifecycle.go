@@ -45,6 +45,12 @@ func (s *Service) GetGroupVersionKind() schema.GroupVersionKind {
 	return SchemeGroupVersion.WithKind("Service")
 }
 
+// VariantConfigurationName returns the name of the Configuration of the
+// variant of the Service with the given name.
+func VariantConfigurationName(service, variant string) string {
+	return service + "-" + variant
+}
+
 // IsReady returns if the service is ready to serve and the latest spec has been observed.
 func (s *Service) IsReady() bool {
 	ss := s.Status
@@ -106,6 +112,26 @@ func (ss *ServiceStatus) PropagateConfigurationStatus(cs *ConfigurationStatus) {
 	}
 }
 
+// PropagateVariantConfigurationStatus takes the status of the Configuration of
+// a variant and surfaces it via the ConfigurationsReady status, unless that
+// is already failing. Unlike the main Configuration, the status fields of
+// the variants aren't propagated.
+func (ss *ServiceStatus) PropagateVariantConfigurationStatus(cs *ConfigurationStatus) {
+	cc := cs.GetCondition(ConfigurationConditionReady)
+	if cc == nil || cc.Status == corev1.ConditionTrue {
+		return
+	}
+	if sc := ss.GetCondition(ServiceConditionConfigurationsReady); sc != nil && sc.IsFalse() {
+		return
+	}
+	switch cc.Status {
+	case corev1.ConditionUnknown:
+		serviceCondSet.Manage(ss).MarkUnknown(ServiceConditionConfigurationsReady, cc.Reason, cc.Message)
+	case corev1.ConditionFalse:
+		serviceCondSet.Manage(ss).MarkFalse(ServiceConditionConfigurationsReady, cc.Reason, cc.Message)
+	}
+}
+
 // MarkRevisionNameTaken notes that the Route has not been programmed because the revision name is taken by a
 // conflicting revision definition.
 func (ss *ServiceStatus) MarkRevisionNameTaken(name string) {
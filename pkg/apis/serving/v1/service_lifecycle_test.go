@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -437,6 +437,55 @@ func TestConfigurationFailurePropagation(t *testing.T) {
 
 }
 
+func TestVariantConfigurationStatusPropagation(t *testing.T) {
+	ready := &ConfigurationStatus{
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:   ConfigurationConditionReady,
+				Status: corev1.ConditionTrue,
+			}},
+		},
+		ConfigurationStatusFields: ConfigurationStatusFields{
+			LatestReadyRevisionName: "main-00001",
+		},
+	}
+	variant := func(status corev1.ConditionStatus) *ConfigurationStatus {
+		return &ConfigurationStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{
+					Type:   ConfigurationConditionReady,
+					Status: status,
+				}},
+			},
+			ConfigurationStatusFields: ConfigurationStatusFields{
+				LatestReadyRevisionName: "main-canary-00001",
+			},
+		}
+	}
+
+	svc := &ServiceStatus{}
+	svc.InitializeConditions()
+	svc.PropagateConfigurationStatus(ready)
+	svc.PropagateVariantConfigurationStatus(variant(corev1.ConditionTrue))
+	apistest.CheckConditionSucceeded(svc, ServiceConditionConfigurationsReady, t)
+	if got, want := svc.LatestReadyRevisionName, "main-00001"; got != want {
+		t.Errorf("LatestReadyRevisionName = %q, want: %q", got, want)
+	}
+
+	// A variant still rolling out makes the Service ongoing.
+	svc.PropagateVariantConfigurationStatus(variant(corev1.ConditionUnknown))
+	apistest.CheckConditionOngoing(svc, ServiceConditionConfigurationsReady, t)
+
+	// A failing variant makes the Service fail.
+	svc.PropagateConfigurationStatus(ready)
+	svc.PropagateVariantConfigurationStatus(variant(corev1.ConditionFalse))
+	apistest.CheckConditionFailed(svc, ServiceConditionConfigurationsReady, t)
+
+	// An ongoing variant doesn't hide a failure.
+	svc.PropagateVariantConfigurationStatus(variant(corev1.ConditionUnknown))
+	apistest.CheckConditionFailed(svc, ServiceConditionConfigurationsReady, t)
+}
+
 func TestConfigurationFailureRecovery(t *testing.T) {
 	svc := &ServiceStatus{}
 	svc.InitializeConditions()
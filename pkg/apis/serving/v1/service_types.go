@@ -84,6 +84,23 @@ type ServiceSpec struct {
 	// Service's configuration and revisions (which also influences
 	// defaults).
 	RouteSpec `json:",inline"`
+
+	// Variants are additional named Configurations of this Service, each
+	// producing its own lineage of Revisions. The Route references a
+	// variant through the tag with its name.
+	// +optional
+	Variants []ServiceVariant `json:"variants,omitempty"`
+}
+
+// ServiceVariant is an additional named Configuration of a Service.
+type ServiceVariant struct {
+	// Name of the variant. It is the tag through which the Route references
+	// the variant, and the Configuration of the variant is named
+	// <service name>-<variant name>.
+	Name string `json:"name"`
+
+	// ServiceVariant inlines an unrestricted ConfigurationSpec.
+	ConfigurationSpec `json:",inline"`
 }
 
 // ConditionType represents a Service condition value
@@ -18,8 +18,11 @@ package v1
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 	"knative.dev/serving/pkg/apis/serving"
 )
@@ -32,7 +35,12 @@ func (s *Service) Validate(ctx context.Context) (errs *apis.FieldError) {
 	// spec validation.
 	if !apis.IsInStatusUpdate(ctx) {
 		errs = errs.Also(serving.ValidateObjectMetadata(ctx, s.GetObjectMeta()).Also(
-			s.validateLabels().ViaField("labels")).ViaField("metadata"))
+			s.validateLabels().ViaField("labels")).Also(
+			serving.ValidateMinTLSVersionAnnotation(s.GetAnnotations()).ViaField("annotations")).Also(
+			serving.ValidateDefaultBackendAnnotation(s.GetAnnotations()).ViaField("annotations")).Also(
+			serving.ValidateMaintenanceAnnotation(s.GetAnnotations()).ViaField("annotations")).Also(
+			serving.ValidateDrainTimeoutAnnotation(s.GetAnnotations()).ViaField("annotations")).Also(
+			serving.ValidateGCDisabledAnnotation(s.GetAnnotations()).ViaField("annotations")).ViaField("metadata"))
 		ctx = apis.WithinParent(ctx, s.ObjectMeta)
 		errs = errs.Also(s.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
 	}
@@ -55,7 +63,83 @@ func (ss *ServiceSpec) Validate(ctx context.Context) *apis.FieldError {
 	return ss.ConfigurationSpec.Validate(ctx).Also(
 		// Within the context of Service, the RouteSpec has a default
 		// configurationName.
-		ss.RouteSpec.Validate(WithDefaultConfigurationName(ctx)))
+		ss.RouteSpec.Validate(WithDefaultConfigurationName(ctx))).Also(
+		validateVariants(ctx, ss.Variants).ViaField("variants")).Also(
+		ss.validateRevisionLineages(ctx))
+}
+
+// validateRevisionLineages checks that the Revisions named by the templates
+// of the Service don't fall in the lineage of the Configuration of another
+// variant, i.e. that they aren't prefixed with <service>-<variant>-, so that
+// the Revisions of different Configurations can't collide.
+func (ss *ServiceSpec) validateRevisionLineages(ctx context.Context) (errs *apis.FieldError) {
+	parent := apis.ParentMeta(ctx)
+	if parent.Name == "" || len(ss.Variants) == 0 {
+		return nil
+	}
+	check := func(own, revisionName string) *apis.FieldError {
+		if revisionName == "" {
+			return nil
+		}
+		for _, v := range ss.Variants {
+			if name := VariantConfigurationName(parent.Name, v.Name); name != own && strings.HasPrefix(revisionName, name+"-") {
+				return apis.ErrInvalidValue(
+					fmt.Sprintf("%q falls in the Revisions of variant %q", revisionName, v.Name),
+					"template.metadata.name")
+			}
+		}
+		return nil
+	}
+	errs = check(parent.Name, ss.Template.Name)
+	for i, v := range ss.Variants {
+		errs = errs.Also(check(VariantConfigurationName(parent.Name, v.Name), v.Template.Name).ViaFieldIndex("variants", i))
+	}
+	return errs
+}
+
+// validateVariants checks that the variants have unique valid names, and
+// validates their ConfigurationSpec within the context of the Configuration
+// created for them.
+func validateVariants(ctx context.Context, variants []ServiceVariant) (errs *apis.FieldError) {
+	parent := apis.ParentMeta(ctx)
+	seen := make(map[string]int, len(variants))
+	for i, v := range variants {
+		if v.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("name").ViaIndex(i))
+			continue
+		}
+		if msgs := validation.IsDNS1035Label(v.Name); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidArrayValue(
+				fmt.Sprint("not a DNS 1035 label: ", msgs), "name", i))
+		}
+		if idx, ok := seen[v.Name]; ok {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("Multiple definitions for %q", v.Name),
+				Paths: []string{
+					fmt.Sprintf("[%d].name", i),
+					fmt.Sprintf("[%d].name", idx),
+				},
+			})
+		} else {
+			seen[v.Name] = i
+		}
+
+		om := metav1.ObjectMeta{
+			Namespace:    parent.Namespace,
+			GenerateName: parent.GenerateName,
+		}
+		if parent.Name != "" {
+			om.Name = VariantConfigurationName(parent.Name, v.Name)
+			if len(om.Name) > validation.DNS1035LabelMaxLength {
+				errs = errs.Also(apis.ErrInvalidArrayValue(
+					fmt.Sprintf("the name of its Configuration %q is longer than %d characters", om.Name, validation.DNS1035LabelMaxLength),
+					"name", i))
+			}
+		}
+		vctx := apis.WithinParent(ctx, om)
+		errs = errs.Also(v.ConfigurationSpec.Validate(vctx).ViaIndex(i))
+	}
+	return errs
 }
 
 // Validate implements apis.Validatable
@@ -70,6 +154,8 @@ func (s *Service) validateLabels() (errs *apis.FieldError) {
 		switch {
 		case key == serving.VisibilityLabelKey:
 			errs = errs.Also(validateClusterVisibilityLabel(val))
+		case strings.HasPrefix(key, serving.TagVisibilityLabelKeyPrefix):
+			errs = errs.Also(validateTagVisibilityLabel(key, val))
 		case strings.HasPrefix(key, serving.GroupNamePrefix):
 			errs = errs.Also(apis.ErrInvalidKeyName(key, apis.CurrentField))
 		}
@@ -18,6 +18,8 @@ package v1
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -129,6 +131,21 @@ func TestServiceValidation(t *testing.T) {
 			},
 		},
 		want: apis.ErrInvalidValue("bad-label", "metadata.labels.serving.knative.dev/visibility"),
+	}, {
+		name: "invalid tag visibility label value",
+		r: &Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+				Labels: map[string]string{
+					serving.TagVisibilityLabelKeyPrefix + "blue": "bad-label",
+				},
+			},
+			Spec: ServiceSpec{
+				ConfigurationSpec: goodConfigSpec,
+				RouteSpec:         goodRouteSpec,
+			},
+		},
+		want: apis.ErrInvalidValue("bad-label", "metadata.labels.visibility.serving.knative.dev/blue"),
 	}, {
 		name: "valid release",
 		r: &Service{
@@ -223,6 +240,163 @@ func TestServiceValidation(t *testing.T) {
 		want: apis.ErrOutOfBoundsValue(
 			-10, 0, config.DefaultMaxRevisionContainerConcurrency,
 			"spec.template.spec.containerConcurrency"),
+	}, {
+		name: "valid variants",
+		r: &Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: ServiceSpec{
+				ConfigurationSpec: goodConfigSpec,
+				RouteSpec:         goodRouteSpec,
+				Variants: []ServiceVariant{{
+					Name:              "canary",
+					ConfigurationSpec: goodConfigSpec,
+				}, {
+					Name:              "beta",
+					ConfigurationSpec: goodConfigSpec,
+				}},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid variant names",
+		r: &Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: ServiceSpec{
+				ConfigurationSpec: goodConfigSpec,
+				RouteSpec:         goodRouteSpec,
+				Variants: []ServiceVariant{{
+					ConfigurationSpec: goodConfigSpec,
+				}, {
+					Name:              "Canary",
+					ConfigurationSpec: goodConfigSpec,
+				}, {
+					Name:              "beta",
+					ConfigurationSpec: goodConfigSpec,
+				}, {
+					Name:              "beta",
+					ConfigurationSpec: goodConfigSpec,
+				}},
+			},
+		},
+		want: apis.ErrMissingField("spec.variants[0].name").Also(
+			apis.ErrInvalidArrayValue("not a DNS 1035 label: [a DNS-1035 label must consist of lower case alphanumeric characters or '-', start with an alphabetic character, and end with an alphanumeric character (e.g. 'my-name',  or 'abc-123', regex used for validation is '[a-z]([-a-z0-9]*[a-z0-9])?')]",
+				"spec.variants.name", 1)).Also(&apis.FieldError{
+			Message: `Multiple definitions for "beta"`,
+			Paths:   []string{"spec.variants[3].name", "spec.variants[2].name"},
+		}),
+	}, {
+		name: "variant revision name without the variant prefix",
+		r: &Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: ServiceSpec{
+				ConfigurationSpec: goodConfigSpec,
+				RouteSpec:         goodRouteSpec,
+				Variants: []ServiceVariant{{
+					Name: "canary",
+					ConfigurationSpec: ConfigurationSpec{
+						Template: RevisionTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "valid-canar-00001",
+							},
+							Spec: goodConfigSpec.Template.Spec,
+						},
+					},
+				}},
+			},
+		},
+		want: apis.ErrInvalidValue(`"valid-canar-00001" must have prefix "valid-canary-"`,
+			"spec.variants[0].template.metadata.name"),
+	}, {
+		name: "revision name in the lineage of a variant",
+		r: &Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: ServiceSpec{
+				ConfigurationSpec: ConfigurationSpec{
+					Template: RevisionTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "valid-canary-00001",
+						},
+						Spec: goodConfigSpec.Template.Spec,
+					},
+				},
+				RouteSpec: goodRouteSpec,
+				Variants: []ServiceVariant{{
+					Name:              "canary",
+					ConfigurationSpec: goodConfigSpec,
+				}, {
+					Name: "beta",
+					ConfigurationSpec: ConfigurationSpec{
+						Template: RevisionTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "valid-beta-one-00001",
+							},
+							Spec: goodConfigSpec.Template.Spec,
+						},
+					},
+				}, {
+					Name:              "beta-one",
+					ConfigurationSpec: goodConfigSpec,
+				}},
+			},
+		},
+		want: apis.ErrInvalidValue(`"valid-canary-00001" falls in the Revisions of variant "canary"`,
+			"spec.template.metadata.name").Also(
+			apis.ErrInvalidValue(`"valid-beta-one-00001" falls in the Revisions of variant "beta-one"`,
+				"spec.variants[1].template.metadata.name")),
+	}, {
+		name: "variant configuration name too long",
+		r: &Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: strings.Repeat("s", 40),
+			},
+			Spec: ServiceSpec{
+				ConfigurationSpec: goodConfigSpec,
+				RouteSpec:         goodRouteSpec,
+				Variants: []ServiceVariant{{
+					Name:              strings.Repeat("v", 30),
+					ConfigurationSpec: goodConfigSpec,
+				}},
+			},
+		},
+		want: apis.ErrInvalidArrayValue(
+			fmt.Sprintf("the name of its Configuration %q is longer than 63 characters", strings.Repeat("s", 40)+"-"+strings.Repeat("v", 30)),
+			"spec.variants.name", 0),	}, {
+		name: "invalid variant configuration",
+		r: &Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: ServiceSpec{
+				ConfigurationSpec: goodConfigSpec,
+				RouteSpec:         goodRouteSpec,
+				Variants: []ServiceVariant{{
+					Name: "canary",
+					ConfigurationSpec: ConfigurationSpec{
+						Template: RevisionTemplateSpec{
+							Spec: RevisionSpec{
+								PodSpec: corev1.PodSpec{
+									Containers: []corev1.Container{{
+										Image: "busybox",
+									}},
+								},
+								ContainerConcurrency: ptr.Int64(-10),
+							},
+						},
+					},
+				}},
+			},
+		},
+		want: apis.ErrOutOfBoundsValue(
+			-10, 0, config.DefaultMaxRevisionContainerConcurrency,
+			"spec.variants[0].template.spec.containerConcurrency"),
 	}}
 
 	// TODO(dangerd): PodSpec validation failures.
@@ -58,6 +58,16 @@ func (source *ConfigurationSpec) ConvertTo(ctx context.Context, sink *v1.Configu
 // ConvertTo helps implement apis.Convertible
 func (source *ConfigurationStatus) ConvertTo(ctx context.Context, sink *v1.ConfigurationStatus) error {
 	source.Status.ConvertTo(ctx, &sink.Status, v1.IsConfigurationCondition)
+	if source.Revisions != nil {
+		sink.Revisions = make([]v1.ConfigurationRevisionSummary, len(source.Revisions))
+		for i, r := range source.Revisions {
+			sink.Revisions[i] = v1.ConfigurationRevisionSummary{
+				Name:              r.Name,
+				Ready:             r.Ready,
+				CreationTimestamp: r.CreationTimestamp,
+			}
+		}
+	}
 	return source.ConfigurationStatusFields.ConvertTo(ctx, &sink.ConfigurationStatusFields)
 }
 
@@ -91,6 +101,16 @@ func (sink *ConfigurationSpec) ConvertFrom(ctx context.Context, source v1.Config
 // ConvertFrom helps implement apis.Convertible
 func (sink *ConfigurationStatus) ConvertFrom(ctx context.Context, source v1.ConfigurationStatus) error {
 	source.Status.ConvertTo(ctx, &sink.Status, v1.IsConfigurationCondition)
+	if source.Revisions != nil {
+		sink.Revisions = make([]ConfigurationRevisionSummary, len(source.Revisions))
+		for i, r := range source.Revisions {
+			sink.Revisions[i] = ConfigurationRevisionSummary{
+				Name:              r.Name,
+				Ready:             r.Ready,
+				CreationTimestamp: r.CreationTimestamp,
+			}
+		}
+	}
 
 	return sink.ConfigurationStatusFields.ConvertFrom(ctx, source.ConfigurationStatusFields)
 }
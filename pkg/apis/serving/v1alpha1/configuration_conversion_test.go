@@ -129,6 +129,15 @@ func TestConfigurationConversion(t *testing.T) {
 					LatestReadyRevisionName:   "foo-00002",
 					LatestCreatedRevisionName: "foo-00009",
 				},
+				Revisions: []ConfigurationRevisionSummary{{
+					Name:              "foo-00009",
+					Ready:             corev1.ConditionUnknown,
+					CreationTimestamp: metav1.Unix(1234, 0),
+				}, {
+					Name:              "foo-00002",
+					Ready:             corev1.ConditionTrue,
+					CreationTimestamp: metav1.Unix(1000, 0),
+				}},
 			},
 		},
 	}, {
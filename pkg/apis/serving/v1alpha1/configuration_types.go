@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"knative.dev/pkg/apis"
@@ -121,6 +122,24 @@ type ConfigurationStatus struct {
 	duckv1.Status `json:",inline"`
 
 	ConfigurationStatusFields `json:",inline"`
+
+	// Revisions summarizes the Revisions stamped out from this Configuration,
+	// the newest generation first.
+	// +optional
+	Revisions []ConfigurationRevisionSummary `json:"revisions,omitempty"`
+}
+
+// ConfigurationRevisionSummary is the summary of a Revision listed in the
+// status of its Configuration.
+type ConfigurationRevisionSummary struct {
+	// Name is the name of the Revision.
+	Name string `json:"name"`
+
+	// Ready is the status of the Ready condition of the Revision.
+	Ready corev1.ConditionStatus `json:"ready"`
+
+	// CreationTimestamp is when the Revision was created.
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -41,6 +41,16 @@ func (source *Service) ConvertTo(ctx context.Context, obj apis.Convertible) erro
 
 // ConvertTo helps implement apis.Convertible
 func (source *ServiceSpec) ConvertTo(ctx context.Context, sink *v1.ServiceSpec) error {
+	if source.Variants != nil {
+		sink.Variants = make([]v1.ServiceVariant, len(source.Variants))
+		for i, v := range source.Variants {
+			sink.Variants[i].Name = v.Name
+			if err := v.ConfigurationSpec.ConvertTo(ctx, &sink.Variants[i].ConfigurationSpec); err != nil {
+				return err
+			}
+		}
+	}
+
 	switch {
 	case source.DeprecatedRunLatest != nil:
 		sink.RouteSpec = v1.RouteSpec{
@@ -130,6 +140,15 @@ func (sink *Service) ConvertFrom(ctx context.Context, obj apis.Convertible) erro
 
 // ConvertFrom helps implement apis.Convertible
 func (sink *ServiceSpec) ConvertFrom(ctx context.Context, source v1.ServiceSpec) error {
+	if source.Variants != nil {
+		sink.Variants = make([]ServiceVariant, len(source.Variants))
+		for i, v := range source.Variants {
+			sink.Variants[i].Name = v.Name
+			if err := sink.Variants[i].ConfigurationSpec.ConvertFrom(ctx, v.ConfigurationSpec); err != nil {
+				return err
+			}
+		}
+	}
 	sink.RouteSpec.ConvertFrom(ctx, source.RouteSpec)
 	return sink.ConfigurationSpec.ConvertFrom(ctx, source.ConfigurationSpec)
 }
@@ -109,6 +109,16 @@ type ServiceSpec struct {
 	// be deprecated, and then dropped in v1beta1.
 	ConfigurationSpec `json:",inline"`
 	RouteSpec         `json:",inline"`
+
+	// Variants are additional named Configurations of this Service.
+	// +optional
+	Variants []ServiceVariant `json:"variants,omitempty"`
+}
+
+// ServiceVariant is an additional named Configuration of a Service.
+type ServiceVariant struct {
+	Name              string `json:"name"`
+	ConfigurationSpec `json:",inline"`
 }
 
 // ManualType contains the options for configuring a manual service. See ServiceSpec for
@@ -104,6 +104,23 @@ func (in *ConfigurationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigurationRevisionSummary) DeepCopyInto(out *ConfigurationRevisionSummary) {
+	*out = *in
+	in.CreationTimestamp.DeepCopyInto(&out.CreationTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigurationRevisionSummary.
+func (in *ConfigurationRevisionSummary) DeepCopy() *ConfigurationRevisionSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigurationRevisionSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigurationSpec) DeepCopyInto(out *ConfigurationSpec) {
 	*out = *in
@@ -140,6 +157,13 @@ func (in *ConfigurationStatus) DeepCopyInto(out *ConfigurationStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
 	out.ConfigurationStatusFields = in.ConfigurationStatusFields
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]ConfigurationRevisionSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -601,6 +625,13 @@ func (in *ServiceSpec) DeepCopyInto(out *ServiceSpec) {
 	}
 	in.ConfigurationSpec.DeepCopyInto(&out.ConfigurationSpec)
 	in.RouteSpec.DeepCopyInto(&out.RouteSpec)
+	if in.Variants != nil {
+		in, out := &in.Variants, &out.Variants
+		*out = make([]ServiceVariant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -633,6 +664,23 @@ func (in *ServiceStatus) DeepCopy() *ServiceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceVariant) DeepCopyInto(out *ServiceVariant) {
+	*out = *in
+	in.ConfigurationSpec.DeepCopyInto(&out.ConfigurationSpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceVariant.
+func (in *ServiceVariant) DeepCopy() *ServiceVariant {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceVariant)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficTarget) DeepCopyInto(out *TrafficTarget) {
 	*out = *in
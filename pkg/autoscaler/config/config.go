@@ -18,12 +18,15 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	cm "knative.dev/pkg/configmap"
 	"knative.dev/serving/pkg/apis/autoscaling"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -38,6 +41,20 @@ const (
 	defaultTargetUtilization = 0.7
 )
 
+// MetricGapPolicy is the behavior of the autoscaler while the metrics of a
+// revision can't be scraped.
+type MetricGapPolicy string
+
+const (
+	// MetricGapPolicyNone scales on whatever metrics were collected, like when
+	// the metrics are flowing.
+	MetricGapPolicyNone MetricGapPolicy = "none"
+	// MetricGapPolicyHoldLast keeps the last scale the autoscaler decided on.
+	MetricGapPolicyHoldLast MetricGapPolicy = "hold-last"
+	// MetricGapPolicyKeepCurrent keeps the current number of ready pods.
+	MetricGapPolicyKeepCurrent MetricGapPolicy = "keep-current"
+)
+
 // Config defines the tunable autoscaler parameters
 // +k8s:deepcopy-gen=true
 type Config struct {
@@ -75,6 +92,10 @@ type Config struct {
 	// autoscaling.knative.dev/maxScale annotation
 	MaxScale int32
 
+	// ScaleBoundsStatus indicates whether the effective min and max scale of
+	// the revisions are reported in the status of their PodAutoscalers.
+	ScaleBoundsStatus bool
+
 	// General autoscaler algorithm configuration.
 	MaxScaleUpRate           float64
 	MaxScaleDownRate         float64
@@ -82,10 +103,29 @@ type Config struct {
 	PanicWindowPercentage    float64
 	PanicThresholdPercentage float64
 
+	// MaxScaleUpStep is the maximum number of pods, or percentage of the
+	// existing pods, a revision may add per autoscaler decision outside of the
+	// panic mode. Zero means no limit.
+	MaxScaleUpStep intstr.IntOrString
+
 	ScaleToZeroGracePeriod        time.Duration
 	ScaleToZeroPodRetentionPeriod time.Duration
 
 	PodAutoscalerClass string
+
+	// ScrapeConcurrency is the maximum number of revisions whose metrics
+	// are scraped at the same time. Zero means no limit.
+	ScrapeConcurrency int32
+
+	// DecisionHistoryDepth is the number of the latest scaling decisions
+	// kept per revision for debugging. Zero disables the history.
+	DecisionHistoryDepth int32
+
+	// MetricGapPolicy is applied while the scrapes of a revision's metrics
+	// fail, for at most MetricGapGracePeriod. After that the autoscaler
+	// scales on the metrics it has.
+	MetricGapPolicy      MetricGapPolicy
+	MetricGapGracePeriod time.Duration
 }
 
 func defaultConfig() *Config {
@@ -98,6 +138,7 @@ func defaultConfig() *Config {
 		RPSTargetDefault:              200,
 		MaxScaleUpRate:                1000,
 		MaxScaleDownRate:              2,
+		MaxScaleUpStep:                intstr.FromInt(0),
 		TargetBurstCapacity:           200,
 		PanicWindowPercentage:         10,
 		ActivatorCapacity:             100,
@@ -109,6 +150,8 @@ func defaultConfig() *Config {
 		AllowZeroInitialScale:         false,
 		InitialScale:                  1,
 		MaxScale:                      0,
+		MetricGapPolicy:               MetricGapPolicyNone,
+		MetricGapGracePeriod:          60 * time.Second,
 	}
 }
 
@@ -118,9 +161,12 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 
 	if err := cm.Parse(data,
 		cm.AsString("pod-autoscaler-class", &lc.PodAutoscalerClass),
+		asMetricGapPolicy("metric-gap-policy", &lc.MetricGapPolicy),
+		asIntOrPercent("max-scale-up-step", &lc.MaxScaleUpStep),
 
 		cm.AsBool("enable-scale-to-zero", &lc.EnableScaleToZero),
 		cm.AsBool("allow-zero-initial-scale", &lc.AllowZeroInitialScale),
+		cm.AsBool("scale-bounds-status", &lc.ScaleBoundsStatus),
 
 		cm.AsFloat64("max-scale-up-rate", &lc.MaxScaleUpRate),
 		cm.AsFloat64("max-scale-down-rate", &lc.MaxScaleDownRate),
@@ -134,10 +180,13 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 
 		cm.AsInt32("initial-scale", &lc.InitialScale),
 		cm.AsInt32("max-scale", &lc.MaxScale),
+		cm.AsInt32("scrape-concurrency", &lc.ScrapeConcurrency),
+		cm.AsInt32("decision-history-depth", &lc.DecisionHistoryDepth),
 
 		cm.AsDuration("stable-window", &lc.StableWindow),
 		cm.AsDuration("scale-to-zero-grace-period", &lc.ScaleToZeroGracePeriod),
 		cm.AsDuration("scale-to-zero-pod-retention-period", &lc.ScaleToZeroPodRetentionPeriod),
+		cm.AsDuration("metric-gap-grace-period", &lc.MetricGapGracePeriod),
 	); err != nil {
 		return nil, fmt.Errorf("failed to parse data: %w", err)
 	}
@@ -190,6 +239,10 @@ func validate(lc *Config) (*Config, error) {
 		return nil, fmt.Errorf("max-scale-down-rate = %v, must be greater than 1.0", lc.MaxScaleDownRate)
 	}
 
+	if step, _ := intstr.GetValueFromIntOrPercent(&lc.MaxScaleUpStep, 100, false); step < 0 {
+		return nil, fmt.Errorf("max-scale-up-step = %v, must be at least 0", lc.MaxScaleUpStep.String())
+	}
+
 	// We can't permit stable window be less than our aggregation window for correctness.
 	// Or too big, so that our desisions are too imprecise.
 	if lc.StableWindow < autoscaling.WindowMin || lc.StableWindow > autoscaling.WindowMax {
@@ -216,9 +269,65 @@ func validate(lc *Config) (*Config, error) {
 	if lc.MaxScale < 0 {
 		return nil, fmt.Errorf("max-scale = %v, must be at least 0", lc.MaxScale)
 	}
+
+	if lc.ScrapeConcurrency < 0 {
+		return nil, fmt.Errorf("scrape-concurrency = %v, must be at least 0", lc.ScrapeConcurrency)
+	}
+
+	if lc.DecisionHistoryDepth < 0 {
+		return nil, fmt.Errorf("decision-history-depth = %v, must be at least 0", lc.DecisionHistoryDepth)
+	}
+
+	if lc.MetricGapGracePeriod < 0 {
+		return nil, fmt.Errorf("metric-gap-grace-period = %v, must be at least 0", lc.MetricGapGracePeriod)
+	}
 	return lc, nil
 }
 
+// asMetricGapPolicy parses the value at key as a MetricGapPolicy into the
+// target, if it exists.
+func asMetricGapPolicy(key string, target *MetricGapPolicy) cm.ParseFunc {
+	return func(data map[string]string) error {
+		raw, ok := data[key]
+		if !ok {
+			return nil
+		}
+		switch p := MetricGapPolicy(strings.TrimSpace(raw)); p {
+		case MetricGapPolicyNone, MetricGapPolicyHoldLast, MetricGapPolicyKeepCurrent:
+			*target = p
+			return nil
+		default:
+			return fmt.Errorf("%s = %q, must be one of %q, %q or %q", key, raw,
+				MetricGapPolicyNone, MetricGapPolicyHoldLast, MetricGapPolicyKeepCurrent)
+		}
+	}
+}
+
+// asIntOrPercent parses the value at key as either an integer or a percentage
+// into the target, if it exists.
+func asIntOrPercent(key string, target *intstr.IntOrString) cm.ParseFunc {
+	return func(data map[string]string) error {
+		raw, ok := data[key]
+		if !ok {
+			return nil
+		}
+		raw = strings.TrimSpace(raw)
+		if percent := strings.TrimSuffix(raw, "%"); percent != raw {
+			if _, err := strconv.Atoi(percent); err != nil {
+				return fmt.Errorf("failed to parse %q: %w", key, err)
+			}
+			*target = intstr.FromString(raw)
+			return nil
+		}
+		val, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse %q: %w", key, err)
+		}
+		*target = intstr.FromInt(val)
+		return nil
+	}
+}
+
 // NewConfigFromConfigMap creates a Config from the supplied ConfigMap
 func NewConfigFromConfigMap(configMap *corev1.ConfigMap) (*Config, error) {
 	return NewConfigFromMap(configMap.Data)
@@ -22,6 +22,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	. "knative.dev/pkg/configmap/testing"
 )
@@ -310,6 +311,110 @@ func TestNewConfig(t *testing.T) {
 			c.MaxScale = 10
 			return c
 		}(),
+	}, {
+		name: "with negative scrape concurrency",
+		input: map[string]string{
+			"scrape-concurrency": "-1",
+		},
+		wantErr: true,
+	}, {
+		name: "with valid scrape concurrency",
+		input: map[string]string{
+			"scrape-concurrency": "50",
+		},
+		want: func() *Config {
+			c := defaultConfig()
+			c.ScrapeConcurrency = 50
+			return c
+		}(),
+	}, {
+		name: "with negative decision history depth",
+		input: map[string]string{
+			"decision-history-depth": "-1",
+		},
+		wantErr: true,
+	}, {
+		name: "with decision history depth",
+		input: map[string]string{
+			"decision-history-depth": "30",
+		},
+		want: func() *Config {
+			c := defaultConfig()
+			c.DecisionHistoryDepth = 30
+			return c
+		}(),
+	}, {
+		name: "with scale bounds status",
+		input: map[string]string{
+			"scale-bounds-status": "true",
+		},
+		want: func() *Config {
+			c := defaultConfig()
+			c.ScaleBoundsStatus = true
+			return c
+		}(),
+	}, {
+		name: "with metric gap policy",
+		input: map[string]string{
+			"metric-gap-policy":       "hold-last",
+			"metric-gap-grace-period": "2m",
+		},
+		want: func() *Config {
+			c := defaultConfig()
+			c.MetricGapPolicy = MetricGapPolicyHoldLast
+			c.MetricGapGracePeriod = 2 * time.Minute
+			return c
+		}(),
+	}, {
+		name: "with max scale up step",
+		input: map[string]string{
+			"max-scale-up-step": "10",
+		},
+		want: func() *Config {
+			c := defaultConfig()
+			c.MaxScaleUpStep = intstr.FromInt(10)
+			return c
+		}(),
+	}, {
+		name: "with max scale up step percentage",
+		input: map[string]string{
+			"max-scale-up-step": " 50% ",
+		},
+		want: func() *Config {
+			c := defaultConfig()
+			c.MaxScaleUpStep = intstr.FromString("50%")
+			return c
+		}(),
+	}, {
+		name: "with negative max scale up step",
+		input: map[string]string{
+			"max-scale-up-step": "-1",
+		},
+		wantErr: true,
+	}, {
+		name: "with negative max scale up step percentage",
+		input: map[string]string{
+			"max-scale-up-step": "-10%",
+		},
+		wantErr: true,
+	}, {
+		name: "with non-parseable max scale up step",
+		input: map[string]string{
+			"max-scale-up-step": "ten%",
+		},
+		wantErr: true,
+	}, {
+		name: "with invalid metric gap policy",
+		input: map[string]string{
+			"metric-gap-policy": "scale-down",
+		},
+		wantErr: true,
+	}, {
+		name: "with negative metric gap grace period",
+		input: map[string]string{
+			"metric-gap-grace-period": "-1s",
+		},
+		wantErr: true,
 	}}
 
 	for _, test := range tests {
@@ -0,0 +1,208 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custommetrics
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// authConfigMapNamespace and authConfigMapName identify the ConfigMap
+	// in which the API server publishes how the aggregated APIs are to
+	// authenticate the requests it proxies to them.
+	authConfigMapNamespace = "kube-system"
+	authConfigMapName      = "extension-apiserver-authentication"
+)
+
+// RequestHeaderConfig is how the API server authenticates the requests it
+// proxies to the aggregated APIs: it presents a client certificate signed by
+// ClientCAs, with one of AllowedNames if any, and passes the user and groups
+// it authenticated in the given headers.
+type RequestHeaderConfig struct {
+	ClientCAs       *x509.CertPool
+	AllowedNames    sets.String
+	UsernameHeaders []string
+	GroupHeaders    []string
+}
+
+// LoadRequestHeaderConfig reads the RequestHeaderConfig published by the API
+// server.
+func LoadRequestHeaderConfig(kubeclient kubernetes.Interface) (*RequestHeaderConfig, error) {
+	cm, err := kubeclient.CoreV1().ConfigMaps(authConfigMapNamespace).Get(authConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", authConfigMapNamespace, authConfigMapName, err)
+	}
+	ca, ok := cm.Data["requestheader-client-ca-file"]
+	if !ok {
+		return nil, errors.New("the API server has no request header client CA configured")
+	}
+	cfg := &RequestHeaderConfig{
+		ClientCAs: x509.NewCertPool(),
+	}
+	if !cfg.ClientCAs.AppendCertsFromPEM([]byte(ca)) {
+		return nil, errors.New("failed to parse the request header client CA")
+	}
+	var names []string
+	for key, into := range map[string]*[]string{
+		"requestheader-allowed-names":    &names,
+		"requestheader-username-headers": &cfg.UsernameHeaders,
+		"requestheader-group-headers":    &cfg.GroupHeaders,
+	} {
+		if v := cm.Data[key]; v != "" {
+			if err := json.Unmarshal([]byte(v), into); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", key, err)
+			}
+		}
+	}
+	cfg.AllowedNames = sets.NewString(names...)
+	return cfg, nil
+}
+
+// authHandler authenticates and authorizes the requests to the custom metrics
+// API by delegating to the API server, like the aggregated API servers do.
+type authHandler struct {
+	next          http.Handler
+	kubeclient    kubernetes.Interface
+	requestHeader *RequestHeaderConfig
+	logger        *zap.SugaredLogger
+}
+
+// WithDelegatedAuth returns a handler serving the requests to next only if the
+// API server authenticates them, either through the front proxy described by
+// requestHeader or through a bearer token, and authorizes them.
+func WithDelegatedAuth(next http.Handler, kubeclient kubernetes.Interface, requestHeader *RequestHeaderConfig, logger *zap.SugaredLogger) http.Handler {
+	return &authHandler{
+		next:          next,
+		kubeclient:    kubeclient,
+		requestHeader: requestHeader,
+		logger:        logger,
+	}
+}
+
+func (h *authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The metrics are read-only.
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, groups, err := h.authenticate(r)
+	if err != nil {
+		h.logger.Debugw("Failed to authenticate custom metrics request", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	allowed, err := h.authorize(r, user, groups)
+	if err != nil {
+		h.logger.Errorw("Failed to authorize custom metrics request", zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	h.next.ServeHTTP(w, r)
+}
+
+// authenticate returns the user and groups the request is made on behalf of.
+func (h *authHandler) authenticate(r *http.Request) (string, []string, error) {
+	// The TLS config only accepts the client certificates of the front proxy.
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		if cn := r.TLS.PeerCertificates[0].Subject.CommonName; h.requestHeader.AllowedNames.Len() > 0 &&
+			!h.requestHeader.AllowedNames.Has(cn) {
+			return "", nil, fmt.Errorf("client certificate %q is not allowed as front proxy", cn)
+		}
+		var user string
+		for _, header := range h.requestHeader.UsernameHeaders {
+			if user = r.Header.Get(header); user != "" {
+				break
+			}
+		}
+		if user == "" {
+			return "", nil, errors.New("the front proxy passed no user")
+		}
+		var groups []string
+		for _, header := range h.requestHeader.GroupHeaders {
+			groups = append(groups, r.Header.Values(header)...)
+		}
+		return user, groups, nil
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == r.Header.Get("Authorization") {
+		return "", nil, errors.New("no credentials")
+	}
+	review, err := h.kubeclient.AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !review.Status.Authenticated {
+		return "", nil, fmt.Errorf("token rejected: %s", review.Status.Error)
+	}
+	return review.Status.User.Username, review.Status.User.Groups, nil
+}
+
+// authorize returns whether the user may make the request.
+func (h *authHandler) authorize(r *http.Request, user string, groups []string) (bool, error) {
+	spec := authorizationv1.SubjectAccessReviewSpec{
+		User:   user,
+		Groups: groups,
+	}
+	if ns, res, name, metric, ok := parseMetricPath(r.URL.Path); ok {
+		gv := strings.SplitN(GroupVersion, "/", 2)
+		verb := "get"
+		if name == allNames {
+			verb, name = "list", ""
+		}
+		spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Namespace:   ns,
+			Verb:        verb,
+			Group:       gv[0],
+			Version:     gv[1],
+			Resource:    res,
+			Subresource: metric,
+			Name:        name,
+		}
+	} else {
+		spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{
+			Path: r.URL.Path,
+			Verb: "get",
+		}
+	}
+	review, err := h.kubeclient.AuthorizationV1().SubjectAccessReviews().Create(&authorizationv1.SubjectAccessReview{
+		Spec: spec,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to review access: %w", err)
+	}
+	return review.Status.Allowed, nil
+}
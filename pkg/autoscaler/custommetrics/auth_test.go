@@ -0,0 +1,250 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custommetrics
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	logtesting "knative.dev/pkg/logging/testing"
+	certresources "knative.dev/pkg/webhook/certificates/resources"
+)
+
+func TestDelegatedAuth(t *testing.T) {
+	const metricPath = "/apis/custom.metrics.k8s.io/v1beta1/namespaces/ns/revisions.serving.knative.dev/rev/concurrency"
+	requestHeader := &RequestHeaderConfig{
+		AllowedNames:    sets.NewString("front-proxy-client"),
+		UsernameHeaders: []string{"X-Remote-User"},
+		GroupHeaders:    []string{"X-Remote-Group"},
+	}
+	frontProxy := func(cn string) *tls.ConnectionState {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+		return &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		tls      *tls.ConnectionState
+		header   http.Header
+		allowed  bool
+		wantCode int
+		wantSAR  *authorizationv1.SubjectAccessReviewSpec
+	}{{
+		name:     "no credentials",
+		path:     metricPath,
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "rejected token",
+		path:     metricPath,
+		header:   http.Header{"Authorization": []string{"Bearer bad"}},
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "token allowed",
+		path:     metricPath,
+		header:   http.Header{"Authorization": []string{"Bearer good"}},
+		allowed:  true,
+		wantCode: http.StatusOK,
+		wantSAR: &authorizationv1.SubjectAccessReviewSpec{
+			User:   "alice",
+			Groups: []string{"devs"},
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   "ns",
+				Verb:        "get",
+				Group:       "custom.metrics.k8s.io",
+				Version:     "v1beta1",
+				Resource:    "revisions.serving.knative.dev",
+				Subresource: "concurrency",
+				Name:        "rev",
+			},
+		},
+	}, {
+		name:     "list allowed",
+		path:     "/apis/custom.metrics.k8s.io/v1beta1/namespaces/ns/revisions.serving.knative.dev/*/concurrency",
+		header:   http.Header{"Authorization": []string{"Bearer good"}},
+		allowed:  true,
+		wantCode: http.StatusOK,
+		wantSAR: &authorizationv1.SubjectAccessReviewSpec{
+			User:   "alice",
+			Groups: []string{"devs"},
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   "ns",
+				Verb:        "list",
+				Group:       "custom.metrics.k8s.io",
+				Version:     "v1beta1",
+				Resource:    "revisions.serving.knative.dev",
+				Subresource: "concurrency",
+			},
+		},
+	}, {
+		name:     "token denied",
+		path:     metricPath,
+		header:   http.Header{"Authorization": []string{"Bearer good"}},
+		wantCode: http.StatusForbidden,
+	}, {
+		name: "front proxy allowed",
+		path: "/apis/custom.metrics.k8s.io/v1beta1",
+		tls:  frontProxy("front-proxy-client"),
+		header: http.Header{
+			"X-Remote-User":  []string{"system:serviceaccount:kube-system:horizontal-pod-autoscaler"},
+			"X-Remote-Group": []string{"system:serviceaccounts", "system:authenticated"},
+		},
+		allowed:  true,
+		wantCode: http.StatusOK,
+		wantSAR: &authorizationv1.SubjectAccessReviewSpec{
+			User:   "system:serviceaccount:kube-system:horizontal-pod-autoscaler",
+			Groups: []string{"system:serviceaccounts", "system:authenticated"},
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: "/apis/custom.metrics.k8s.io/v1beta1",
+				Verb: "get",
+			},
+		},
+	}, {
+		name:     "front proxy with another name",
+		path:     metricPath,
+		tls:      frontProxy("someone"),
+		header:   http.Header{"X-Remote-User": []string{"alice"}},
+		allowed:  true,
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "front proxy without user",
+		path:     metricPath,
+		tls:      frontProxy("front-proxy-client"),
+		allowed:  true,
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "not a read",
+		method:   http.MethodPost,
+		path:     metricPath,
+		header:   http.Header{"Authorization": []string{"Bearer good"}},
+		allowed:  true,
+		wantCode: http.StatusMethodNotAllowed,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kubeclient := fakekubeclientset.NewSimpleClientset()
+			kubeclient.PrependReactor("create", "tokenreviews", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				review := action.(clientgotesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+				if review.Spec.Token == "good" {
+					review.Status.Authenticated = true
+					review.Status.User = authenticationv1.UserInfo{Username: "alice", Groups: []string{"devs"}}
+				}
+				return true, review, nil
+			})
+			var gotSAR *authorizationv1.SubjectAccessReviewSpec
+			kubeclient.PrependReactor("create", "subjectaccessreviews", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				review := action.(clientgotesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				gotSAR = review.Spec.DeepCopy()
+				review.Status.Allowed = test.allowed
+				return true, review, nil
+			})
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler := WithDelegatedAuth(next, kubeclient, requestHeader, logtesting.TestLogger(t))
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "https://autoscaler"+test.path, nil)
+			req.TLS = test.tls
+			for k, v := range test.header {
+				req.Header[k] = v
+			}
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != test.wantCode {
+				t.Errorf("StatusCode = %d, want: %d", resp.Code, test.wantCode)
+			}
+			if test.wantSAR != nil && !cmp.Equal(gotSAR, test.wantSAR) {
+				t.Error("SubjectAccessReview (-want, +got):", cmp.Diff(test.wantSAR, gotSAR))
+			}
+		})
+	}
+}
+
+func TestLoadRequestHeaderConfig(t *testing.T) {
+	_, _, ca, err := certresources.CreateCerts(context.Background(), "front-proxy", "kube-system", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal("CreateCerts() =", err)
+	}
+	kubeclient := fakekubeclientset.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: authConfigMapNamespace,
+			Name:      authConfigMapName,
+		},
+		Data: map[string]string{
+			"requestheader-client-ca-file":       string(ca),
+			"requestheader-allowed-names":        `["front-proxy-client"]`,
+			"requestheader-username-headers":     `["X-Remote-User"]`,
+			"requestheader-group-headers":        `["X-Remote-Group"]`,
+			"requestheader-extra-headers-prefix": `["X-Remote-Extra-"]`,
+		},
+	})
+
+	cfg, err := LoadRequestHeaderConfig(kubeclient)
+	if err != nil {
+		t.Fatal("LoadRequestHeaderConfig() =", err)
+	}
+	if got, want := cfg.AllowedNames, sets.NewString("front-proxy-client"); !got.Equal(want) {
+		t.Errorf("AllowedNames = %v, want: %v", got, want)
+	}
+	if got, want := cfg.UsernameHeaders, []string{"X-Remote-User"}; !cmp.Equal(got, want) {
+		t.Errorf("UsernameHeaders = %v, want: %v", got, want)
+	}
+	if got, want := cfg.GroupHeaders, []string{"X-Remote-Group"}; !cmp.Equal(got, want) {
+		t.Errorf("GroupHeaders = %v, want: %v", got, want)
+	}
+	if len(cfg.ClientCAs.Subjects()) != 1 {
+		t.Errorf("ClientCAs has %d certificates, want: 1", len(cfg.ClientCAs.Subjects()))
+	}
+}
+
+func TestLoadRequestHeaderConfigMissing(t *testing.T) {
+	kubeclient := fakekubeclientset.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: authConfigMapNamespace,
+			Name:      authConfigMapName,
+		},
+	})
+	if _, err := LoadRequestHeaderConfig(kubeclient); err == nil {
+		t.Error("LoadRequestHeaderConfig() = nil, want an error without a client CA")
+	}
+}
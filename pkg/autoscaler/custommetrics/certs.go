@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custommetrics
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"knative.dev/pkg/system"
+	certresources "knative.dev/pkg/webhook/certificates/resources"
+)
+
+const (
+	// APIServiceName is the name of the APIService registering the custom
+	// metrics API served by the autoscaler.
+	APIServiceName = "v1beta1.custom.metrics.k8s.io"
+
+	// certSecretName is the name of the Secret holding the serving
+	// certificate, shared by the replicas of the autoscaler.
+	certSecretName = "custom-metrics-certs"
+
+	// certValidity is how long the serving certificate is valid.
+	certValidity = 10 * 365 * 24 * time.Hour
+)
+
+var apiServicesResource = schema.GroupVersionResource{
+	Group:    "apiregistration.k8s.io",
+	Version:  "v1",
+	Resource: "apiservices",
+}
+
+// ErrNotRegistered is returned when the APIService of the custom metrics API
+// isn't installed, so that there is nothing to serve.
+var ErrNotRegistered = errors.New("the " + APIServiceName + " APIService is not installed")
+
+// ServingCertificate returns the certificate the custom metrics API is to be
+// served with for the given Service, after registering its CA as the
+// caBundle of the APIService. The certificate is created on first use and
+// kept in a Secret.
+func ServingCertificate(ctx context.Context, kubeclient kubernetes.Interface, dynamicclient dynamic.Interface, serviceName string) (tls.Certificate, error) {
+	apiServices := dynamicclient.Resource(apiServicesResource)
+	apiService, err := apiServices.Get(APIServiceName, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return tls.Certificate{}, ErrNotRegistered
+	} else if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to get APIService %s: %w", APIServiceName, err)
+	}
+
+	secret, err := certificateSecret(ctx, kubeclient, serviceName)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	caBundle := base64.StdEncoding.EncodeToString(secret.Data[certresources.CACert])
+	if got, _, _ := unstructured.NestedString(apiService.Object, "spec", "caBundle"); got != caBundle {
+		apiService = apiService.DeepCopy()
+		if err := unstructured.SetNestedField(apiService.Object, caBundle, "spec", "caBundle"); err != nil {
+			return tls.Certificate{}, err
+		}
+		if _, err := apiServices.Update(apiService, metav1.UpdateOptions{}); err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to update the caBundle of APIService %s: %w", APIServiceName, err)
+		}
+	}
+
+	pair, err := tls.X509KeyPair(secret.Data[certresources.ServerCert], secret.Data[certresources.ServerKey])
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load the serving certificate: %w", err)
+	}
+	return pair, nil
+}
+
+// certificateSecret returns the Secret holding the serving certificate,
+// creating it if needed.
+func certificateSecret(ctx context.Context, kubeclient kubernetes.Interface, serviceName string) (*corev1.Secret, error) {
+	secrets := kubeclient.CoreV1().Secrets(system.Namespace())
+	secret, err := secrets.Get(certSecretName, metav1.GetOptions{})
+	if err == nil {
+		return secret, nil
+	} else if !apierrs.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get Secret %s: %w", certSecretName, err)
+	}
+
+	key, cert, caCert, err := certresources.CreateCerts(ctx, serviceName, system.Namespace(), time.Now().Add(certValidity))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the serving certificate: %w", err)
+	}
+	secret, err = secrets.Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      certSecretName,
+			Namespace: system.Namespace(),
+		},
+		Data: map[string][]byte{
+			certresources.ServerKey:  key,
+			certresources.ServerCert: cert,
+			certresources.CACert:     caCert,
+		},
+	})
+	if apierrs.IsAlreadyExists(err) {
+		// Another replica created it first.
+		return secrets.Get(certSecretName, metav1.GetOptions{})
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to create Secret %s: %w", certSecretName, err)
+	}
+	return secret, nil
+}
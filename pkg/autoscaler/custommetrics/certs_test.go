@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custommetrics
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamicclient "k8s.io/client-go/dynamic/fake"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+
+	"knative.dev/pkg/system"
+	certresources "knative.dev/pkg/webhook/certificates/resources"
+
+	_ "knative.dev/pkg/system/testing"
+)
+
+func apiService() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"metadata": map[string]interface{}{
+			"name": APIServiceName,
+		},
+		"spec": map[string]interface{}{
+			"group":   "custom.metrics.k8s.io",
+			"version": "v1beta1",
+		},
+	}}
+}
+
+func TestServingCertificateNotRegistered(t *testing.T) {
+	kubeclient := fakekubeclientset.NewSimpleClientset()
+	dynamicclient := fakedynamicclient.NewSimpleDynamicClient(runtime.NewScheme())
+
+	if _, err := ServingCertificate(context.Background(), kubeclient, dynamicclient, "autoscaler"); !errors.Is(err, ErrNotRegistered) {
+		t.Errorf("ServingCertificate() = %v, want: %v", err, ErrNotRegistered)
+	}
+	if _, err := kubeclient.CoreV1().Secrets(system.Namespace()).Get(certSecretName, metav1.GetOptions{}); err == nil {
+		t.Error("The certificate Secret was created without an APIService")
+	}
+}
+
+func TestServingCertificate(t *testing.T) {
+	kubeclient := fakekubeclientset.NewSimpleClientset()
+	dynamicclient := fakedynamicclient.NewSimpleDynamicClient(runtime.NewScheme(), apiService())
+
+	cert, err := ServingCertificate(context.Background(), kubeclient, dynamicclient, "autoscaler")
+	if err != nil {
+		t.Fatal("ServingCertificate() =", err)
+	}
+
+	secret, err := kubeclient.CoreV1().Secrets(system.Namespace()).Get(certSecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get the certificate Secret:", err)
+	}
+	got, err := dynamicclient.Resource(apiServicesResource).Get(APIServiceName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get the APIService:", err)
+	}
+	caBundle, _, _ := unstructured.NestedString(got.Object, "spec", "caBundle")
+	if want := base64.StdEncoding.EncodeToString(secret.Data[certresources.CACert]); caBundle != want {
+		t.Errorf("caBundle = %q, want: %q", caBundle, want)
+	}
+
+	// The CA of the caBundle signs the certificate served.
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal("Failed to parse the serving certificate:", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(secret.Data[certresources.CACert])
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		DNSName: "autoscaler." + system.Namespace() + ".svc",
+		Roots:   roots,
+	}); err != nil {
+		t.Error("The serving certificate doesn't verify with the caBundle:", err)
+	}
+
+	// The other replicas serve the same certificate.
+	again, err := ServingCertificate(context.Background(), kubeclient, dynamicclient, "autoscaler")
+	if err != nil {
+		t.Fatal("ServingCertificate() =", err)
+	}
+	if string(again.Certificate[0]) != string(cert.Certificate[0]) {
+		t.Error("ServingCertificate() created another certificate")
+	}
+}
@@ -0,0 +1,25 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package custommetrics provides an HTTP handler serving the concurrency observed
+by the autoscaler for each revision in the shape of the Kubernetes custom
+metrics API, so that e.g. the HPA or KEDA can scale on it. The API is served
+over TLS behind the APIService of config/custom-metrics, which is opt-in, and
+the requests are authenticated and authorized by the API server, like those
+of the aggregated API servers.
+*/
+package custommetrics
@@ -0,0 +1,218 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custommetrics
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"knative.dev/serving/pkg/apis/serving"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
+	listers "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
+)
+
+const (
+	// GroupVersion is the group and version of the custom metrics API served.
+	GroupVersion = "custom.metrics.k8s.io/v1beta1"
+
+	// ConcurrencyMetricName is the name of the metric carrying the stable
+	// concurrency observed for a revision.
+	ConcurrencyMetricName = "concurrency"
+
+	// revisionsResource is the resource the metrics are described by.
+	revisionsResource = "revisions." + serving.GroupName
+
+	// allNames is the name of the described objects when listing the
+	// metrics of all of them.
+	allNames = "*"
+)
+
+// MetricValueList mirrors the MetricValueList type of the custom metrics API.
+type MetricValueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []MetricValue `json:"items"`
+}
+
+// MetricValue mirrors the MetricValue type of the custom metrics API.
+type MetricValue struct {
+	metav1.TypeMeta `json:",inline"`
+
+	DescribedObject corev1.ObjectReference `json:"describedObject"`
+	MetricName      string                 `json:"metricName"`
+	Timestamp       metav1.Time            `json:"timestamp"`
+	Value           resource.Quantity      `json:"value"`
+}
+
+// Handler serves the concurrency of the revisions observed by the autoscaler
+// under /apis/custom.metrics.k8s.io/v1beta1/namespaces/<ns>/revisions.serving.knative.dev/<name>/concurrency.
+// The name may be "*" to list the concurrency of all the revisions of the
+// namespace, optionally filtered with a labelSelector on their labels.
+type Handler struct {
+	metricClient asmetrics.MetricClient
+	metricLister listers.MetricLister
+	clock        clock.PassiveClock
+	logger       *zap.SugaredLogger
+}
+
+// NewHandler creates a Handler serving the metrics collected by metricClient,
+// for the revisions whose Metric is listed by metricLister.
+func NewHandler(metricClient asmetrics.MetricClient, metricLister listers.MetricLister, logger *zap.SugaredLogger) *Handler {
+	return &Handler{
+		metricClient: metricClient,
+		metricLister: metricLister,
+		clock:        clock.RealClock{},
+		logger:       logger,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	if path == "apis/"+GroupVersion {
+		h.writeJSON(w, discovery())
+		return
+	}
+
+	ns, res, name, metric, ok := parseMetricPath(path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if res != revisionsResource || metric != ConcurrencyMetricName {
+		http.Error(w, "unknown metric "+metric+" for resource "+res, http.StatusNotFound)
+		return
+	}
+
+	now := h.clock.Now()
+	if name == allNames {
+		h.serveList(w, r, ns, now)
+		return
+	}
+	concurrency, _, err := h.metricClient.StableAndPanicConcurrency(types.NamespacedName{Namespace: ns, Name: name}, now)
+	if errors.Is(err, asmetrics.ErrNotCollecting) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	h.writeJSON(w, metricValueList(metricValue(ns, name, concurrency, now)))
+}
+
+// serveList serves the concurrency of the revisions of the namespace matching
+// the labelSelector of the request, if any.
+func (h *Handler) serveList(w http.ResponseWriter, r *http.Request, ns string, now time.Time) {
+	selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// The Metrics carry the labels of their revisions.
+	metrics, err := h.metricLister.Metrics(ns).List(selector)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Name < metrics[j].Name
+	})
+	items := make([]MetricValue, 0, len(metrics))
+	for _, m := range metrics {
+		concurrency, _, err := h.metricClient.StableAndPanicConcurrency(types.NamespacedName{Namespace: ns, Name: m.Name}, now)
+		if err != nil {
+			// The revisions without data yet are left out of the list.
+			continue
+		}
+		items = append(items, metricValue(ns, m.Name, concurrency, now))
+	}
+	h.writeJSON(w, metricValueList(items...))
+}
+
+func metricValueList(items ...MetricValue) *MetricValueList {
+	return &MetricValueList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MetricValueList",
+			APIVersion: GroupVersion,
+		},
+		Items: items,
+	}
+}
+
+func metricValue(ns, name string, concurrency float64, now time.Time) MetricValue {
+	return MetricValue{
+		DescribedObject: corev1.ObjectReference{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "Revision",
+			Namespace:  ns,
+			Name:       name,
+		},
+		MetricName: ConcurrencyMetricName,
+		Timestamp:  metav1.NewTime(now),
+		Value:      *resource.NewMilliQuantity(int64(concurrency*1000), resource.DecimalSI),
+	}
+}
+
+// parseMetricPath splits the path of a request for a metric of an object,
+// apis/<group>/<version>/namespaces/<ns>/<resource>/<name>/<metric>, into its
+// parts.
+func parseMetricPath(path string) (ns, res, name, metric string, ok bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 8 || strings.Join(parts[:3], "/") != "apis/"+GroupVersion || parts[3] != "namespaces" {
+		return "", "", "", "", false
+	}
+	return parts[4], parts[5], parts[6], parts[7], true
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Errorw("Failed to write custom metrics response", zap.Error(err))
+	}
+}
+
+// discovery lists the metrics served, as the API server expects from an
+// aggregated API.
+func discovery() *metav1.APIResourceList {
+	return &metav1.APIResourceList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "APIResourceList",
+			APIVersion: "v1",
+		},
+		GroupVersion: GroupVersion,
+		APIResources: []metav1.APIResource{{
+			Name:       revisionsResource + "/" + ConcurrencyMetricName,
+			Namespaced: true,
+			Kind:       "MetricValueList",
+			Verbs:      []string{"get"},
+		}},
+	}
+}
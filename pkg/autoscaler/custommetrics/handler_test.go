@@ -0,0 +1,258 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custommetrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	logtesting "knative.dev/pkg/logging/testing"
+	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
+	listers "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
+)
+
+type fakeMetricClient struct {
+	concurrency map[types.NamespacedName]float64
+	err         error
+}
+
+func (f *fakeMetricClient) StableAndPanicConcurrency(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	if f.err != nil {
+		return 0, 0, f.err
+	}
+	c, ok := f.concurrency[key]
+	if !ok {
+		return 0, 0, asmetrics.ErrNotCollecting
+	}
+	return c, 2 * c, nil
+}
+
+func (f *fakeMetricClient) StableAndPanicRPS(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	return 0, 0, asmetrics.ErrNotCollecting
+}
+
+func (f *fakeMetricClient) MissingSince(types.NamespacedName) time.Time {
+	return time.Time{}
+}
+
+// metricLister lists a Metric for each of the given revisions of namespace
+// "ns", labeled with the given labels.
+func metricLister(revs map[string]map[string]string) listers.MetricLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for name, labels := range revs {
+		indexer.Add(&autoscalingv1alpha1.Metric{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      name,
+				Labels:    labels,
+			},
+		})
+	}
+	return listers.NewMetricLister(indexer)
+}
+
+func TestHandler(t *testing.T) {
+	const revPath = "/apis/custom.metrics.k8s.io/v1beta1/namespaces/ns/revisions.serving.knative.dev/rev/"
+	rev := types.NamespacedName{Namespace: "ns", Name: "rev"}
+
+	tests := []struct {
+		name      string
+		path      string
+		client    *fakeMetricClient
+		wantCode  int
+		wantValue string
+	}{{
+		name:      "concurrency",
+		path:      revPath + "concurrency",
+		client:    &fakeMetricClient{concurrency: map[types.NamespacedName]float64{rev: 12.5}},
+		wantCode:  http.StatusOK,
+		wantValue: "12500m",
+	}, {
+		name:      "whole concurrency",
+		path:      revPath + "concurrency",
+		client:    &fakeMetricClient{concurrency: map[types.NamespacedName]float64{rev: 3}},
+		wantCode:  http.StatusOK,
+		wantValue: "3",
+	}, {
+		name:     "revision not collected",
+		path:     revPath + "concurrency",
+		client:   &fakeMetricClient{},
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "no data yet",
+		path:     revPath + "concurrency",
+		client:   &fakeMetricClient{err: asmetrics.ErrNoData},
+		wantCode: http.StatusServiceUnavailable,
+	}, {
+		name:     "unknown metric",
+		path:     revPath + "rps",
+		client:   &fakeMetricClient{concurrency: map[types.NamespacedName]float64{rev: 3}},
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "unknown resource",
+		path:     "/apis/custom.metrics.k8s.io/v1beta1/namespaces/ns/pods/rev/concurrency",
+		client:   &fakeMetricClient{concurrency: map[types.NamespacedName]float64{rev: 3}},
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "unknown path",
+		path:     "/healthz",
+		client:   &fakeMetricClient{},
+		wantCode: http.StatusNotFound,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := time.Now()
+			h := NewHandler(test.client, metricLister(nil), logtesting.TestLogger(t))
+			h.clock = clock.NewFakePassiveClock(now)
+
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, test.path, nil))
+			if resp.Code != test.wantCode {
+				t.Fatalf("StatusCode = %d, want: %d", resp.Code, test.wantCode)
+			}
+			if test.wantCode != http.StatusOK {
+				return
+			}
+
+			var got MetricValueList
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal("Failed to decode response:", err)
+			}
+			if len(got.Items) != 1 {
+				t.Fatalf("len(Items) = %d, want: 1", len(got.Items))
+			}
+			item := got.Items[0]
+			if got, want := item.Value.String(), test.wantValue; got != want {
+				t.Errorf("Value = %s, want: %s", got, want)
+			}
+			if got, want := item.MetricName, ConcurrencyMetricName; got != want {
+				t.Errorf("MetricName = %s, want: %s", got, want)
+			}
+			if got, want := (types.NamespacedName{Namespace: item.DescribedObject.Namespace, Name: item.DescribedObject.Name}), rev; got != want {
+				t.Errorf("DescribedObject = %v, want: %v", got, want)
+			}
+			if got, want := item.Timestamp.Unix(), now.Unix(); got != want {
+				t.Errorf("Timestamp = %d, want: %d", got, want)
+			}
+		})
+	}
+}
+
+func TestHandlerDiscovery(t *testing.T) {
+	h := NewHandler(&fakeMetricClient{}, metricLister(nil), logtesting.TestLogger(t))
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/apis/custom.metrics.k8s.io/v1beta1", nil))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("StatusCode = %d, want: %d", resp.Code, http.StatusOK)
+	}
+
+	var got struct {
+		Resources []struct {
+			Name string `json:"name"`
+		} `json:"resources"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal("Failed to decode response:", err)
+	}
+	want := []string{"revisions.serving.knative.dev/concurrency"}
+	var names []string
+	for _, r := range got.Resources {
+		names = append(names, r.Name)
+	}
+	if !cmp.Equal(names, want) {
+		t.Error("Unexpected resources (-want, +got):", cmp.Diff(want, names))
+	}
+}
+
+func TestHandlerList(t *testing.T) {
+	const listPath = "/apis/custom.metrics.k8s.io/v1beta1/namespaces/ns/revisions.serving.knative.dev/*/concurrency"
+	client := &fakeMetricClient{concurrency: map[types.NamespacedName]float64{
+		{Namespace: "ns", Name: "blue"}:   1,
+		{Namespace: "ns", Name: "green"}:  2,
+		{Namespace: "other", Name: "red"}: 3,
+	}}
+	lister := metricLister(map[string]map[string]string{
+		"blue":  {"serving.knative.dev/configuration": "sky"},
+		"green": {"serving.knative.dev/configuration": "grass"},
+		// Not collected yet.
+		"yellow": {"serving.knative.dev/configuration": "sky"},
+	})
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		want     map[string]string
+	}{{
+		name:     "all revisions",
+		wantCode: http.StatusOK,
+		want:     map[string]string{"blue": "1", "green": "2"},
+	}, {
+		name:     "label selector",
+		query:    "?labelSelector=serving.knative.dev%2Fconfiguration%3Dsky",
+		wantCode: http.StatusOK,
+		want:     map[string]string{"blue": "1"},
+	}, {
+		name:     "nothing selected",
+		query:    "?labelSelector=serving.knative.dev%2Fconfiguration%3Dsea",
+		wantCode: http.StatusOK,
+		want:     map[string]string{},
+	}, {
+		name:     "bad label selector",
+		query:    "?labelSelector=%3D%3D%3D",
+		wantCode: http.StatusBadRequest,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := NewHandler(client, lister, logtesting.TestLogger(t))
+
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, listPath+test.query, nil))
+			if resp.Code != test.wantCode {
+				t.Fatalf("StatusCode = %d, want: %d", resp.Code, test.wantCode)
+			}
+			if test.wantCode != http.StatusOK {
+				return
+			}
+
+			var list MetricValueList
+			if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+				t.Fatal("Failed to decode response:", err)
+			}
+			got := make(map[string]string, len(list.Items))
+			for _, item := range list.Items {
+				got[item.DescribedObject.Name] = item.Value.String()
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Error("Unexpected values (-want, +got):", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custommetrics
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// NewServer creates a server serving handler over TLS with the given
+// certificate, and accepting the client certificates of the front proxy of
+// the API server described by requestHeader. It is to be started with
+// ListenAndServeTLS("", "").
+func NewServer(addr string, cert tls.Certificate, handler http.Handler, requestHeader *RequestHeaderConfig) *http.Server {
+	return &http.Server{
+		Addr:    addr,
+		Handler: handler,
+		TLSConfig: &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{cert},
+			// Bearer tokens are accepted as well, so the client certificate
+			// is optional.
+			ClientAuth: tls.VerifyClientCertIfGiven,
+			ClientCAs:  requestHeader.ClientCAs,
+		},
+	}
+}
@@ -79,6 +79,10 @@ type MetricClient interface {
 	// StableAndPanicRPS returns both the stable and the panic RPS
 	// for the given replica as of the given time.
 	StableAndPanicRPS(key types.NamespacedName, now time.Time) (float64, float64, error)
+
+	// MissingSince returns the time since which the scrapes of the metrics
+	// of the given replica have been failing, or the zero time if they aren't.
+	MissingSince(key types.NamespacedName) time.Time
 }
 
 // MetricCollector manages collection of metrics for many entities.
@@ -93,6 +97,10 @@ type MetricCollector struct {
 
 	watcherMutex sync.RWMutex
 	watcher      func(types.NamespacedName)
+
+	// scrapeLimiter bounds the number of scrapes running at the same time
+	// across all the collections.
+	scrapeLimiter scrapeLimiter
 }
 
 var _ Collector = (*MetricCollector)(nil)
@@ -127,10 +135,16 @@ func (c *MetricCollector) CreateOrUpdate(metric *av1alpha1.Metric) error {
 		return collection.lastError()
 	}
 
-	c.collections[key] = newCollection(metric, scraper, c.clock, c.Inform, c.logger)
+	c.collections[key] = newCollection(metric, scraper, c.clock, c.Inform, &c.scrapeLimiter, c.logger)
 	return nil
 }
 
+// SetScrapeConcurrency sets the maximum number of scrapes running at the same
+// time across all the collections. Zero or less means no limit.
+func (c *MetricCollector) SetScrapeConcurrency(limit int32) {
+	c.scrapeLimiter.setLimit(limit)
+}
+
 // Delete deletes a Metric and halts collection.
 func (c *MetricCollector) Delete(namespace, name string) error {
 	c.collectionsMutex.Lock()
@@ -213,6 +227,19 @@ func (c *MetricCollector) StableAndPanicRPS(key types.NamespacedName, now time.T
 		nil
 }
 
+// MissingSince returns the time since which the scrapes of the metrics have
+// been failing, or the zero time if the last scrape succeeded.
+func (c *MetricCollector) MissingSince(key types.NamespacedName) time.Time {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return time.Time{}
+	}
+	return collection.missingSince()
+}
+
 // collection represents the collection of metrics for one specific entity.
 type collection struct {
 	// mux guards access to all of the collection's state.
@@ -229,8 +256,10 @@ type collection struct {
 	// Fields relevant for metric scraping specifically.
 	scraper StatsScraper
 	lastErr error
-	grp     sync.WaitGroup
-	stopCh  chan struct{}
+	// gapStart is when the scrapes started failing, zero if they're not.
+	gapStart time.Time
+	grp      sync.WaitGroup
+	stopCh   chan struct{}
 }
 
 func (c *collection) updateScraper(ss StatsScraper) {
@@ -245,10 +274,50 @@ func (c *collection) getScraper() StatsScraper {
 	return c.scraper
 }
 
+// scrapeLimiter is a semaphore bounding the number of concurrent scrapes. The
+// zero value imposes no limit.
+type scrapeLimiter struct {
+	mux   sync.RWMutex
+	slots chan struct{}
+}
+
+// setLimit replaces the semaphore with one of the given size. The scrapes
+// in flight release their slot to the semaphore they acquired it from.
+func (l *scrapeLimiter) setLimit(limit int32) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if limit <= 0 {
+		l.slots = nil
+		return
+	}
+	if l.slots == nil || cap(l.slots) != int(limit) {
+		l.slots = make(chan struct{}, limit)
+	}
+}
+
+// acquire blocks until a slot is free and returns the function releasing it.
+// It returns false if stopCh is closed first.
+func (l *scrapeLimiter) acquire(stopCh <-chan struct{}) (func(), bool) {
+	l.mux.RLock()
+	slots := l.slots
+	l.mux.RUnlock()
+
+	if slots == nil {
+		return func() {}, true
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, true
+	case <-stopCh:
+		return nil, false
+	}
+}
+
 // newCollection creates a new collection, which uses the given scraper to
-// collect stats every scrapeTickInterval.
+// collect stats every scrapeTickInterval. The scrapes wait for a slot of
+// the limiter, so that a slow or failing scraper only holds its own.
 func newCollection(metric *av1alpha1.Metric, scraper StatsScraper, clock clock.Clock,
-	callback func(types.NamespacedName), logger *zap.SugaredLogger) *collection {
+	callback func(types.NamespacedName), limiter *scrapeLimiter, logger *zap.SugaredLogger) *collection {
 	c := &collection{
 		metric: metric,
 		concurrencyBuckets: aggregation.NewTimedFloat64Buckets(
@@ -287,13 +356,19 @@ func newCollection(metric *av1alpha1.Metric, scraper StatsScraper, clock clock.C
 					continue
 				}
 
+				release, ok := limiter.acquire(c.stopCh)
+				if !ok {
+					return
+				}
 				stat, err := scraper.Scrape(c.currentMetric().Spec.StableWindow)
+				release()
 				if err != nil {
 					logger.Errorw("Failed to scrape metrics", zap.Error(err))
 				}
 				if c.updateLastError(err) {
 					callback(key)
 				}
+				c.updateGap(clock.Now(), err)
 				if stat != emptyStat {
 					c.record(clock.Now(), stat)
 				}
@@ -350,6 +425,26 @@ func (c *collection) lastError() error {
 	return c.lastErr
 }
 
+// updateGap starts a gap in the metrics at now if the scrape failed with err
+// and none is ongoing, and ends it if the scrape succeeded.
+func (c *collection) updateGap(now time.Time, err error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if err == nil {
+		c.gapStart = time.Time{}
+	} else if c.gapStart.IsZero() {
+		c.gapStart = now
+	}
+}
+
+func (c *collection) missingSince() time.Time {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	return c.gapStart
+}
+
 // record adds a stat to the current collection.
 func (c *collection) record(now time.Time, stat Stat) {
 	// Proxied requests have been counted at the activator. Subtract
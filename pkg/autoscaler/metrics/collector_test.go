@@ -19,6 +19,7 @@ package metrics
 import (
 	"errors"
 	"math"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -571,6 +572,285 @@ func TestMetricCollectorError(t *testing.T) {
 	}
 }
 
+func TestMetricCollectorMissingSince(t *testing.T) {
+	logger := TestLogger(t)
+
+	mtp := &fake.ManualTickProvider{
+		Channel: make(chan time.Time),
+	}
+	now := time.Now()
+	fc := fake.Clock{
+		FakeClock: clock.NewFakeClock(now),
+		TP:        mtp,
+	}
+	results := make(chan error)
+	scraper := &testScraper{
+		s: func() (Stat, error) {
+			if err := <-results; err != nil {
+				return emptyStat, err
+			}
+			return Stat{PodName: "testPod", AverageConcurrentRequests: 1}, nil
+		},
+	}
+	coll := NewMetricCollector(scraperFactory(scraper, nil), logger)
+	coll.clock = fc
+	coll.CreateOrUpdate(&defaultMetric)
+	defer coll.Delete(defaultNamespace, defaultName)
+
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	if got := coll.MissingSince(metricKey); !got.IsZero() {
+		t.Errorf("MissingSince() = %v before scraping, want: zero", got)
+	}
+	if got := coll.MissingSince(types.NamespacedName{Namespace: "other", Name: "rev"}); !got.IsZero() {
+		t.Errorf("MissingSince() = %v for an unknown revision, want: zero", got)
+	}
+
+	scrapeErr := errors.New("pods unreachable")
+	for _, step := range []struct {
+		name string
+		err  error
+		want time.Time
+	}{{
+		name: "gap starts",
+		err:  scrapeErr,
+		want: now,
+	}, {
+		name: "gap continues",
+		err:  scrapeErr,
+		want: now,
+	}, {
+		name: "gap ends",
+	}, {
+		name: "new gap",
+		err:  scrapeErr,
+		want: now.Add(3 * time.Second),
+	}} {
+		mtp.Channel <- fc.Now()
+		results <- step.err
+		var got time.Time
+		if err := wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+			got = coll.MissingSince(metricKey)
+			return got.Equal(step.want), nil
+		}); err != nil {
+			t.Errorf("%s: MissingSince() = %v, want: %v", step.name, got, step.want)
+		}
+		fc.Step(time.Second)
+	}
+}
+
+func TestMetricCollectorScrapeConcurrency(t *testing.T) {
+	logger := TestLogger(t)
+
+	mtp := &fake.ManualTickProvider{
+		Channel: make(chan time.Time),
+	}
+	now := time.Now()
+	fc := fake.Clock{
+		FakeClock: clock.NewFakeClock(now),
+		TP:        mtp,
+	}
+
+	var inFlight, maxInFlight, scrapes int32
+	unblock := make(chan struct{})
+	scraper := &testScraper{
+		s: func() (Stat, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			<-unblock
+			atomic.AddInt32(&scrapes, 1)
+			return emptyStat, nil
+		},
+	}
+
+	coll := NewMetricCollector(scraperFactory(scraper, nil), logger)
+	coll.clock = fc
+	coll.SetScrapeConcurrency(2)
+
+	names := []string{"first", "second", "third"}
+	for _, name := range names {
+		m := defaultMetric
+		m.Name = name
+		coll.CreateOrUpdate(&m)
+	}
+
+	// Each tick is consumed by a collection that's not scraping yet.
+	for range names {
+		mtp.Channel <- now
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&inFlight) == 2, nil
+	}); err != nil {
+		t.Fatal("Scrapes in flight never reached the limit:", err)
+	}
+	// The third scrape must keep waiting for a slot.
+	time.Sleep(100 * time.Millisecond)
+	if got := atomic.LoadInt32(&inFlight); got != 2 {
+		t.Errorf("Scrapes in flight = %d, want: 2", got)
+	}
+
+	close(unblock)
+	if err := wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&scrapes) == int32(len(names)), nil
+	}); err != nil {
+		t.Fatal("Scrapes never completed:", err)
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got != 2 {
+		t.Errorf("Max scrapes in flight = %d, want: 2", got)
+	}
+
+	for _, name := range names {
+		coll.Delete(defaultNamespace, name)
+	}
+}
+
+func TestMetricCollectorScrapeConcurrencyDeleteWhileWaiting(t *testing.T) {
+	logger := TestLogger(t)
+
+	mtp := &fake.ManualTickProvider{
+		Channel: make(chan time.Time),
+	}
+	now := time.Now()
+	fc := fake.Clock{
+		FakeClock: clock.NewFakeClock(now),
+		TP:        mtp,
+	}
+
+	started := make(chan string, 2)
+	unblock := make(chan struct{})
+	factory := func(m *av1alpha1.Metric, _ *zap.SugaredLogger) (StatsScraper, error) {
+		name := m.Name
+		return &testScraper{
+			s: func() (Stat, error) {
+				started <- name
+				<-unblock
+				return emptyStat, nil
+			},
+		}, nil
+	}
+
+	coll := NewMetricCollector(factory, logger)
+	coll.clock = fc
+	coll.SetScrapeConcurrency(1)
+
+	first, second := defaultMetric, defaultMetric
+	first.Name, second.Name = "first", "second"
+	coll.CreateOrUpdate(&first)
+	coll.CreateOrUpdate(&second)
+
+	// One collection takes the slot, the other waits for it.
+	mtp.Channel <- now
+	mtp.Channel <- now
+	var waiting string
+	select {
+	case scraping := <-started:
+		waiting = first.Name
+		if scraping == first.Name {
+			waiting = second.Name
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No scrape started")
+	}
+
+	deleted := make(chan struct{})
+	go func() {
+		defer close(deleted)
+		coll.Delete(defaultNamespace, waiting)
+	}()
+	select {
+	case <-deleted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Delete blocked on the collection waiting for a slot")
+	}
+
+	close(unblock)
+	coll.Delete(defaultNamespace, first.Name)
+	coll.Delete(defaultNamespace, second.Name)
+}
+
+func TestMetricCollectorScrapeFailureIsolation(t *testing.T) {
+	logger := TestLogger(t)
+
+	mtp := &fake.ManualTickProvider{
+		Channel: make(chan time.Time),
+	}
+	now := time.Now()
+	fc := fake.Clock{
+		FakeClock: clock.NewFakeClock(now),
+		TP:        mtp,
+	}
+
+	scrapeErr := errors.New("pods unreachable")
+	healthy := &testScraper{
+		s: func() (Stat, error) {
+			return Stat{
+				PodName:                   "testPod",
+				AverageConcurrentRequests: 10,
+				RequestCount:              20,
+			}, nil
+		},
+	}
+	failing := &testScraper{
+		s: func() (Stat, error) {
+			return emptyStat, scrapeErr
+		},
+	}
+	factory := func(m *av1alpha1.Metric, _ *zap.SugaredLogger) (StatsScraper, error) {
+		if m.Name == "failing" {
+			return failing, nil
+		}
+		return healthy, nil
+	}
+
+	coll := NewMetricCollector(factory, logger)
+	coll.clock = fc
+	coll.SetScrapeConcurrency(1)
+
+	bad, good := defaultMetric, defaultMetric
+	bad.Name, good.Name = "failing", "healthy"
+	coll.CreateOrUpdate(&bad)
+	coll.CreateOrUpdate(&good)
+	defer coll.Delete(defaultNamespace, bad.Name)
+	defer coll.Delete(defaultNamespace, good.Name)
+
+	goodKey := types.NamespacedName{Namespace: defaultNamespace, Name: good.Name}
+	badKey := types.NamespacedName{Namespace: defaultNamespace, Name: bad.Name}
+
+	// Keep ticking until both collections scraped, the failures of one
+	// must neither hold the slot nor stop the other.
+	stopTicking := make(chan struct{})
+	defer close(stopTicking)
+	go func() {
+		for {
+			select {
+			case mtp.Channel <- now:
+			case <-stopTicking:
+				return
+			}
+		}
+	}()
+
+	if err := wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		stable, _, err := coll.StableAndPanicConcurrency(goodKey, now)
+		return err == nil && stable > 0, nil
+	}); err != nil {
+		t.Fatal("The healthy collection never got data:", err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return coll.CreateOrUpdate(&bad) == scrapeErr, nil
+	}); err != nil {
+		t.Fatal("The failing collection never reported its error:", err)
+	}
+	if _, _, err := coll.StableAndPanicConcurrency(badKey, now); err != ErrNoData {
+		t.Errorf("StableAndPanicConcurrency() = %v, want: %v", err, ErrNoData)
+	}
+}
+
 func scraperFactory(scraper StatsScraper, err error) StatsScraperFactory {
 	return func(*av1alpha1.Metric, *zap.SugaredLogger) (StatsScraper, error) {
 		return scraper, err
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"math"
+
+	"knative.dev/serving/pkg/apis/autoscaling"
+)
+
+const (
+	// The gains of the PID algorithm, applied to the difference between the
+	// pods the observed value needs and the ready pods.
+	pidProportionalGain = 0.5
+	pidIntegralGain     = 0.1
+	pidDerivativeGain   = 0.05
+	// pidMaxIntegral bounds the accumulated error, in pods, so that a long
+	// period where the ready pods can't follow the decisions (e.g. at the
+	// max scale) doesn't make the algorithm overshoot once they can.
+	pidMaxIntegral = 5
+)
+
+// Algorithm computes the number of pods needed to serve the observed stable
+// value of the scaling metric. Implementations may keep state between the
+// decisions, so every autoscaler owns its own instance.
+type Algorithm interface {
+	// DesiredPodCount returns the unrounded number of pods needed for the
+	// observed value, given the number of ready pods.
+	DesiredPodCount(observed, readyPods float64, spec *DeciderSpec) float64
+}
+
+// newAlgorithm returns the algorithm with the given name, defaulting to the
+// ratio one.
+func newAlgorithm(name string) Algorithm {
+	switch name {
+	case autoscaling.AlgorithmPID:
+		return &pidAlgorithm{}
+	default:
+		return ratioAlgorithm{}
+	}
+}
+
+// ratioAlgorithm needs as many pods as the observed value divided by the
+// target value.
+type ratioAlgorithm struct{}
+
+// DesiredPodCount implements Algorithm.
+func (ratioAlgorithm) DesiredPodCount(observed, _ float64, spec *DeciderSpec) float64 {
+	return observed / spec.TargetValue
+}
+
+// pidAlgorithm is a proportional-integral-derivative controller moving the
+// ready pods towards the ratio of the observed and target values, which
+// reacts less to short spikes and dips of the metric than the ratio itself.
+type pidAlgorithm struct {
+	integral  float64
+	lastError float64
+	started   bool
+}
+
+// DesiredPodCount implements Algorithm.
+func (p *pidAlgorithm) DesiredPodCount(observed, readyPods float64, spec *DeciderSpec) float64 {
+	e := observed/spec.TargetValue - readyPods
+	p.integral = math.Max(-pidMaxIntegral, math.Min(pidMaxIntegral, p.integral+e))
+	var derivative float64
+	if p.started {
+		derivative = e - p.lastError
+	}
+	p.lastError, p.started = e, true
+
+	return math.Max(0, readyPods+
+		pidProportionalGain*e+pidIntegralGain*p.integral+pidDerivativeGain*derivative)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"math"
+	"testing"
+
+	"knative.dev/serving/pkg/apis/autoscaling"
+)
+
+// simulate feeds the series of observed values to the algorithm, presuming
+// that the ready pods always follow the previous decision.
+func simulate(algorithm Algorithm, readyPods int32, series []float64) []int32 {
+	spec := &DeciderSpec{TargetValue: 10}
+	ret := make([]int32, 0, len(series))
+	for _, observed := range series {
+		readyPods = int32(math.Ceil(algorithm.DesiredPodCount(observed,
+			math.Max(1, float64(readyPods)), spec)))
+		ret = append(ret, readyPods)
+	}
+	return ret
+}
+
+func repeat(value float64, n int) []float64 {
+	ret := make([]float64, n)
+	for i := range ret {
+		ret[i] = value
+	}
+	return ret
+}
+
+func spread(pods []int32) int32 {
+	min, max := pods[0], pods[0]
+	for _, p := range pods {
+		if p < min {
+			min = p
+		}
+		if p > max {
+			max = p
+		}
+	}
+	return max - min
+}
+
+func TestNewAlgorithm(t *testing.T) {
+	if _, ok := newAlgorithm("").(ratioAlgorithm); !ok {
+		t.Error("The default algorithm is not the ratio")
+	}
+	if _, ok := newAlgorithm(autoscaling.AlgorithmRatio).(ratioAlgorithm); !ok {
+		t.Error("The ratio algorithm is not the ratio")
+	}
+	if _, ok := newAlgorithm(autoscaling.AlgorithmPID).(*pidAlgorithm); !ok {
+		t.Error("The pid algorithm is not the PID")
+	}
+}
+
+func TestAlgorithmsStep(t *testing.T) {
+	series := append(repeat(10, 3), repeat(100, 25)...)
+	ratio := simulate(newAlgorithm(autoscaling.AlgorithmRatio), 1, series)
+	pid := simulate(newAlgorithm(autoscaling.AlgorithmPID), 1, series)
+
+	// The ratio follows the step at once, the PID gets there over a few
+	// decisions.
+	if got, want := ratio[3], int32(10); got != want {
+		t.Errorf("Ratio after the step = %d, want: %d", got, want)
+	}
+	if got := pid[3]; got <= 1 || got >= 10 {
+		t.Errorf("PID after the step = %d, want between 1 and 10", got)
+	}
+	// Both settle on the same scale.
+	if got, want := pid[len(pid)-1], ratio[len(ratio)-1]; got != want {
+		t.Errorf("PID settled on %d, want: %d (ratio: %v, pid: %v)", got, want, ratio, pid)
+	}
+}
+
+func TestAlgorithmsSpike(t *testing.T) {
+	series := append(append(repeat(50, 5), 200), repeat(50, 10)...)
+	ratio := simulate(newAlgorithm(autoscaling.AlgorithmRatio), 5, series)
+	pid := simulate(newAlgorithm(autoscaling.AlgorithmPID), 5, series)
+
+	if got, want := pid[5], ratio[5]; got >= want {
+		t.Errorf("PID on the spike = %d, want less than the ratio of %d", got, want)
+	}
+	if got, want := pid[len(pid)-1], ratio[len(ratio)-1]; got != want {
+		t.Errorf("PID settled on %d, want: %d (ratio: %v, pid: %v)", got, want, ratio, pid)
+	}
+}
+
+func TestAlgorithmsNoise(t *testing.T) {
+	series := []float64{100, 130, 80, 110, 90, 120, 70, 100}
+	ratio := simulate(newAlgorithm(autoscaling.AlgorithmRatio), 10, series)
+	pid := simulate(newAlgorithm(autoscaling.AlgorithmPID), 10, series)
+
+	if got, want := spread(pid), spread(ratio); got >= want {
+		t.Errorf("PID spread = %d, want less than the ratio spread of %d (ratio: %v, pid: %v)",
+			got, want, ratio, pid)
+	}
+}
+
+func TestAlgorithmsScaleToZero(t *testing.T) {
+	series := append(repeat(100, 3), repeat(0, 15)...)
+	for _, name := range []string{autoscaling.AlgorithmRatio, autoscaling.AlgorithmPID} {
+		t.Run(name, func(t *testing.T) {
+			got := simulate(newAlgorithm(name), 1, series)
+			if last := got[len(got)-1]; last != 0 {
+				t.Errorf("Scale without load = %d, want: 0 (%v)", last, got)
+			}
+		})
+	}
+}
@@ -28,11 +28,13 @@ import (
 	"knative.dev/pkg/logging"
 	pkgmetrics "knative.dev/pkg/metrics"
 	"knative.dev/serving/pkg/apis/autoscaling"
+	autoscalerconfig "knative.dev/serving/pkg/autoscaler/config"
 	"knative.dev/serving/pkg/autoscaler/metrics"
 	"knative.dev/serving/pkg/resources"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // MinActivators is the minimum number of activators a revision will get.
@@ -40,6 +42,7 @@ const MinActivators = 2
 
 type podCounter interface {
 	ReadyCount() (int, error)
+	NotReadyCount() (int, error)
 }
 
 // autoscaler stores current state of an instance of an autoscaler.
@@ -54,6 +57,15 @@ type autoscaler struct {
 	panicTime    time.Time
 	maxPanicPods int32
 
+	// lastDesiredPodCount is the last scale decided on, held during metric
+	// gaps with the hold-last policy.
+	lastDesiredPodCount int32
+
+	// algorithm computes the desired stable pod count, it's replaced
+	// whenever the spec selects a different one.
+	algorithmName string
+	algorithm     Algorithm
+
 	// specMux guards the current DeciderSpec.
 	specMux     sync.RWMutex
 	deciderSpec *DeciderSpec
@@ -117,6 +129,11 @@ func newAutoscaler(
 
 		panicTime:    pt,
 		maxPanicPods: int32(curC),
+
+		lastDesiredPodCount: int32(curC),
+
+		algorithmName: deciderSpec.Algorithm,
+		algorithm:     newAlgorithm(deciderSpec.Algorithm),
 	}
 }
 
@@ -187,7 +204,11 @@ func (a *autoscaler) Scale(ctx context.Context, now time.Time) ScaleResult {
 		maxScaleDown = math.Floor(readyPodsCount / spec.MaxScaleDownRate)
 	}
 
-	dspc := math.Ceil(observedStableValue / spec.TargetValue)
+	if spec.Algorithm != a.algorithmName {
+		logger.Infof("Switching the scaling algorithm from %q to %q.", a.algorithmName, spec.Algorithm)
+		a.algorithmName, a.algorithm = spec.Algorithm, newAlgorithm(spec.Algorithm)
+	}
+	dspc := math.Ceil(a.algorithm.DesiredPodCount(observedStableValue, readyPodsCount, spec))
 	dppc := math.Ceil(observedPanicValue / spec.TargetValue)
 	logger.Debugf("DesiredStablePodCount = %0.3f, DesiredPanicPodCount = %0.3f, ReadyEndpointCount = %d, MaxScaleUp = %0.3f, MaxScaleDown = %0.3f",
 		dspc, dppc, originalReadyPodsCount, maxScaleUp, maxScaleDown)
@@ -238,7 +259,45 @@ func (a *autoscaler) Scale(ctx context.Context, now time.Time) ScaleResult {
 		desiredPodCount = a.maxPanicPods
 	} else {
 		logger.Debug("Operating in stable mode.")
+		// Spread large scale ups over several decisions, not to overwhelm the
+		// scheduler. This is not applied in panic mode, which must catch up
+		// with the load as fast as possible. The step is added to all the
+		// pods, not to cut the ones that aren't ready but may still be
+		// serving requests or about to. As the step is at least a pod, only
+		// the scale ups past the ready pods may be capped.
+		if hasMaxScaleUpStep(spec.MaxScaleUpStep) && desiredPodCount > int32(originalReadyPodsCount) {
+			notReadyPodsCount, err := a.podCounter.NotReadyCount()
+			// If the error is NotFound, then presume 0.
+			if err != nil && !apierrors.IsNotFound(err) {
+				logger.Errorw("Failed to get not ready pod count via K8S Lister", zap.Error(err))
+				return invalidSR
+			}
+			pods := originalReadyPodsCount + notReadyPodsCount
+			if step, ok := maxScaleUpStep(spec.MaxScaleUpStep, pods); ok {
+				if maxPods := int32(pods) + step; desiredPodCount > maxPods {
+					logger.Infof("Capping the scale up from %d to %d by the max scale up step of %d.",
+						desiredPodCount, maxPods, step)
+					desiredPodCount = maxPods
+				}
+			}
+		}
+	}
+
+	// While the scrapes fail the windows empty out and the observed values
+	// drop, so within the grace period the policy decides the scale instead.
+	if gapStart := a.metricClient.MissingSince(metricKey); !gapStart.IsZero() && now.Sub(gapStart) < spec.MetricGapGracePeriod {
+		switch spec.MetricGapPolicy {
+		case autoscalerconfig.MetricGapPolicyHoldLast:
+			logger.Infof("Metrics missing since %v, holding the last scale of %d instead of %d.",
+				gapStart, a.lastDesiredPodCount, desiredPodCount)
+			desiredPodCount = a.lastDesiredPodCount
+		case autoscalerconfig.MetricGapPolicyKeepCurrent:
+			logger.Infof("Metrics missing since %v, keeping the current scale of %d instead of %d.",
+				gapStart, originalReadyPodsCount, desiredPodCount)
+			desiredPodCount = int32(originalReadyPodsCount)
+		}
 	}
+	a.lastDesiredPodCount = desiredPodCount
 
 	// Here we compute two numbers: excess burst capacity and number of activators
 	// for subsetting.
@@ -290,3 +349,27 @@ func (a *autoscaler) currentSpec() *DeciderSpec {
 	defer a.specMux.RUnlock()
 	return a.deciderSpec
 }
+
+// hasMaxScaleUpStep returns whether the step limits the scale ups, i.e. it is
+// a positive number or percentage.
+func hasMaxScaleUpStep(step intstr.IntOrString) bool {
+	limit, err := intstr.GetValueFromIntOrPercent(&step, 100, false)
+	return err == nil && limit > 0
+}
+
+// maxScaleUpStep returns the maximum number of pods that may be added to the
+// existing ones in a single decision, and whether there's such a limit.
+// Percentages are rounded up, and at least a single pod may always be added.
+func maxScaleUpStep(step intstr.IntOrString, pods int) (int32, bool) {
+	if !hasMaxScaleUpStep(step) {
+		return 0, false
+	}
+	n, err := intstr.GetValueFromIntOrPercent(&step, pods, true)
+	if err != nil {
+		return 0, false
+	}
+	if n < 1 {
+		n = 1
+	}
+	return int32(n), true
+}
@@ -27,10 +27,13 @@ import (
 	"go.opencensus.io/resource"
 
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"knative.dev/pkg/metrics/metricskey"
 	"knative.dev/pkg/metrics/metricstest"
 
+	"knative.dev/serving/pkg/apis/autoscaling"
+	autoscalerconfig "knative.dev/serving/pkg/autoscaler/config"
 	"knative.dev/serving/pkg/autoscaler/metrics"
 	smetrics "knative.dev/serving/pkg/metrics"
 	"knative.dev/serving/pkg/resources"
@@ -60,14 +63,23 @@ var wantResource = &resource.Resource{
 
 type fakePodCounter struct {
 	resources.EndpointsCounter
-	readyCount int
-	err        error
+	readyCount    int
+	notReadyCount int
+	err           error
+	notReadyErr   error
 }
 
 func (fpc fakePodCounter) ReadyCount() (int, error) {
 	return fpc.readyCount, fpc.err
 }
 
+func (fpc fakePodCounter) NotReadyCount() (int, error) {
+	if fpc.notReadyErr != nil {
+		return 0, fpc.notReadyErr
+	}
+	return fpc.notReadyCount, fpc.err
+}
+
 func TestNewErrorWhenGivenNilReadyPodCounter(t *testing.T) {
 	if _, err := New(testNamespace, testRevision, &metricClient{}, nil,
 		&DeciderSpec{TargetValue: 10}, context.Background()); err == nil {
@@ -255,6 +267,49 @@ func TestAutoscalerStableModeDecrease(t *testing.T) {
 	expectScale(t, a, time.Now(), ScaleResult{5, expectedEBC(10, 98, 50, 8), na, true})
 }
 
+func TestAutoscalerMetricGap(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy autoscalerconfig.MetricGapPolicy
+		want   int32
+	}{{
+		name:   "none",
+		policy: autoscalerconfig.MetricGapPolicyNone,
+		want:   2,
+	}, {
+		name:   "hold last",
+		policy: autoscalerconfig.MetricGapPolicyHoldLast,
+		want:   10,
+	}, {
+		name:   "keep current",
+		policy: autoscalerconfig.MetricGapPolicyKeepCurrent,
+		want:   8,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metrics := &metricClient{StableConcurrency: 100, PanicConcurrency: 100}
+			a, pc := newTestAutoscaler(t, 10, 98, metrics)
+			a.deciderSpec.MetricGapPolicy = test.policy
+			a.deciderSpec.MetricGapGracePeriod = time.Minute
+			pc.readyCount = 8
+			na := expectedNA(a, 8)
+			now := time.Now()
+			expectScale(t, a, now, ScaleResult{10, expectedEBC(10, 98, 100, 8), na, true})
+
+			// The scrapes start failing and the averages drop.
+			metrics.GapStart = now
+			metrics.SetStableAndPanicConcurrency(20, 20)
+			now = now.Add(30 * time.Second)
+			expectScale(t, a, now, ScaleResult{test.want, expectedEBC(10, 98, 20, 8), na, true})
+
+			// Past the grace period the metrics are trusted again.
+			now = now.Add(30 * time.Second)
+			expectScale(t, a, now, ScaleResult{2, expectedEBC(10, 98, 20, 8), na, true})
+		})
+	}
+}
+
 func TestAutoscalerStableModeNoTrafficScaleToZero(t *testing.T) {
 	metrics := &metricClient{StableConcurrency: 1, PanicConcurrency: 0}
 	a := newTestAutoscalerNoPC(t, 10, 75, metrics)
@@ -457,6 +512,112 @@ func TestAutoscalerRateLimitScaleUp(t *testing.T) {
 	expectScale(t, a, time.Now(), ScaleResult{100, expectedEBC(10, 61, 1001, 10), na, true})
 }
 
+func TestAutoscalerMaxScaleUpStep(t *testing.T) {
+	tests := []struct {
+		name         string
+		step         intstr.IntOrString
+		readyPods    int
+		notReadyPods int
+		stable       float64
+		panic        float64
+		wantScale    int32
+		wantPanicked bool
+	}{{
+		name:      "no step",
+		step:      intstr.FromInt(0),
+		readyPods: 10,
+		stable:    300,
+		panic:     150,
+		wantScale: 30,
+	}, {
+		name:      "absolute step",
+		step:      intstr.FromInt(5),
+		readyPods: 10,
+		stable:    300,
+		panic:     150,
+		wantScale: 15,
+	}, {
+		name:      "absolute step not reached",
+		step:      intstr.FromInt(50),
+		readyPods: 10,
+		stable:    300,
+		panic:     150,
+		wantScale: 30,
+	}, {
+		name:         "absolute step over all pods",
+		step:         intstr.FromInt(5),
+		readyPods:    10,
+		notReadyPods: 8,
+		stable:       300,
+		panic:        150,
+		wantScale:    23,
+	}, {
+		name:      "percentage step",
+		step:      intstr.FromString("25%"),
+		readyPods: 10,
+		stable:    300,
+		panic:     150,
+		wantScale: 13, // 2.5 rounded up.
+	}, {
+		name:      "percentage step adds at least a pod",
+		step:      intstr.FromString("10%"),
+		readyPods: 1,
+		stable:    30,
+		panic:     10,
+		wantScale: 2,
+	}, {
+		name:      "scale down is not capped",
+		step:      intstr.FromInt(1),
+		readyPods: 10,
+		stable:    50,
+		panic:     50,
+		wantScale: 5,
+	}, {
+		name:         "bypassed in panic",
+		step:         intstr.FromInt(5),
+		readyPods:    10,
+		stable:       300,
+		panic:        300,
+		wantScale:    30,
+		wantPanicked: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metrics := &metricClient{StableConcurrency: test.stable, PanicConcurrency: test.panic}
+			a, pc := newTestAutoscaler(t, 10, 100, metrics)
+			a.deciderSpec.MaxScaleUpStep = test.step
+			pc.readyCount = test.readyPods
+			pc.notReadyCount = test.notReadyPods
+
+			na := expectedNA(a, float64(test.readyPods))
+			expectScale(t, a, time.Now(), ScaleResult{test.wantScale,
+				expectedEBC(10, 100, test.panic, float64(test.readyPods)), na, true})
+			if panicked := !a.panicTime.IsZero(); panicked != test.wantPanicked {
+				t.Errorf("Panicked = %v, want: %v", panicked, test.wantPanicked)
+			}
+		})
+	}
+}
+
+func TestAutoscalerMaxScaleUpStepCantCountNotReadyPods(t *testing.T) {
+	metrics := &metricClient{StableConcurrency: 300, PanicConcurrency: 150}
+
+	// Without a step the pods that aren't ready aren't counted.
+	a, pc := newTestAutoscaler(t, 10, 100, metrics)
+	pc.readyCount = 10
+	pc.notReadyErr = errors.New("peaches-in-regalia")
+	expectScale(t, a, time.Now(), ScaleResult{30, expectedEBC(10, 100, 150, 10), expectedNA(a, 10), true})
+
+	a, pc = newTestAutoscaler(t, 10, 100, metrics)
+	a.deciderSpec.MaxScaleUpStep = intstr.FromInt(5)
+	pc.readyCount = 10
+	pc.notReadyErr = errors.New("peaches-in-regalia")
+	if got, want := a.Scale(context.Background(), time.Now()), invalidSR; !cmp.Equal(got, want) {
+		t.Errorf("Scale = %v, want: %v", got, want)
+	}
+}
+
 func TestAutoscalerRateLimitScaleDown(t *testing.T) {
 	metrics := &metricClient{StableConcurrency: 1, PanicConcurrency: 1}
 	a, pc := newTestAutoscaler(t, 10, 61, metrics)
@@ -512,6 +673,26 @@ func TestAutoscalerUpdateTarget(t *testing.T) {
 	expectScale(t, a, time.Now(), ScaleResult{100, expectedEBC(1, 71, 101, 10), na, true})
 }
 
+func TestAutoscalerAlgorithm(t *testing.T) {
+	metrics := &metricClient{StableConcurrency: 100, PanicConcurrency: 50}
+	a, pc := newTestAutoscaler(t, 10, 77, metrics)
+	pc.readyCount = 5
+
+	spec := *a.currentSpec()
+	spec.Algorithm = autoscaling.AlgorithmPID
+	a.Update(&spec)
+	// The PID moves half of the way, plus its integral and derivative terms.
+	if got, want := a.Scale(context.Background(), time.Now()).DesiredPodCount, int32(8); got != want {
+		t.Errorf("PID DesiredPodCount = %d, want: %d", got, want)
+	}
+
+	spec.Algorithm = autoscaling.AlgorithmRatio
+	a.Update(&spec)
+	if got, want := a.Scale(context.Background(), time.Now()).DesiredPodCount, int32(10); got != want {
+		t.Errorf("Ratio DesiredPodCount = %d, want: %d", got, want)
+	}
+}
+
 // For table tests and tests that don't care about changing scale.
 func newTestAutoscalerNoPC(t *testing.T, targetValue, targetBurstCapacity float64,
 	metrics metrics.MetricClient) *autoscaler {
@@ -652,6 +833,7 @@ type metricClient struct {
 	StableRPS         float64
 	PanicRPS          float64
 	ErrF              func(key types.NamespacedName, now time.Time) error
+	GapStart          time.Time
 }
 
 // SetStableAndPanicConcurrency sets the stable and panic concurrencies.
@@ -678,3 +860,8 @@ func (mc *metricClient) StableAndPanicRPS(key types.NamespacedName, now time.Tim
 	}
 	return mc.StableRPS, mc.PanicRPS, err
 }
+
+// MissingSince returns the GapStart stored in the object.
+func (mc *metricClient) MissingSince(types.NamespacedName) time.Time {
+	return mc.GapStart
+}
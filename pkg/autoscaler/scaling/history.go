@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Decision is a valid scaling decision of the autoscaler for a revision.
+type Decision struct {
+	Time                time.Time `json:"time"`
+	DesiredPodCount     int32     `json:"desiredPodCount"`
+	ExcessBurstCapacity int32     `json:"excessBurstCapacity"`
+	NumActivators       int32     `json:"numActivators"`
+}
+
+// decisionHistory is a ring buffer of the latest decisions of a revision.
+type decisionHistory struct {
+	decisions []Decision
+	// next is the index the next decision is written at, which holds the
+	// oldest decision once the buffer is full.
+	next int
+	full bool
+}
+
+// record adds the decision to the history, which keeps at most depth
+// decisions. The latest decisions are kept when depth changes.
+func (h *decisionHistory) record(d Decision, depth int) {
+	if depth <= 0 {
+		*h = decisionHistory{}
+		return
+	}
+	if len(h.decisions) != depth {
+		kept := h.list()
+		if len(kept) > depth {
+			kept = kept[len(kept)-depth:]
+		}
+		h.decisions = make([]Decision, depth)
+		h.next = copy(h.decisions, kept) % depth
+		h.full = len(kept) == depth
+	}
+
+	h.decisions[h.next] = d
+	h.next = (h.next + 1) % depth
+	h.full = h.full || h.next == 0
+}
+
+// list returns a copy of the decisions in the history, oldest first.
+func (h *decisionHistory) list() []Decision {
+	if !h.full {
+		return append([]Decision{}, h.decisions[:h.next]...)
+	}
+	return append(append([]Decision{}, h.decisions[h.next:]...), h.decisions[:h.next]...)
+}
+
+// historyReader returns the decision history of the revisions, as the
+// MultiScaler does.
+type historyReader interface {
+	DecisionHistory(namespace, name string) ([]Decision, error)
+}
+
+// DecisionHistoryHandler serves the decision history of the revisions as
+// JSON under /decisions/<namespace>/<name>.
+type DecisionHistoryHandler struct {
+	reader historyReader
+	logger *zap.SugaredLogger
+}
+
+// NewDecisionHistoryHandler creates a DecisionHistoryHandler serving the
+// decision history kept by the MultiScaler.
+func NewDecisionHistoryHandler(m *MultiScaler, logger *zap.SugaredLogger) *DecisionHistoryHandler {
+	return &DecisionHistoryHandler{
+		reader: m,
+		logger: logger,
+	}
+}
+
+func (h *DecisionHistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// decisions/<ns>/<name>
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "decisions" {
+		http.NotFound(w, r)
+		return
+	}
+
+	decisions, err := h.reader.DecisionHistory(parts[1], parts[2])
+	if apierrors.IsNotFound(err) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(decisions); err != nil {
+		h.logger.Errorw("Failed to write decision history response", zap.Error(err))
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/logging/testing"
+	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler/fake"
+)
+
+func decisions(pods ...int32) []Decision {
+	ret := make([]Decision, 0, len(pods))
+	for _, p := range pods {
+		ret = append(ret, Decision{DesiredPodCount: p})
+	}
+	return ret
+}
+
+func TestDecisionHistoryRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		pods   []int32
+		depths []int
+		want   []Decision
+	}{{
+		name:   "disabled",
+		pods:   []int32{1, 2, 3},
+		depths: []int{0, 0, 0},
+		want:   decisions(),
+	}, {
+		name:   "not full",
+		pods:   []int32{1, 2},
+		depths: []int{3, 3},
+		want:   decisions(1, 2),
+	}, {
+		name:   "full",
+		pods:   []int32{1, 2, 3},
+		depths: []int{3, 3, 3},
+		want:   decisions(1, 2, 3),
+	}, {
+		name:   "wraps around",
+		pods:   []int32{1, 2, 3, 4, 5},
+		depths: []int{3, 3, 3, 3, 3},
+		want:   decisions(3, 4, 5),
+	}, {
+		name:   "shrinks to the latest",
+		pods:   []int32{1, 2, 3, 4, 5},
+		depths: []int{4, 4, 4, 4, 2},
+		want:   decisions(4, 5),
+	}, {
+		name:   "grows",
+		pods:   []int32{1, 2, 3, 4, 5},
+		depths: []int{2, 2, 2, 4, 4},
+		want:   decisions(2, 3, 4, 5),
+	}, {
+		name:   "disabled drops the history",
+		pods:   []int32{1, 2, 3, 4},
+		depths: []int{3, 3, 0, 3},
+		want:   decisions(4),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var h decisionHistory
+			for i, p := range test.pods {
+				h.record(Decision{DesiredPodCount: p}, test.depths[i])
+			}
+			if got := h.list(); !cmp.Equal(got, test.want) {
+				t.Errorf("list() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMultiScalerDecisionHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ms, uniScaler := createMultiScaler(ctx, TestLogger(t))
+	mtp := &fake.ManualTickProvider{
+		Channel: make(chan time.Time, 1),
+	}
+	ms.tickProvider = mtp.NewTicker
+
+	decider := newDecider()
+	decider.Spec.DecisionHistoryDepth = 2
+	key := types.NamespacedName{Namespace: decider.Namespace, Name: decider.Name}
+
+	if _, err := ms.DecisionHistory(decider.Namespace, decider.Name); !apierrors.IsNotFound(err) {
+		t.Errorf("DecisionHistory() = %v, want not found error", err)
+	}
+
+	if _, err := ms.Create(ctx, decider); err != nil {
+		t.Fatal("Create() =", err)
+	}
+	ms.scalersMutex.RLock()
+	runner := ms.scalers[key]
+	ms.scalersMutex.RUnlock()
+
+	// The invalid results aren't recorded.
+	for _, sr := range []struct {
+		pods  int32
+		valid bool
+	}{{1, true}, {2, false}, {3, true}, {4, true}} {
+		uniScaler.setScaleResult(sr.pods, 5, 2, sr.valid)
+		ms.tickScaler(ctx, uniScaler, runner, key)
+	}
+
+	got, err := ms.DecisionHistory(decider.Namespace, decider.Name)
+	if err != nil {
+		t.Fatal("DecisionHistory() =", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("DecisionHistory() = %v, want 2 decisions", got)
+	}
+	for i, want := range []int32{3, 4} {
+		if d := got[i]; d.DesiredPodCount != want || d.ExcessBurstCapacity != 5 ||
+			d.NumActivators != 2 || d.Time.IsZero() {
+			t.Errorf("Decision[%d] = %#v, want DesiredPodCount %d", i, d, want)
+		}
+	}
+}
+
+type fakeHistoryReader map[types.NamespacedName][]Decision
+
+func (f fakeHistoryReader) DecisionHistory(namespace, name string) ([]Decision, error) {
+	if namespace == "broken" {
+		return nil, errors.New("broken")
+	}
+	d, ok := f[types.NamespacedName{Namespace: namespace, Name: name}]
+	if !ok {
+		return nil, apierrors.NewNotFound(av1alpha1.Resource("Deciders"), namespace+"/"+name)
+	}
+	return d, nil
+}
+
+func TestDecisionHistoryHandler(t *testing.T) {
+	now := time.Date(2020, 8, 1, 12, 0, 0, 0, time.UTC)
+	h := &DecisionHistoryHandler{
+		reader: fakeHistoryReader{
+			{Namespace: "ns", Name: "rev"}: {{
+				Time:                now,
+				DesiredPodCount:     3,
+				ExcessBurstCapacity: -10,
+				NumActivators:       2,
+			}},
+			{Namespace: "ns", Name: "empty"}: {},
+		},
+		logger: TestLogger(t),
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		wantCode int
+		wantBody string
+	}{{
+		name:     "history",
+		path:     "/decisions/ns/rev",
+		wantCode: http.StatusOK,
+		wantBody: `[{"time":"2020-08-01T12:00:00Z","desiredPodCount":3,"excessBurstCapacity":-10,"numActivators":2}]`,
+	}, {
+		name:     "empty history",
+		path:     "/decisions/ns/empty",
+		wantCode: http.StatusOK,
+		wantBody: `[]`,
+	}, {
+		name:     "unknown revision",
+		path:     "/decisions/ns/unknown",
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "error",
+		path:     "/decisions/broken/rev",
+		wantCode: http.StatusInternalServerError,
+	}, {
+		name:     "bad path",
+		path:     "/decisions/ns",
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "read only",
+		method:   http.MethodDelete,
+		path:     "/decisions/ns/rev",
+		wantCode: http.StatusMethodNotAllowed,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, test.path, nil))
+
+			if rec.Code != test.wantCode {
+				t.Fatalf("StatusCode = %d, want: %d, body: %s", rec.Code, test.wantCode, rec.Body)
+			}
+			if test.wantBody == "" {
+				return
+			}
+			if got, want := rec.Header().Get("Content-Type"), "application/json"; got != want {
+				t.Errorf("Content-Type = %q, want: %q", got, want)
+			}
+			var got, want interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal("Failed to parse the response:", err)
+			}
+			json.Unmarshal([]byte(test.wantBody), &want)
+			if !cmp.Equal(got, want) {
+				t.Errorf("Body = %s, want: %s", rec.Body, test.wantBody)
+			}
+		})
+	}
+}
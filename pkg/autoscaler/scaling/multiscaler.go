@@ -26,9 +26,11 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/logging/logkey"
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	autoscalerconfig "knative.dev/serving/pkg/autoscaler/config"
 	"knative.dev/serving/pkg/autoscaler/metrics"
 )
 
@@ -49,8 +51,15 @@ type Decider struct {
 type DeciderSpec struct {
 	MaxScaleUpRate   float64
 	MaxScaleDownRate float64
+	// MaxScaleUpStep caps the number of pods added per decision outside of
+	// the panic mode, either absolutely or as a percentage of the ready pods.
+	// Zero means no cap.
+	MaxScaleUpStep intstr.IntOrString
 	// The metric used for scaling, i.e. concurrency, rps.
 	ScalingMetric string
+	// Algorithm computes the desired scale from the observed stable metric,
+	// i.e. ratio, pid. Panic mode always uses the ratio.
+	Algorithm string
 	// The value of scaling metric per pod that we target to maintain.
 	// TargetValue <= TotalValue.
 	TargetValue float64
@@ -74,6 +83,13 @@ type DeciderSpec struct {
 	InitialScale int32
 	// Reachable describes whether the revision is referenced by any route.
 	Reachable bool
+	// MetricGapPolicy is applied while the metrics can't be scraped, for at
+	// most MetricGapGracePeriod.
+	MetricGapPolicy      autoscalerconfig.MetricGapPolicy
+	MetricGapGracePeriod time.Duration
+	// DecisionHistoryDepth is the number of the latest decisions kept for
+	// debugging. Zero disables the history.
+	DecisionHistoryDepth int32
 }
 
 // DeciderStatus is the current scale recommendation.
@@ -131,9 +147,10 @@ type scalerRunner struct {
 	stopCh chan struct{}
 	pokeCh chan struct{}
 
-	// mux guards access to decider.
+	// mux guards access to decider and history.
 	mux     sync.RWMutex
 	decider *Decider
+	history decisionHistory
 }
 
 func (sr *scalerRunner) latestScale() int32 {
@@ -167,6 +184,19 @@ func (sr *scalerRunner) updateLatestScale(sRes ScaleResult) bool {
 	return ret
 }
 
+// recordDecision adds the scale result to the decision history, if the
+// decider keeps one.
+func (sr *scalerRunner) recordDecision(now time.Time, sRes ScaleResult) {
+	sr.mux.Lock()
+	defer sr.mux.Unlock()
+	sr.history.record(Decision{
+		Time:                now,
+		DesiredPodCount:     sRes.DesiredPodCount,
+		ExcessBurstCapacity: sRes.ExcessBurstCapacity,
+		NumActivators:       sRes.NumActivators,
+	}, int(sr.decider.Spec.DecisionHistoryDepth))
+}
+
 // MultiScaler maintains a collection of UniScalers.
 type MultiScaler struct {
 	scalersMutex sync.RWMutex
@@ -213,6 +243,21 @@ func (m *MultiScaler) Get(_ context.Context, namespace, name string) (*Decider,
 	return scaler.decider.DeepCopy(), nil
 }
 
+// DecisionHistory returns the latest decisions of the Decider, oldest first.
+func (m *MultiScaler) DecisionHistory(namespace, name string) ([]Decision, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	m.scalersMutex.RLock()
+	defer m.scalersMutex.RUnlock()
+	scaler, exists := m.scalers[key]
+	if !exists {
+		// This GroupResource is a lie, but unfortunately this interface requires one.
+		return nil, errors.NewNotFound(av1alpha1.Resource("Deciders"), key.String())
+	}
+	scaler.mux.RLock()
+	defer scaler.mux.RUnlock()
+	return scaler.history.list(), nil
+}
+
 // Create instantiates the desired Decider.
 func (m *MultiScaler) Create(ctx context.Context, decider *Decider) (*Decider, error) {
 	key := types.NamespacedName{Namespace: decider.Namespace, Name: decider.Name}
@@ -335,11 +380,13 @@ func (m *MultiScaler) createScaler(ctx context.Context, decider *Decider) (*scal
 }
 
 func (m *MultiScaler) tickScaler(ctx context.Context, scaler UniScaler, runner *scalerRunner, metricKey types.NamespacedName) {
-	sr := scaler.Scale(ctx, time.Now())
+	now := time.Now()
+	sr := scaler.Scale(ctx, now)
 
 	if !sr.ScaleValid {
 		return
 	}
+	runner.recordDecision(now, sr)
 
 	if runner.updateLatestScale(sr) {
 		m.Inform(metricKey)
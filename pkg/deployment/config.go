@@ -19,11 +19,15 @@ package deployment
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	cm "knative.dev/pkg/configmap"
 )
@@ -39,6 +43,10 @@ const (
 	// ProgressDeadlineSeconds. This does not match the K8s default value of 600s.
 	ProgressDeadlineDefault = 120 * time.Second
 
+	// QueueSidecarSamePodRetryBackoffDefault is the default value for the
+	// config's QueueSidecarSamePodRetryBackoff.
+	QueueSidecarSamePodRetryBackoffDefault = 10 * time.Millisecond
+
 	// ProgressDeadlineKey is the key to configure deployment progress deadline.
 	ProgressDeadlineKey = "progressDeadline"
 
@@ -46,6 +54,64 @@ const (
 	// (e.g. ko.local) where tags should not be resolved to digests.
 	registriesSkippingTagResolvingKey = "registriesSkippingTagResolving"
 
+	// nodePoolLabelKey is the config map key for the node label that the
+	// serving.knative.dev/nodePool annotation of a revision selects on.
+	nodePoolLabelKey = "nodePoolLabelKey"
+
+	// NodePoolLabelKeyDefault is the default node label selected on by the
+	// serving.knative.dev/nodePool annotation.
+	NodePoolLabelKeyDefault = "knative.dev/node-pool"
+
+	// defaultNodeSelectorKey is the config map key for the nodeSelector
+	// merged into the one of every revision.
+	defaultNodeSelectorKey = "defaultNodeSelector"
+
+	// revisionSelectorKey is the config map key for the label selector
+	// restricting the revisions reconciled by this controller instance.
+	revisionSelectorKey = "revisionSelector"
+
+	// revisionWarmupRateKey is the config map key for how many revisions
+	// per second are enqueued when the controller starts leading.
+	revisionWarmupRateKey = "revisionWarmupRate"
+
+	// imageScanAnnotationKey is the config map key for the revision annotation
+	// carrying the scan status of its images.
+	imageScanAnnotationKey = "imageScanAnnotation"
+
+	// imageScanFlaggedValuesKey is the config map key for the scan statuses
+	// that block the deployment of a revision.
+	imageScanFlaggedValuesKey = "imageScanFlaggedValues"
+
+	// preStopDelayKey is the config map key for how long the queue-proxy
+	// is held before it receives the TERM signal.
+	preStopDelayKey = "preStopDelay"
+
+	// schedulingFailureGracePeriodKey is the config map key for how long the
+	// pods of a revision may be unschedulable before it is reported.
+	schedulingFailureGracePeriodKey = "schedulingFailureGracePeriod"
+
+	// imagePullProgressDeadlineKey is the config map key for how long the
+	// pods of a revision may be pulling an image before it is reported.
+	imagePullProgressDeadlineKey = "imagePullProgressDeadline"
+
+	// queueSidecarMetricsServiceKey is the config map key for whether a
+	// Service exposing the queue-proxy metrics ports is made per revision.
+	queueSidecarMetricsServiceKey = "queueSidecarMetricsService"
+
+	// queueSidecarImmediateContinueKey is the config map key for whether the
+	// queue-proxy answers "Expect: 100-continue" requests on arrival.
+	queueSidecarImmediateContinueKey = "queueSidecarImmediateContinue"
+
+	// queueSidecarSamePodRetriesKey and queueSidecarSamePodRetryBackoffKey
+	// are the config map keys for how the queue-proxy retries the idempotent
+	// requests to its user container.
+	queueSidecarSamePodRetriesKey      = "queueSidecarSamePodRetries"
+	queueSidecarSamePodRetryBackoffKey = "queueSidecarSamePodRetryBackoff"
+
+	// workloadRBACAllowedRulesKey is the config map key for the access the
+	// serving.knative.dev/workloadRBAC annotation of a revision may request.
+	workloadRBACAllowedRulesKey = "workloadRBACAllowedRules"
+
 	// queueSidecar resource request keys.
 	queueSidecarCPURequestKey              = "queueSidecarCPURequest"
 	queueSidecarMemoryRequestKey           = "queueSidecarMemoryRequest"
@@ -55,6 +121,10 @@ const (
 	queueSidecarCPULimitKey              = "queueSidecarCPULimit"
 	queueSidecarMemoryLimitKey           = "queueSidecarMemoryLimit"
 	queueSidecarEphemeralStorageLimitKey = "queueSidecarEphemeralStorageLimit"
+
+	// queueSidecarResourceProfileKeyPrefix prefixes the config map keys of the
+	// named queue-proxy resource profiles, e.g. "queueSidecarResourceProfile.large".
+	queueSidecarResourceProfileKeyPrefix = "queueSidecarResourceProfile."
 )
 
 var (
@@ -66,20 +136,38 @@ var (
 
 func defaultConfig() *Config {
 	return &Config{
-		ProgressDeadline:               ProgressDeadlineDefault,
-		RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
-		QueueSidecarCPURequest:         &QueueSidecarCPURequestDefault,
+		ProgressDeadline:                ProgressDeadlineDefault,
+		RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+		QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+		NodePoolLabelKey:                NodePoolLabelKeyDefault,
+		ImageScanFlaggedValues:          sets.NewString("flagged"),
+		QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
 	}
 }
 
 // NewConfigFromMap creates a DeploymentConfig from the supplied Map
 func NewConfigFromMap(configMap map[string]string) (*Config, error) {
 	nc := defaultConfig()
+	var defaultNodeSelector, workloadRBACAllowedRules string
 
 	if err := cm.Parse(configMap,
 		cm.AsString(QueueSidecarImageKey, &nc.QueueSidecarImage),
 		cm.AsDuration(ProgressDeadlineKey, &nc.ProgressDeadline),
+		cm.AsDuration(preStopDelayKey, &nc.PreStopDelay),
+		cm.AsDuration(schedulingFailureGracePeriodKey, &nc.SchedulingFailureGracePeriod),
+		cm.AsDuration(imagePullProgressDeadlineKey, &nc.ImagePullProgressDeadline),
 		cm.AsStringSet(registriesSkippingTagResolvingKey, &nc.RegistriesSkippingTagResolving),
+		cm.AsString(revisionSelectorKey, &nc.RevisionSelector),
+		cm.AsFloat64(revisionWarmupRateKey, &nc.RevisionWarmupRate),
+		cm.AsString(nodePoolLabelKey, &nc.NodePoolLabelKey),
+		cm.AsString(defaultNodeSelectorKey, &defaultNodeSelector),
+		cm.AsString(imageScanAnnotationKey, &nc.ImageScanAnnotation),
+		cm.AsStringSet(imageScanFlaggedValuesKey, &nc.ImageScanFlaggedValues),
+		cm.AsBool(queueSidecarMetricsServiceKey, &nc.QueueSidecarMetricsService),
+		cm.AsBool(queueSidecarImmediateContinueKey, &nc.QueueSidecarImmediateContinue),
+		cm.AsInt32(queueSidecarSamePodRetriesKey, &nc.QueueSidecarSamePodRetries),
+		cm.AsDuration(queueSidecarSamePodRetryBackoffKey, &nc.QueueSidecarSamePodRetryBackoff),
+		cm.AsString(workloadRBACAllowedRulesKey, &workloadRBACAllowedRules),
 
 		cm.AsQuantity(queueSidecarCPURequestKey, &nc.QueueSidecarCPURequest),
 		cm.AsQuantity(queueSidecarMemoryRequestKey, &nc.QueueSidecarMemoryRequest),
@@ -99,9 +187,130 @@ func NewConfigFromMap(configMap map[string]string) (*Config, error) {
 		return nil, fmt.Errorf("progressDeadline cannot be a non-positive duration, was %v", nc.ProgressDeadline)
 	}
 
+	if nc.PreStopDelay < 0 {
+		return nil, fmt.Errorf("%s cannot be a negative duration, was %v", preStopDelayKey, nc.PreStopDelay)
+	}
+
+	if nc.SchedulingFailureGracePeriod < 0 {
+		return nil, fmt.Errorf("%s cannot be a negative duration, was %v",
+			schedulingFailureGracePeriodKey, nc.SchedulingFailureGracePeriod)
+	}
+
+	if nc.ImagePullProgressDeadline < 0 {
+		return nil, fmt.Errorf("%s cannot be a negative duration, was %v",
+			imagePullProgressDeadlineKey, nc.ImagePullProgressDeadline)
+	}
+
+	if errs := validation.IsQualifiedName(nc.NodePoolLabelKey); len(errs) != 0 {
+		return nil, fmt.Errorf("%s %q is not a valid label key: %v", nodePoolLabelKey, nc.NodePoolLabelKey, errs)
+	}
+
+	if defaultNodeSelector != "" {
+		selector, err := labels.ConvertSelectorToLabelsMap(defaultNodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s %q: %w", defaultNodeSelectorKey, defaultNodeSelector, err)
+		}
+		nc.DefaultNodeSelector = selector
+	}
+
+	if _, err := labels.Parse(nc.RevisionSelector); err != nil {
+		return nil, fmt.Errorf("failed to parse %s %q: %w", revisionSelectorKey, nc.RevisionSelector, err)
+	}
+
+	if nc.QueueSidecarSamePodRetries < 0 {
+		return nil, fmt.Errorf("%s cannot be negative, was %d", queueSidecarSamePodRetriesKey, nc.QueueSidecarSamePodRetries)
+	}
+
+	if nc.QueueSidecarSamePodRetryBackoff < 0 {
+		return nil, fmt.Errorf("%s cannot be a negative duration, was %v",
+			queueSidecarSamePodRetryBackoffKey, nc.QueueSidecarSamePodRetryBackoff)
+	}
+
+	for _, rule := range strings.Split(workloadRBACAllowedRules, ",") {
+		if rule = strings.TrimSpace(rule); rule == "" {
+			continue
+		}
+		if parts := strings.Split(rule, ":"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("%s has an invalid rule %q, want verb:resource", workloadRBACAllowedRulesKey, rule)
+		}
+		if nc.WorkloadRBACAllowedRules == nil {
+			nc.WorkloadRBACAllowedRules = sets.NewString()
+		}
+		nc.WorkloadRBACAllowedRules.Insert(rule)
+	}
+
+	if nc.RevisionWarmupRate < 0 {
+		return nil, fmt.Errorf("%s cannot be negative, was %v", revisionWarmupRateKey, nc.RevisionWarmupRate)
+	}
+
+	for key, value := range configMap {
+		if !strings.HasPrefix(key, queueSidecarResourceProfileKeyPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(key, queueSidecarResourceProfileKeyPrefix)
+		if errs := validation.IsDNS1123Label(name); len(errs) != 0 {
+			return nil, fmt.Errorf("%s has an invalid profile name: %v", key, errs)
+		}
+		profile, err := parseResourceProfile(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s %q: %w", key, value, err)
+		}
+		if nc.QueueSidecarResourceProfiles == nil {
+			nc.QueueSidecarResourceProfiles = make(map[string]corev1.ResourceRequirements, 1)
+		}
+		nc.QueueSidecarResourceProfiles[name] = profile
+	}
+
+	if nc.ImageScanAnnotation != "" {
+		if errs := validation.IsQualifiedName(nc.ImageScanAnnotation); len(errs) != 0 {
+			return nil, fmt.Errorf("%s %q is not a valid annotation key: %v", imageScanAnnotationKey, nc.ImageScanAnnotation, errs)
+		}
+	}
+
 	return nc, nil
 }
 
+// parseResourceProfile parses a comma separated list of resources, such as
+// "requests.cpu=50m,limits.memory=200Mi", into the resources of a profile.
+func parseResourceProfile(value string) (corev1.ResourceRequirements, error) {
+	profile := corev1.ResourceRequirements{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return profile, fmt.Errorf("%q is not of the form <kind>.<resource>=<quantity>", entry)
+		}
+		key, raw := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+		var list *corev1.ResourceList
+		switch {
+		case strings.HasPrefix(key, "requests."):
+			list = &profile.Requests
+		case strings.HasPrefix(key, "limits."):
+			list = &profile.Limits
+		default:
+			return profile, fmt.Errorf("%q is neither a request nor a limit", key)
+		}
+		name := corev1.ResourceName(key[strings.Index(key, ".")+1:])
+		switch name {
+		case corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage:
+		default:
+			return profile, fmt.Errorf("unsupported resource %q", name)
+		}
+		quantity, err := resource.ParseQuantity(raw)
+		if err != nil {
+			return profile, fmt.Errorf("invalid quantity %q for %s: %w", raw, key, err)
+		}
+		if *list == nil {
+			*list = corev1.ResourceList{}
+		}
+		(*list)[name] = quantity
+	}
+	return profile, nil
+}
+
 // NewConfigFromConfigMap creates a DeploymentConfig from the supplied configMap
 func NewConfigFromConfigMap(config *corev1.ConfigMap) (*Config, error) {
 	return NewConfigFromMap(config.Data)
@@ -116,10 +325,74 @@ type Config struct {
 	// Repositories for which tag to digest resolving should be skipped
 	RegistriesSkippingTagResolving sets.String
 
+	// RevisionSelector is a label selector restricting the revisions
+	// reconciled by this controller instance, e.g. to shard revisions
+	// across several controllers. Empty selects all revisions.
+	RevisionSelector string
+
+	// RevisionWarmupRate is how many revisions per second are enqueued when
+	// the controller is promoted to leader, so that a large number of
+	// pre-existing revisions isn't reconciled all at once. Zero enqueues
+	// them all at once.
+	RevisionWarmupRate float64
+
+	// NodePoolLabelKey is the node label whose value is selected on by the
+	// serving.knative.dev/nodePool annotation of a revision.
+	NodePoolLabelKey string
+
+	// DefaultNodeSelector is merged into the nodeSelector of every revision,
+	// e.g. to keep them on the worker nodes. The keys the revision selects
+	// on itself take precedence.
+	DefaultNodeSelector map[string]string
+
+	// ImageScanAnnotation is the revision annotation, set e.g. by the
+	// admission webhook of an image scanner, carrying the scan status of the
+	// revision's images. Empty disables the check.
+	ImageScanAnnotation string
+
+	// ImageScanFlaggedValues are the scan statuses that block the deployment
+	// of a revision.
+	ImageScanFlaggedValues sets.String
+
 	// ProgressDeadline is the time in seconds we wait for the deployment to
 	// be ready before considering it failed.
 	ProgressDeadline time.Duration
 
+	// PreStopDelay is how long the queue-proxy is held by its PreStop hook
+	// before it receives the TERM signal. Zero disables the hook.
+	PreStopDelay time.Duration
+
+	// SchedulingFailureGracePeriod is how long the pods of a revision may be
+	// unschedulable before the revision reports its resources unavailable,
+	// e.g. to give a cluster autoscaler the time to add nodes. Zero reports
+	// them at once.
+	SchedulingFailureGracePeriod time.Duration
+
+	// ImagePullProgressDeadline is how long the pods of a revision may be
+	// pulling an image before the revision reports the slow pull, rather
+	// than just deploying. Zero disables the report.
+	ImagePullProgressDeadline time.Duration
+
+	// QueueSidecarMetricsService makes a Service per revision exposing the
+	// metrics ports of its queue-proxies, e.g. for the scraping by a
+	// Prometheus that doesn't discover the pods.
+	QueueSidecarMetricsService bool
+
+	// QueueSidecarImmediateContinue makes the queue-proxy send the 100
+	// Continue to the "Expect: 100-continue" requests as soon as they
+	// arrive, rather than once they leave its queue.
+	QueueSidecarImmediateContinue bool
+
+	// QueueSidecarSamePodRetries is the number of times the queue-proxy
+	// retries an idempotent request to its user container, when the
+	// connection fails or the container answers with a 503. Zero disables
+	// the retries.
+	QueueSidecarSamePodRetries int32
+
+	// QueueSidecarSamePodRetryBackoff is how long the queue-proxy waits
+	// before its first retry of a request, doubled for every further one.
+	QueueSidecarSamePodRetryBackoff time.Duration
+
 	// QueueSidecarCPURequest is the CPU Request to set for the queue proxy sidecar container
 	QueueSidecarCPURequest *resource.Quantity
 
@@ -139,4 +412,58 @@ type Config struct {
 	// QueueSidecarEphemeralStorageLimit is the Ephemeral Storage Limit to set
 	// for the queue proxy sidecar container
 	QueueSidecarEphemeralStorageLimit *resource.Quantity
+
+	// WorkloadRBACAllowedRules are the "verb:resource" pairs, with the
+	// resource qualified by its API group like in the
+	// serving.knative.dev/workloadRBAC annotation, that revisions may be
+	// granted. Empty grants nothing.
+	WorkloadRBACAllowedRules sets.String
+
+	// QueueSidecarResourceProfiles are the named resources of the queue
+	// proxy sidecar container, selected by the revisions with the
+	// queue.sidecar.serving.knative.dev/resourceProfile annotation in place
+	// of the resources above.
+	QueueSidecarResourceProfiles map[string]corev1.ResourceRequirements
+}
+
+// MatchesRevision returns true if a revision with the given labels should be
+// reconciled according to RevisionSelector.
+func (c *Config) MatchesRevision(revLabels map[string]string) bool {
+	selector, err := labels.Parse(c.RevisionSelector)
+	if err != nil {
+		// RevisionSelector is validated when the config is parsed.
+		return false
+	}
+	return selector.Matches(labels.Set(revLabels))
+}
+
+// DisallowedWorkloadRBAC returns the sorted "verb:resource" pairs of the
+// given rules that are not in WorkloadRBACAllowedRules.
+func (c *Config) DisallowedWorkloadRBAC(rules []rbacv1.PolicyRule) []string {
+	disallowed := sets.NewString()
+	for _, rule := range rules {
+		for _, group := range rule.APIGroups {
+			for _, res := range rule.Resources {
+				if group != "" {
+					res += "." + group
+				}
+				for _, verb := range rule.Verbs {
+					if pair := verb + ":" + res; !c.WorkloadRBACAllowedRules.Has(pair) {
+						disallowed.Insert(pair)
+					}
+				}
+			}
+		}
+	}
+	return disallowed.List()
+}
+
+// ImageScanFlagged returns the scan status of a revision with the given
+// annotations, and whether it blocks the deployment of the revision.
+func (c *Config) ImageScanFlagged(revAnnotations map[string]string) (string, bool) {
+	if c.ImageScanAnnotation == "" {
+		return "", false
+	}
+	status, ok := revAnnotations[c.ImageScanAnnotation]
+	return status, ok && c.ImageScanFlaggedValues.Has(status)
 }
@@ -21,8 +21,10 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -69,10 +71,13 @@ func TestControllerConfiguration(t *testing.T) {
 	}{{
 		name: "controller configuration with bad registries",
 		wantConfig: &Config{
-			RegistriesSkippingTagResolving: sets.NewString("ko.local", ""),
-			QueueSidecarImage:              defaultSidecarImage,
-			QueueSidecarCPURequest:         &QueueSidecarCPURequestDefault,
-			ProgressDeadline:               ProgressDeadlineDefault,
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", ""),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
 		},
 		data: map[string]string{
 			QueueSidecarImageKey:              defaultSidecarImage,
@@ -81,10 +86,13 @@ func TestControllerConfiguration(t *testing.T) {
 	}, {
 		name: "controller configuration good progress deadline",
 		wantConfig: &Config{
-			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
-			QueueSidecarImage:              defaultSidecarImage,
-			QueueSidecarCPURequest:         &QueueSidecarCPURequestDefault,
-			ProgressDeadline:               444 * time.Second,
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                444 * time.Second,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
 		},
 		data: map[string]string{
 			QueueSidecarImageKey: defaultSidecarImage,
@@ -93,10 +101,13 @@ func TestControllerConfiguration(t *testing.T) {
 	}, {
 		name: "controller configuration with registries",
 		wantConfig: &Config{
-			RegistriesSkippingTagResolving: sets.NewString("ko.local", "ko.dev"),
-			QueueSidecarImage:              defaultSidecarImage,
-			QueueSidecarCPURequest:         &QueueSidecarCPURequestDefault,
-			ProgressDeadline:               ProgressDeadlineDefault,
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "ko.dev"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
 		},
 		data: map[string]string{
 			QueueSidecarImageKey:              defaultSidecarImage,
@@ -108,6 +119,9 @@ func TestControllerConfiguration(t *testing.T) {
 			RegistriesSkippingTagResolving:      sets.NewString("ko.local", "dev.local"),
 			QueueSidecarImage:                   defaultSidecarImage,
 			ProgressDeadline:                    ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff:     QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                    NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:              sets.NewString("flagged"),
 			QueueSidecarCPURequest:              resourcePtr(resource.MustParse("123m")),
 			QueueSidecarMemoryRequest:           resourcePtr(resource.MustParse("456M")),
 			QueueSidecarEphemeralStorageRequest: resourcePtr(resource.MustParse("789m")),
@@ -124,6 +138,334 @@ func TestControllerConfiguration(t *testing.T) {
 			queueSidecarMemoryLimitKey:             "654m",
 			queueSidecarEphemeralStorageLimitKey:   "321M",
 		},
+	}, {
+		name: "controller configuration with revision selector",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
+			RevisionSelector:                "shard in (a,b)",
+		},
+		data: map[string]string{
+			QueueSidecarImageKey: defaultSidecarImage,
+			revisionSelectorKey:  "shard in (a,b)",
+		},
+	}, {
+		name: "controller configuration with node pool label key",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                "cloud.google.com/gke-nodepool",
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
+		},
+		data: map[string]string{
+			QueueSidecarImageKey: defaultSidecarImage,
+			nodePoolLabelKey:     "cloud.google.com/gke-nodepool",
+		},
+	}, {
+		name: "controller configuration with image scan",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanAnnotation:             "scanner.example.com/status",
+			ImageScanFlaggedValues:          sets.NewString("critical", "high"),
+		},
+		data: map[string]string{
+			QueueSidecarImageKey:      defaultSidecarImage,
+			imageScanAnnotationKey:    "scanner.example.com/status",
+			imageScanFlaggedValuesKey: "critical,high",
+		},
+	}, {
+		name: "controller configuration with workload rbac",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
+			WorkloadRBACAllowedRules:        sets.NewString("get:configmaps", "list:deployments.apps"),
+		},
+		data: map[string]string{
+			QueueSidecarImageKey:        defaultSidecarImage,
+			workloadRBACAllowedRulesKey: "get:configmaps, list:deployments.apps,",
+		},
+	}, {
+		name:    "controller configuration with invalid workload rbac",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey:        defaultSidecarImage,
+			workloadRBACAllowedRulesKey: "get:configmaps,secrets",
+		},
+	}, {
+		name: "controller configuration with pre-stop delay",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			PreStopDelay:                    5 * time.Second,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
+		},
+		data: map[string]string{
+			QueueSidecarImageKey: defaultSidecarImage,
+			preStopDelayKey:      "5s",
+		},
+	}, {
+		name: "controller configuration with scheduling failure grace period",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			SchedulingFailureGracePeriod:    time.Minute,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
+		},
+		data: map[string]string{
+			QueueSidecarImageKey:            defaultSidecarImage,
+			schedulingFailureGracePeriodKey: "1m",
+		},
+	}, {
+		name: "controller configuration with image pull progress deadline",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
+			ImagePullProgressDeadline:       5 * time.Minute,
+		},
+		data: map[string]string{
+			QueueSidecarImageKey:         defaultSidecarImage,
+			imagePullProgressDeadlineKey: "5m",
+		},
+	}, {
+		name: "controller configuration with default node selector",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			DefaultNodeSelector: map[string]string{
+				"node-role.kubernetes.io/worker": "true",
+				"disktype":                       "ssd",
+			},
+			ImageScanFlaggedValues: sets.NewString("flagged"),
+		},
+		data: map[string]string{
+			QueueSidecarImageKey:   defaultSidecarImage,
+			defaultNodeSelectorKey: "node-role.kubernetes.io/worker=true, disktype=ssd",
+		},
+	}, {
+		name: "controller configuration with same pod retries",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
+			QueueSidecarSamePodRetries:      2,
+			QueueSidecarSamePodRetryBackoff: 25 * time.Millisecond,
+		},
+		data: map[string]string{
+			QueueSidecarImageKey:               defaultSidecarImage,
+			queueSidecarSamePodRetriesKey:      "2",
+			queueSidecarSamePodRetryBackoffKey: "25ms",
+		},
+	}, {
+		name: "controller configuration with queue sidecar resource profiles",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
+			QueueSidecarResourceProfiles: map[string]corev1.ResourceRequirements{
+				"small": {
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("10m"),
+					},
+				},
+				"large": {
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("100Mi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceMemory:           resource.MustParse("500Mi"),
+						corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+					},
+				},
+			},
+		},
+		data: map[string]string{
+			QueueSidecarImageKey:                           defaultSidecarImage,
+			queueSidecarResourceProfileKeyPrefix + "small": "requests.cpu=10m",
+			queueSidecarResourceProfileKeyPrefix + "large": "requests.cpu=100m, requests.memory=100Mi, " +
+				"limits.memory=500Mi, limits.ephemeral-storage=1Gi",
+		},
+	}, {
+		name: "controller configuration with metrics service",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
+			QueueSidecarMetricsService:      true,
+		},
+		data: map[string]string{
+			QueueSidecarImageKey:          defaultSidecarImage,
+			queueSidecarMetricsServiceKey: "true",
+		},
+	}, {
+		name: "controller configuration with revision warmup rate",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
+			RevisionWarmupRate:              12.5,
+		},
+		data: map[string]string{
+			QueueSidecarImageKey:  defaultSidecarImage,
+			revisionWarmupRateKey: "12.5",
+		},
+	}, {
+		name:    "controller configuration negative revision warmup rate",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey:  defaultSidecarImage,
+			revisionWarmupRateKey: "-1",
+		},
+	}, {
+		name: "controller configuration with immediate continue",
+		wantConfig: &Config{
+			RegistriesSkippingTagResolving:  sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:               defaultSidecarImage,
+			QueueSidecarCPURequest:          &QueueSidecarCPURequestDefault,
+			ProgressDeadline:                ProgressDeadlineDefault,
+			QueueSidecarSamePodRetryBackoff: QueueSidecarSamePodRetryBackoffDefault,
+			NodePoolLabelKey:                NodePoolLabelKeyDefault,
+			ImageScanFlaggedValues:          sets.NewString("flagged"),
+			QueueSidecarImmediateContinue:   true,
+		},
+		data: map[string]string{
+			QueueSidecarImageKey:             defaultSidecarImage,
+			queueSidecarImmediateContinueKey: "true",
+		},
+	}, {
+		name:    "controller configuration negative pre-stop delay",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey: defaultSidecarImage,
+			preStopDelayKey:      "-5s",
+		},
+	}, {
+		name:    "controller configuration negative scheduling failure grace period",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey:            defaultSidecarImage,
+			schedulingFailureGracePeriodKey: "-1m",
+		},
+	}, {
+		name:    "controller configuration invalid queue sidecar resource profile name",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey:                           defaultSidecarImage,
+			queueSidecarResourceProfileKeyPrefix + "Large": "requests.cpu=100m",
+		},
+	}, {
+		name:    "controller configuration unsupported queue sidecar resource profile resource",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey:                           defaultSidecarImage,
+			queueSidecarResourceProfileKeyPrefix + "large": "requests.gpu=1",
+		},
+	}, {
+		name:    "controller configuration invalid queue sidecar resource profile quantity",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey:                           defaultSidecarImage,
+			queueSidecarResourceProfileKeyPrefix + "large": "limits.cpu=lots",
+		},
+	}, {
+		name:    "controller configuration negative image pull progress deadline",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey:         defaultSidecarImage,
+			imagePullProgressDeadlineKey: "-5m",
+		},
+	}, {
+		name:    "controller configuration invalid image scan annotation",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey:   defaultSidecarImage,
+			imageScanAnnotationKey: "not an annotation!",
+		},
+	}, {
+		name:    "controller configuration invalid node pool label key",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey: defaultSidecarImage,
+			nodePoolLabelKey:     "not a label!",
+		},
+	}, {
+		name:    "controller configuration invalid default node selector",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey:   defaultSidecarImage,
+			defaultNodeSelectorKey: "disktype",
+		},
+	}, {
+		name:    "controller configuration negative same pod retries",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey:          defaultSidecarImage,
+			queueSidecarSamePodRetriesKey: "-1",
+		},
+	}, {
+		name:    "controller configuration negative same pod retry backoff",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey:               defaultSidecarImage,
+			queueSidecarSamePodRetryBackoffKey: "-1ms",
+		},
+	}, {
+		name:    "controller configuration invalid revision selector",
+		wantErr: true,
+		data: map[string]string{
+			QueueSidecarImageKey: defaultSidecarImage,
+			revisionSelectorKey:  "shard in (a,",
+		},
 	}, {
 		name:    "controller with no side car image",
 		wantErr: true,
@@ -180,6 +522,114 @@ func TestControllerConfiguration(t *testing.T) {
 	}
 }
 
+func TestMatchesRevision(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		labels   map[string]string
+		want     bool
+	}{{
+		name:   "empty selector matches everything",
+		labels: map[string]string{"shard": "c"},
+		want:   true,
+	}, {
+		name:     "matching labels",
+		selector: "shard in (a,b)",
+		labels:   map[string]string{"shard": "a"},
+		want:     true,
+	}, {
+		name:     "non-matching labels",
+		selector: "shard in (a,b)",
+		labels:   map[string]string{"shard": "c"},
+	}, {
+		name:     "missing label",
+		selector: "shard=a",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{RevisionSelector: tt.selector}
+			if got := c.MatchesRevision(tt.labels); got != tt.want {
+				t.Errorf("MatchesRevision(%v) = %v, want: %v", tt.labels, got, tt.want)
+			}
+		})
+	}
+}
+
 func resourcePtr(q resource.Quantity) *resource.Quantity {
 	return &q
 }
+
+func TestImageScanFlagged(t *testing.T) {
+	const annotation = "scanner.example.com/status"
+	tests := []struct {
+		name        string
+		annotation  string
+		annotations map[string]string
+		want        bool
+	}{{
+		name:        "check disabled",
+		annotations: map[string]string{annotation: "flagged"},
+	}, {
+		name:        "flagged image",
+		annotation:  annotation,
+		annotations: map[string]string{annotation: "flagged"},
+		want:        true,
+	}, {
+		name:        "clean image",
+		annotation:  annotation,
+		annotations: map[string]string{annotation: "clean"},
+	}, {
+		name:       "not scanned",
+		annotation: annotation,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				ImageScanAnnotation:    tt.annotation,
+				ImageScanFlaggedValues: sets.NewString("flagged"),
+			}
+			if _, got := c.ImageScanFlagged(tt.annotations); got != tt.want {
+				t.Errorf("ImageScanFlagged(%v) = %v, want: %v", tt.annotations, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisallowedWorkloadRBAC(t *testing.T) {
+	rules := []rbacv1.PolicyRule{{
+		Verbs:     []string{"get", "list"},
+		APIGroups: []string{""},
+		Resources: []string{"configmaps"},
+	}, {
+		Verbs:     []string{"get"},
+		APIGroups: []string{"apps"},
+		Resources: []string{"deployments"},
+	}}
+
+	tests := []struct {
+		name    string
+		allowed sets.String
+		want    []string
+	}{{
+		name: "nothing allowed",
+		want: []string{"get:configmaps", "get:deployments.apps", "list:configmaps"},
+	}, {
+		name:    "some allowed",
+		allowed: sets.NewString("get:configmaps", "get:deployments"),
+		want:    []string{"get:deployments.apps", "list:configmaps"},
+	}, {
+		name:    "all allowed",
+		allowed: sets.NewString("get:configmaps", "list:configmaps", "get:deployments.apps"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{WorkloadRBACAllowedRules: tt.allowed}
+			if got := c.DisallowedWorkloadRBAC(rules); !cmp.Equal(got, tt.want, cmpopts.EquateEmpty()) {
+				t.Errorf("DisallowedWorkloadRBAC() = %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ limitations under the License.
 package deployment
 
 import (
+	v1 "k8s.io/api/core/v1"
 	sets "k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -34,6 +35,20 @@ func (in *Config) DeepCopyInto(out *Config) {
 			(*out)[key] = val
 		}
 	}
+	if in.DefaultNodeSelector != nil {
+		in, out := &in.DefaultNodeSelector, &out.DefaultNodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImageScanFlaggedValues != nil {
+		in, out := &in.ImageScanFlaggedValues, &out.ImageScanFlaggedValues
+		*out = make(sets.String, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.QueueSidecarCPURequest != nil {
 		in, out := &in.QueueSidecarCPURequest, &out.QueueSidecarCPURequest
 		x := (*in).DeepCopy()
@@ -64,6 +79,13 @@ func (in *Config) DeepCopyInto(out *Config) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.QueueSidecarResourceProfiles != nil {
+		in, out := &in.QueueSidecarResourceProfiles, &out.QueueSidecarResourceProfiles
+		*out = make(map[string]v1.ResourceRequirements, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -61,6 +61,9 @@ type Config struct {
 	// regardless of creation or staleness time-bounds.
 	// Set Disabled (-1) to disable/ignore max.
 	MaxNonActiveRevisions int64
+
+	// Whether to emit an event on the Configuration for every revision GC'd.
+	EmitDeletionEvents bool
 }
 
 func defaultConfig() *Config {
@@ -97,6 +100,8 @@ func NewConfigFromConfigMapFunc(ctx context.Context) func(configMap *corev1.Conf
 			cm.AsString("retain-since-last-active-time", &retainActive),
 			cm.AsInt64("min-non-active-revisions", &c.MinNonActiveRevisions),
 			cm.AsString("max-non-active-revisions", &max),
+
+			cm.AsBool("emit-deletion-events", &c.EmitDeletionEvents),
 		); err != nil {
 			return nil, fmt.Errorf("failed to parse data: %w", err)
 		}
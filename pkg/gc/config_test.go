@@ -66,6 +66,16 @@ func TestOurConfig(t *testing.T) {
 			"min-non-active-revisions":           "5",
 			"max-non-active-revisions":           "500",
 		},
+	}, {
+		name: "deletion events",
+		want: func() *Config {
+			d := defaultConfig()
+			d.EmitDeletionEvents = true
+			return d
+		}(),
+		data: map[string]string{
+			"emit-deletion-events": "true",
+		},
 	}, {
 		name: "invalid duration",
 		fail: true,
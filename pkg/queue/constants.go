@@ -26,4 +26,10 @@ const (
 	// Main usage is to delay the termination of user-container until all
 	// accepted requests have been processed.
 	RequestQueueDrainPath = "/wait-for-drain"
+
+	// RequestQueuePreStopPath specifies the path of the PreStop hook of the
+	// queue-proxy, which holds it for the configured pre-stop delay before
+	// it receives the TERM signal, so that the removal of the pod from the
+	// endpoints propagates before its listener closes.
+	RequestQueuePreStopPath = "/pre-stop"
 )
@@ -0,0 +1,43 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ImmediateContinueHandler answers the requests with an "Expect: 100-continue"
+// header with the interim 100 Continue response as soon as they arrive.
+//
+// Otherwise the server only sends it once the body is first read, i.e. when
+// the request is proxied to the user container after waiting in the queue,
+// and the clients waiting for it without a timeout stall their uploads for
+// as long as the request is queued.
+func ImmediateContinueHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && strings.EqualFold(r.Header.Get("Expect"), "100-continue") {
+			// The server writes the 100 Continue on the first read of the
+			// body, which doesn't consume any of it when empty.
+			r.Body.Read(nil)
+			// The expectation is met, so the user container must not be
+			// asked to meet it again.
+			r.Header.Del("Expect")
+		}
+		h.ServeHTTP(w, r)
+	})
+}
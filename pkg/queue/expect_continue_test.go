@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImmediateContinueHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers string
+		body    string
+	}{{
+		name:    "content length",
+		headers: "Content-Length: 5\r\n",
+		body:    "hello",
+	}, {
+		name:    "chunked",
+		headers: "Transfer-Encoding: chunked\r\n",
+		body:    "5\r\nhello\r\n0\r\n\r\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// The handler stands for a request waiting in the queue until
+			// release is closed.
+			release := make(chan struct{})
+			server := httptest.NewServer(ImmediateContinueHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-release
+				if got := r.Header.Get("Expect"); got != "" {
+					t.Errorf("Expect = %q, want it removed", got)
+				}
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					t.Error("Failed to read the body:", err)
+				}
+				w.Write(body)
+			})))
+			defer server.Close()
+
+			conn, err := net.Dial("tcp", server.Listener.Addr().String())
+			if err != nil {
+				t.Fatal("Failed to dial:", err)
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+			reader := bufio.NewReader(conn)
+
+			if _, err := conn.Write([]byte("POST / HTTP/1.1\r\nHost: example.com\r\n" +
+				"Expect: 100-continue\r\n" + test.headers + "\r\n")); err != nil {
+				t.Fatal("Failed to write the headers:", err)
+			}
+
+			// The 100 Continue arrives while the request is still queued.
+			resp, err := http.ReadResponse(reader, nil)
+			if err != nil {
+				t.Fatal("Failed to read the interim response:", err)
+			}
+			if resp.StatusCode != http.StatusContinue {
+				t.Fatalf("StatusCode = %d, want: %d", resp.StatusCode, http.StatusContinue)
+			}
+
+			if _, err := conn.Write([]byte(test.body)); err != nil {
+				t.Fatal("Failed to write the body:", err)
+			}
+			close(release)
+
+			resp, err = http.ReadResponse(reader, nil)
+			if err != nil {
+				t.Fatal("Failed to read the response:", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("StatusCode = %d, want: %d", resp.StatusCode, http.StatusOK)
+			}
+			if got, err := ioutil.ReadAll(resp.Body); err != nil {
+				t.Error("Failed to read the response body:", err)
+			} else if !strings.Contains(string(got), "hello") {
+				t.Errorf("Body = %q, want the request body echoed", got)
+			}
+		})
+	}
+}
+
+func TestImmediateContinueHandlerPassThrough(t *testing.T) {
+	var gotExpect string
+	h := ImmediateContinueHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotExpect = r.Header.Get("Expect")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	req.Header.Set("Expect", "something-else")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if gotExpect != "something-else" {
+		t.Errorf("Expect = %q, want: something-else", gotExpect)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// samePodRetryRoundTripper retries the idempotent requests to the user
+// container when the connection fails or the container answers with a 503,
+// waiting for a doubling backoff between the attempts. Only the requests
+// without a body are retried, since the body of a proxied request can't be
+// read again.
+type samePodRetryRoundTripper struct {
+	next    http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+// NewSamePodRetryRoundTripper returns a RoundTripper retrying the idempotent
+// requests to the user container up to retries times, the first time after
+// backoff. Zero retries returns next.
+func NewSamePodRetryRoundTripper(next http.RoundTripper, retries int, backoff time.Duration) http.RoundTripper {
+	if retries <= 0 {
+		return next
+	}
+	return &samePodRetryRoundTripper{
+		next:    next,
+		retries: retries,
+		backoff: backoff,
+	}
+}
+
+func (rt *samePodRetryRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !IsIdempotent(r.Method) || (r.Body != nil && r.Body != http.NoBody) {
+		return rt.next.RoundTrip(r)
+	}
+
+	backoff := rt.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := rt.next.RoundTrip(r)
+		if attempt == rt.retries || !isTransient(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			// Drain the body to allow the connection to be reused.
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return nil, r.Context().Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// IsIdempotent returns whether requests with the method can be sent more
+// than once, as defined by RFC 7231.
+func IsIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isTransient returns whether the outcome of an attempt is worth a retry to
+// the same container, i.e. it couldn't be reached or was briefly unavailable.
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		var opErr *net.OpError
+		return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+			(errors.As(err, &opErr) && opErr.Op == "dial")
+	}
+	return resp.StatusCode == http.StatusServiceUnavailable
+}
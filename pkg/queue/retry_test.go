@@ -0,0 +1,204 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// attempt is the outcome of an attempt to reach the user container.
+type attempt struct {
+	code int
+	err  error
+}
+
+func TestSamePodRetryRoundTripper(t *testing.T) {
+	refused := attempt{err: syscall.ECONNREFUSED}
+	unavailable := attempt{code: http.StatusServiceUnavailable}
+	ok := attempt{code: http.StatusOK}
+
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		retries  int
+		attempts []attempt
+		wantCode int
+		wantErr  bool
+		wantTry  int
+	}{{
+		name:     "retries disabled",
+		attempts: []attempt{unavailable, ok},
+		wantCode: http.StatusServiceUnavailable,
+		wantTry:  1,
+	}, {
+		name:     "success",
+		retries:  2,
+		attempts: []attempt{ok},
+		wantCode: http.StatusOK,
+		wantTry:  1,
+	}, {
+		name:     "503 retried",
+		retries:  2,
+		attempts: []attempt{unavailable, ok},
+		wantCode: http.StatusOK,
+		wantTry:  2,
+	}, {
+		name:     "connection error retried",
+		retries:  2,
+		attempts: []attempt{refused, refused, ok},
+		wantCode: http.StatusOK,
+		wantTry:  3,
+	}, {
+		name:     "retries exhausted",
+		retries:  2,
+		attempts: []attempt{unavailable, unavailable, unavailable, ok},
+		wantCode: http.StatusServiceUnavailable,
+		wantTry:  3,
+	}, {
+		name:     "retries exhausted by connection errors",
+		retries:  1,
+		attempts: []attempt{refused, refused, ok},
+		wantErr:  true,
+		wantTry:  2,
+	}, {
+		name:     "other errors not retried",
+		retries:  2,
+		attempts: []attempt{{code: http.StatusInternalServerError}, ok},
+		wantCode: http.StatusInternalServerError,
+		wantTry:  1,
+	}, {
+		name:     "non-idempotent method not retried",
+		method:   http.MethodPost,
+		retries:  2,
+		attempts: []attempt{unavailable, ok},
+		wantCode: http.StatusServiceUnavailable,
+		wantTry:  1,
+	}, {
+		name:     "idempotent method with body not retried",
+		method:   http.MethodPut,
+		body:     "payload",
+		retries:  2,
+		attempts: []attempt{unavailable, ok},
+		wantCode: http.StatusServiceUnavailable,
+		wantTry:  1,
+	}, {
+		name:     "idempotent method without body retried",
+		method:   http.MethodDelete,
+		retries:  2,
+		attempts: []attempt{unavailable, ok},
+		wantCode: http.StatusOK,
+		wantTry:  2,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var tries int
+			next := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				a := test.attempts[tries]
+				tries++
+				if a.err != nil {
+					return nil, a.err
+				}
+				return &http.Response{
+					StatusCode: a.code,
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+				}, nil
+			})
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			var req *http.Request
+			if test.body != "" {
+				req = httptest.NewRequest(method, "http://example.com", strings.NewReader(test.body))
+			} else {
+				req = httptest.NewRequest(method, "http://example.com", nil)
+			}
+
+			rt := NewSamePodRetryRoundTripper(next, test.retries, time.Millisecond)
+			resp, err := rt.RoundTrip(req)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("RoundTrip() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if err == nil && resp.StatusCode != test.wantCode {
+				t.Errorf("StatusCode = %d, want: %d", resp.StatusCode, test.wantCode)
+			}
+			if tries != test.wantTry {
+				t.Errorf("Attempts = %d, want: %d", tries, test.wantTry)
+			}
+		})
+	}
+}
+
+func TestSamePodRetryRoundTripperBackoff(t *testing.T) {
+	var times []time.Time
+	next := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		times = append(times, time.Now())
+		return nil, syscall.ECONNRESET
+	})
+
+	const backoff = 20 * time.Millisecond
+	rt := NewSamePodRetryRoundTripper(next, 2, backoff)
+	if _, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "http://example.com", nil)); err == nil {
+		t.Fatal("RoundTrip() = nil, want an error")
+	}
+
+	if len(times) != 3 {
+		t.Fatalf("Attempts = %d, want: 3", len(times))
+	}
+	// The backoff doubles after every attempt.
+	for i, want := range []time.Duration{backoff, 2 * backoff} {
+		if got := times[i+1].Sub(times[i]); got < want {
+			t.Errorf("Backoff %d = %v, want at least: %v", i, got, want)
+		}
+	}
+}
+
+func TestSamePodRetryRoundTripperCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var tries int
+	next := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		tries++
+		cancel()
+		return nil, syscall.ECONNREFUSED
+	})
+
+	rt := NewSamePodRetryRoundTripper(next, 3, time.Hour)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil).WithContext(ctx)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("RoundTrip() = %v, want: %v", err, context.Canceled)
+	}
+	if tries != 1 {
+		t.Errorf("Attempts = %d, want: 1", tries)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// minWarmupStep is the shortest interval at which the capacity of the
+// breaker is raised during a warmup.
+const minWarmupStep = 100 * time.Millisecond
+
+// ConcurrencyWarmup linearly raises the capacity of a Breaker from 1 to the
+// target concurrency over a window, so that a freshly started container
+// isn't hit with its full concurrency right away.
+type ConcurrencyWarmup struct {
+	breaker *Breaker
+	target  int
+	window  time.Duration
+	clock   clock.Clock
+
+	once sync.Once
+	done chan struct{}
+}
+
+// NewConcurrencyWarmup creates a ConcurrencyWarmup for the breaker, which
+// is expected to have been created with an initial capacity of 1.
+func NewConcurrencyWarmup(breaker *Breaker, target int, window time.Duration) *ConcurrencyWarmup {
+	return &ConcurrencyWarmup{
+		breaker: breaker,
+		target:  target,
+		window:  window,
+		clock:   clock.RealClock{},
+		done:    make(chan struct{}),
+	}
+}
+
+// Start starts raising the capacity of the breaker in the background. Only
+// the first call has an effect.
+func (w *ConcurrencyWarmup) Start() {
+	w.once.Do(func() {
+		go w.run()
+	})
+}
+
+// Done returns a channel that is closed once the target capacity is reached.
+func (w *ConcurrencyWarmup) Done() <-chan struct{} {
+	return w.done
+}
+
+func (w *ConcurrencyWarmup) run() {
+	defer close(w.done)
+
+	step := w.window / time.Duration(w.target)
+	if step < minWarmupStep {
+		step = minWarmupStep
+	}
+	start := w.clock.Now()
+	ticker := w.clock.NewTicker(step)
+	defer ticker.Stop()
+
+	for now := range ticker.C() {
+		capacity := w.capacityAt(now.Sub(start))
+		// The capacity is within the bounds of the breaker, so this can't fail.
+		w.breaker.UpdateConcurrency(capacity)
+		if capacity >= w.target {
+			return
+		}
+	}
+}
+
+// capacityAt returns the capacity allowed after elapsed of the window.
+func (w *ConcurrencyWarmup) capacityAt(elapsed time.Duration) int {
+	if elapsed >= w.window {
+		return w.target
+	}
+	capacity := int(math.Ceil(float64(w.target) * float64(elapsed) / float64(w.window)))
+	if capacity < 1 {
+		return 1
+	}
+	return capacity
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestConcurrencyWarmupCapacity(t *testing.T) {
+	w := NewConcurrencyWarmup(nil, 10, 10*time.Second)
+	for _, tc := range []struct {
+		elapsed time.Duration
+		want    int
+	}{
+		{0, 1},
+		{500 * time.Millisecond, 1},
+		{time.Second, 1},
+		{1500 * time.Millisecond, 2},
+		{5 * time.Second, 5},
+		{9900 * time.Millisecond, 10},
+		{10 * time.Second, 10},
+		{time.Minute, 10},
+	} {
+		if got := w.capacityAt(tc.elapsed); got != tc.want {
+			t.Errorf("capacityAt(%v) = %d, want: %d", tc.elapsed, got, tc.want)
+		}
+	}
+}
+
+func TestConcurrencyWarmup(t *testing.T) {
+	b := NewBreaker(BreakerParams{QueueDepth: 100, MaxConcurrency: 10, InitialCapacity: 1})
+	fakeClock := clock.NewFakeClock(time.Now())
+	w := NewConcurrencyWarmup(b, 10, 10*time.Second)
+	w.clock = fakeClock
+
+	// Only a single request is let through before the warmup starts.
+	release, ok := b.Reserve(context.Background())
+	if !ok {
+		t.Fatal("Reserve() = false, want: true")
+	}
+	if _, ok := b.Reserve(context.Background()); ok {
+		t.Error("Reserve() = true before the warmup, want: false")
+	}
+	release()
+
+	w.Start()
+	w.Start() // No-op.
+	if err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	}); err != nil {
+		t.Fatal("The warmup never started ticking")
+	}
+
+	for _, step := range []struct {
+		advance time.Duration
+		want    int
+	}{
+		{3 * time.Second, 3},
+		{4500 * time.Millisecond, 8},
+		{5 * time.Second, 10},
+	} {
+		fakeClock.Step(step.advance)
+		if err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+			return b.Capacity() == step.want, nil
+		}); err != nil {
+			t.Errorf("Capacity() = %d after %v more, want: %d", b.Capacity(), step.advance, step.want)
+		}
+	}
+
+	select {
+	case <-w.Done():
+	case <-time.After(time.Second):
+		t.Error("The warmup didn't finish after the window")
+	}
+}
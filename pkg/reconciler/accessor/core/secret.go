@@ -17,13 +17,18 @@ limitations under the License.
 package core
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"strings"
+	"text/template"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 
@@ -38,13 +43,85 @@ type SecretAccessor interface {
 	GetSecretLister() corev1listers.SecretLister
 }
 
+// ReconcileSecretOption customizes the behavior of ReconcileSecret.
+type ReconcileSecretOption func(*reconcileSecretOptions)
+
+type reconcileSecretOptions struct {
+	adoptStaleController bool
+	transform            func(map[string][]byte) map[string][]byte
+	nameTemplate         *template.Template
+}
+
+// WithStaleControllerAdoption allows ReconcileSecret to take over a Secret whose
+// controller owner reference has the owner's kind and name but a different UID,
+// e.g. because the owner was deleted and recreated.
+func WithStaleControllerAdoption() ReconcileSecretOption {
+	return func(o *reconcileSecretOptions) {
+		o.adoptStaleController = true
+	}
+}
+
+// WithSecretDataTransform makes ReconcileSecret reconcile the Secret to the
+// desired Data as transformed by fn, e.g. to wrap the values or to add keys
+// derived from them. fn is given a copy of the desired Data, which it may
+// modify in place.
+func WithSecretDataTransform(fn func(map[string][]byte) map[string][]byte) ReconcileSecretOption {
+	return func(o *reconcileSecretOptions) {
+		o.transform = fn
+	}
+}
+
+// WithSecretNameTemplate makes ReconcileSecret reconcile the Secret named by
+// executing tmpl rather than the desired one, e.g. to prefix the name with the
+// namespace when mirroring a Secret into many namespaces. tmpl is given the
+// Namespace and the Name of the desired Secret, e.g. "{{.Namespace}}-{{.Name}}",
+// and must yield a valid Secret name.
+func WithSecretNameTemplate(tmpl *template.Template) ReconcileSecretOption {
+	return func(o *reconcileSecretOptions) {
+		o.nameTemplate = tmpl
+	}
+}
+
+// secretNameValues are the values given to the Secret name templates.
+type secretNameValues struct {
+	Namespace string
+	Name      string
+}
+
 // ReconcileSecret reconciles Secret to the desired status.
-func ReconcileSecret(ctx context.Context, owner kmeta.Accessor, desired *corev1.Secret, accessor SecretAccessor) (*corev1.Secret, error) {
+func ReconcileSecret(ctx context.Context, owner kmeta.Accessor, desired *corev1.Secret, accessor SecretAccessor, opts ...ReconcileSecretOption) (*corev1.Secret, error) {
+	o := &reconcileSecretOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.transform != nil || o.nameTemplate != nil {
+		// Don't modify the caller's copy
+		desired = desired.DeepCopy()
+	}
+	if o.transform != nil {
+		desired.Data = o.transform(desired.Data)
+	}
+	if o.nameTemplate != nil {
+		name, err := secretName(o.nameTemplate, desired)
+		if err != nil {
+			return nil, err
+		}
+		desired.Name = name
+	}
+
 	recorder := controller.GetEventRecorder(ctx)
 	if recorder == nil {
 		return nil, fmt.Errorf("recoder for reconciling Secret %s/%s is not created", desired.Namespace, desired.Name)
 	}
 	secret, err := accessor.GetSecretLister().Secrets(desired.Namespace).Get(desired.Name)
+	if err == nil && o.adoptStaleController && isStaleControllerOf(secret, owner) {
+		if secret, err = adoptSecret(secret, owner, accessor); err != nil {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "UpdateFailed",
+				"Failed to adopt Secret %s/%s: %v", desired.Namespace, desired.Name, err)
+			return nil, fmt.Errorf("failed to adopt Secret: %w", err)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Adopted", "Adopted Secret %s/%s", secret.Namespace, secret.Name)
+	}
 	if apierrs.IsNotFound(err) {
 		secret, err = accessor.GetKubeClient().CoreV1().Secrets(desired.Namespace).Create(desired)
 		if err != nil {
@@ -55,6 +132,17 @@ func ReconcileSecret(ctx context.Context, owner kmeta.Accessor, desired *corev1.
 		recorder.Eventf(owner, corev1.EventTypeNormal, "Created", "Created Secret %s/%s", desired.Namespace, desired.Name)
 	} else if err != nil {
 		return nil, fmt.Errorf("failed to get Secret: %w", err)
+	} else if ref := metav1.GetControllerOf(secret); ref != nil && ref.UID != owner.GetUID() &&
+		!equality.Semantic.DeepEqual(secret.Data, desired.Data) {
+		// Another owner controls the Secret and wants different data in it,
+		// overwriting them would make the Secret flap between both.
+		recorder.Eventf(owner, corev1.EventTypeWarning, "Conflict",
+			"Secret %s/%s is controlled by %s %s with different data", secret.Namespace, secret.Name, ref.Kind, ref.Name)
+		return nil, &kaccessor.ConflictError{
+			Kind:       "Secret",
+			Name:       secret.Name,
+			Controller: ref.Kind + "/" + ref.Name,
+		}
 	} else if !metav1.IsControlledBy(secret, owner) {
 		// Return an error with NotControlledBy information.
 		return nil, kaccessor.NewAccessorError(
@@ -73,3 +161,50 @@ func ReconcileSecret(ctx context.Context, owner kmeta.Accessor, desired *corev1.
 	}
 	return secret, nil
 }
+
+// secretName returns the name of the desired Secret according to tmpl.
+func secretName(tmpl *template.Template, desired *corev1.Secret) (string, error) {
+	buf := bytes.Buffer{}
+	if err := tmpl.Execute(&buf, secretNameValues{
+		Namespace: desired.Namespace,
+		Name:      desired.Name,
+	}); err != nil {
+		return "", fmt.Errorf("failed to execute the Secret name template: %w", err)
+	}
+	name := buf.String()
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("invalid Secret name %q from the name template: %s", name, strings.Join(errs, "; "))
+	}
+	return name, nil
+}
+
+// isStaleControllerOf returns true if the controller of the secret has the
+// API group, kind and name of the owner, but not its UID.
+func isStaleControllerOf(secret *corev1.Secret, owner kmeta.Accessor) bool {
+	ref := metav1.GetControllerOf(secret)
+	if ref == nil || ref.UID == owner.GetUID() || ref.Name != owner.GetName() {
+		return false
+	}
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return false
+	}
+	gvk := owner.GroupVersionKind()
+	// The version may differ, e.g. if the owner was recreated through another
+	// version of its API.
+	return gv.Group == gvk.Group && ref.Kind == gvk.Kind
+}
+
+// adoptSecret points the stale controller owner reference of the secret to the
+// owner.
+func adoptSecret(secret *corev1.Secret, owner kmeta.Accessor, accessor SecretAccessor) (*corev1.Secret, error) {
+	// Don't modify the informers copy
+	copy := secret.DeepCopy()
+	for i, ref := range copy.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		copy.OwnerReferences[i].UID = owner.GetUID()
+	}
+	return accessor.GetKubeClient().CoreV1().Secrets(copy.Namespace).Update(copy)
+}
@@ -17,7 +17,10 @@ package core
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -104,6 +107,7 @@ func TestReconcileSecretCreate(t *testing.T) {
 	ctx, accessor, done := setup([]*corev1.Secret{}, t)
 	defer done()
 	ReconcileSecret(ctx, ownerObj, desired, accessor)
+	want := desired
 
 	secretInformer := fakesecretinformer.Get(ctx)
 	if err := wait.PollImmediate(10*time.Millisecond, 3*time.Second, func() (bool, error) {
@@ -114,7 +118,7 @@ func TestReconcileSecretCreate(t *testing.T) {
 			}
 			return false, err
 		}
-		return cmp.Equal(secret, desired), nil
+		return cmp.Equal(secret, want), nil
 	}); err != nil {
 		t.Fatal("Failed to see secret propagation:", err)
 	}
@@ -125,6 +129,7 @@ func TestReconcileSecretUpdate(t *testing.T) {
 	defer done()
 
 	ReconcileSecret(ctx, ownerObj, desired, accessor)
+	want := desired
 	secretInformer := fakesecretinformer.Get(ctx)
 	if err := wait.PollImmediate(10*time.Millisecond, 3*time.Second, func() (bool, error) {
 		secret, err := secretInformer.Lister().Secrets(desired.Namespace).Get(desired.Name)
@@ -134,7 +139,7 @@ func TestReconcileSecretUpdate(t *testing.T) {
 			}
 			return false, err
 		}
-		return cmp.Equal(secret, desired), nil
+		return cmp.Equal(secret, want), nil
 	}); err != nil {
 		t.Fatal("Failed to see secret propagation:", err)
 	}
@@ -153,6 +158,277 @@ func TestNotOwnedFailure(t *testing.T) {
 	}
 }
 
+func TestReconcileSecretSameOwnerUpdate(t *testing.T) {
+	ctx, accessor, done := setup([]*corev1.Secret{origin}, t)
+	defer done()
+
+	secret, err := ReconcileSecret(ctx, ownerObj, desired, accessor)
+	if err != nil {
+		t.Fatal("ReconcileSecret() =", err)
+	}
+	if want := desired; !cmp.Equal(secret, want) {
+		t.Error("ReconcileSecret (-want, +got):", cmp.Diff(want, secret))
+	}
+}
+
+func TestReconcileSecretConflict(t *testing.T) {
+	otherRef := ownerRef
+	otherRef.Name = "otherOwner"
+	otherRef.UID = "efgh"
+	writtenByOther := origin.DeepCopy()
+	writtenByOther.OwnerReferences = []metav1.OwnerReference{otherRef}
+	ctx, accessor, done := setup([]*corev1.Secret{writtenByOther}, t)
+	defer done()
+
+	_, err := ReconcileSecret(ctx, ownerObj, desired, accessor)
+	if err == nil {
+		t.Fatal("Expected to get error when calling ReconcileSecret, but got no error.")
+	}
+	if !kaccessor.IsConflict(err) {
+		t.Errorf("Expected to get ConflictError but got %v", err)
+	}
+
+	// The secret must not have been overwritten.
+	secret, err := fakekubeclient.Get(ctx).CoreV1().Secrets(origin.Namespace).Get(origin.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get secret:", err)
+	}
+	if !cmp.Equal(secret, writtenByOther) {
+		t.Error("Secret was modified (-want, +got):", cmp.Diff(writtenByOther, secret))
+	}
+}
+
+func TestReconcileSecretNoConflictWhenDataMatches(t *testing.T) {
+	otherRef := ownerRef
+	otherRef.Name = "otherOwner"
+	otherRef.UID = "efgh"
+	writtenByOther := desired.DeepCopy()
+	writtenByOther.OwnerReferences = []metav1.OwnerReference{otherRef}
+	ctx, accessor, done := setup([]*corev1.Secret{writtenByOther}, t)
+	defer done()
+
+	// Both owners agree on the data, but the secret still isn't ours.
+	if _, err := ReconcileSecret(ctx, ownerObj, desired, accessor); !kaccessor.IsNotOwned(err) {
+		t.Errorf("Expected to get NotOwnedError but got %v", err)
+	}
+}
+
+func TestReconcileSecretStaleController(t *testing.T) {
+	// The secret was created by an owner with the same kind and name as
+	// ownerObj, which has since been recreated with a new UID.
+	staleRef := ownerRef
+	staleRef.UID = "old-uid"
+	stale := origin.DeepCopy()
+	stale.OwnerReferences = []metav1.OwnerReference{staleRef}
+
+	otherRef := staleRef
+	otherRef.Name = "otherOwner"
+	otherOwned := stale.DeepCopy()
+	otherOwned.OwnerReferences = []metav1.OwnerReference{otherRef}
+
+	otherGroupRef := staleRef
+	otherGroupRef.APIVersion = "example.com/v1"
+	otherGroupOwned := stale.DeepCopy()
+	otherGroupOwned.OwnerReferences = []metav1.OwnerReference{otherGroupRef}
+
+	tests := []struct {
+		name        string
+		existing    *corev1.Secret
+		opts        []ReconcileSecretOption
+		wantErr     func(error) bool
+		wantSecret  *corev1.Secret
+		wantUpdates int
+	}{{
+		name:     "stale controller is not adopted by default",
+		existing: stale,
+		// The stale owner wants different data.
+		wantErr: kaccessor.IsConflict,
+	}, {
+		name:     "stale controller is adopted",
+		existing: stale,
+		opts:     []ReconcileSecretOption{WithStaleControllerAdoption()},
+		// The owner reference points to the new UID, and the data is
+		// reconciled.
+		wantSecret: desired,
+		// Adoption, then data.
+		wantUpdates: 2,
+	}, {
+		name:     "controller with a different name is not adopted",
+		existing: otherOwned,
+		opts:     []ReconcileSecretOption{WithStaleControllerAdoption()},
+		wantErr:  kaccessor.IsConflict,
+	}, {
+		name:     "controller of another API group is not adopted",
+		existing: otherGroupOwned,
+		opts:     []ReconcileSecretOption{WithStaleControllerAdoption()},
+		wantErr:  kaccessor.IsConflict,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, accessor, done := setup([]*corev1.Secret{test.existing}, t)
+			defer done()
+
+			secret, err := ReconcileSecret(ctx, ownerObj, desired, accessor, test.opts...)
+			if test.wantErr != nil {
+				if !test.wantErr(err) {
+					t.Error("Unexpected error:", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("ReconcileSecret() =", err)
+			}
+			if !cmp.Equal(secret, test.wantSecret) {
+				t.Error("ReconcileSecret (-want, +got):", cmp.Diff(test.wantSecret, secret))
+			}
+			updates := 0
+			for _, action := range fakekubeclient.Get(ctx).Actions() {
+				if action.GetVerb() == "update" {
+					updates++
+				}
+			}
+			if updates != test.wantUpdates {
+				t.Errorf("Got %d updates, want: %d", updates, test.wantUpdates)
+			}
+		})
+	}
+}
+
+func TestReconcileSecretDataTransform(t *testing.T) {
+	// Adds the length of every value as a derived key.
+	withLengths := WithSecretDataTransform(func(data map[string][]byte) map[string][]byte {
+		out := make(map[string][]byte, 2*len(data))
+		for k, v := range data {
+			out[k] = v
+			out[k+"-length"] = []byte(strconv.Itoa(len(v)))
+		}
+		return out
+	})
+	transformed := desired.DeepCopy()
+	transformed.Data["test-secret-length"] = []byte("7")
+
+	tests := []struct {
+		name      string
+		existing  []*corev1.Secret
+		wantVerbs []string
+	}{{
+		name:      "create",
+		wantVerbs: []string{"create"},
+	}, {
+		name:      "update",
+		existing:  []*corev1.Secret{desired},
+		wantVerbs: []string{"create", "update"},
+	}, {
+		name:      "unchanged",
+		existing:  []*corev1.Secret{transformed},
+		wantVerbs: []string{"create"}, // By setup.
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, accessor, done := setup(test.existing, t)
+			defer done()
+
+			secret, err := ReconcileSecret(ctx, ownerObj, desired, accessor, withLengths)
+			if err != nil {
+				t.Fatal("ReconcileSecret() =", err)
+			}
+			if want := transformed; !cmp.Equal(secret, want) {
+				t.Error("ReconcileSecret (-want, +got):", cmp.Diff(want, secret))
+			}
+			if _, ok := desired.Data["test-secret-length"]; ok {
+				t.Error("The desired Secret was modified by the transform")
+			}
+
+			var verbs []string
+			for _, action := range fakekubeclient.Get(ctx).Actions() {
+				if verb := action.GetVerb(); verb != "list" && verb != "watch" {
+					verbs = append(verbs, verb)
+				}
+			}
+			if !cmp.Equal(verbs, test.wantVerbs) {
+				t.Errorf("Verbs = %v, want: %v", verbs, test.wantVerbs)
+			}
+		})
+	}
+}
+
+func TestReconcileSecretNameTemplate(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		namespace string
+		want      string
+		wantErr   bool
+	}{{
+		name:      "namespace prefix",
+		template:  "{{.Namespace}}-{{.Name}}",
+		namespace: "default",
+		want:      "default-secret",
+	}, {
+		name:      "per namespace",
+		template:  "{{.Namespace}}-{{.Name}}",
+		namespace: "other",
+		want:      "other-secret",
+	}, {
+		name:      "constant name",
+		template:  "mirrored",
+		namespace: "default",
+		want:      "mirrored",
+	}, {
+		name:      "invalid name",
+		template:  "{{.Namespace}}_{{.Name}}",
+		namespace: "default",
+		wantErr:   true,
+	}, {
+		name:      "empty name",
+		template:  "",
+		namespace: "default",
+		wantErr:   true,
+	}, {
+		name:      "too long",
+		template:  strings.Repeat("a", 254),
+		namespace: "default",
+		wantErr:   true,
+	}, {
+		name:      "unknown field",
+		template:  "{{.Nope}}",
+		namespace: "default",
+		wantErr:   true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, accessor, done := setup(nil, t)
+			defer done()
+
+			in := desired.DeepCopy()
+			in.Namespace = test.namespace
+			tmpl := template.Must(template.New("name").Parse(test.template))
+			secret, err := ReconcileSecret(ctx, ownerObj, in, accessor, WithSecretNameTemplate(tmpl))
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("ReconcileSecret() = %v, wanted an error", secret.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("ReconcileSecret() =", err)
+			}
+			if secret.Name != test.want || secret.Namespace != test.namespace {
+				t.Errorf("Secret = %s/%s, want: %s/%s", secret.Namespace, secret.Name, test.namespace, test.want)
+			}
+			if in.Name != desired.Name {
+				t.Error("The desired Secret was modified by the name template")
+			}
+			if _, err := fakekubeclient.Get(ctx).CoreV1().Secrets(test.namespace).Get(test.want, metav1.GetOptions{}); err != nil {
+				t.Errorf("Failed to get the Secret %s/%s: %v", test.namespace, test.want, err)
+			}
+		})
+	}
+}
+
 func setup(secrets []*corev1.Secret, t *testing.T) (context.Context, *FakeAccessor, func()) {
 	ctx, cancel, _ := SetupFakeContextWithCancel(t)
 	secretInformer := fakesecretinformer.Get(ctx)
@@ -15,7 +15,11 @@ limitations under the License.
 */
 package accessor
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // Error defines a type of error coming from Accessor.
 type Error struct {
@@ -28,6 +32,21 @@ const (
 	NotOwnResource string = "NotOwned"
 )
 
+// ConflictError is returned when a resource is controlled by another owner
+// which wants different data in it, so that overwriting them would make the
+// resource flap between the desires of both.
+type ConflictError struct {
+	// Kind and Name identify the resource.
+	Kind string
+	Name string
+	// Controller is the kind and name of the owner controlling the resource.
+	Controller string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflict: %s %s is controlled by %s, which wants different data", e.Kind, e.Name, e.Controller)
+}
+
 // NewAccessorError creates a new accessor Error
 func NewAccessorError(err error, reason string) Error {
 	return Error{
@@ -48,3 +67,9 @@ func IsNotOwned(err error) bool {
 	}
 	return accessorError.errorReason == NotOwnResource
 }
+
+// IsConflict returns true if the error is a ConflictError.
+func IsConflict(err error) bool {
+	var conflict *ConflictError
+	return errors.As(err, &conflict)
+}
@@ -17,6 +17,7 @@ package accessor
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -47,6 +48,41 @@ func TestIsNotOwned(t *testing.T) {
 	}
 }
 
+func TestIsConflict(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{{
+		name: "IsConflict error",
+		err:  &ConflictError{Kind: "Secret", Name: "secret", Controller: "Service/other"},
+		want: true,
+	}, {
+		name: "wrapped IsConflict error",
+		err:  fmt.Errorf("failed: %w", &ConflictError{Kind: "Secret", Name: "secret", Controller: "Service/other"}),
+		want: true,
+	}, {
+		name: "IsNotOwned error",
+		err: Error{
+			err:         errors.New("test error"),
+			errorReason: NotOwnResource,
+		},
+		want: false,
+	}, {
+		name: "other error",
+		err:  errors.New("test error"),
+		want: false,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsConflict(tc.err); tc.want != got {
+				t.Errorf("IsConflict(%v) = %v, want = %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
 func TestError(t *testing.T) {
 	err := Error{
 		err:         errors.New("test error"),
@@ -19,6 +19,8 @@ package kpa
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
 
 	"go.opencensus.io/stats"
 	"go.uber.org/zap"
@@ -28,8 +30,10 @@ import (
 	pkgmetrics "knative.dev/pkg/metrics"
 	"knative.dev/pkg/ptr"
 	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
+	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
 	"knative.dev/serving/pkg/autoscaler/scaling"
 	pareconciler "knative.dev/serving/pkg/client/injection/reconciler/autoscaling/v1alpha1/podautoscaler"
 	"knative.dev/serving/pkg/metrics"
@@ -41,10 +45,18 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 )
 
-const noPrivateServiceName = "No Private Service Name"
+const (
+	noPrivateServiceName = "No Private Service Name"
+
+	// utilizationStep is the granularity, in percentage points, at which the
+	// concurrency utilization is reported.
+	utilizationStep = 5
+)
 
 // podCounts keeps record of various numbers of pods
 // for each revision.
@@ -64,6 +76,8 @@ type Reconciler struct {
 	podsLister corev1listers.PodLister
 	deciders   resources.Deciders
 	scaler     *scaler
+	metrics    asmetrics.MetricClient
+	clock      clock.Clock
 }
 
 // Check that our Reconciler implements pareconciler.Interface
@@ -159,9 +173,44 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pa *pav1alpha1.PodAutosc
 		terminating: terminating,
 	}
 	logger.Infof("Observed pod counts=%#v", pc)
+	c.computeUtilization(pa, decider, ready, logger)
 	return computeStatus(ctx, pa, pc, logger)
 }
 
+// computeUtilization surfaces the observed concurrency of the revision as a
+// percentage of the concurrency its ready pods can maintain at the per-pod
+// target. The value is rounded to utilizationStep so that the jitter of the
+// observed concurrency doesn't rewrite the status on every reconcile. The
+// annotation is removed when there is nothing to compare, e.g. no ready pods
+// or no metrics yet.
+func (c *Reconciler) computeUtilization(pa *pav1alpha1.PodAutoscaler, decider *scaling.Decider, ready int, logger *zap.SugaredLogger) {
+	if c.metrics == nil || decider.Spec.ScalingMetric != autoscaling.Concurrency ||
+		decider.Spec.TotalValue <= 0 || ready == 0 {
+		delete(pa.Status.Annotations, autoscaling.ConcurrencyUtilizationAnnotationKey)
+		return
+	}
+
+	observed, _, err := c.metrics.StableAndPanicConcurrency(types.NamespacedName{
+		Namespace: pa.Namespace,
+		Name:      pa.Name,
+	}, c.clock.Now())
+	if err != nil {
+		logger.Debugw("Unable to compute concurrency utilization", zap.Error(err))
+		delete(pa.Status.Annotations, autoscaling.ConcurrencyUtilizationAnnotationKey)
+		return
+	}
+
+	utilization := observed / (decider.Spec.TotalValue * float64(ready)) * 100
+	want := strconv.Itoa(int(math.Round(utilization/utilizationStep) * utilizationStep))
+	if pa.Status.Annotations[autoscaling.ConcurrencyUtilizationAnnotationKey] == want {
+		return
+	}
+	if pa.Status.Annotations == nil {
+		pa.Status.Annotations = make(map[string]string, 1)
+	}
+	pa.Status.Annotations[autoscaling.ConcurrencyUtilizationAnnotationKey] = want
+}
+
 func (c *Reconciler) reconcileDecider(ctx context.Context, pa *pav1alpha1.PodAutoscaler) (*scaling.Decider, error) {
 	desiredDecider := resources.MakeDecider(ctx, pa, config.FromContext(ctx).Autoscaler)
 	decider, err := c.deciders.Get(ctx, desiredDecider.Namespace, desiredDecider.Name)
@@ -187,6 +236,7 @@ func (c *Reconciler) reconcileDecider(ctx context.Context, pa *pav1alpha1.PodAut
 
 func computeStatus(ctx context.Context, pa *pav1alpha1.PodAutoscaler, pc podCounts, logger *zap.SugaredLogger) error {
 	pa.Status.DesiredScale, pa.Status.ActualScale = ptr.Int32(int32(pc.want)), ptr.Int32(int32(pc.ready))
+	computeScaleBounds(ctx, pa)
 
 	if err := reportMetrics(pa, pc); err != nil {
 		return fmt.Errorf("error reporting metrics: %w", err)
@@ -198,6 +248,18 @@ func computeStatus(ctx context.Context, pa *pav1alpha1.PodAutoscaler, pc podCoun
 	return nil
 }
 
+// computeScaleBounds surfaces the effective scale bounds of the revision,
+// i.e. its annotations with the cluster defaults applied, when enabled.
+func computeScaleBounds(ctx context.Context, pa *pav1alpha1.PodAutoscaler) {
+	asConfig := config.FromContext(ctx).Autoscaler
+	if !asConfig.ScaleBoundsStatus {
+		pa.Status.MinScale, pa.Status.MaxScale = nil, nil
+		return
+	}
+	min, max := pa.ScaleBounds(asConfig)
+	pa.Status.MinScale, pa.Status.MaxScale = ptr.Int32(min), ptr.Int32(max)
+}
+
 func reportMetrics(pa *pav1alpha1.PodAutoscaler, pc podCounts) error {
 	serviceLabel := pa.Labels[serving.ServiceLabelKey] // This might be empty.
 	configLabel := pa.Labels[serving.ConfigurationLabelKey]
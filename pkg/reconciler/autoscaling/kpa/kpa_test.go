@@ -50,6 +50,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientgotesting "k8s.io/client-go/testing"
 
@@ -64,6 +65,7 @@ import (
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
+	logtesting "knative.dev/pkg/logging/testing"
 	"knative.dev/pkg/metrics/metricskey"
 	"knative.dev/pkg/metrics/metricstest"
 	_ "knative.dev/pkg/metrics/testing"
@@ -76,6 +78,7 @@ import (
 	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	autoscalerconfig "knative.dev/serving/pkg/autoscaler/config"
+	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
 	"knative.dev/serving/pkg/autoscaler/scaling"
 	"knative.dev/serving/pkg/deployment"
 	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
@@ -1186,7 +1189,7 @@ func TestGlobalResyncOnUpdateAutoscalerConfigMap(t *testing.T) {
 	watcher := &configmap.ManualWatcher{Namespace: system.Namespace()}
 
 	fakeDeciders := newTestDeciders()
-	ctl := NewController(ctx, watcher, fakeDeciders)
+	ctl := NewController(ctx, watcher, fakeDeciders, nil)
 
 	// Load default config
 	watcher.OnChange(&corev1.ConfigMap{
@@ -1272,7 +1275,7 @@ func TestReconcileDeciderCreatesAndDeletes(t *testing.T) {
 	ctx, cancel, informers := SetupFakeContextWithCancel(t)
 
 	fakeDeciders := newTestDeciders()
-	ctl := NewController(ctx, newConfigWatcher(), fakeDeciders)
+	ctl := NewController(ctx, newConfigWatcher(), fakeDeciders, nil)
 
 	wf, err := controller.RunInformers(ctx.Done(), informers...)
 	if err != nil {
@@ -1345,7 +1348,7 @@ func TestUpdate(t *testing.T) {
 	t.Cleanup(cancel)
 
 	fakeDeciders := newTestDeciders()
-	ctl := NewController(ctx, newConfigWatcher(), fakeDeciders)
+	ctl := NewController(ctx, newConfigWatcher(), fakeDeciders, nil)
 
 	rev := newTestRevision(testNamespace, testRevision)
 	fakeservingclient.Get(ctx).ServingV1().Revisions(testNamespace).Create(rev)
@@ -1432,7 +1435,7 @@ func TestControllerCreateError(t *testing.T) {
 		&failingDeciders{
 			getErr:    apierrors.NewNotFound(asv1a1.Resource("Deciders"), key),
 			createErr: want,
-		})
+		}, nil)
 
 	kpa := revisionresources.MakePA(newTestRevision(testNamespace, testRevision))
 	fakeservingclient.Get(ctx).AutoscalingV1alpha1().PodAutoscalers(testNamespace).Create(kpa)
@@ -1473,7 +1476,7 @@ func TestControllerUpdateError(t *testing.T) {
 		&failingDeciders{
 			getErr:    apierrors.NewNotFound(asv1a1.Resource("Deciders"), key),
 			createErr: want,
-		})
+		}, nil)
 
 	kpa := revisionresources.MakePA(newTestRevision(testNamespace, testRevision))
 	fakeservingclient.Get(ctx).AutoscalingV1alpha1().PodAutoscalers(testNamespace).Create(kpa)
@@ -1513,7 +1516,7 @@ func TestControllerGetError(t *testing.T) {
 	ctl := NewController(ctx, newConfigWatcher(),
 		&failingDeciders{
 			getErr: want,
-		})
+		}, nil)
 
 	kpa := revisionresources.MakePA(newTestRevision(testNamespace, testRevision))
 	fakeservingclient.Get(ctx).AutoscalingV1alpha1().PodAutoscalers(testNamespace).Create(kpa)
@@ -1548,7 +1551,7 @@ func TestScaleFailure(t *testing.T) {
 		waitInformers()
 	})
 
-	ctl := NewController(ctx, newConfigWatcher(), newTestDeciders())
+	ctl := NewController(ctx, newConfigWatcher(), newTestDeciders(), nil)
 
 	// Only put the KPA in the lister, which will prompt failures scaling it.
 	rev := newTestRevision(testNamespace, testRevision)
@@ -1803,6 +1806,167 @@ func TestMetricsReporter(t *testing.T) {
 	metricstest.AssertMetric(t, wantMetrics...)
 }
 
+type testMetricClient struct {
+	concurrency float64
+	err         error
+}
+
+func (mc *testMetricClient) StableAndPanicConcurrency(types.NamespacedName, time.Time) (float64, float64, error) {
+	return mc.concurrency, mc.concurrency, mc.err
+}
+
+func (mc *testMetricClient) StableAndPanicRPS(types.NamespacedName, time.Time) (float64, float64, error) {
+	return 0, 0, mc.err
+}
+
+func (mc *testMetricClient) MissingSince(types.NamespacedName) time.Time {
+	return time.Time{}
+}
+
+func TestComputeUtilization(t *testing.T) {
+	tests := []struct {
+		name     string
+		metrics  *testMetricClient
+		metric   string
+		ready    int
+		existing string
+		want     string
+	}{{
+		name:    "idle",
+		metrics: &testMetricClient{concurrency: 0},
+		ready:   1,
+		want:    "0",
+	}, {
+		name:    "half utilized",
+		metrics: &testMetricClient{concurrency: 150},
+		ready:   3,
+		want:    "50",
+	}, {
+		name:    "rounded to the step",
+		metrics: &testMetricClient{concurrency: 100},
+		ready:   3,
+		want:    "35",
+	}, {
+		name:    "at target",
+		metrics: &testMetricClient{concurrency: 200},
+		ready:   2,
+		want:    "100",
+	}, {
+		name:    "saturated",
+		metrics: &testMetricClient{concurrency: 300},
+		ready:   2,
+		want:    "150",
+	}, {
+		name:     "jitter within the step",
+		metrics:  &testMetricClient{concurrency: 102},
+		ready:    1,
+		existing: "100",
+		want:     "100",
+	}, {
+		name:     "stale value is updated",
+		metrics:  &testMetricClient{concurrency: 50},
+		ready:    1,
+		existing: "40",
+		want:     "50",
+	}, {
+		name:     "no ready pods",
+		metrics:  &testMetricClient{concurrency: 100},
+		existing: "40",
+	}, {
+		name:     "no metrics",
+		metrics:  &testMetricClient{err: asmetrics.ErrNotCollecting},
+		ready:    2,
+		existing: "40",
+	}, {
+		name:     "rps",
+		metrics:  &testMetricClient{concurrency: 100},
+		metric:   autoscaling.RPS,
+		ready:    1,
+		existing: "40",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pa := kpa(testNamespace, testRevision)
+			if test.existing != "" {
+				pa.Status.Annotations = map[string]string{
+					autoscaling.ConcurrencyUtilizationAnnotationKey: test.existing,
+				}
+			}
+			d := decider(testNamespace, testRevision, 1, 0, 1)
+			d.Spec.ScalingMetric = autoscaling.Concurrency
+			if test.metric != "" {
+				d.Spec.ScalingMetric = test.metric
+			}
+
+			r := &Reconciler{
+				metrics: test.metrics,
+				clock:   clock.NewFakeClock(time.Now()),
+			}
+			r.computeUtilization(pa, d, test.ready, logtesting.TestLogger(t))
+
+			got, ok := pa.Status.Annotations[autoscaling.ConcurrencyUtilizationAnnotationKey]
+			if test.want == "" && ok {
+				t.Errorf("Utilization = %q, want: none", got)
+			} else if got != test.want {
+				t.Errorf("Utilization = %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestComputeScaleBounds(t *testing.T) {
+	withMaxScale := func(pa *asv1a1.PodAutoscaler) {
+		pa.Annotations[autoscaling.MaxScaleAnnotationKey] = "5"
+	}
+	tests := []struct {
+		name     string
+		disabled bool
+		pa       *asv1a1.PodAutoscaler
+		wantMin  *int32
+		wantMax  *int32
+	}{{
+		name:     "disabled",
+		disabled: true,
+		pa:       kpa(testNamespace, testRevision, withMinScale(2), withMaxScale),
+	}, {
+		name:    "defaulted",
+		pa:      kpa(testNamespace, testRevision),
+		wantMin: ptr.Int32(0),
+		wantMax: ptr.Int32(10),
+	}, {
+		name:    "explicit",
+		pa:      kpa(testNamespace, testRevision, withMinScale(2), withMaxScale),
+		wantMin: ptr.Int32(2),
+		wantMax: ptr.Int32(5),
+	}, {
+		name:    "unreachable",
+		pa:      kpa(testNamespace, testRevision, withMinScale(2), withMaxScale, WithReachabilityUnreachable),
+		wantMin: ptr.Int32(0),
+		wantMax: ptr.Int32(5),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Autoscaler.ScaleBoundsStatus = !test.disabled
+			cfg.Autoscaler.MaxScale = 10
+			ctx := config.ToContext(context.Background(), cfg)
+
+			// Stale bounds are replaced, or cleared when disabled.
+			test.pa.Status.MinScale, test.pa.Status.MaxScale = ptr.Int32(42), ptr.Int32(42)
+			computeScaleBounds(ctx, test.pa)
+
+			if got := test.pa.Status.MinScale; !cmp.Equal(got, test.wantMin) {
+				t.Error("MinScale (-want, +got):", cmp.Diff(test.wantMin, got))
+			}
+			if got := test.pa.Status.MaxScale; !cmp.Equal(got, test.wantMax) {
+				t.Error("MaxScale (-want, +got):", cmp.Diff(test.wantMax, got))
+			}
+		})
+	}
+}
+
 func TestResolveScrapeTarget(t *testing.T) {
 	pa := kpa(testNamespace, testRevision, WithPAMetricsService("echo"))
 	tc := &testConfigStore{config: defaultConfig()}
@@ -63,17 +63,22 @@ func MakeDecider(ctx context.Context, pa *asv1a1.PodAutoscaler, config *autoscal
 	return &scaling.Decider{
 		ObjectMeta: *pa.ObjectMeta.DeepCopy(),
 		Spec: scaling.DeciderSpec{
-			MaxScaleUpRate:      config.MaxScaleUpRate,
-			MaxScaleDownRate:    config.MaxScaleDownRate,
-			ScalingMetric:       pa.Metric(),
-			TargetValue:         target,
-			TotalValue:          total,
-			TargetBurstCapacity: tbc,
-			ActivatorCapacity:   config.ActivatorCapacity,
-			PanicThreshold:      panicThreshold,
-			StableWindow:        resources.StableWindow(pa, config),
-			InitialScale:        GetInitialScale(config, pa),
-			Reachable:           pa.Spec.Reachability != asv1a1.ReachabilityUnreachable,
+			MaxScaleUpRate:       config.MaxScaleUpRate,
+			MaxScaleDownRate:     config.MaxScaleDownRate,
+			MaxScaleUpStep:       config.MaxScaleUpStep,
+			ScalingMetric:        pa.Metric(),
+			Algorithm:            pa.Algorithm(),
+			TargetValue:          target,
+			TotalValue:           total,
+			TargetBurstCapacity:  tbc,
+			ActivatorCapacity:    config.ActivatorCapacity,
+			PanicThreshold:       panicThreshold,
+			StableWindow:         resources.StableWindow(pa, config),
+			InitialScale:         GetInitialScale(config, pa),
+			Reachable:            pa.Spec.Reachability != asv1a1.ReachabilityUnreachable,
+			MetricGapPolicy:      config.MetricGapPolicy,
+			MetricGapGracePeriod: config.MetricGapGracePeriod,
+			DecisionHistoryDepth: config.DecisionHistoryDepth,
 		},
 	}
 }
@@ -74,6 +74,16 @@ func TestMakeDecider(t *testing.T) {
 			c.MaxScaleDownRate = 19.88
 			return &c
 		},
+	}, {
+		name: "decision history depth",
+		pa:   pa(),
+		want: decider(withTarget(100.0), withPanicThreshold(2.0), withTotal(100), func(d *scaling.Decider) {
+			d.Spec.DecisionHistoryDepth = 30
+		}),
+		cfgOpt: func(c autoscalerconfig.Config) *autoscalerconfig.Config {
+			c.DecisionHistoryDepth = 30
+			return &c
+		},
 	}, {
 		name: "with container concurrency 1",
 		pa:   pa(WithPAContainerConcurrency(1)),
@@ -135,6 +145,16 @@ func TestMakeDecider(t *testing.T) {
 		name: "with metric annotation",
 		pa:   pa(WithMetricAnnotation("rps")),
 		want: decider(withTarget(100.0), withPanicThreshold(2.0), withTotal(100), withMetric("rps"), withMetricAnnotation("rps")),
+	}, {
+		name: "with algorithm annotation",
+		pa: pa(func(pa *v1alpha1.PodAutoscaler) {
+			pa.Annotations[autoscaling.AlgorithmAnnotationKey] = autoscaling.AlgorithmPID
+		}),
+		want: decider(withTarget(100.0), withPanicThreshold(2.0), withTotal(100),
+			func(d *scaling.Decider) {
+				d.Spec.Algorithm = autoscaling.AlgorithmPID
+				d.Annotations[autoscaling.AlgorithmAnnotationKey] = autoscaling.AlgorithmPID
+			}),
 	}, {
 		name: "with initial scale",
 		pa: pa(func(pa *v1alpha1.PodAutoscaler) {
@@ -261,6 +281,7 @@ func decider(options ...deciderOption) *scaling.Decider {
 		Spec: scaling.DeciderSpec{
 			MaxScaleUpRate:      config.MaxScaleUpRate,
 			ScalingMetric:       "concurrency",
+			Algorithm:           autoscaling.AlgorithmRatio,
 			TargetValue:         100,
 			TotalValue:          100,
 			TargetBurstCapacity: 211,
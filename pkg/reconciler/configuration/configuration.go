@@ -33,6 +33,7 @@ import (
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
+	cfgmap "knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	clientset "knative.dev/serving/pkg/client/clientset/versioned"
@@ -118,9 +119,60 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, config *v1.Configuration
 	if err = c.findAndSetLatestReadyRevision(ctx, config); err != nil {
 		return fmt.Errorf("failed to find and set latest ready revision: %w", err)
 	}
+
+	if cfgmap.FromContextOrDefaults(ctx).Features.ConfigurationRevisionSummary != cfgmap.Enabled {
+		config.Status.Revisions = nil
+		return nil
+	}
+	if err := c.summarizeRevisions(config); err != nil {
+		return fmt.Errorf("failed to summarize revisions: %w", err)
+	}
+	return nil
+}
+
+// summarizeRevisions lists all the revisions of the configuration in its
+// status, in descending generation order.
+func (c *Reconciler) summarizeRevisions(config *v1.Configuration) error {
+	list, err := c.revisionLister.Revisions(config.Namespace).List(labels.SelectorFromSet(labels.Set{
+		serving.ConfigurationLabelKey: config.Name,
+	}))
+	if err != nil {
+		return err
+	}
+	sort.Slice(list, func(i, j int) bool {
+		genI, genJ := revisionGeneration(list[i]), revisionGeneration(list[j])
+		if genI != genJ {
+			return genI > genJ
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	var summary []v1.ConfigurationRevisionSummary
+	for _, rev := range list {
+		ready := corev1.ConditionUnknown
+		if rc := rev.Status.GetCondition(v1.RevisionConditionReady); rc != nil {
+			ready = rc.Status
+		}
+		summary = append(summary, v1.ConfigurationRevisionSummary{
+			Name:              rev.Name,
+			Ready:             ready,
+			CreationTimestamp: rev.CreationTimestamp,
+		})
+	}
+	config.Status.Revisions = summary
 	return nil
 }
 
+// revisionGeneration returns the generation of the configuration the
+// revision was stamped out from, or 0 if its label can't be parsed.
+func revisionGeneration(rev *v1.Revision) int64 {
+	gen, err := strconv.ParseInt(rev.Labels[serving.ConfigurationGenerationLabelKey], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return gen
+}
+
 // findAndSetLatestReadyRevision finds the last ready revision and sets LatestReadyRevisionName to it.
 func (c *Reconciler) findAndSetLatestReadyRevision(ctx context.Context, config *v1.Configuration) error {
 	sortedRevisions, err := c.getSortedCreatedRevisions(ctx, config)
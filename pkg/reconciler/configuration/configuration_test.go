@@ -80,6 +80,86 @@ func TestReconcileNewGCEnabled(t *testing.T) {
 	test(t)
 }
 
+func TestReconcileRevisionSummary(t *testing.T) {
+	testClock = clock.NewFakeClock(time.Now())
+	testCtx = context.Background()
+	now := testClock.Now()
+
+	withSummary := func(flag cfgMap.Flag) context.Context {
+		return cfgMap.ToContext(context.Background(), &cfgMap.Config{
+			Features: &cfgMap.Features{
+				ConfigurationRevisionSummary: flag,
+			},
+		})
+	}
+	summary := []v1.ConfigurationRevisionSummary{{
+		Name:              "summary-00003",
+		Ready:             corev1.ConditionUnknown,
+		CreationTimestamp: metav1.NewTime(now),
+	}, {
+		Name:              "summary-00002",
+		Ready:             corev1.ConditionTrue,
+		CreationTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+	}, {
+		Name:              "summary-00001",
+		Ready:             corev1.ConditionFalse,
+		CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Minute)),
+	}}
+	revisions := []runtime.Object{
+		rev("summary", "foo", 2,
+			WithRevName("summary-00002"),
+			WithCreationTimestamp(now.Add(-time.Minute)), MarkRevisionReady),
+		rev("summary", "foo", 1,
+			WithRevName("summary-00001"),
+			WithCreationTimestamp(now.Add(-2*time.Minute)), MarkContainerExiting(1, "boom")),
+		rev("summary", "foo", 3,
+			WithRevName("summary-00003"),
+			WithCreationTimestamp(now)),
+	}
+	config := func(co ...ConfigOption) *v1.Configuration {
+		return cfg("summary", "foo", 3, append([]ConfigOption{
+			WithLatestCreated("summary-00003"),
+			WithLatestReady("summary-00002"),
+			WithConfigObservedGen,
+		}, co...)...)
+	}
+
+	table := TableTest{{
+		Name:    "revisions listed by descending generation",
+		Ctx:     withSummary(cfgMap.Enabled),
+		Objects: append([]runtime.Object{config()}, revisions...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: config(WithRevisionSummary(summary...)),
+		}},
+		Key: "foo/summary",
+	}, {
+		Name:    "summary up to date",
+		Ctx:     withSummary(cfgMap.Enabled),
+		Objects: append([]runtime.Object{config(WithRevisionSummary(summary...))}, revisions...),
+		Key:     "foo/summary",
+	}, {
+		Name:    "summary removed when disabled",
+		Ctx:     withSummary(cfgMap.Disabled),
+		Objects: append([]runtime.Object{config(WithRevisionSummary(summary...))}, revisions...),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: config(),
+		}},
+		Key: "foo/summary",
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			client:         servingclient.Get(ctx),
+			revisionLister: listers.GetRevisionLister(),
+			clock:          testClock,
+		}
+
+		return configreconciler.NewReconciler(ctx, logging.FromContext(ctx),
+			servingclient.Get(ctx), listers.GetConfigurationLister(),
+			controller.GetEventRecorder(ctx), r)
+	}))
+}
+
 func test(t *testing.T) {
 	retryAttempted := false
 	now := testClock.Now()
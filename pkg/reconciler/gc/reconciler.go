@@ -18,9 +18,12 @@ package gc
 
 import (
 	"context"
+	"strconv"
 
+	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	cfgmap "knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	clientset "knative.dev/serving/pkg/client/clientset/versioned"
 	configreconciler "knative.dev/serving/pkg/client/injection/reconciler/serving/v1/configuration"
@@ -42,6 +45,11 @@ type reconciler struct {
 var _ configreconciler.Interface = (*reconciler)(nil)
 
 func (c *reconciler) ReconcileKind(ctx context.Context, config *v1.Configuration) pkgreconciler.Event {
+	if disabled, _ := strconv.ParseBool(config.Annotations[serving.GCDisabledAnnotationKey]); disabled {
+		logging.FromContext(ctx).Debug("Garbage collection is disabled by annotation")
+		return nil
+	}
+
 	switch configns.FromContext(ctx).Features.ResponsiveRevisionGC {
 
 	case cfgmap.Disabled: // v1 logic
@@ -25,6 +25,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/serving/pkg/apis/serving"
@@ -67,11 +68,24 @@ func Collect(
 				logger.With(zap.Error(err)).Errorf("Failed to delete stale revision %q", rev.Name)
 				continue
 			}
+			if cfg.EmitDeletionEvents {
+				controller.GetEventRecorder(ctx).Eventf(config, corev1.EventTypeNormal, "RevisionCollected",
+					"Garbage collected Revision %q: %s", rev.Name, staleReason(rev))
+			}
 		}
 	}
 	return nil
 }
 
+// staleReason describes why the stale revision was collected.
+func staleReason(rev *v1.Revision) string {
+	lastPin, err := rev.GetLastPinned()
+	if err != nil {
+		return "never became ready"
+	}
+	return "stale, last pinned at " + lastPin.UTC().Format(time.RFC3339)
+}
+
 func isRevisionStale(ctx context.Context, rev *v1.Revision, config *v1.Configuration) bool {
 	if config.Status.LatestReadyRevisionName == rev.Name {
 		return false
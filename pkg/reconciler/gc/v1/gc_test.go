@@ -22,12 +22,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/clock"
 	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/ptr"
 	pkgrec "knative.dev/pkg/reconciler"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
@@ -387,3 +390,77 @@ func (t *testConfigStore) ToContext(ctx context.Context) context.Context {
 }
 
 var _ pkgrec.ConfigStore = (*testConfigStore)(nil)
+
+func TestCollectEvents(t *testing.T) {
+	now := time.Now()
+	tenMinutesAgo := now.Add(-10 * time.Minute)
+
+	old := now.Add(-11 * time.Minute)
+	older := now.Add(-12 * time.Minute)
+	oldest := now.Add(-13 * time.Minute)
+
+	for _, test := range []struct {
+		name       string
+		emit       bool
+		wantEvents []string
+	}{{
+		name: "enabled",
+		emit: true,
+		wantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "RevisionCollected", "Garbage collected Revision %q: %s",
+				"5554", "stale, last pinned at "+tenMinutesAgo.UTC().Format(time.RFC3339)),
+			Eventf(corev1.EventTypeNormal, "RevisionCollected", "Garbage collected Revision %q: %s",
+				"5553", "never became ready"),
+		},
+	}, {
+		name: "disabled",
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, _ := SetupFakeContext(t)
+			ctx = config.ToContext(ctx, &config.Config{
+				RevisionGC: &gcconfig.Config{
+					StaleRevisionCreateDelay:        5 * time.Minute,
+					StaleRevisionTimeout:            5 * time.Minute,
+					StaleRevisionMinimumGenerations: 1,
+					EmitDeletionEvents:              test.emit,
+				},
+			})
+			recorder := record.NewFakeRecorder(10)
+			ctx = controller.WithEventRecorder(ctx, recorder)
+			client := fakeservingclient.Get(ctx)
+
+			cfg := cfg("events", "foo", 5555,
+				WithLatestCreated("5555"),
+				WithLatestReady("5555"),
+				WithConfigObservedGen)
+			ri := fakerevisioninformer.Get(ctx)
+			for _, rev := range []*v1.Revision{
+				rev(ctx, "events", "foo", 5553, // Not Ready
+					WithRevName("5553"),
+					WithCreationTimestamp(oldest)),
+				rev(ctx, "events", "foo", 5554, MarkRevisionReady,
+					WithRevName("5554"),
+					WithCreationTimestamp(older),
+					WithLastPinned(tenMinutesAgo)),
+				rev(ctx, "events", "foo", 5555, MarkRevisionReady,
+					WithRevName("5555"),
+					WithCreationTimestamp(old),
+					WithLastPinned(tenMinutesAgo)),
+			} {
+				ri.Informer().GetIndexer().Add(rev)
+				client.ServingV1().Revisions(rev.Namespace).Create(rev)
+			}
+
+			Collect(ctx, client, ri.Lister(), cfg)
+
+			close(recorder.Events)
+			var got []string
+			for event := range recorder.Events {
+				got = append(got, event)
+			}
+			if !cmp.Equal(got, test.wantEvents) {
+				t.Errorf("Events (-want, +got): %s", cmp.Diff(test.wantEvents, got))
+			}
+		})
+	}
+}
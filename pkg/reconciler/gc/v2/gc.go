@@ -18,13 +18,16 @@ package v2
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"strings"
 	"time"
 
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/serving/pkg/apis/serving"
@@ -83,6 +86,9 @@ func Collect(
 			logger.Info("Deleting stale revision: ", rev.ObjectMeta.Name)
 			if err := client.ServingV1().Revisions(rev.Namespace).Delete(rev.Name, &metav1.DeleteOptions{}); err != nil {
 				logger.Errorw("Failed to GC revision: "+rev.Name, zap.Error(err))
+			} else if cfg.EmitDeletionEvents {
+				recordDeletion(ctx, config, rev, "stale, last active at "+
+					revisionLastActiveTime(rev).UTC().Format(time.RFC3339))
 			}
 		default:
 			swap--
@@ -102,11 +108,19 @@ func Collect(
 		logger.Info("Deleting non-active revision: ", rev.ObjectMeta.Name)
 		if err := client.ServingV1().Revisions(rev.Namespace).Delete(rev.Name, &metav1.DeleteOptions{}); err != nil {
 			logger.Errorw("Failed to GC revision: "+rev.Name, zap.Error(err))
+		} else if cfg.EmitDeletionEvents {
+			recordDeletion(ctx, config, rev, fmt.Sprintf("over the maximum of %d non-active revisions", max))
 		}
 	}
 	return nil
 }
 
+// recordDeletion emits an event on the Configuration for the collected revision.
+func recordDeletion(ctx context.Context, config *v1.Configuration, rev *v1.Revision, reason string) {
+	controller.GetEventRecorder(ctx).Eventf(config, corev1.EventTypeNormal, "RevisionCollected",
+		"Garbage collected Revision %q: %s", rev.Name, reason)
+}
+
 // nonactiveRevisions swaps active revisions to the end and reslices to omit them
 func nonactiveRevisions(revs []*v1.Revision, config *v1.Configuration) []*v1.Revision {
 	swap := len(revs)
@@ -22,12 +22,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/clock"
 	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/ptr"
 	pkgrec "knative.dev/pkg/reconciler"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
@@ -622,3 +625,85 @@ func (t *testConfigStore) ToContext(ctx context.Context) context.Context {
 }
 
 var _ pkgrec.ConfigStore = (*testConfigStore)(nil)
+
+func TestCollectEvents(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-11 * time.Minute)
+	recent := now.Add(-time.Minute)
+
+	cfg := cfg("events-test", "foo", 5556,
+		WithLatestCreated("5556"),
+		WithLatestReady("5556"),
+		WithConfigObservedGen)
+
+	revs := []*v1.Revision{
+		// Stale.
+		rev("events-test", "foo", 5553, MarkRevisionReady,
+			WithRevName("5553"),
+			WithRoutingState(v1.RoutingStateReserve),
+			WithRoutingStateModified(old)),
+		// Over the max.
+		rev("events-test", "foo", 5554, MarkRevisionReady,
+			WithRevName("5554"),
+			WithRoutingState(v1.RoutingStateReserve),
+			WithRoutingStateModified(recent)),
+		rev("events-test", "foo", 5555, MarkRevisionReady,
+			WithRevName("5555"),
+			WithRoutingState(v1.RoutingStateReserve),
+			WithRoutingStateModified(now)),
+		rev("events-test", "foo", 5556, MarkRevisionReady,
+			WithRevName("5556"),
+			WithRoutingState(v1.RoutingStateActive),
+			WithRoutingStateModified(now)),
+	}
+
+	for _, test := range []struct {
+		name       string
+		emit       bool
+		wantEvents []string
+	}{{
+		name: "enabled",
+		emit: true,
+		wantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "RevisionCollected", "Garbage collected Revision %q: %s",
+				"5553", "stale, last active at "+old.UTC().Format(time.RFC3339)),
+			Eventf(corev1.EventTypeNormal, "RevisionCollected", "Garbage collected Revision %q: %s",
+				"5554", "over the maximum of 1 non-active revisions"),
+		},
+	}, {
+		name: "disabled",
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, _ := SetupFakeContext(t)
+			ctx = config.ToContext(ctx, &config.Config{
+				RevisionGC: &gc.Config{
+					RetainSinceCreateTime:     time.Duration(gc.Disabled),
+					RetainSinceLastActiveTime: 5 * time.Minute,
+					MinNonActiveRevisions:     0,
+					MaxNonActiveRevisions:     1,
+					EmitDeletionEvents:        test.emit,
+				},
+			})
+			recorder := record.NewFakeRecorder(10)
+			ctx = controller.WithEventRecorder(ctx, recorder)
+			client := fakeservingclient.Get(ctx)
+
+			ri := fakerevisioninformer.Get(ctx)
+			for _, rev := range revs {
+				ri.Informer().GetIndexer().Add(rev)
+				client.ServingV1().Revisions(rev.Namespace).Create(rev)
+			}
+
+			Collect(ctx, client, ri.Lister(), cfg)
+
+			close(recorder.Events)
+			var got []string
+			for event := range recorder.Events {
+				got = append(got, event)
+			}
+			if !cmp.Equal(got, test.wantEvents) {
+				t.Errorf("Events (-want, +got): %s", cmp.Diff(test.wantEvents, got))
+			}
+		})
+	}
+}
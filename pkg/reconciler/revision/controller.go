@@ -24,17 +24,26 @@ import (
 	imageinformer "knative.dev/caching/pkg/client/injection/informers/caching/v1alpha1/image"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	deploymentinformer "knative.dev/pkg/client/injection/kube/informers/apps/v1/deployment"
+	podinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod"
+	serviceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/service"
+	runtimeclassinformer "knative.dev/pkg/client/injection/kube/informers/node/v1beta1/runtimeclass"
+	roleinformer "knative.dev/pkg/client/injection/kube/informers/rbac/v1/role"
+	rolebindinginformer "knative.dev/pkg/client/injection/kube/informers/rbac/v1/rolebinding"
 	servingclient "knative.dev/serving/pkg/client/injection/client"
 	painformer "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler"
 	revisioninformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/revision"
 	revisionreconciler "knative.dev/serving/pkg/client/injection/reconciler/serving/v1/revision"
+	listers "knative.dev/serving/pkg/client/listers/serving/v1"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/tools/cache"
 	network "knative.dev/networking/pkg"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/metrics"
+	pkgreconciler "knative.dev/pkg/reconciler"
 	apisconfig "knative.dev/serving/pkg/apis/config"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	"knative.dev/serving/pkg/deployment"
@@ -73,6 +82,9 @@ func newControllerWithOptions(
 	deploymentInformer := deploymentinformer.Get(ctx)
 	imageInformer := imageinformer.Get(ctx)
 	paInformer := painformer.Get(ctx)
+	roleInformer := roleinformer.Get(ctx)
+	roleBindingInformer := rolebindinginformer.Get(ctx)
+	serviceInformer := serviceinformer.Get(ctx)
 
 	c := &Reconciler{
 		kubeclient:    kubeclient.Get(ctx),
@@ -82,11 +94,18 @@ func newControllerWithOptions(
 		podAutoscalerLister: paInformer.Lister(),
 		imageLister:         imageInformer.Lister(),
 		deploymentLister:    deploymentInformer.Lister(),
+		roleLister:          roleInformer.Lister(),
+		roleBindingLister:   roleBindingInformer.Lister(),
+		serviceLister:       serviceInformer.Lister(),
+		podLister:           podinformer.Get(ctx).Lister(),
+		runtimeClassLister:  runtimeclassinformer.Get(ctx).Lister(),
 		resolver: &digestResolver{
 			client:    kubeclient.Get(ctx),
 			transport: transport,
 		},
+		clock: clock.RealClock{},
 	}
+	var configStore *config.Store
 	impl := revisionreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 		configsToResync := []interface{}{
 			&network.Config{},
@@ -98,24 +117,49 @@ func newControllerWithOptions(
 		resync := configmap.TypeFilter(configsToResync...)(func(string, interface{}) {
 			// Triggers syncs on all revisions when configuration
 			// changes
-			impl.GlobalResync(revisionInformer.Informer())
+			impl.FilteredGlobalResync(revisionSelectorFilter(configStore), revisionInformer.Informer())
 		})
 
-		configStore := config.NewStore(logger.Named("config-store"), resync)
+		configStore = config.NewStore(logger.Named("config-store"), resync)
 		configStore.WatchConfigs(cmw)
 		return controller.Options{ConfigStore: configStore}
 	})
 
+	c.enqueueAfter = impl.EnqueueAfter
+
+	// The generated reconciler enqueues every Revision when it is promoted
+	// to leader, so drop the keys of the Revisions outside of our selector
+	// before they are reconciled, and spread the enqueuing over time when a
+	// warm-up rate is configured.
+	impl.Reconciler = &revisionSelectorReconciler{
+		Reconciler:  impl.Reconciler,
+		LeaderAware: newWarmupLeaderAware(impl.Reconciler.(pkgreconciler.LeaderAware), c.clock, revisionWarmupRate(configStore)),
+		lister:      revisionInformer.Lister(),
+		filter:      revisionSelectorFilter(configStore),
+	}
+
 	// Set up an event handler for when the resource types of interest change
 	logger.Info("Setting up event handlers")
-	revisionInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+	revisionInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: revisionSelectorFilter(configStore),
+		Handler:    controller.HandleAll(impl.Enqueue),
+	})
 
+	// The Deployments, PodAutoscalers, Roles, RoleBindings and metrics
+	// Services carry the labels of their Revision, so the same selector
+	// applies to them.
 	handleMatchingControllers := cache.FilteringResourceEventHandler{
-		FilterFunc: controller.FilterControllerGK(v1.Kind("Revision")),
-		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+		FilterFunc: pkgreconciler.ChainFilterFuncs(
+			controller.FilterControllerGK(v1.Kind("Revision")),
+			revisionSelectorFilter(configStore),
+		),
+		Handler: controller.HandleAll(impl.EnqueueControllerOf),
 	}
 	deploymentInformer.Informer().AddEventHandler(handleMatchingControllers)
 	paInformer.Informer().AddEventHandler(handleMatchingControllers)
+	roleInformer.Informer().AddEventHandler(handleMatchingControllers)
+	roleBindingInformer.Informer().AddEventHandler(handleMatchingControllers)
+	serviceInformer.Informer().AddEventHandler(handleMatchingControllers)
 
 	// We don't watch for changes to Image because we don't incorporate any of its
 	// properties into our own status and should work completely in the absence of
@@ -126,3 +170,50 @@ func newControllerWithOptions(
 	}
 	return impl
 }
+
+// revisionSelectorFilter returns a filter accepting only the objects whose
+// labels match the revision selector of the current deployment config.
+func revisionSelectorFilter(configStore *config.Store) func(interface{}) bool {
+	return func(obj interface{}) bool {
+		mo, ok := obj.(metav1.Object)
+		if !ok {
+			return false
+		}
+		cfg := configStore.Load().Deployment
+		return cfg == nil || cfg.MatchesRevision(mo.GetLabels())
+	}
+}
+
+// revisionWarmupRate returns a func returning the revision warm-up rate of
+// the current deployment config.
+func revisionWarmupRate(configStore *config.Store) func() float64 {
+	return func() float64 {
+		if cfg := configStore.Load().Deployment; cfg != nil {
+			return cfg.RevisionWarmupRate
+		}
+		return 0
+	}
+}
+
+// revisionSelectorReconciler wraps a Revision reconciler to only reconcile the
+// Revisions accepted by filter.
+type revisionSelectorReconciler struct {
+	controller.Reconciler
+	pkgreconciler.LeaderAware
+
+	lister listers.RevisionLister
+	filter func(interface{}) bool
+}
+
+// Reconcile implements controller.Reconciler
+func (r *revisionSelectorReconciler) Reconcile(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return r.Reconciler.Reconcile(ctx, key)
+	}
+	// Let the wrapped reconciler deal with missing Revisions.
+	if rev, err := r.lister.Revisions(namespace).Get(name); err == nil && !r.filter(rev) {
+		return nil
+	}
+	return r.Reconciler.Reconcile(ctx, key)
+}
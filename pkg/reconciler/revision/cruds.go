@@ -21,6 +21,8 @@ import (
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 
 	caching "knative.dev/caching/pkg/apis/caching/v1alpha1"
@@ -28,6 +30,7 @@ import (
 	"knative.dev/pkg/kmp"
 	"knative.dev/pkg/logging"
 	autoscaling "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	"knative.dev/serving/pkg/reconciler/revision/config"
 	"knative.dev/serving/pkg/reconciler/revision/resources"
@@ -78,6 +81,13 @@ func (c *Reconciler) checkAndUpdateDeployment(ctx context.Context, rev *v1.Revis
 	// TODO(dprotaso): determine other immutable properties.
 	deployment.Spec.Selector = have.Spec.Selector
 
+	// Preserve the restart marker, so we don't undo a rolling replacement
+	// triggered by the maximum pod lifetime.
+	if ts, ok := have.Spec.Template.Annotations[serving.RestartedAtAnnotationKey]; ok {
+		deployment.Spec.Template.Annotations = kmeta.UnionMaps(deployment.Spec.Template.Annotations,
+			map[string]string{serving.RestartedAtAnnotationKey: ts})
+	}
+
 	// If the spec we want is the spec we have, then we're good.
 	if equality.Semantic.DeepEqual(have.Spec, deployment.Spec) {
 		return have, nil
@@ -115,6 +125,21 @@ func (c *Reconciler) createImageCache(rev *v1.Revision, containerName, imageDige
 	return c.cachingclient.CachingV1alpha1().Images(image.Namespace).Create(image)
 }
 
+func (c *Reconciler) createRole(rev *v1.Revision, rules []rbacv1.PolicyRule) (*rbacv1.Role, error) {
+	role := resources.MakeRole(rev, rules)
+	return c.kubeclient.RbacV1().Roles(role.Namespace).Create(role)
+}
+
+func (c *Reconciler) createRoleBinding(rev *v1.Revision) (*rbacv1.RoleBinding, error) {
+	rb := resources.MakeRoleBinding(rev)
+	return c.kubeclient.RbacV1().RoleBindings(rb.Namespace).Create(rb)
+}
+
+func (c *Reconciler) createMetricsService(rev *v1.Revision) (*corev1.Service, error) {
+	svc := resources.MakeMetricsService(rev)
+	return c.kubeclient.CoreV1().Services(svc.Namespace).Create(svc)
+}
+
 func (c *Reconciler) createPA(ctx context.Context, rev *v1.Revision) (*autoscaling.PodAutoscaler, error) {
 	pa := resources.MakePA(rev)
 	return c.client.AutoscalingV1alpha1().PodAutoscalers(pa.Namespace).Create(pa)
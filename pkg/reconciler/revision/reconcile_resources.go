@@ -19,6 +19,9 @@ package revision
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -27,16 +30,57 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/kmp"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/logging/logkey"
+	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/reconciler/revision/config"
 	"knative.dev/serving/pkg/reconciler/revision/resources"
 	resourcenames "knative.dev/serving/pkg/reconciler/revision/resources/names"
 )
 
+const (
+	// containerCreatingReason is the reason the containers wait with until
+	// they're started, e.g. while their images are pulled.
+	containerCreatingReason = "ContainerCreating"
+
+	// pullingImageReason and pulledImageReason are the reasons of the events
+	// of the kubelet starting and finishing the pull of an image.
+	pullingImageReason = "Pulling"
+	pulledImageReason  = "Pulled"
+)
+
+// removeFlaggedDeployment deletes the Deployment of a revision whose images
+// were flagged by their scan. It is created again by reconcileDeployment once
+// the images are no longer flagged.
+func (c *Reconciler) removeFlaggedDeployment(ctx context.Context, rev *v1.Revision) error {
+	deploymentName := resourcenames.Deployment(rev)
+	deployment, err := c.deploymentLister.Deployments(rev.Namespace).Get(deploymentName)
+	if apierrs.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get deployment %q: %w", deploymentName, err)
+	} else if !metav1.IsControlledBy(deployment, rev) || deployment.DeletionTimestamp != nil {
+		return nil
+	}
+
+	err = c.kubeclient.AppsV1().Deployments(rev.Namespace).Delete(deploymentName, &metav1.DeleteOptions{
+		Preconditions: metav1.NewUIDPreconditions(string(deployment.UID)),
+	})
+	if err != nil && !apierrs.IsNotFound(err) {
+		return fmt.Errorf("failed to delete deployment %q: %w", deploymentName, err)
+	}
+	logging.FromContext(ctx).Infof("Deleted deployment %q of the flagged revision", deploymentName)
+	controller.GetEventRecorder(ctx).Eventf(rev, corev1.EventTypeWarning, v1.ReasonImageFlagged,
+		"Deleted deployment %q since the images of the revision were flagged", deploymentName)
+	return nil
+}
+
 func (c *Reconciler) reconcileDeployment(ctx context.Context, rev *v1.Revision) error {
 	ns := rev.Namespace
 	deploymentName := resourcenames.Deployment(rev)
@@ -65,6 +109,11 @@ func (c *Reconciler) reconcileDeployment(ctx context.Context, rev *v1.Revision)
 			return fmt.Errorf("failed to update deployment %q: %w", deploymentName, err)
 		}
 
+		deployment, err = c.reconcilePodLifetime(ctx, rev, deployment)
+		if err != nil {
+			return fmt.Errorf("failed to recycle pods of deployment %q: %w", deploymentName, err)
+		}
+
 		// Now that we have a Deployment, determine whether there is any relevant
 		// status to surface in the Revision.
 		//
@@ -90,16 +139,56 @@ func (c *Reconciler) reconcileDeployment(ctx context.Context, rev *v1.Revision)
 
 			// Update the revision status if pod cannot be scheduled (possibly resource constraints)
 			// If pod cannot be scheduled then we expect the container status to be empty.
-			for _, cond := range pod.Status.Conditions {
-				if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+			if cond := unschedulableCondition(pods.Items); cond != nil {
+				grace := config.FromContext(ctx).Deployment.SchedulingFailureGracePeriod
+				if pending := grace - c.clock.Since(cond.LastTransitionTime.Time); pending > 0 {
+					// Give the nodes the time to come up before failing the revision.
+					c.enqueueAfter(rev, pending)
+				} else {
+					logger.Infof("marking unschedulable with: %s: %s", cond.Reason, cond.Message)
 					rev.Status.MarkResourcesAvailableFalse(cond.Reason, cond.Message)
-					break
 				}
 			}
 
+			// The containers just wait with ContainerCreating while their images
+			// are pulled, the slow pulls only show in the events of the pod.
+			if deadline := config.FromContext(ctx).Deployment.ImagePullProgressDeadline; deadline > 0 {
+				if image, since, ok := c.pullingImage(ctx, &pod); ok {
+					if pending := deadline - c.clock.Since(since); pending > 0 {
+						c.enqueueAfter(rev, pending)
+					} else {
+						logger.Infof("marking image pull slow: %s", image)
+						rev.Status.MarkResourcesAvailableUnknown(v1.ReasonImagePullSlow,
+							v1.RevisionImagePullSlowMessage(image, deadline))
+					}
+				}
+			}
+
+			// Running out of memory is surfaced as such rather than by the exit
+			// code, whichever container it happened to.
+			if name, t, ok := oomKilledContainer(pod.Status.ContainerStatuses); ok {
+				// The termination stays in the status of the container until the
+				// next one, so it's only reported when it is newer than the status
+				// of the revision.
+				if cond := rev.Status.GetCondition(v1.RevisionConditionContainerHealthy); cond == nil ||
+					cond.Reason != v1.ReasonOOMKilled || t.FinishedAt.After(cond.LastTransitionTime.Inner.Time) {
+					controller.GetEventRecorder(ctx).Eventf(rev, corev1.EventTypeWarning, v1.ReasonOOMKilled,
+						"Container %q of pod %q was killed for exceeding its memory limit", name, pod.Name)
+				}
+				logger.Info("marking OOMKilled: ", name)
+				rev.Status.MarkContainerHealthyFalse(v1.ReasonOOMKilled, v1.RevisionContainerOOMKilledMessage(name))
+				return nil
+			}
+
 			for _, status := range pod.Status.ContainerStatuses {
 				if status.Name == rev.Spec.GetContainer().Name {
-					if t := status.LastTerminationState.Terminated; t != nil {
+					if t := status.LastTerminationState.Terminated; t != nil && t.ExitCode == 0 &&
+						rev.Spec.RestartPolicy == corev1.RestartPolicyOnFailure {
+						// The container ran to completion, which is not a failure
+						// of the run-to-completion revisions.
+						logger.Debug("marking completed")
+						rev.Status.MarkCompleted()
+					} else if t != nil {
 						logger.Infof("marking exiting with: %d/%s", t.ExitCode, t.Message)
 						rev.Status.MarkContainerHealthyFalse(v1.ExitCodeReason(t.ExitCode), v1.RevisionContainerExitingMessage(t.Message))
 					} else if w := status.State.Waiting; w != nil && hasDeploymentTimedOut(deployment) {
@@ -115,6 +204,145 @@ func (c *Reconciler) reconcileDeployment(ctx context.Context, rev *v1.Revision)
 	return nil
 }
 
+// unschedulableCondition returns the PodScheduled condition of the first pod
+// the scheduler failed to place, if any.
+func unschedulableCondition(pods []corev1.Pod) *corev1.PodCondition {
+	for i := range pods {
+		for j, cond := range pods[i].Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+				return &pods[i].Status.Conditions[j]
+			}
+		}
+	}
+	return nil
+}
+
+// pullingImage returns the image a container of the pod has been pulling
+// since the returned time, according to the events of the kubelet, if any.
+func (c *Reconciler) pullingImage(ctx context.Context, pod *corev1.Pod) (string, time.Time, bool) {
+	creating := false
+	for _, status := range pod.Status.ContainerStatuses {
+		if w := status.State.Waiting; w != nil && w.Reason == containerCreatingReason {
+			creating = true
+			break
+		}
+	}
+	if !creating {
+		return "", time.Time{}, false
+	}
+
+	events, err := c.kubeclient.CoreV1().Events(pod.Namespace).List(metav1.ListOptions{
+		FieldSelector: fields.Set{
+			"involvedObject.kind": "Pod",
+			"involvedObject.name": pod.Name,
+		}.AsSelector().String(),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorw("Error getting pod events", zap.Error(err))
+		return "", time.Time{}, false
+	}
+
+	// The latest pull events of each container, keyed by their field path.
+	pulling := make(map[string]time.Time, len(events.Items))
+	pulled := make(map[string]time.Time, len(events.Items))
+	for _, ev := range events.Items {
+		if ev.InvolvedObject.Kind != "Pod" || ev.InvolvedObject.Name != pod.Name {
+			continue
+		}
+		ts := ev.LastTimestamp.Time
+		if ts.IsZero() {
+			ts = ev.EventTime.Time
+		}
+		var latest map[string]time.Time
+		switch ev.Reason {
+		case pullingImageReason:
+			latest = pulling
+		case pulledImageReason:
+			latest = pulled
+		default:
+			continue
+		}
+		if ts.After(latest[ev.InvolvedObject.FieldPath]) {
+			latest[ev.InvolvedObject.FieldPath] = ts
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		path := "spec.containers{" + container.Name + "}"
+		if since, ok := pulling[path]; ok && !pulled[path].After(since) {
+			return container.Image, since, true
+		}
+	}
+	return "", time.Time{}, false
+}
+
+// oomKilledContainer returns the name and the termination of the first
+// container that is or was last terminated for running out of memory, if any.
+func oomKilledContainer(statuses []corev1.ContainerStatus) (string, *corev1.ContainerStateTerminated, bool) {
+	for _, status := range statuses {
+		for _, t := range []*corev1.ContainerStateTerminated{
+			status.State.Terminated, status.LastTerminationState.Terminated,
+		} {
+			if t != nil && t.Reason == v1.ReasonOOMKilled {
+				return status.Name, t, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// reconcilePodLifetime triggers a rolling replacement of the revision's pods
+// once the oldest of them has outlived the maximum pod lifetime requested via
+// annotation. Until then it schedules the revision to be looked at again when
+// that is going to happen.
+func (c *Reconciler) reconcilePodLifetime(ctx context.Context, rev *v1.Revision, deployment *appsv1.Deployment) (*appsv1.Deployment, error) {
+	maxLifetime, ok := rev.GetMaxPodLifetime()
+	if !ok {
+		return deployment, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+	pods, err := c.podLister.Pods(rev.Namespace).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	// Pods created before the last restart are already being replaced.
+	var restartedAt time.Time
+	if ts, ok := deployment.Spec.Template.Annotations[serving.RestartedAtAnnotationKey]; ok {
+		restartedAt, _ = time.Parse(time.RFC3339, ts)
+	}
+	var oldest time.Time
+	for _, pod := range pods {
+		created := pod.CreationTimestamp.Time
+		if pod.DeletionTimestamp != nil || created.Before(restartedAt) {
+			continue
+		}
+		if oldest.IsZero() || created.Before(oldest) {
+			oldest = created
+		}
+	}
+	if oldest.IsZero() {
+		return deployment, nil
+	}
+
+	now := c.clock.Now()
+	if age := now.Sub(oldest); age < maxLifetime {
+		c.enqueueAfter(rev, maxLifetime-age)
+		return deployment, nil
+	}
+
+	logging.FromContext(ctx).Infof("Pods created at %v exceeded the maximum lifetime of %v, restarting", oldest, maxLifetime)
+	want := deployment.DeepCopy()
+	want.Spec.Template.Annotations = kmeta.UnionMaps(want.Spec.Template.Annotations, map[string]string{
+		serving.RestartedAtAnnotationKey: now.UTC().Format(time.RFC3339),
+	})
+	return c.kubeclient.AppsV1().Deployments(want.Namespace).Update(want)
+}
+
 func (c *Reconciler) reconcileImageCache(ctx context.Context, rev *v1.Revision) error {
 	logger := logging.FromContext(ctx)
 
@@ -176,6 +404,182 @@ func (c *Reconciler) reconcilePA(ctx context.Context, rev *v1.Revision) error {
 	return nil
 }
 
+// reconcilePodOverhead reports in a status annotation the pod overhead of the
+// RuntimeClass of the revision, if any.
+func (c *Reconciler) reconcilePodOverhead(ctx context.Context, rev *v1.Revision) error {
+	var overhead corev1.ResourceList
+	if name := rev.Spec.RuntimeClassName; name != nil {
+		rc, err := c.runtimeClassLister.Get(*name)
+		switch {
+		case apierrs.IsNotFound(err):
+			// The pods are rejected by the API server, which surfaces
+			// through the deployment.
+		case err != nil:
+			return fmt.Errorf("failed to get RuntimeClass %q: %w", *name, err)
+		case rc.Overhead != nil:
+			overhead = rc.Overhead.PodFixed
+		}
+	}
+
+	if len(overhead) == 0 {
+		delete(rev.Status.Annotations, serving.PodOverheadAnnotationKey)
+		return nil
+	}
+	rev.Status.Annotations = kmeta.UnionMaps(rev.Status.Annotations,
+		map[string]string{serving.PodOverheadAnnotationKey: formatResourceList(overhead)})
+	return nil
+}
+
+// formatResourceList formats the resources as "name=quantity" pairs, sorted
+// by name and separated by commas.
+func formatResourceList(rl corev1.ResourceList) string {
+	pairs := make([]string, 0, len(rl))
+	for name, q := range rl {
+		pairs = append(pairs, string(name)+"="+q.String())
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// reconcileWorkloadRBAC reconciles the Role, and its RoleBinding to the
+// revision's service account, requested via annotation. Only the access
+// allowed in config-deployment is granted. Once the annotation is removed,
+// they are deleted again.
+func (c *Reconciler) reconcileWorkloadRBAC(ctx context.Context, rev *v1.Revision) error {
+	rules, ok := rev.GetWorkloadRBACRules()
+	if !ok {
+		return c.deleteWorkloadRBAC(ctx, rev)
+	}
+
+	if disallowed := config.FromContext(ctx).Deployment.DisallowedWorkloadRBAC(rules); len(disallowed) != 0 {
+		rev.Status.MarkResourcesAvailableFalse(v1.ReasonWorkloadRBACNotAllowed,
+			fmt.Sprintf("The access requested by the revision is not allowed: %s", strings.Join(disallowed, ", ")))
+		if err := c.deleteWorkloadRBAC(ctx, rev); err != nil {
+			return err
+		}
+		return fmt.Errorf("revision: %q requests access that is not allowed: %s", rev.Name, strings.Join(disallowed, ", "))
+	}
+
+	ns := rev.Namespace
+	name := resourcenames.WorkloadRBAC(rev)
+	logger := logging.FromContext(ctx)
+	role, err := c.roleLister.Roles(ns).Get(name)
+	if apierrs.IsNotFound(err) {
+		if _, err := c.createRole(rev, rules); err != nil {
+			return fmt.Errorf("failed to create Role %q: %w", name, err)
+		}
+		logger.Info("Created Role: ", name)
+	} else if err != nil {
+		return fmt.Errorf("failed to get Role %q: %w", name, err)
+	} else if !metav1.IsControlledBy(role, rev) {
+		rev.Status.MarkResourcesAvailableFalse(v1.ReasonNotOwned, v1.ResourceNotOwnedMessage("Role", name))
+		return fmt.Errorf("revision: %q does not own Role: %q", rev.Name, name)
+	} else if !equality.Semantic.DeepEqual(role.Rules, rules) {
+		want := role.DeepCopy()
+		want.Rules = rules
+		if _, err := c.kubeclient.RbacV1().Roles(ns).Update(want); err != nil {
+			return fmt.Errorf("failed to update Role %q: %w", name, err)
+		}
+	}
+
+	rb, err := c.roleBindingLister.RoleBindings(ns).Get(name)
+	if apierrs.IsNotFound(err) {
+		if _, err := c.createRoleBinding(rev); err != nil {
+			return fmt.Errorf("failed to create RoleBinding %q: %w", name, err)
+		}
+		logger.Info("Created RoleBinding: ", name)
+	} else if err != nil {
+		return fmt.Errorf("failed to get RoleBinding %q: %w", name, err)
+	} else if !metav1.IsControlledBy(rb, rev) {
+		rev.Status.MarkResourcesAvailableFalse(v1.ReasonNotOwned, v1.ResourceNotOwnedMessage("RoleBinding", name))
+		return fmt.Errorf("revision: %q does not own RoleBinding: %q", rev.Name, name)
+	} else if tmpl := resources.MakeRoleBinding(rev); !equality.Semantic.DeepEqual(rb.Subjects, tmpl.Subjects) {
+		// The RoleRef is immutable, but always names our own Role anyway.
+		want := rb.DeepCopy()
+		want.Subjects = tmpl.Subjects
+		if _, err := c.kubeclient.RbacV1().RoleBindings(ns).Update(want); err != nil {
+			return fmt.Errorf("failed to update RoleBinding %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// deleteWorkloadRBAC deletes the Role and RoleBinding made by
+// reconcileWorkloadRBAC, if any.
+func (c *Reconciler) deleteWorkloadRBAC(ctx context.Context, rev *v1.Revision) error {
+	ns := rev.Namespace
+	name := resourcenames.WorkloadRBAC(rev)
+	logger := logging.FromContext(ctx)
+	if rb, err := c.roleBindingLister.RoleBindings(ns).Get(name); err == nil && metav1.IsControlledBy(rb, rev) {
+		if err := c.kubeclient.RbacV1().RoleBindings(ns).Delete(name, &metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete RoleBinding %q: %w", name, err)
+		}
+		logger.Info("Deleted RoleBinding: ", name)
+	} else if err != nil && !apierrs.IsNotFound(err) {
+		return fmt.Errorf("failed to get RoleBinding %q: %w", name, err)
+	}
+
+	if role, err := c.roleLister.Roles(ns).Get(name); err == nil && metav1.IsControlledBy(role, rev) {
+		if err := c.kubeclient.RbacV1().Roles(ns).Delete(name, &metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete Role %q: %w", name, err)
+		}
+		logger.Info("Deleted Role: ", name)
+	} else if err != nil && !apierrs.IsNotFound(err) {
+		return fmt.Errorf("failed to get Role %q: %w", name, err)
+	}
+	return nil
+}
+
+// reconcileMetricsService reconciles the Service exposing the metrics ports
+// of the revision's queue-proxies, when enabled in config-deployment. Once
+// disabled, it is deleted again.
+func (c *Reconciler) reconcileMetricsService(ctx context.Context, rev *v1.Revision) error {
+	if !config.FromContext(ctx).Deployment.QueueSidecarMetricsService {
+		return c.deleteMetricsService(ctx, rev)
+	}
+
+	ns := rev.Namespace
+	name := resourcenames.MetricsService(rev)
+	svc, err := c.serviceLister.Services(ns).Get(name)
+	if apierrs.IsNotFound(err) {
+		if _, err := c.createMetricsService(rev); err != nil {
+			return fmt.Errorf("failed to create metrics Service %q: %w", name, err)
+		}
+		logging.FromContext(ctx).Info("Created metrics Service: ", name)
+	} else if err != nil {
+		return fmt.Errorf("failed to get metrics Service %q: %w", name, err)
+	} else if !metav1.IsControlledBy(svc, rev) {
+		rev.Status.MarkResourcesAvailableFalse(v1.ReasonNotOwned, v1.ResourceNotOwnedMessage("Service", name))
+		return fmt.Errorf("revision: %q does not own Service: %q", rev.Name, name)
+	} else if tmpl := resources.MakeMetricsService(rev); !equality.Semantic.DeepEqual(svc.Spec.Ports, tmpl.Spec.Ports) ||
+		!equality.Semantic.DeepEqual(svc.Spec.Selector, tmpl.Spec.Selector) {
+		// The ClusterIP is immutable, so only the ports and selector are updated.
+		want := svc.DeepCopy()
+		want.Spec.Ports = tmpl.Spec.Ports
+		want.Spec.Selector = tmpl.Spec.Selector
+		if _, err := c.kubeclient.CoreV1().Services(ns).Update(want); err != nil {
+			return fmt.Errorf("failed to update metrics Service %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// deleteMetricsService deletes the Service made by reconcileMetricsService,
+// if any.
+func (c *Reconciler) deleteMetricsService(ctx context.Context, rev *v1.Revision) error {
+	ns := rev.Namespace
+	name := resourcenames.MetricsService(rev)
+	if svc, err := c.serviceLister.Services(ns).Get(name); err == nil && metav1.IsControlledBy(svc, rev) {
+		if err := c.kubeclient.CoreV1().Services(ns).Delete(name, &metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete metrics Service %q: %w", name, err)
+		}
+		logging.FromContext(ctx).Info("Deleted the metrics Service: ", name)
+	} else if err != nil && !apierrs.IsNotFound(err) {
+		return fmt.Errorf("failed to get metrics Service %q: %w", name, err)
+	}
+	return nil
+}
+
 func hasDeploymentTimedOut(deployment *appsv1.Deployment) bool {
 	// as per https://kubernetes.io/docs/concepts/workloads/controllers/deployment
 	for _, cond := range deployment.Status.Conditions {
@@ -26,4 +26,9 @@ const (
 
 	// AppLabelKey is the label defining the application's name.
 	AppLabelKey = "app"
+
+	// DefaultRevisionHistoryLimit is the number of old ReplicaSets kept by the
+	// Deployment of a revision, unless the revision asks otherwise. Each
+	// revision has its own Deployment, so there is little history worth keeping.
+	DefaultRevisionHistoryLimit = 2
 )
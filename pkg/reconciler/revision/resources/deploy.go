@@ -18,6 +18,7 @@ package resources
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 
 	network "knative.dev/networking/pkg"
@@ -28,6 +29,7 @@ import (
 	"knative.dev/pkg/ptr"
 	tracingconfig "knative.dev/pkg/tracing/config"
 	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	asconfig "knative.dev/serving/pkg/autoscaler/config"
 	"knative.dev/serving/pkg/deployment"
@@ -73,6 +75,10 @@ func rewriteUserProbe(p *corev1.Probe, userPort int) {
 		return
 	}
 	switch {
+	case p.HTTPGet != nil && p.HTTPGet.Scheme == corev1.URISchemeHTTPS:
+		// The queue container only serves plain HTTP, so HTTPS probes are
+		// sent to the user container directly.
+		p.HTTPGet.Port = intstr.FromInt(userPort)
 	case p.HTTPGet != nil:
 		// For HTTP probes, we route them through the queue container
 		// so that we know the queue proxy is ready/live as well.
@@ -99,6 +105,25 @@ func makePodSpec(rev *v1.Revision, loggingConfig *logging.Config, tracingConfig
 
 	podSpec := BuildPodSpec(rev, append(BuildUserContainers(rev), *queueContainer))
 
+	// The pre-stop delay of the queue-proxy counts against the grace period,
+	// unless the Revision sets its grace period explicitly.
+	_, explicitGrace := rev.GetTerminationGracePeriodSeconds()
+	if deploymentConfig.PreStopDelay > 0 && !explicitGrace && podSpec.TerminationGracePeriodSeconds != nil {
+		podSpec.TerminationGracePeriodSeconds = ptr.Int64(*podSpec.TerminationGracePeriodSeconds +
+			int64(math.Ceil(deploymentConfig.PreStopDelay.Seconds())))
+	}
+
+	// The revision's own selector wins over the default one.
+	if len(deploymentConfig.DefaultNodeSelector) > 0 {
+		podSpec.NodeSelector = kmeta.UnionMaps(deploymentConfig.DefaultNodeSelector, podSpec.NodeSelector)
+	}
+
+	if pool, ok := rev.Annotations[serving.NodePoolAnnotationKey]; ok {
+		podSpec.NodeSelector = kmeta.UnionMaps(podSpec.NodeSelector, map[string]string{
+			deploymentConfig.NodePoolLabelKey: pool,
+		})
+	}
+
 	return podSpec, nil
 }
 
@@ -132,7 +157,16 @@ func makeContainer(container corev1.Container, rev *v1.Revision) corev1.Containe
 	varLogMount.SubPathExpr += container.Name
 
 	container.VolumeMounts = append(container.VolumeMounts, *varLogMount)
-	container.Lifecycle = userLifecycle
+	// The postStart hook of the user is kept next to our preStop hook.
+	if lc := container.Lifecycle; lc != nil && lc.PostStart != nil {
+		container.Lifecycle = &corev1.Lifecycle{
+			PostStart: lc.PostStart,
+			PreStop:   userLifecycle.PreStop,
+		}
+	} else {
+		container.Lifecycle = userLifecycle
+	}
+	container.Env = append(container.Env, getGoRuntimeEnvVar(&container, rev)...)
 	container.Env = append(container.Env, getKnativeEnvVar(rev)...)
 	container.Env = append(container.Env, buildVarLogSubpathEnvs()...)
 	// Explicitly disable stdin and tty allocation
@@ -152,7 +186,11 @@ func makeServingContainer(servingContainer corev1.Container, rev *v1.Revision) c
 	servingContainer.Env = append(servingContainer.Env, buildUserPortEnv(userPortStr))
 	container := makeContainer(servingContainer, rev)
 	if container.ReadinessProbe != nil {
-		if container.ReadinessProbe.HTTPGet != nil || container.ReadinessProbe.TCPSocket != nil {
+		if readinessPassthrough(rev) {
+			// The kubelet runs the probe against the user-container itself,
+			// so that it alone decides whether the pod is ready.
+			rewritePassthroughProbe(container.ReadinessProbe, int(userPort))
+		} else if container.ReadinessProbe.HTTPGet != nil || container.ReadinessProbe.TCPSocket != nil {
 			// HTTP and TCP ReadinessProbes are executed by the queue-proxy directly against the
 			// user-container instead of via kubelet.
 			container.ReadinessProbe = nil
@@ -163,12 +201,36 @@ func makeServingContainer(servingContainer corev1.Container, rev *v1.Revision) c
 	return container
 }
 
+// readinessPassthrough returns whether the readiness probe of the revision is
+// left to the kubelet rather than aggregated by the queue-proxy.
+func readinessPassthrough(rev *v1.Revision) bool {
+	passthrough, _ := strconv.ParseBool(rev.Annotations[serving.QueueSideCarReadinessPassthroughAnnotation])
+	return passthrough
+}
+
+// rewritePassthroughProbe points the probe at the user port, bypassing the
+// queue container.
+func rewritePassthroughProbe(p *corev1.Probe, userPort int) {
+	switch {
+	case p.HTTPGet != nil:
+		p.HTTPGet.Port = intstr.FromInt(userPort)
+	case p.TCPSocket != nil:
+		p.TCPSocket.Port = intstr.FromInt(userPort)
+	}
+}
+
 // BuildPodSpec creates a PodSpec from the given revision and containers.
 func BuildPodSpec(rev *v1.Revision, containers []corev1.Container) *corev1.PodSpec {
 	pod := rev.Spec.PodSpec.DeepCopy()
 	pod.Containers = containers
 	pod.Volumes = append([]corev1.Volume{varLogVolume}, rev.Spec.Volumes...)
 	pod.TerminationGracePeriodSeconds = rev.Spec.TimeoutSeconds
+	if grace, ok := rev.GetTerminationGracePeriodSeconds(); ok {
+		pod.TerminationGracePeriodSeconds = ptr.Int64(grace)
+	}
+	// Deployments only run pods that always restart, the restart policy of
+	// the Revision is a hint for the reporting of its container's exits.
+	pod.RestartPolicy = ""
 	return pod
 }
 
@@ -232,6 +294,11 @@ func MakeDeployment(rev *v1.Revision,
 		replicaCount, _ = strconv.Atoi(ann)
 	}
 
+	historyLimit, ok := rev.GetRevisionHistoryLimit()
+	if !ok {
+		historyLimit = DefaultRevisionHistoryLimit
+	}
+
 	labels := makeLabels(rev)
 	anns := makeAnnotations(rev)
 
@@ -247,6 +314,7 @@ func MakeDeployment(rev *v1.Revision,
 			Replicas:                ptr.Int32(int32(replicaCount)),
 			Selector:                makeSelector(rev),
 			ProgressDeadlineSeconds: ptr.Int32(int32(deploymentConfig.ProgressDeadline.Seconds())),
+			RevisionHistoryLimit:    ptr.Int32(historyLimit),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      labels,
@@ -210,6 +210,7 @@ var (
 				},
 			},
 			ProgressDeadlineSeconds: ptr.Int32(0),
+			RevisionHistoryLimit:    ptr.Int32(DefaultRevisionHistoryLimit),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
@@ -502,6 +503,50 @@ func TestMakePodSpec(t *testing.T) {
 					withEnvVar("USER_PORT", "8888"),
 					withEnvVar("SERVING_READINESS_PROBE", `{"tcpSocket":{"port":8888,"host":"127.0.0.1"}}`),
 				)}),
+	}, {
+		name: "termination message passed through",
+		rev: revision("bar", "foo",
+			withContainers([]corev1.Container{{
+				Name:                     servingContainerName,
+				Image:                    "busybox",
+				ReadinessProbe:           withTCPReadinessProbe(v1.DefaultUserPort),
+				TerminationMessagePath:   "/tmp/termination-log",
+				TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+			}}),
+			WithContainerStatuses([]v1.ContainerStatuses{{
+				ImageDigest: "busybox@sha256:deadbeef",
+			}}),
+		),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(func(container *corev1.Container) {
+					container.Image = "busybox@sha256:deadbeef"
+					container.TerminationMessagePath = "/tmp/termination-log"
+					container.TerminationMessagePolicy = corev1.TerminationMessageReadFile
+				}),
+				queueContainer(),
+			}),
+	}, {
+		name: "working directory passed through",
+		rev: revision("bar", "foo",
+			withContainers([]corev1.Container{{
+				Name:           servingContainerName,
+				Image:          "busybox",
+				ReadinessProbe: withTCPReadinessProbe(v1.DefaultUserPort),
+				WorkingDir:     "/srv/app",
+			}}),
+			WithContainerStatuses([]v1.ContainerStatuses{{
+				ImageDigest: "busybox@sha256:deadbeef",
+			}}),
+		),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(func(container *corev1.Container) {
+					container.Image = "busybox@sha256:deadbeef"
+					container.WorkingDir = "/srv/app"
+				}),
+				queueContainer(),
+			}),
 	}, {
 		name: "volumes passed through",
 		rev: revision("bar", "foo",
@@ -689,6 +734,74 @@ func TestMakePodSpec(t *testing.T) {
 					withEnvVar("SERVING_READINESS_PROBE", `{"tcpSocket":{"port":8080,"host":"127.0.0.1"}}`),
 				),
 			}),
+	}, {
+		name: "with http readiness probe passed through",
+		rev: revision("bar", "foo",
+			withContainers([]corev1.Container{{
+				Name:           servingContainerName,
+				Image:          "busybox",
+				ReadinessProbe: withHTTPReadinessProbe(v1.DefaultUserPort),
+			}}),
+			WithContainerStatuses([]v1.ContainerStatuses{{
+				ImageDigest: "busybox@sha256:deadbeef",
+			}}),
+			WithRevisionAnn(serving.QueueSideCarReadinessPassthroughAnnotation, "true"),
+		),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(func(container *corev1.Container) {
+					container.Image = "busybox@sha256:deadbeef"
+					container.ReadinessProbe = withHTTPReadinessProbe(v1.DefaultUserPort)
+				}),
+				queueContainer(
+					withEnvVar("SERVING_READINESS_PROBE", `{"tcpSocket":{"port":8080,"host":"127.0.0.1"}}`),
+				),
+			}),
+	}, {
+		name: "with tcp readiness probe passed through",
+		rev: revision("bar", "foo",
+			withContainers([]corev1.Container{{
+				Name:           servingContainerName,
+				Image:          "busybox",
+				ReadinessProbe: withTCPReadinessProbe(12345),
+			}}),
+			WithContainerStatuses([]v1.ContainerStatuses{{
+				ImageDigest: "busybox@sha256:deadbeef",
+			}}),
+			WithRevisionAnn(serving.QueueSideCarReadinessPassthroughAnnotation, "true"),
+		),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(func(container *corev1.Container) {
+					container.Image = "busybox@sha256:deadbeef"
+					container.ReadinessProbe = withTCPReadinessProbe(v1.DefaultUserPort)
+				}),
+				queueContainer(
+					withEnvVar("SERVING_READINESS_PROBE", `{"tcpSocket":{"port":8080,"host":"127.0.0.1"}}`),
+				),
+			}),
+	}, {
+		name: "with readiness passthrough disabled",
+		rev: revision("bar", "foo",
+			withContainers([]corev1.Container{{
+				Name:           servingContainerName,
+				Image:          "busybox",
+				ReadinessProbe: withHTTPReadinessProbe(v1.DefaultUserPort),
+			}}),
+			WithContainerStatuses([]v1.ContainerStatuses{{
+				ImageDigest: "busybox@sha256:deadbeef",
+			}}),
+			WithRevisionAnn(serving.QueueSideCarReadinessPassthroughAnnotation, "false"),
+		),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(func(container *corev1.Container) {
+					container.Image = "busybox@sha256:deadbeef"
+				}),
+				queueContainer(
+					withEnvVar("SERVING_READINESS_PROBE", `{"httpGet":{"path":"/","port":8080,"host":"127.0.0.1","scheme":"HTTP","httpHeaders":[{"name":"K-Kubelet-Probe","value":"queue"}]}}`),
+				),
+			}),
 	}, {
 		name: "with HTTP liveness probe",
 		rev: revision("bar", "foo",
@@ -727,6 +840,106 @@ func TestMakePodSpec(t *testing.T) {
 				),
 				queueContainer(),
 			}),
+	}, {
+		name: "with HTTPS liveness probe",
+		rev: revision("bar", "foo",
+			withContainers([]corev1.Container{{
+				Name:           servingContainerName,
+				Image:          "busybox",
+				ReadinessProbe: withTCPReadinessProbe(v1.DefaultUserPort),
+				LivenessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{
+							Path:   "/",
+							Scheme: corev1.URISchemeHTTPS,
+						},
+					},
+				},
+			}}),
+			WithContainerStatuses([]v1.ContainerStatuses{{
+				ImageDigest: "busybox@sha256:deadbeef",
+			}}),
+		),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(
+					func(container *corev1.Container) {
+						container.Image = "busybox@sha256:deadbeef"
+					},
+					withLivenessProbe(corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{
+							Path:   "/",
+							Port:   intstr.FromInt(v1.DefaultUserPort),
+							Scheme: corev1.URISchemeHTTPS,
+						},
+					}),
+				),
+				queueContainer(),
+			}),
+	}, {
+		name: "with HTTPS readiness probe",
+		rev: revision("bar", "foo",
+			withContainers([]corev1.Container{{
+				Name:  servingContainerName,
+				Image: "busybox",
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{
+							Path:   "/",
+							Port:   intstr.FromInt(v1.DefaultUserPort),
+							Scheme: corev1.URISchemeHTTPS,
+						},
+					},
+				},
+			}}),
+			WithContainerStatuses([]v1.ContainerStatuses{{
+				ImageDigest: "busybox@sha256:deadbeef",
+			}}),
+		),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(func(container *corev1.Container) {
+					container.Image = "busybox@sha256:deadbeef"
+				}),
+				queueContainer(
+					withEnvVar("SERVING_READINESS_PROBE", `{"httpGet":{"path":"/","port":8080,"host":"127.0.0.1","scheme":"HTTPS","httpHeaders":[{"name":"K-Kubelet-Probe","value":"queue"}]}}`),
+				),
+			}),
+	}, {
+		name: "with postStart hook",
+		rev: revision("bar", "foo",
+			withContainers([]corev1.Container{{
+				Name:           servingContainerName,
+				Image:          "busybox",
+				ReadinessProbe: withTCPReadinessProbe(v1.DefaultUserPort),
+				Lifecycle: &corev1.Lifecycle{
+					PostStart: &corev1.Handler{
+						Exec: &corev1.ExecAction{
+							Command: []string{"/signal", "started"},
+						},
+					},
+				},
+			}}),
+			WithContainerStatuses([]v1.ContainerStatuses{{
+				ImageDigest: "busybox@sha256:deadbeef",
+			}}),
+		),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(func(container *corev1.Container) {
+					container.Image = "busybox@sha256:deadbeef"
+					// The preStop hook draining through the queue-proxy is kept.
+					container.Lifecycle = &corev1.Lifecycle{
+						PostStart: &corev1.Handler{
+							Exec: &corev1.ExecAction{
+								Command: []string{"/signal", "started"},
+							},
+						},
+						PreStop: userLifecycle.PreStop,
+					}
+				}),
+				queueContainer(),
+			}),
 	}, {
 		name: "with tcp liveness probe",
 		rev: revision("bar", "foo",
@@ -938,6 +1151,164 @@ func TestMakePodSpec(t *testing.T) {
 				p.EnableServiceLinks = ptr.Bool(false)
 			},
 		),
+	}, {
+		name: "with automountServiceAccountToken false",
+		rev: revision("bar", "foo",
+			withContainers([]corev1.Container{{
+				Name:  servingContainerName,
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8080,
+				}},
+				ReadinessProbe: withTCPReadinessProbe(v1.DefaultUserPort),
+			}}),
+			func(r *v1.Revision) {
+				r.Spec.AutomountServiceAccountToken = ptr.Bool(false)
+			}),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(
+					withEnvVar("PORT", "8080"),
+					withEnvVar("K_REVISION", "bar"),
+				),
+				queueContainer(
+					withEnvVar("USER_PORT", "8080"),
+					withEnvVar("SERVING_READINESS_PROBE", `{"tcpSocket":{"port":8080,"host":"127.0.0.1"}}`),
+				),
+			},
+			func(p *corev1.PodSpec) {
+				p.AutomountServiceAccountToken = ptr.Bool(false)
+			},
+		),
+	}, {
+		name: "host aliases passed through",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(r *v1.Revision) {
+				r.Spec.HostAliases = []corev1.HostAlias{{
+					IP:        "10.0.0.1",
+					Hostnames: []string{"legacy.example.com"},
+				}}
+			}),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(),
+				queueContainer(),
+			},
+			func(p *corev1.PodSpec) {
+				p.HostAliases = []corev1.HostAlias{{
+					IP:        "10.0.0.1",
+					Hostnames: []string{"legacy.example.com"},
+				}}
+			},
+		),
+	}, {
+		name: "priority class name passed through",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(r *v1.Revision) {
+				r.Spec.PriorityClassName = "critical"
+			}),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(),
+				queueContainer(),
+			},
+			func(p *corev1.PodSpec) {
+				p.PriorityClassName = "critical"
+			},
+		),
+	}, {
+		name: "runtime class name passed through",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(r *v1.Revision) {
+				r.Spec.RuntimeClassName = ptr.String("gvisor")
+			}),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(),
+				queueContainer(),
+			},
+			func(p *corev1.PodSpec) {
+				p.RuntimeClassName = ptr.String("gvisor")
+			},
+		),
+	}, {
+		name: "share process namespace passed through",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(r *v1.Revision) {
+				r.Spec.ShareProcessNamespace = ptr.Bool(true)
+			}),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(),
+				queueContainer(),
+			},
+			func(p *corev1.PodSpec) {
+				p.ShareProcessNamespace = ptr.Bool(true)
+			},
+		),
+	}, {
+		name: "restart policy not passed through",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(r *v1.Revision) {
+				r.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
+			}),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(),
+				queueContainer(),
+			},
+		),
+	}, {
+		name: "fsGroup passed through",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(r *v1.Revision) {
+				r.Spec.SecurityContext = &corev1.PodSecurityContext{
+					FSGroup: ptr.Int64(2000),
+				}
+			}),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(),
+				queueContainer(),
+			},
+			func(p *corev1.PodSpec) {
+				p.SecurityContext = &corev1.PodSecurityContext{
+					FSGroup: ptr.Int64(2000),
+				}
+			},
+		),
+	}, {
+		name: "sysctls passed through",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(r *v1.Revision) {
+				r.Spec.SecurityContext = &corev1.PodSecurityContext{
+					Sysctls: []corev1.Sysctl{{
+						Name:  "net.ipv4.tcp_syncookies",
+						Value: "1",
+					}},
+				}
+			}),
+		want: podSpec(
+			[]corev1.Container{
+				servingContainer(),
+				queueContainer(),
+			},
+			func(p *corev1.PodSpec) {
+				p.SecurityContext = &corev1.PodSecurityContext{
+					Sysctls: []corev1.Sysctl{{
+						Name:  "net.ipv4.tcp_syncookies",
+						Value: "1",
+					}},
+				}
+			},
+		),
 	}}
 
 	for _, test := range tests {
@@ -953,6 +1324,156 @@ func TestMakePodSpec(t *testing.T) {
 	}
 }
 
+func TestMakePodSpecNodePool(t *testing.T) {
+	rev := revision("bar", "foo",
+		withContainers(containers),
+		WithRevisionAnn(serving.NodePoolAnnotationKey, "gpu-pool"),
+	)
+	rev.Spec.NodeSelector = map[string]string{"disktype": "ssd"}
+
+	cfg := deploymentConfig
+	cfg.NodePoolLabelKey = "cloud.google.com/gke-nodepool"
+	got, err := makePodSpec(rev, &logConfig, &traceConfig, &obsConfig, &cfg)
+	if err != nil {
+		t.Fatal("makePodSpec returned error:", err)
+	}
+
+	want := map[string]string{
+		"disktype":                      "ssd",
+		"cloud.google.com/gke-nodepool": "gpu-pool",
+	}
+	if !cmp.Equal(got.NodeSelector, want) {
+		t.Error("NodeSelector (-want, +got):", cmp.Diff(want, got.NodeSelector))
+	}
+	// The revision's own spec must not be modified.
+	if got, want := len(rev.Spec.NodeSelector), 1; got != want {
+		t.Errorf("len(rev.Spec.NodeSelector) = %d, want: %d", got, want)
+	}
+}
+
+func TestMakePodSpecDefaultNodeSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults map[string]string
+		selector map[string]string
+		pool     string
+		want     map[string]string
+	}{{
+		name:     "no default",
+		selector: map[string]string{"disktype": "ssd"},
+		want:     map[string]string{"disktype": "ssd"},
+	}, {
+		name:     "default only",
+		defaults: map[string]string{"node-role.kubernetes.io/worker": "true"},
+		want:     map[string]string{"node-role.kubernetes.io/worker": "true"},
+	}, {
+		name:     "merged with the revision's",
+		defaults: map[string]string{"node-role.kubernetes.io/worker": "true"},
+		selector: map[string]string{"disktype": "ssd"},
+		want: map[string]string{
+			"node-role.kubernetes.io/worker": "true",
+			"disktype":                       "ssd",
+		},
+	}, {
+		name:     "revision's wins",
+		defaults: map[string]string{"node-role.kubernetes.io/worker": "true", "disktype": "hdd"},
+		selector: map[string]string{"disktype": "ssd"},
+		want: map[string]string{
+			"node-role.kubernetes.io/worker": "true",
+			"disktype":                       "ssd",
+		},
+	}, {
+		name:     "node pool wins",
+		defaults: map[string]string{"knative.dev/node-pool": "default-pool"},
+		pool:     "gpu-pool",
+		want:     map[string]string{"knative.dev/node-pool": "gpu-pool"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := revision("bar", "foo", withContainers(containers))
+			if test.pool != "" {
+				rev.Annotations = map[string]string{serving.NodePoolAnnotationKey: test.pool}
+			}
+			rev.Spec.NodeSelector = test.selector
+
+			cfg := deploymentConfig
+			cfg.NodePoolLabelKey = "knative.dev/node-pool"
+			cfg.DefaultNodeSelector = test.defaults
+			got, err := makePodSpec(rev, &logConfig, &traceConfig, &obsConfig, &cfg)
+			if err != nil {
+				t.Fatal("makePodSpec returned error:", err)
+			}
+			if !cmp.Equal(got.NodeSelector, test.want) {
+				t.Error("NodeSelector (-want, +got):", cmp.Diff(test.want, got.NodeSelector))
+			}
+			// Neither the revision nor the config are modified.
+			if !cmp.Equal(rev.Spec.NodeSelector, test.selector) {
+				t.Errorf("rev.Spec.NodeSelector = %v, want: %v", rev.Spec.NodeSelector, test.selector)
+			}
+			if len(test.defaults) > 0 && len(cfg.DefaultNodeSelector) != len(test.defaults) {
+				t.Errorf("DefaultNodeSelector = %v, want: %v", cfg.DefaultNodeSelector, test.defaults)
+			}
+		})
+	}
+}
+
+func TestMakePodSpecPreStopDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		delay     time.Duration
+		grace     string
+		wantHook  bool
+		wantGrace int64
+	}{{
+		name:      "no delay",
+		wantGrace: 45,
+	}, {
+		name:      "with delay",
+		delay:     10 * time.Second,
+		wantHook:  true,
+		wantGrace: 55,
+	}, {
+		name:      "with sub-second delay",
+		delay:     1500 * time.Millisecond,
+		wantHook:  true,
+		wantGrace: 47,
+	}, {
+		name:      "with grace period annotation",
+		grace:     "900",
+		wantGrace: 900,
+	}, {
+		name:      "with grace period annotation and delay",
+		delay:     10 * time.Second,
+		grace:     "900",
+		wantHook:  true,
+		wantGrace: 900,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := revision("bar", "foo", withContainers(containers))
+			if test.grace != "" {
+				rev.Annotations = map[string]string{serving.TerminationGracePeriodAnnotationKey: test.grace}
+			}
+			cfg := deploymentConfig
+			cfg.PreStopDelay = test.delay
+			got, err := makePodSpec(rev, &logConfig, &traceConfig, &obsConfig, &cfg)
+			if err != nil {
+				t.Fatal("makePodSpec returned error:", err)
+			}
+
+			queue := got.Containers[len(got.Containers)-1]
+			if hasHook := queue.Lifecycle != nil && queue.Lifecycle.PreStop != nil; hasHook != test.wantHook {
+				t.Errorf("queue-proxy has PreStop hook = %v, want: %v", hasHook, test.wantHook)
+			}
+			if got := *got.TerminationGracePeriodSeconds; got != test.wantGrace {
+				t.Errorf("TerminationGracePeriodSeconds = %d, want: %d", got, test.wantGrace)
+			}
+		})
+	}
+}
+
 var quantityComparer = cmp.Comparer(func(x, y resource.Quantity) bool {
 	return x.Cmp(y) == 0
 })
@@ -1046,6 +1567,23 @@ func TestMakeDeployment(t *testing.T) {
 		want: appsv1deployment(func(deploy *appsv1.Deployment) {
 			deploy.Spec.ProgressDeadlineSeconds = ptr.Int32(42)
 		}),
+	}, {
+		name: "with revisionHistoryLimit annotation",
+		rev: revision("bar", "foo",
+			withContainers([]corev1.Container{{
+				Name:           servingContainerName,
+				Image:          "ubuntu",
+				ReadinessProbe: withTCPReadinessProbe(12345),
+			}}),
+			WithContainerStatuses([]v1.ContainerStatuses{{
+				ImageDigest: "busybox@sha256:deadbeef",
+			}}), withoutLabels,
+			WithRevisionAnn(serving.RevisionHistoryLimitAnnotationKey, "0")),
+		want: appsv1deployment(func(deploy *appsv1.Deployment) {
+			deploy.Spec.RevisionHistoryLimit = ptr.Int32(0)
+			deploy.Annotations = map[string]string{serving.RevisionHistoryLimitAnnotationKey: "0"}
+			deploy.Spec.Template.Annotations = map[string]string{serving.RevisionHistoryLimitAnnotationKey: "0"}
+		}),
 	}, {
 		name: "cluster initial scale",
 		acMutator: func(ac *asconfig.Config) {
@@ -1114,3 +1652,92 @@ func TestMakeDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestGoRuntimeEnvVar(t *testing.T) {
+	limits := func(cpu, memory string) corev1.ResourceList {
+		rl := corev1.ResourceList{}
+		if cpu != "" {
+			rl[corev1.ResourceCPU] = resource.MustParse(cpu)
+		}
+		if memory != "" {
+			rl[corev1.ResourceMemory] = resource.MustParse(memory)
+		}
+		return rl
+	}
+
+	tests := []struct {
+		name       string
+		annotation string
+		limits     corev1.ResourceList
+		env        []corev1.EnvVar
+		want       []corev1.EnvVar
+	}{{
+		name:   "no annotation",
+		limits: limits("2", "1Gi"),
+	}, {
+		name:       "annotation disabled",
+		annotation: "false",
+		limits:     limits("2", "1Gi"),
+	}, {
+		name:       "no limits",
+		annotation: "true",
+	}, {
+		name:       "whole cpus",
+		annotation: "true",
+		limits:     limits("2", ""),
+		want:       []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "2"}},
+	}, {
+		name:       "fractional cpu under one",
+		annotation: "true",
+		limits:     limits("500m", ""),
+		want:       []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "1"}},
+	}, {
+		name:       "fractional cpu over one",
+		annotation: "true",
+		limits:     limits("1500m", ""),
+		want:       []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "2"}},
+	}, {
+		name:       "memory",
+		annotation: "true",
+		limits:     limits("", "1Gi"),
+		want:       []corev1.EnvVar{{Name: "GOMEMLIMIT", Value: "966367641"}},
+	}, {
+		name:       "cpu and memory",
+		annotation: "true",
+		limits:     limits("250m", "100M"),
+		want: []corev1.EnvVar{
+			{Name: "GOMAXPROCS", Value: "1"},
+			{Name: "GOMEMLIMIT", Value: "90000000"},
+		},
+	}, {
+		name:       "zero limits",
+		annotation: "true",
+		limits:     limits("0", "0"),
+	}, {
+		name:       "user set values are kept",
+		annotation: "true",
+		limits:     limits("4", "1Gi"),
+		env:        []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "8"}},
+		want:       []corev1.EnvVar{{Name: "GOMEMLIMIT", Value: "966367641"}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1.Revision{}
+			if test.annotation != "" {
+				rev.Annotations = map[string]string{
+					serving.GoRuntimeEnvAnnotationKey: test.annotation,
+				}
+			}
+			container := &corev1.Container{
+				Env: test.env,
+				Resources: corev1.ResourceRequirements{
+					Limits: test.limits,
+				},
+			}
+			if got := getGoRuntimeEnvVar(container, rev); !cmp.Equal(got, test.want) {
+				t.Error("getGoRuntimeEnvVar (-want, +got):", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package resources
 
 import (
+	"strconv"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 )
@@ -26,8 +29,15 @@ const (
 	knativeRevisionEnvVariableKey      = "K_REVISION"
 	knativeConfigurationEnvVariableKey = "K_CONFIGURATION"
 	knativeServiceEnvVariableKey       = "K_SERVICE"
+
+	goMaxProcsEnvVariableKey = "GOMAXPROCS"
+	goMemLimitEnvVariableKey = "GOMEMLIMIT"
 )
 
+// goMemLimitRatio is the share of the memory limit of a container that its
+// Go runtime is asked to stay under, leaving room for the non-heap memory.
+const goMemLimitRatio = 0.9
+
 func getKnativeEnvVar(rev *v1.Revision) []corev1.EnvVar {
 	return []corev1.EnvVar{{
 		Name:  knativeRevisionEnvVariableKey,
@@ -40,3 +50,34 @@ func getKnativeEnvVar(rev *v1.Revision) []corev1.EnvVar {
 		Value: rev.Labels[serving.ServiceLabelKey],
 	}}
 }
+
+// getGoRuntimeEnvVar returns the GOMAXPROCS and GOMEMLIMIT environment
+// variables derived from the CPU and memory limits of the container, when its
+// Revision opts in via annotation. The variables the user set are left alone.
+func getGoRuntimeEnvVar(container *corev1.Container, rev *v1.Revision) []corev1.EnvVar {
+	if enabled, _ := strconv.ParseBool(rev.Annotations[serving.GoRuntimeEnvAnnotationKey]); !enabled {
+		return nil
+	}
+
+	userSet := sets.NewString()
+	for _, env := range container.Env {
+		userSet.Insert(env.Name)
+	}
+
+	var envs []corev1.EnvVar
+	if cpu, ok := container.Resources.Limits[corev1.ResourceCPU]; ok && !cpu.IsZero() && !userSet.Has(goMaxProcsEnvVariableKey) {
+		// The Go runtime only uses whole CPUs, so fractions are rounded up.
+		procs := (cpu.MilliValue() + 999) / 1000
+		envs = append(envs, corev1.EnvVar{
+			Name:  goMaxProcsEnvVariableKey,
+			Value: strconv.FormatInt(procs, 10),
+		})
+	}
+	if memory, ok := container.Resources.Limits[corev1.ResourceMemory]; ok && !memory.IsZero() && !userSet.Has(goMemLimitEnvVariableKey) {
+		envs = append(envs, corev1.EnvVar{
+			Name:  goMemLimitEnvVariableKey,
+			Value: strconv.FormatInt(int64(float64(memory.Value())*goMemLimitRatio), 10),
+		})
+	}
+	return envs
+}
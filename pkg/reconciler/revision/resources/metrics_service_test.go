@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/serving"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestMakeMetricsService(t *testing.T) {
+	rev := &v1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+			UID:       "1234",
+			Labels: map[string]string{
+				serving.ConfigurationLabelKey: "baz",
+			},
+			Annotations: map[string]string{
+				"a": "b",
+			},
+		},
+	}
+
+	want := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar-metrics",
+			Labels: map[string]string{
+				serving.ConfigurationLabelKey: "baz",
+				serving.RevisionLabelKey:      "bar",
+				serving.RevisionUID:           "1234",
+				AppLabelKey:                   "bar",
+			},
+			Annotations: map[string]string{
+				"a": "b",
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         v1.SchemeGroupVersion.String(),
+				Kind:               "Revision",
+				Name:               "bar",
+				UID:                "1234",
+				Controller:         ptr.Bool(true),
+				BlockOwnerDeletion: ptr.Bool(true),
+			}},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:       "http-autometric",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       9090,
+				TargetPort: intstr.FromString("http-autometric"),
+			}, {
+				Name:       "http-usermetric",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       9091,
+				TargetPort: intstr.FromString("http-usermetric"),
+			}},
+			Selector: map[string]string{
+				serving.RevisionUID: "1234",
+			},
+		},
+	}
+
+	if got := MakeMetricsService(rev); !cmp.Equal(got, want) {
+		t.Errorf("MakeMetricsService() = (-want, +got): %s", cmp.Diff(want, got))
+	}
+}
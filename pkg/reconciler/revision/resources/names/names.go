@@ -32,3 +32,15 @@ func ImageCache(rev kmeta.Accessor) string {
 func PA(rev kmeta.Accessor) string {
 	return rev.GetName()
 }
+
+// MetricsService returns the name of the Service exposing the queue-proxy
+// metrics ports of the revision.
+func MetricsService(rev kmeta.Accessor) string {
+	return kmeta.ChildName(rev.GetName(), "-metrics")
+}
+
+// WorkloadRBAC returns the name of the Role, and of its RoleBinding, granted
+// to the revision's service account.
+func WorkloadRBAC(rev kmeta.Accessor) string {
+	return kmeta.ChildName(rev.GetName(), "-workload")
+}
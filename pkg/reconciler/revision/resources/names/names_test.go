@@ -86,6 +86,24 @@ func TestNamer(t *testing.T) {
 		},
 		f:    ImageCache,
 		want: "foo-cache",
+	}, {
+		name: "MetricsService",
+		rev: &v1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "foo",
+			},
+		},
+		f:    MetricsService,
+		want: "foo-metrics",
+	}, {
+		name: "WorkloadRBAC",
+		rev: &v1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "foo",
+			},
+		},
+		f:    WorkloadRBAC,
+		want: "foo-workload",
 	}, {
 		name: "PA",
 		rev: &v1.Revision{
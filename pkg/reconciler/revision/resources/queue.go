@@ -107,6 +107,18 @@ func createQueueResources(cfg *deployment.Config, annotations map[string]string,
 		}
 	}
 
+	// The resource profile selected by the revision replaces the defaults,
+	// which are kept when the profile doesn't exist.
+	if profile, ok := cfg.QueueSidecarResourceProfiles[annotations[serving.QueueSideCarResourceProfileAnnotation]]; ok {
+		resourceRequests, resourceLimits = corev1.ResourceList{}, corev1.ResourceList{}
+		for name, q := range profile.Requests {
+			resourceRequests[name] = q.DeepCopy()
+		}
+		for name, q := range profile.Limits {
+			resourceLimits[name] = q.DeepCopy()
+		}
+	}
+
 	var requestCPU, limitCPU, requestMemory, limitMemory resource.Quantity
 
 	if resourceFraction, ok := fractionFromPercentage(annotations, serving.QueueSideCarResourcePercentageAnnotation); ok {
@@ -232,6 +244,10 @@ func makeQueueContainer(rev *v1.Revision, loggingConfig *logging.Config, tracing
 	if ll, ok := loggingConfig.LoggingLevel["queueproxy"]; ok {
 		loggingLevel = ll.String()
 	}
+	// The annotation bumps the level of a single revision, e.g. to debug it.
+	if ll, ok := rev.Annotations[serving.QueueSideCarLogLevelAnnotation]; ok {
+		loggingLevel = ll
+	}
 
 	ts := int64(0)
 	if rev.Spec.TimeoutSeconds != nil {
@@ -252,6 +268,15 @@ func makeQueueContainer(rev *v1.Revision, loggingConfig *logging.Config, tracing
 
 	container := rev.Spec.GetContainer()
 	rp := container.ReadinessProbe.DeepCopy()
+	if readinessPassthrough(rev) {
+		// The kubelet runs the probe of the user-container, the queue-proxy
+		// only waits for it to accept connections.
+		rp = &corev1.Probe{
+			Handler: corev1.Handler{
+				TCPSocket: &corev1.TCPSocketAction{},
+			},
+		}
+	}
 
 	applyReadinessProbeDefaults(rp, userPort)
 
@@ -260,12 +285,13 @@ func makeQueueContainer(rev *v1.Revision, loggingConfig *logging.Config, tracing
 		return nil, fmt.Errorf("failed to serialize readiness probe: %w", err)
 	}
 
-	return &corev1.Container{
+	c := &corev1.Container{
 		Name:            QueueContainerName,
 		Image:           deploymentConfig.QueueSidecarImage,
 		Resources:       createQueueResources(deploymentConfig, rev.GetAnnotations(), container),
 		Ports:           ports,
 		ReadinessProbe:  makeQueueProbe(rp),
+		Lifecycle:       makeQueueLifecycle(deploymentConfig),
 		SecurityContext: queueSecurityContext,
 		Env: []corev1.EnvVar{{
 			Name:  "SERVING_NAMESPACE",
@@ -351,7 +377,58 @@ func makeQueueContainer(rev *v1.Revision, loggingConfig *logging.Config, tracing
 			Name:  "SERVING_ENABLE_PROBE_REQUEST_LOG",
 			Value: strconv.FormatBool(observabilityConfig.EnableProbeRequestLog),
 		}},
-	}, nil
+	}
+
+	// The optional settings are only passed when they differ from the default
+	// of the queue-proxy, so that introducing them doesn't roll out every
+	// existing revision.
+	if deploymentConfig.PreStopDelay > 0 {
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  "PRE_STOP_DELAY",
+			Value: deploymentConfig.PreStopDelay.String(),
+		})
+	}
+	if deploymentConfig.QueueSidecarImmediateContinue {
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  "IMMEDIATE_CONTINUE",
+			Value: "true",
+		})
+	}
+	if deploymentConfig.QueueSidecarSamePodRetries > 0 {
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  "SAME_POD_RETRIES",
+			Value: strconv.Itoa(int(deploymentConfig.QueueSidecarSamePodRetries)),
+		})
+		if deploymentConfig.QueueSidecarSamePodRetryBackoff != deployment.QueueSidecarSamePodRetryBackoffDefault {
+			c.Env = append(c.Env, corev1.EnvVar{
+				Name:  "SAME_POD_RETRY_BACKOFF",
+				Value: deploymentConfig.QueueSidecarSamePodRetryBackoff.String(),
+			})
+		}
+	}
+	if warmup, ok := rev.GetConcurrencyWarmup(); ok {
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  "CONCURRENCY_WARMUP",
+			Value: warmup.String(),
+		})
+	}
+	return c, nil
+}
+
+// makeQueueLifecycle returns the PreStop hook holding the queue-proxy for the
+// configured pre-stop delay, if any.
+func makeQueueLifecycle(cfg *deployment.Config) *corev1.Lifecycle {
+	if cfg.PreStopDelay <= 0 {
+		return nil
+	}
+	return &corev1.Lifecycle{
+		PreStop: &corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Port: intstr.FromInt(networking.QueueAdminPort),
+				Path: queue.RequestQueuePreStopPath,
+			},
+		},
+	}
 }
 
 func applyReadinessProbeDefaults(p *corev1.Probe, port int32) {
@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"go.uber.org/zap/zapcore"
@@ -45,6 +46,7 @@ import (
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	asconfig "knative.dev/serving/pkg/autoscaler/config"
 	"knative.dev/serving/pkg/deployment"
+	"knative.dev/serving/pkg/queue"
 )
 
 var (
@@ -94,6 +96,81 @@ func TestMakeQueueContainer(t *testing.T) {
 				"CONTAINER_CONCURRENCY": "1",
 			})
 		}),
+	}, {
+		name: "pre-stop delay",
+		rev: revision("bar", "foo",
+			withContainers(containers)),
+		dc: deployment.Config{
+			PreStopDelay: 10 * time.Second,
+		},
+		want: queueContainer(func(c *corev1.Container) {
+			c.Lifecycle = &corev1.Lifecycle{
+				PreStop: &corev1.Handler{
+					HTTPGet: &corev1.HTTPGetAction{
+						Port: intstr.FromInt(networking.QueueAdminPort),
+						Path: queue.RequestQueuePreStopPath,
+					},
+				},
+			}
+			c.Env = env(map[string]string{
+				"PRE_STOP_DELAY": "10s",
+			})
+		}),
+	}, {
+		name: "immediate continue",
+		rev: revision("bar", "foo",
+			withContainers(containers)),
+		dc: deployment.Config{
+			QueueSidecarImmediateContinue: true,
+		},
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{
+				"IMMEDIATE_CONTINUE": "true",
+			})
+		}),
+	}, {
+		name: "same pod retries",
+		rev: revision("bar", "foo",
+			withContainers(containers)),
+		dc: deployment.Config{
+			QueueSidecarSamePodRetries:      2,
+			QueueSidecarSamePodRetryBackoff: 25 * time.Millisecond,
+		},
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{
+				"SAME_POD_RETRIES":       "2",
+				"SAME_POD_RETRY_BACKOFF": "25ms",
+			})
+		}),
+	}, {
+		name: "same pod retries with the default backoff",
+		rev: revision("bar", "foo",
+			withContainers(containers)),
+		dc: deployment.Config{
+			QueueSidecarSamePodRetries:      2,
+			QueueSidecarSamePodRetryBackoff: deployment.QueueSidecarSamePodRetryBackoffDefault,
+		},
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{
+				"SAME_POD_RETRIES": "2",
+			})
+		}),
+	}, {
+		name: "concurrency warmup",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			withContainerConcurrency(10),
+			func(revision *v1.Revision) {
+				revision.Annotations = map[string]string{
+					serving.ConcurrencyWarmupAnnotationKey: "1m",
+				}
+			}),
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{
+				"CONTAINER_CONCURRENCY": "10",
+				"CONCURRENCY_WARMUP":    "1m0s",
+			})
+		}),
 	}, {
 		name: "custom sidecar image, container port, protocol",
 		rev: revision("bar", "foo",
@@ -170,6 +247,27 @@ func TestMakeQueueContainer(t *testing.T) {
 				"SERVING_REVISION":       "this",
 			})
 		}),
+	}, {
+		name: "log level annotation",
+		rev: revision("this", "log",
+			withContainers(containers),
+			func(revision *v1.Revision) {
+				revision.Annotations = map[string]string{
+					serving.QueueSideCarLogLevelAnnotation: "debug",
+				}
+			}),
+		lc: logging.Config{
+			LoggingLevel: map[string]zapcore.Level{
+				"queueproxy": zapcore.ErrorLevel,
+			},
+		},
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{
+				"SERVING_LOGGING_LEVEL": "debug",
+				"SERVING_NAMESPACE":     "log",
+				"SERVING_REVISION":      "this",
+			})
+		}),
 	}, {
 		name: "container concurrency 10",
 		rev: revision("bar", "foo",
@@ -436,6 +534,85 @@ func TestMakeQueueContainerWithPercentageAnnotation(t *testing.T) {
 	}
 }
 
+func TestMakeQueueContainerWithResourceProfile(t *testing.T) {
+	dc := deployment.Config{
+		QueueSidecarCPURequest: resourcePtr(resource.MustParse("25m")),
+		QueueSidecarResourceProfiles: map[string]corev1.ResourceRequirements{
+			"large": {
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("100Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("500Mi"),
+				},
+			},
+		},
+	}
+	withProfile := func(profile string) func(*v1.Revision) {
+		return func(revision *v1.Revision) {
+			revision.Annotations = map[string]string{
+				serving.QueueSideCarResourceProfileAnnotation: profile,
+			}
+		}
+	}
+
+	tests := []struct {
+		name string
+		rev  *v1.Revision
+		want corev1.Container
+	}{{
+		name: "no profile",
+		rev:  revision("bar", "foo", withContainers(containers)),
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{})
+			c.Resources.Requests = corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("25m"),
+			}
+		}),
+	}, {
+		name: "profile selected",
+		rev:  revision("bar", "foo", withContainers(containers), withProfile("large")),
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{})
+			c.Resources.Requests = corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("100Mi"),
+			}
+			c.Resources.Limits = corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("500Mi"),
+			}
+		}),
+	}, {
+		name: "unknown profile falls back to defaults",
+		rev:  revision("bar", "foo", withContainers(containers), withProfile("huge")),
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{})
+			c.Resources.Requests = corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("25m"),
+			}
+		}),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := makeQueueContainer(test.rev, &logConfig, &traceConfig, &obsConfig, &dc)
+			if err != nil {
+				t.Fatal("makeQueueContainer returned error:", err)
+			}
+			test.want.Env = append(test.want.Env, corev1.EnvVar{
+				Name:  "SERVING_READINESS_PROBE",
+				Value: probeJSON(test.rev.Spec.GetContainer()),
+			})
+			sortEnv(got.Env)
+			sortEnv(test.want.Env)
+			if got, want := *got, test.want; !cmp.Equal(got, want, quantityComparer) {
+				t.Errorf("makeQueueContainer (-want, +got) =\n%s", cmp.Diff(want, got, quantityComparer))
+			}
+		})
+	}
+}
+
 func TestProbeGenerationHTTPDefaults(t *testing.T) {
 	rev := revision("bar", "foo",
 		func(revision *v1.Revision) {
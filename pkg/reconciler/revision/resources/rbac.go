@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/kmeta"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/reconciler/revision/resources/names"
+)
+
+// MakeRole makes the Role granting the given rules to the revision's workload.
+func MakeRole(rev *v1.Revision, rules []rbacv1.PolicyRule) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: makeRBACMeta(rev),
+		Rules:      rules,
+	}
+}
+
+// MakeRoleBinding makes the RoleBinding of the Role made by MakeRole to the
+// revision's service account.
+func MakeRoleBinding(rev *v1.Revision) *rbacv1.RoleBinding {
+	sa := rev.Spec.ServiceAccountName
+	if sa == "" {
+		sa = "default"
+	}
+	return &rbacv1.RoleBinding{
+		ObjectMeta: makeRBACMeta(rev),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     names.WorkloadRBAC(rev),
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      sa,
+			Namespace: rev.Namespace,
+		}},
+	}
+}
+
+func makeRBACMeta(rev *v1.Revision) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:            names.WorkloadRBAC(rev),
+		Namespace:       rev.Namespace,
+		Labels:          makeLabels(rev),
+		Annotations:     makeAnnotations(rev),
+		OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(rev)},
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/serving"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestMakeWorkloadRBAC(t *testing.T) {
+	rules := []rbacv1.PolicyRule{{
+		Verbs:     []string{"get"},
+		APIGroups: []string{""},
+		Resources: []string{"configmaps"},
+	}}
+	meta := metav1.ObjectMeta{
+		Namespace: "foo",
+		Name:      "bar-workload",
+		Labels: map[string]string{
+			serving.RevisionLabelKey: "bar",
+			serving.RevisionUID:      "1234",
+			AppLabelKey:              "bar",
+		},
+		Annotations: map[string]string{
+			serving.WorkloadRBACAnnotationKey: "get:configmaps",
+		},
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion:         v1.SchemeGroupVersion.String(),
+			Kind:               "Revision",
+			Name:               "bar",
+			UID:                "1234",
+			Controller:         ptr.Bool(true),
+			BlockOwnerDeletion: ptr.Bool(true),
+		}},
+	}
+
+	tests := []struct {
+		name        string
+		sa          string
+		wantSubject string
+	}{{
+		name:        "default service account",
+		wantSubject: "default",
+	}, {
+		name:        "explicit service account",
+		sa:          "builder",
+		wantSubject: "builder",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1.Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+					UID:       "1234",
+					Annotations: map[string]string{
+						serving.WorkloadRBACAnnotationKey: "get:configmaps",
+					},
+				},
+			}
+			rev.Spec.ServiceAccountName = test.sa
+
+			wantRole := &rbacv1.Role{ObjectMeta: meta, Rules: rules}
+			if got := MakeRole(rev, rules); !cmp.Equal(got, wantRole) {
+				t.Error("MakeRole (-want, +got) =", cmp.Diff(wantRole, got))
+			}
+
+			wantBinding := &rbacv1.RoleBinding{
+				ObjectMeta: meta,
+				RoleRef: rbacv1.RoleRef{
+					APIGroup: "rbac.authorization.k8s.io",
+					Kind:     "Role",
+					Name:     "bar-workload",
+				},
+				Subjects: []rbacv1.Subject{{
+					Kind:      "ServiceAccount",
+					Name:      test.wantSubject,
+					Namespace: "foo",
+				}},
+			}
+			if got := MakeRoleBinding(rev); !cmp.Equal(got, wantBinding) {
+				t.Error("MakeRoleBinding (-want, +got) =", cmp.Diff(wantBinding, got))
+			}
+		})
+	}
+}
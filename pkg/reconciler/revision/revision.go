@@ -27,9 +27,13 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	nodev1beta1listers "k8s.io/client-go/listers/node/v1beta1"
+	rbacv1listers "k8s.io/client-go/listers/rbac/v1"
 	cachingclientset "knative.dev/caching/pkg/client/clientset/versioned"
 	clientset "knative.dev/serving/pkg/client/clientset/versioned"
 	revisionreconciler "knative.dev/serving/pkg/client/injection/reconciler/serving/v1/revision"
@@ -59,8 +63,16 @@ type Reconciler struct {
 	podAutoscalerLister palisters.PodAutoscalerLister
 	imageLister         cachinglisters.ImageLister
 	deploymentLister    appsv1listers.DeploymentLister
+	roleLister          rbacv1listers.RoleLister
+	roleBindingLister   rbacv1listers.RoleBindingLister
+	serviceLister       corev1listers.ServiceLister
+	podLister           corev1listers.PodLister
+	runtimeClassLister  nodev1beta1listers.RuntimeClassLister
 
 	resolver resolver
+
+	clock        clock.Clock
+	enqueueAfter func(interface{}, time.Duration)
 }
 
 // Check that our Reconciler implements revisionreconciler.Interface
@@ -132,13 +144,31 @@ func (c *Reconciler) reconcileDigest(ctx context.Context, rev *v1.Revision) erro
 	return nil
 }
 
+// ReconcileKind implements Interface.ReconcileKind.
+// The phases below only mutate rev.Status in memory; the generated reconciler
+// writes it back with a single status update once all of them have run (or
+// one of them failed), so that they don't race each other with conflicting
+// updates.
 func (c *Reconciler) ReconcileKind(ctx context.Context, rev *v1.Revision) pkgreconciler.Event {
 	readyBeforeReconcile := rev.IsReady()
 	c.updateRevisionLoggingURL(ctx, rev)
+	if !readyBeforeReconcile {
+		warnContainerConcurrency(ctx, rev)
+	}
+
+	// Refuse to deploy the revision while the scan of its images flags them.
+	if status, flagged := config.FromContext(ctx).Deployment.ImageScanFlagged(rev.Annotations); flagged {
+		rev.Status.MarkResourcesAvailableFalse(v1.ReasonImageFlagged,
+			fmt.Sprintf("The image scan status of the revision is %q", status))
+		// Don't leave the flagged images running if they were deployed before
+		// the scan flagged them.
+		return c.removeFlaggedDeployment(ctx, rev)
+	}
 
 	for _, phase := range []func(context.Context, *v1.Revision) error{
-		c.reconcileDigest, c.reconcileDeployment,
-		c.reconcileImageCache, c.reconcilePA,
+		c.reconcileDigest, c.reconcileWorkloadRBAC, c.reconcileDeployment,
+		c.reconcilePodOverhead, c.reconcileImageCache, c.reconcilePA,
+		c.reconcileMetricsService,
 	} {
 		if err := phase(ctx, rev); err != nil {
 			return err
@@ -156,6 +186,23 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, rev *v1.Revision) pkgrec
 	return nil
 }
 
+// warnContainerConcurrency emits a warning event when the containerConcurrency
+// of the revision exceeds the threshold configured by the operator, since very
+// high values usually mean the setting was misconfigured.
+func warnContainerConcurrency(ctx context.Context, rev *v1.Revision) {
+	defaults := config.FromContext(ctx).Defaults
+	if defaults == nil || defaults.ContainerConcurrencyWarningThreshold == 0 ||
+		rev.Spec.ContainerConcurrency == nil {
+		return
+	}
+	if cc := *rev.Spec.ContainerConcurrency; cc > defaults.ContainerConcurrencyWarningThreshold {
+		controller.GetEventRecorder(ctx).Eventf(rev, corev1.EventTypeWarning,
+			"ContainerConcurrencyExceedsThreshold",
+			"containerConcurrency %d exceeds the recommended maximum of %d",
+			cc, defaults.ContainerConcurrencyWarningThreshold)
+	}
+}
+
 func (c *Reconciler) updateRevisionLoggingURL(ctx context.Context, rev *v1.Revision) {
 	config := config.FromContext(ctx)
 	if config.Observability.LoggingURLTemplate == "" {
@@ -19,7 +19,9 @@ package revision
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,7 +31,11 @@ import (
 	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
 	fakedeploymentinformer "knative.dev/pkg/client/injection/kube/informers/apps/v1/deployment/fake"
 	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/configmap/fake"
-	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/service/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/pod/fake"
+	fakeserviceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/service/fake"
+	fakeruntimeclassinformer "knative.dev/pkg/client/injection/kube/informers/node/v1beta1/runtimeclass/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/rbac/v1/role/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/rbac/v1/rolebinding/fake"
 	"knative.dev/pkg/ptr"
 	fakeservingclient "knative.dev/serving/pkg/client/injection/client/fake"
 	fakepainformer "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler/fake"
@@ -42,10 +48,14 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	nodev1beta1 "k8s.io/api/node/v1beta1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 
 	network "knative.dev/networking/pkg"
 	"knative.dev/pkg/apis"
@@ -303,6 +313,8 @@ func testDeploymentCM() *corev1.ConfigMap {
 		Data: map[string]string{
 			"queueSidecarImage": testQueueImage,
 			"autoscalerImage":   testAutoscalerImage,
+			// Allow the access requested by the workload RBAC tests.
+			"workloadRBACAllowedRules": "get:configmaps,list:configmaps",
 		},
 	}
 }
@@ -361,6 +373,543 @@ func TestResolutionFailed(t *testing.T) {
 	}
 }
 
+func TestReconcileSingleStatusUpdate(t *testing.T) {
+	ctx, _, _, controller, _ := newTestController(t, nil /*additional CMs*/)
+
+	rev := testRevision(testPodSpec())
+	createRevision(t, ctx, controller, rev)
+
+	// All the sub-reconcilers (digest, deployment, image cache, PA) have
+	// contributed to the status, but it was written only once.
+	statusUpdates := 0
+	for _, action := range fakeservingclient.Get(ctx).Actions() {
+		if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+			statusUpdates++
+		}
+	}
+	if statusUpdates != 1 {
+		t.Errorf("Got %d status updates, want: 1", statusUpdates)
+	}
+
+	rev, err := fakeservingclient.Get(ctx).ServingV1().Revisions(testNamespace).Get(rev.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Couldn't get revision:", err)
+	}
+	if got, want := rev.Status.ObservedGeneration, rev.Generation; got != want {
+		t.Errorf("ObservedGeneration = %d, want: %d", got, want)
+	}
+	for _, ct := range []apis.ConditionType{"ContainerHealthy", "ResourcesAvailable", "Ready"} {
+		if got := rev.Status.GetCondition(ct); got == nil || got.Status != corev1.ConditionUnknown {
+			t.Errorf("Condition %s = %v, want Unknown", ct, got)
+		}
+	}
+}
+
+func TestImageScanFlaggedRevision(t *testing.T) {
+	const scanAnnotation = "scanner.example.com/status"
+	tests := []struct {
+		name       string
+		status     string
+		wantDeploy bool
+	}{{
+		name:   "flagged image",
+		status: "flagged",
+	}, {
+		name:       "clean image",
+		status:     "clean",
+		wantDeploy: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deploymentCM := testDeploymentCM()
+			deploymentCM.Data["imageScanAnnotation"] = scanAnnotation
+			ctx, _, _, controller, _ := newTestController(t, []*corev1.ConfigMap{deploymentCM})
+
+			rev := testRevision(testPodSpec())
+			rev.Annotations = map[string]string{scanAnnotation: test.status}
+			fakeservingclient.Get(ctx).ServingV1().Revisions(rev.Namespace).Create(rev)
+			fakerevisioninformer.Get(ctx).Informer().GetIndexer().Add(rev)
+			if err := controller.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+				t.Fatal("Reconcile() =", err)
+			}
+
+			_, err := fakekubeclient.Get(ctx).AppsV1().Deployments(rev.Namespace).Get(
+				names.Deployment(rev), metav1.GetOptions{})
+			if deployed := err == nil; deployed != test.wantDeploy {
+				t.Errorf("Deployment created = %v, want: %v", deployed, test.wantDeploy)
+			}
+
+			rev, err = fakeservingclient.Get(ctx).ServingV1().Revisions(rev.Namespace).Get(rev.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("Couldn't get revision:", err)
+			}
+			cond := rev.Status.GetCondition(v1.RevisionConditionResourcesAvailable)
+			if flagged := cond.Status == corev1.ConditionFalse && cond.Reason == v1.ReasonImageFlagged; flagged == test.wantDeploy {
+				t.Errorf("ResourcesAvailable = %v, want flagged: %v", cond, !test.wantDeploy)
+			}
+		})
+	}
+}
+
+func TestImageScanFlaggedDeployedRevision(t *testing.T) {
+	const scanAnnotation = "scanner.example.com/status"
+	deploymentCM := testDeploymentCM()
+	deploymentCM.Data["imageScanAnnotation"] = scanAnnotation
+	ctx, _, _, controller, _ := newTestController(t, []*corev1.ConfigMap{deploymentCM})
+
+	rev := testRevision(testPodSpec())
+	rev.Annotations = map[string]string{scanAnnotation: "clean"}
+	fakeservingclient.Get(ctx).ServingV1().Revisions(rev.Namespace).Create(rev)
+	fakerevisioninformer.Get(ctx).Informer().GetIndexer().Add(rev)
+	if err := controller.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+	deployments := fakekubeclient.Get(ctx).AppsV1().Deployments(rev.Namespace)
+	deployment, err := deployments.Get(names.Deployment(rev), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Couldn't get deployment:", err)
+	}
+	fakedeploymentinformer.Get(ctx).Informer().GetIndexer().Add(deployment)
+
+	// The scan flags the images of the running revision.
+	rev, err = fakeservingclient.Get(ctx).ServingV1().Revisions(rev.Namespace).Get(rev.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Couldn't get revision:", err)
+	}
+	rev.Annotations[scanAnnotation] = "flagged"
+	fakeservingclient.Get(ctx).ServingV1().Revisions(rev.Namespace).Update(rev)
+	fakerevisioninformer.Get(ctx).Informer().GetIndexer().Update(rev)
+	if err := controller.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+
+	if _, err := deployments.Get(names.Deployment(rev), metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Errorf("Deployment of the flagged revision still exists, err = %v", err)
+	}
+}
+
+func TestRevisionContainerExit(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        corev1.RestartPolicy
+		exitCode      int32
+		wantStatus    corev1.ConditionStatus
+		wantReason    string
+		wantCompleted bool
+	}{{
+		name:       "success",
+		exitCode:   0,
+		wantStatus: corev1.ConditionFalse,
+		wantReason: v1.ExitCodeReason(0),
+	}, {
+		name:          "run to completion",
+		policy:        corev1.RestartPolicyOnFailure,
+		exitCode:      0,
+		wantStatus:    corev1.ConditionTrue,
+		wantReason:    v1.ReasonCompleted,
+		wantCompleted: true,
+	}, {
+		name:       "run to failure",
+		policy:     corev1.RestartPolicyOnFailure,
+		exitCode:   1,
+		wantStatus: corev1.ConditionFalse,
+		wantReason: v1.ExitCodeReason(1),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, _, _, controller, _ := newTestController(t, nil)
+
+			podSpec := testPodSpec()
+			podSpec.RestartPolicy = test.policy
+			rev := createRevision(t, ctx, controller, testRevision(podSpec))
+
+			deployment, err := fakekubeclient.Get(ctx).AppsV1().Deployments(rev.Namespace).Get(
+				names.Deployment(rev), metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("Couldn't get deployment:", err)
+			}
+			if _, err := fakekubeclient.Get(ctx).CoreV1().Pods(rev.Namespace).Create(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "exited",
+					Namespace: rev.Namespace,
+					Labels:    deployment.Spec.Template.Labels,
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name: rev.Spec.GetContainer().Name,
+						LastTerminationState: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{ExitCode: test.exitCode},
+						},
+					}},
+				},
+			}); err != nil {
+				t.Fatal("Couldn't create pod:", err)
+			}
+
+			if err := controller.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+				t.Fatal("Reconcile() =", err)
+			}
+			rev, err = fakeservingclient.Get(ctx).ServingV1().Revisions(rev.Namespace).Get(rev.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("Couldn't get revision:", err)
+			}
+			cond := rev.Status.GetCondition(v1.RevisionConditionContainerHealthy)
+			if cond == nil || cond.Status != test.wantStatus || cond.Reason != test.wantReason {
+				t.Errorf("ContainerHealthy = %v, want status %s and reason %q", cond, test.wantStatus, test.wantReason)
+			}
+			if got := rev.Status.GetCondition(v1.RevisionConditionCompleted).IsTrue(); got != test.wantCompleted {
+				t.Errorf("Completed = %v, want: %v", got, test.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestRevisionContainerOOMKilled(t *testing.T) {
+	oomKilled := &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}
+	tests := []struct {
+		name       string
+		statuses   func(container string) []corev1.ContainerStatus
+		wantReason string
+		wantOOM    string
+	}{{
+		name: "serving container last terminated",
+		statuses: func(container string) []corev1.ContainerStatus {
+			return []corev1.ContainerStatus{{
+				Name:                 container,
+				LastTerminationState: corev1.ContainerState{Terminated: oomKilled},
+			}}
+		},
+		wantReason: v1.ReasonOOMKilled,
+		wantOOM:    "user-container",
+	}, {
+		name: "serving container terminated",
+		statuses: func(container string) []corev1.ContainerStatus {
+			return []corev1.ContainerStatus{{
+				Name:  container,
+				State: corev1.ContainerState{Terminated: oomKilled},
+			}}
+		},
+		wantReason: v1.ReasonOOMKilled,
+		wantOOM:    "user-container",
+	}, {
+		name: "sidecar",
+		statuses: func(container string) []corev1.ContainerStatus {
+			return []corev1.ContainerStatus{{
+				Name: container,
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 1},
+				},
+			}, {
+				Name:                 "queue-proxy",
+				LastTerminationState: corev1.ContainerState{Terminated: oomKilled},
+			}}
+		},
+		wantReason: v1.ReasonOOMKilled,
+		wantOOM:    "queue-proxy",
+	}, {
+		name: "not OOMKilled",
+		statuses: func(container string) []corev1.ContainerStatus {
+			return []corev1.ContainerStatus{{
+				Name: container,
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "Error"},
+				},
+			}}
+		},
+		wantReason: v1.ExitCodeReason(137),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, _, _, ctrl, _ := newTestController(t, nil)
+			recorder := controller.GetEventRecorder(ctx).(*record.FakeRecorder)
+
+			podSpec := testPodSpec()
+			podSpec.Containers[0].Name = "user-container"
+			rev := createRevision(t, ctx, ctrl, testRevision(podSpec))
+			// Drain the events of the creation.
+			for len(recorder.Events) > 0 {
+				<-recorder.Events
+			}
+
+			deployment, err := fakekubeclient.Get(ctx).AppsV1().Deployments(rev.Namespace).Get(
+				names.Deployment(rev), metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("Couldn't get deployment:", err)
+			}
+			if _, err := fakekubeclient.Get(ctx).CoreV1().Pods(rev.Namespace).Create(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "killed",
+					Namespace: rev.Namespace,
+					Labels:    deployment.Spec.Template.Labels,
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: test.statuses(rev.Spec.GetContainer().Name),
+				},
+			}); err != nil {
+				t.Fatal("Couldn't create pod:", err)
+			}
+
+			if err := ctrl.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+				t.Fatal("Reconcile() =", err)
+			}
+			rev, err = fakeservingclient.Get(ctx).ServingV1().Revisions(rev.Namespace).Get(rev.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("Couldn't get revision:", err)
+			}
+			cond := rev.Status.GetCondition(v1.RevisionConditionContainerHealthy)
+			if cond == nil || cond.Status != corev1.ConditionFalse || cond.Reason != test.wantReason {
+				t.Errorf("ContainerHealthy = %v, want status False and reason %q", cond, test.wantReason)
+			}
+
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			var want []string
+			if test.wantOOM != "" {
+				if got, want := cond.Message, v1.RevisionContainerOOMKilledMessage(test.wantOOM); got != want {
+					t.Errorf("Message = %q, want: %q", got, want)
+				}
+				want = []string{fmt.Sprintf(`Warning OOMKilled Container %q of pod "killed" was killed for exceeding its memory limit`, test.wantOOM)}
+			}
+			if !cmp.Equal(events, want) {
+				t.Errorf("Events = %v, want: %v", events, want)
+			}
+
+			// The same termination is only reported once.
+			fakerevisioninformer.Get(ctx).Informer().GetIndexer().Update(rev)
+			if err := ctrl.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+				t.Fatal("Reconcile() =", err)
+			}
+			if len(recorder.Events) > 0 {
+				t.Errorf("Events = %v, want none for the same termination", <-recorder.Events)
+			}
+		})
+	}
+}
+
+func TestRevisionContainerOOMKilledAgain(t *testing.T) {
+	ctx, _, _, ctrl, _ := newTestController(t, nil)
+	recorder := controller.GetEventRecorder(ctx).(*record.FakeRecorder)
+
+	rev := createRevision(t, ctx, ctrl, testRevision(testPodSpec()))
+	deployment, err := fakekubeclient.Get(ctx).AppsV1().Deployments(rev.Namespace).Get(
+		names.Deployment(rev), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Couldn't get deployment:", err)
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "killed",
+			Namespace: rev.Namespace,
+			Labels:    deployment.Spec.Template.Labels,
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: rev.Spec.GetContainer().Name,
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"},
+				},
+			}},
+		},
+	}
+	pods := fakekubeclient.Get(ctx).CoreV1().Pods(rev.Namespace)
+	if _, err := pods.Create(pod); err != nil {
+		t.Fatal("Couldn't create pod:", err)
+	}
+
+	reconcile := func() int {
+		if err := ctrl.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+			t.Fatal("Reconcile() =", err)
+		}
+		rev, err = fakeservingclient.Get(ctx).ServingV1().Revisions(rev.Namespace).Get(rev.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("Couldn't get revision:", err)
+		}
+		fakerevisioninformer.Get(ctx).Informer().GetIndexer().Update(rev)
+		oomEvents := 0
+		for len(recorder.Events) > 0 {
+			if strings.Contains(<-recorder.Events, "OOMKilled") {
+				oomEvents++
+			}
+		}
+		return oomEvents
+	}
+	if got := reconcile(); got != 1 {
+		t.Errorf("OOMKilled events = %d, want 1", got)
+	}
+	if got := reconcile(); got != 0 {
+		t.Errorf("OOMKilled events = %d, want none for the same termination", got)
+	}
+
+	// The container runs out of memory again after restarting.
+	pod.Status.ContainerStatuses[0].RestartCount = 2
+	pod.Status.ContainerStatuses[0].LastTerminationState.Terminated.FinishedAt = metav1.NewTime(time.Now().Add(time.Minute))
+	if _, err := pods.Update(pod); err != nil {
+		t.Fatal("Couldn't update pod:", err)
+	}
+	if got := reconcile(); got != 1 {
+		t.Errorf("OOMKilled events = %d, want 1 for the new termination", got)
+	}
+}
+
+func TestRevisionContainerConcurrencyWarning(t *testing.T) {
+	const want = "Warning ContainerConcurrencyExceedsThreshold containerConcurrency 1000 exceeds the recommended maximum of 500"
+	tests := []struct {
+		name      string
+		threshold string
+		cc        int64
+		wantEvent bool
+	}{{
+		name: "warning disabled",
+		cc:   1000,
+	}, {
+		name:      "below threshold",
+		threshold: "500",
+		cc:        100,
+	}, {
+		name:      "at threshold",
+		threshold: "500",
+		cc:        500,
+	}, {
+		name:      "above threshold",
+		threshold: "500",
+		cc:        1000,
+		wantEvent: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defaultsCM := testDefaultsCM()
+			if test.threshold != "" {
+				defaultsCM.Data["container-concurrency-warning-threshold"] = test.threshold
+			}
+			ctx, _, _, ctrl, _ := newTestController(t, []*corev1.ConfigMap{defaultsCM})
+			recorder := controller.GetEventRecorder(ctx).(*record.FakeRecorder)
+
+			rev := testRevision(testPodSpec())
+			rev.Spec.ContainerConcurrency = ptr.Int64(test.cc)
+			createRevision(t, ctx, ctrl, rev)
+
+			var got bool
+			for len(recorder.Events) > 0 {
+				if event := <-recorder.Events; event == want {
+					got = true
+				} else if strings.Contains(event, "ContainerConcurrencyExceedsThreshold") {
+					t.Errorf("Unexpected event %q, want: %q", event, want)
+				}
+			}
+			if got != test.wantEvent {
+				t.Errorf("Got warning event = %v, want: %v", got, test.wantEvent)
+			}
+		})
+	}
+}
+
+func TestRevisionPodOverhead(t *testing.T) {
+	tests := []struct {
+		name         string
+		runtimeClass *string
+		overhead     *nodev1beta1.Overhead
+		want         string
+	}{{
+		name: "no runtime class",
+	}, {
+		name:         "runtime class without overhead",
+		runtimeClass: ptr.String("runc"),
+	}, {
+		name:         "missing runtime class",
+		runtimeClass: ptr.String("missing"),
+	}, {
+		name:         "runtime class with overhead",
+		runtimeClass: ptr.String("gvisor"),
+		overhead: &nodev1beta1.Overhead{
+			PodFixed: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("120Mi"),
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+			},
+		},
+		want: "cpu=250m,memory=120Mi",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, _, _, ctrl, _ := newTestController(t, nil)
+			for _, name := range []string{"runc", "gvisor"} {
+				rc := &nodev1beta1.RuntimeClass{
+					ObjectMeta: metav1.ObjectMeta{Name: name},
+					Handler:    name,
+				}
+				if test.runtimeClass != nil && *test.runtimeClass == name {
+					rc.Overhead = test.overhead
+				}
+				fakeruntimeclassinformer.Get(ctx).Informer().GetIndexer().Add(rc)
+			}
+
+			rev := testRevision(testPodSpec())
+			rev.Spec.RuntimeClassName = test.runtimeClass
+			rev = createRevision(t, ctx, ctrl, rev)
+
+			got, ok := rev.Status.Annotations[serving.PodOverheadAnnotationKey]
+			if test.want == "" && ok {
+				t.Errorf("Unexpected pod overhead annotation %q", got)
+			} else if got != test.want {
+				t.Errorf("Pod overhead = %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMetricsService(t *testing.T) {
+	deploymentCM := testDeploymentCM()
+	deploymentCM.Data["queueSidecarMetricsService"] = "true"
+	ctx, _, _, controller, _ := newTestController(t, []*corev1.ConfigMap{deploymentCM})
+
+	rev := testRevision(testPodSpec())
+	revClient := fakeservingclient.Get(ctx).ServingV1().Revisions(rev.Namespace)
+	revClient.Create(rev)
+	fakerevisioninformer.Get(ctx).Informer().GetIndexer().Add(rev)
+	if err := controller.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+
+	rev, err := revClient.Get(rev.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Couldn't get revision:", err)
+	}
+	svc, err := fakekubeclient.Get(ctx).CoreV1().Services(rev.Namespace).Get(names.MetricsService(rev), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Couldn't get metrics service:", err)
+	}
+	if want := resources.MakeMetricsService(rev); !cmp.Equal(svc, want) {
+		t.Error("Metrics service (-want, +got):", cmp.Diff(want, svc))
+	}
+}
+
+func TestMetricsServiceCleanup(t *testing.T) {
+	// The metrics service is disabled by default.
+	ctx, _, _, controller, _ := newTestController(t, nil /*additional CMs*/)
+
+	rev := testRevision(testPodSpec())
+	revClient := fakeservingclient.Get(ctx).ServingV1().Revisions(rev.Namespace)
+	svcClient := fakekubeclient.Get(ctx).CoreV1().Services(rev.Namespace)
+	revClient.Create(rev)
+	fakerevisioninformer.Get(ctx).Informer().GetIndexer().Add(rev)
+	svc := resources.MakeMetricsService(rev)
+	svcClient.Create(svc)
+	fakeserviceinformer.Get(ctx).Informer().GetIndexer().Add(svc)
+	if err := controller.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+
+	if _, err := svcClient.Get(names.MetricsService(rev), metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Error("Metrics service not deleted, Get() =", err)
+	}
+}
+
 func TestUpdateRevWithWithUpdatedLoggingURL(t *testing.T) {
 	ctx, _, _, controller, watcher := newTestController(t, []*corev1.ConfigMap{{
 		ObjectMeta: metav1.ObjectMeta{
@@ -682,3 +1231,60 @@ func TestNewRevisionCallsSyncHandler(t *testing.T) {
 		t.Error("Failed to see deployment creation:", err)
 	}
 }
+
+func TestRevisionSelectorIgnoresNonMatchingRevisions(t *testing.T) {
+	deploymentCM := testDeploymentCM()
+	deploymentCM.Data["revisionSelector"] = "shard=a"
+	ctx, cancel, informers, ctrl, _ := newTestController(t, []*corev1.ConfigMap{deploymentCM})
+
+	eg := errgroup.Group{}
+	servingClient := fakeservingclient.Get(ctx)
+
+	waitInformers, err := controller.RunInformers(ctx.Done(), informers...)
+	if err != nil {
+		t.Fatal("Error starting informers:", err)
+	}
+	defer func() {
+		cancel()
+		if err := eg.Wait(); err != nil {
+			t.Fatal("Error running controller:", err)
+		}
+		waitInformers()
+	}()
+
+	eg.Go(func() error {
+		return ctrl.Run(1, ctx.Done())
+	})
+
+	// Create the non-matching revision first, so by the time the matching
+	// one has been reconciled the other would have been too.
+	ignored := testRevision(testPodSpec())
+	ignored.Name = "ignored-rev"
+	ignored.Labels["shard"] = "b"
+	matching := testRevision(testPodSpec())
+	matching.Name = "matching-rev"
+	matching.Labels["shard"] = "a"
+	for _, rev := range []*v1.Revision{ignored, matching} {
+		if _, err := servingClient.ServingV1().Revisions(rev.Namespace).Create(rev); err != nil {
+			t.Fatal("Error creating revision:", err)
+		}
+	}
+
+	// Poll to see PA object to be created for the matching revision.
+	if err := wait.PollImmediate(25*time.Millisecond, 3*time.Second, func() (bool, error) {
+		pa, _ := servingClient.AutoscalingV1alpha1().PodAutoscalers(matching.Namespace).Get(
+			matching.Name, metav1.GetOptions{})
+		return pa != nil, nil
+	}); err != nil {
+		t.Fatal("Failed to see PA creation for matching revision")
+	}
+
+	if pa, err := servingClient.AutoscalingV1alpha1().PodAutoscalers(ignored.Namespace).Get(
+		ignored.Name, metav1.GetOptions{}); err == nil {
+		t.Errorf("Unexpected PA created for non-matching revision: %v", pa)
+	}
+	if _, err := fakekubeclient.Get(ctx).AppsV1().Deployments(ignored.Namespace).Get(
+		names.Deployment(ignored), metav1.GetOptions{}); err == nil {
+		t.Error("Unexpected deployment created for non-matching revision")
+	}
+}
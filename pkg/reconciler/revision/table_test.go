@@ -19,11 +19,14 @@ package revision
 import (
 	"context"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
 	clientgotesting "k8s.io/client-go/testing"
 
 	caching "knative.dev/caching/pkg/apis/caching/v1alpha1"
@@ -32,6 +35,7 @@ import (
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/metrics"
 	pkgreconciler "knative.dev/pkg/reconciler"
@@ -608,6 +612,232 @@ func TestReconcile(t *testing.T) {
 				WithLogURL, allUnknownConditions, MarkDeploying("Deploying"), withDefaultContainerStatuses(), WithRevisionObservedGeneration(1)),
 		}},
 		Key: "foo/image-pull-secrets",
+	}, {
+		Name: "pods outlived max pod lifetime",
+		// Test that pods older than the requested maximum lifetime trigger a
+		// rolling replacement of the Deployment's pods.
+		Objects: []runtime.Object{
+			Revision("foo", "max-lifetime", WithK8sServiceName("max-lifetime"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1),
+				WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")),
+			pa("foo", "max-lifetime", WithPASKSReady, WithTraffic, WithReachabilityUnreachable,
+				WithScaleTargetInitialized, WithPAStatusService("max-lifetime")),
+			pod(t, "foo", "max-lifetime", withPodCreationTimestamp(testClockTime.Add(-90*time.Minute))),
+			deploy(t, "foo", "max-lifetime", WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")),
+			image("foo", "max-lifetime"),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withRestartedAt(deploy(t, "foo", "max-lifetime",
+				WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")), testClockTime),
+		}},
+		Key: "foo/max-lifetime",
+	}, {
+		Name: "pods within max pod lifetime",
+		// Test that pods younger than the requested maximum lifetime are left alone.
+		Objects: []runtime.Object{
+			Revision("foo", "young-pods", WithK8sServiceName("young-pods"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1),
+				WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")),
+			pa("foo", "young-pods", WithPASKSReady, WithTraffic, WithReachabilityUnreachable,
+				WithScaleTargetInitialized, WithPAStatusService("young-pods")),
+			pod(t, "foo", "young-pods", withPodCreationTimestamp(testClockTime.Add(-30*time.Minute))),
+			deploy(t, "foo", "young-pods", WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")),
+			image("foo", "young-pods"),
+		},
+		Key: "foo/young-pods",
+	}, {
+		Name: "pods created before last restart are ignored",
+		// Test that pods which are already being replaced due to a previous
+		// restart don't trigger yet another one, and that the restart marker is kept.
+		Objects: []runtime.Object{
+			Revision("foo", "restarting", WithK8sServiceName("restarting"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1),
+				WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")),
+			pa("foo", "restarting", WithPASKSReady, WithTraffic, WithReachabilityUnreachable,
+				WithScaleTargetInitialized, WithPAStatusService("restarting")),
+			pod(t, "foo", "restarting", withPodCreationTimestamp(testClockTime.Add(-2*time.Hour))),
+			withRestartedAt(deploy(t, "foo", "restarting",
+				WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")), testClockTime.Add(-time.Minute)),
+			image("foo", "restarting"),
+		},
+		Key: "foo/restarting",
+	}, {
+		Name: "restart in progress with replacement pods",
+		// Test that the pods older than the last restart don't count while
+		// the young pods replacing them don't need a restart yet.
+		Objects: []runtime.Object{
+			Revision("foo", "replacing", WithK8sServiceName("replacing"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1),
+				WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")),
+			pa("foo", "replacing", WithPASKSReady, WithTraffic, WithReachabilityUnreachable,
+				WithScaleTargetInitialized, WithPAStatusService("replacing")),
+			pod(t, "foo", "replacing", withPodCreationTimestamp(testClockTime.Add(-3*time.Hour))),
+			pod(t, "foo", "replacing", withPodName("replacing-new"),
+				withPodCreationTimestamp(testClockTime.Add(-5*time.Minute))),
+			withRestartedAt(deploy(t, "foo", "replacing",
+				WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")), testClockTime.Add(-10*time.Minute)),
+			image("foo", "replacing"),
+		},
+		Key: "foo/replacing",
+	}, {
+		Name: "replacement pods outlived max pod lifetime",
+		// Test that the pods created since the last restart trigger another
+		// one once they outlive the maximum lifetime, even while pods older
+		// than the last restart are still around.
+		Objects: []runtime.Object{
+			Revision("foo", "restart-again", WithK8sServiceName("restart-again"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1),
+				WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")),
+			pa("foo", "restart-again", WithPASKSReady, WithTraffic, WithReachabilityUnreachable,
+				WithScaleTargetInitialized, WithPAStatusService("restart-again")),
+			pod(t, "foo", "restart-again", withPodCreationTimestamp(testClockTime.Add(-3*time.Hour))),
+			pod(t, "foo", "restart-again", withPodName("restart-again-new"),
+				withPodCreationTimestamp(testClockTime.Add(-90*time.Minute))),
+			withRestartedAt(deploy(t, "foo", "restart-again",
+				WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")), testClockTime.Add(-2*time.Hour)),
+			image("foo", "restart-again"),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withRestartedAt(deploy(t, "foo", "restart-again",
+				WithRevisionAnn(serving.MaxPodLifetimeAnnotationKey, "1h")), testClockTime),
+		}},
+		Key: "foo/restart-again",
+	}, {
+		Name: "create workload rbac",
+		// Test that the Role and RoleBinding requested via annotation are created.
+		Objects: []runtime.Object{
+			Revision("foo", "workload-rbac", WithK8sServiceName("workload-rbac"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1),
+				WithRevisionAnn(serving.WorkloadRBACAnnotationKey, "get:configmaps")),
+			pa("foo", "workload-rbac", WithPASKSReady, WithTraffic, WithReachabilityUnreachable,
+				WithScaleTargetInitialized, WithPAStatusService("workload-rbac")),
+			readyDeploy(deploy(t, "foo", "workload-rbac",
+				WithRevisionAnn(serving.WorkloadRBACAnnotationKey, "get:configmaps"))),
+			image("foo", "workload-rbac"),
+		},
+		WantCreates: []runtime.Object{
+			role("foo", "workload-rbac", "get:configmaps"),
+			roleBinding("foo", "workload-rbac", "get:configmaps"),
+		},
+		Key: "foo/workload-rbac",
+	}, {
+		Name: "update workload rbac",
+		// Test that the rules of the Role follow the annotation.
+		Objects: []runtime.Object{
+			Revision("foo", "workload-rbac", WithK8sServiceName("workload-rbac"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1),
+				WithRevisionAnn(serving.WorkloadRBACAnnotationKey, "get,list:configmaps")),
+			pa("foo", "workload-rbac", WithPASKSReady, WithTraffic, WithReachabilityUnreachable,
+				WithScaleTargetInitialized, WithPAStatusService("workload-rbac")),
+			readyDeploy(deploy(t, "foo", "workload-rbac",
+				WithRevisionAnn(serving.WorkloadRBACAnnotationKey, "get,list:configmaps"))),
+			image("foo", "workload-rbac"),
+			role("foo", "workload-rbac", "get:configmaps"),
+			roleBinding("foo", "workload-rbac", "get:configmaps"),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withRules(role("foo", "workload-rbac", "get:configmaps"), "get,list:configmaps"),
+		}},
+		Key: "foo/workload-rbac",
+	}, {
+		Name: "workload rbac not owned",
+		// Test that a Role of the same name we don't own is left alone.
+		WantErr: true,
+		Objects: []runtime.Object{
+			Revision("foo", "workload-rbac", WithK8sServiceName("workload-rbac"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1),
+				WithRevisionAnn(serving.WorkloadRBACAnnotationKey, "get:configmaps")),
+			pa("foo", "workload-rbac", WithPASKSReady, WithTraffic, WithReachabilityUnreachable,
+				WithScaleTargetInitialized, WithPAStatusService("workload-rbac")),
+			readyDeploy(deploy(t, "foo", "workload-rbac",
+				WithRevisionAnn(serving.WorkloadRBACAnnotationKey, "get:configmaps"))),
+			image("foo", "workload-rbac"),
+			noRoleOwner(role("foo", "workload-rbac", "get:configmaps")),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Revision("foo", "workload-rbac", WithK8sServiceName("workload-rbac"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1),
+				WithRevisionAnn(serving.WorkloadRBACAnnotationKey, "get:configmaps"),
+				MarkResourceNotOwned("Role", "workload-rbac-workload")),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError",
+				`revision: "workload-rbac" does not own Role: "workload-rbac-workload"`),
+		},
+		Key: "foo/workload-rbac",
+	}, {
+		Name: "delete workload rbac",
+		// Test that the Role and RoleBinding are deleted once the annotation is removed.
+		Objects: []runtime.Object{
+			Revision("foo", "workload-rbac", WithK8sServiceName("workload-rbac"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1)),
+			pa("foo", "workload-rbac", WithPASKSReady, WithTraffic, WithReachabilityUnreachable,
+				WithScaleTargetInitialized, WithPAStatusService("workload-rbac")),
+			readyDeploy(deploy(t, "foo", "workload-rbac")),
+			image("foo", "workload-rbac"),
+			role("foo", "workload-rbac", "get:configmaps"),
+			roleBinding("foo", "workload-rbac", "get:configmaps"),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+				Verb:      "delete",
+				Resource:  rbacv1.SchemeGroupVersion.WithResource("rolebindings"),
+			},
+			Name: "workload-rbac-workload",
+		}, {
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+				Verb:      "delete",
+				Resource:  rbacv1.SchemeGroupVersion.WithResource("roles"),
+			},
+			Name: "workload-rbac-workload",
+		}},
+		Key: "foo/workload-rbac",
+	}, {
+		Name: "workload rbac not allowed",
+		// Test that the access not allowed in config-deployment is revoked,
+		// and that the revision reports it.
+		WantErr: true,
+		Objects: []runtime.Object{
+			Revision("foo", "workload-rbac", WithK8sServiceName("workload-rbac"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1),
+				WithRevisionAnn(serving.WorkloadRBACAnnotationKey, "get,list:secrets")),
+			pa("foo", "workload-rbac", WithPASKSReady, WithTraffic, WithReachabilityUnreachable,
+				WithScaleTargetInitialized, WithPAStatusService("workload-rbac")),
+			readyDeploy(deploy(t, "foo", "workload-rbac",
+				WithRevisionAnn(serving.WorkloadRBACAnnotationKey, "get,list:secrets"))),
+			image("foo", "workload-rbac"),
+			role("foo", "workload-rbac", "get:configmaps"),
+			roleBinding("foo", "workload-rbac", "get:configmaps"),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+				Verb:      "delete",
+				Resource:  rbacv1.SchemeGroupVersion.WithResource("rolebindings"),
+			},
+			Name: "workload-rbac-workload",
+		}, {
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+				Verb:      "delete",
+				Resource:  rbacv1.SchemeGroupVersion.WithResource("roles"),
+			},
+			Name: "workload-rbac-workload",
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Revision("foo", "workload-rbac", WithK8sServiceName("workload-rbac"), WithLogURL,
+				MarkRevisionReady, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1),
+				WithRevisionAnn(serving.WorkloadRBACAnnotationKey, "get,list:secrets"),
+				MarkResourcesUnavailable(v1.ReasonWorkloadRBACNotAllowed,
+					"The access requested by the revision is not allowed: get:secrets, list:secrets")),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError",
+				`revision: "workload-rbac" requests access that is not allowed: get:secrets, list:secrets`),
+		},
+		Key: "foo/workload-rbac",
 	}}
 
 	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
@@ -619,7 +849,14 @@ func TestReconcile(t *testing.T) {
 			podAutoscalerLister: listers.GetPodAutoscalerLister(),
 			imageLister:         listers.GetImageLister(),
 			deploymentLister:    listers.GetDeploymentLister(),
+			roleLister:          listers.GetRoleLister(),
+			roleBindingLister:   listers.GetRoleBindingLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			podLister:           listers.GetPodsLister(),
+			runtimeClassLister:  listers.GetRuntimeClassLister(),
 			resolver:            &nopResolver{},
+			clock:               clock.NewFakeClock(testClockTime),
+			enqueueAfter:        func(interface{}, time.Duration) {},
 		}
 
 		return revisionreconciler.NewReconciler(ctx, logging.FromContext(ctx), servingclient.Get(ctx),
@@ -632,6 +869,282 @@ func TestReconcile(t *testing.T) {
 	}))
 }
 
+func TestReconcileSchedulingFailureGracePeriod(t *testing.T) {
+	// The revision is requeued for when the grace period ends.
+	var requeued time.Duration
+	wantRequeued := func(want time.Duration) func(*testing.T, *TableRow) {
+		return func(t *testing.T, _ *TableRow) {
+			if requeued != want {
+				t.Errorf("Requeued after = %v, want: %v", requeued, want)
+			}
+		}
+	}
+
+	table := TableTest{{
+		Name: "unschedulable within the grace period",
+		Objects: []runtime.Object{
+			Revision("foo", "pod-schedule-wait",
+				WithK8sServiceName("a-pod-schedule-wait"), WithLogURL, allUnknownConditions, MarkActive),
+			pa("foo", "pod-schedule-wait"),
+			pod(t, "foo", "pod-schedule-wait", WithUnschedulableContainer("Unschedulable", "0/3 nodes are available: 3 Insufficient cpu."),
+				withPodScheduledTransitionTime(testClockTime.Add(-10*time.Second))),
+			deploy(t, "foo", "pod-schedule-wait"),
+			image("foo", "pod-schedule-wait"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Revision("foo", "pod-schedule-wait",
+				WithLogURL, allUnknownConditions, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1)),
+		}},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: pa("foo", "pod-schedule-wait", WithReachabilityUnreachable),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){wantRequeued(50 * time.Second)},
+		Key:            "foo/pod-schedule-wait",
+	}, {
+		Name: "unschedulable past the grace period",
+		Objects: []runtime.Object{
+			Revision("foo", "pod-schedule-error",
+				WithK8sServiceName("a-pod-schedule-error"), WithLogURL, allUnknownConditions, MarkActive),
+			pa("foo", "pod-schedule-error"),
+			pod(t, "foo", "pod-schedule-error", WithUnschedulableContainer("Unschedulable", "0/3 nodes are available: 3 Insufficient cpu."),
+				withPodScheduledTransitionTime(testClockTime.Add(-2*time.Minute))),
+			deploy(t, "foo", "pod-schedule-error"),
+			image("foo", "pod-schedule-error"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Revision("foo", "pod-schedule-error",
+				WithLogURL, allUnknownConditions, MarkResourcesUnavailable("Unschedulable",
+					"0/3 nodes are available: 3 Insufficient cpu."), withDefaultContainerStatuses(), WithRevisionObservedGeneration(1)),
+		}},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: pa("foo", "pod-schedule-error", WithReachabilityUnreachable),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){wantRequeued(0)},
+		Key:            "foo/pod-schedule-error",
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		requeued = 0
+		r := &Reconciler{
+			kubeclient:    kubeclient.Get(ctx),
+			client:        servingclient.Get(ctx),
+			cachingclient: cachingclient.Get(ctx),
+
+			podAutoscalerLister: listers.GetPodAutoscalerLister(),
+			imageLister:         listers.GetImageLister(),
+			deploymentLister:    listers.GetDeploymentLister(),
+			roleLister:          listers.GetRoleLister(),
+			roleBindingLister:   listers.GetRoleBindingLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			podLister:           listers.GetPodsLister(),
+			runtimeClassLister:  listers.GetRuntimeClassLister(),
+			resolver:            &nopResolver{},
+			clock:               clock.NewFakeClock(testClockTime),
+			enqueueAfter: func(_ interface{}, d time.Duration) {
+				requeued = d
+			},
+		}
+
+		cfg := ReconcilerTestConfig()
+		cfg.Deployment.SchedulingFailureGracePeriod = time.Minute
+		return revisionreconciler.NewReconciler(ctx, logging.FromContext(ctx), servingclient.Get(ctx),
+			listers.GetRevisionLister(), controller.GetEventRecorder(ctx), r,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+func TestReconcileImagePullProgressDeadline(t *testing.T) {
+	// The revision is requeued for when the deadline passes.
+	var requeued time.Duration
+	wantRequeued := func(want time.Duration) func(*testing.T, *TableRow) {
+		return func(t *testing.T, _ *TableRow) {
+			if requeued != want {
+				t.Errorf("Requeued after = %v, want: %v", requeued, want)
+			}
+		}
+	}
+	pullingPod := func(name string) *corev1.Pod {
+		return pod(t, "foo", name, WithWaitingContainer("user-container", "ContainerCreating", ""),
+			func(pod *corev1.Pod) {
+				pod.Spec.Containers = []corev1.Container{{
+					Name:  "user-container",
+					Image: "busybox",
+				}}
+			})
+	}
+
+	table := TableTest{{
+		Name: "pulling within the deadline",
+		Objects: []runtime.Object{
+			Revision("foo", "pull-wait",
+				WithK8sServiceName("a-pull-wait"), WithLogURL, allUnknownConditions, MarkActive),
+			pa("foo", "pull-wait"),
+			pullingPod("pull-wait"),
+			pullEvent("pull-wait", "Pulling", testClockTime.Add(-time.Minute)),
+			deploy(t, "foo", "pull-wait"),
+			image("foo", "pull-wait"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Revision("foo", "pull-wait",
+				WithLogURL, allUnknownConditions, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1)),
+		}},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: pa("foo", "pull-wait", WithReachabilityUnreachable),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){wantRequeued(4 * time.Minute)},
+		Key:            "foo/pull-wait",
+	}, {
+		Name: "pulling past the deadline",
+		Objects: []runtime.Object{
+			Revision("foo", "pull-slow",
+				WithK8sServiceName("a-pull-slow"), WithLogURL, allUnknownConditions, MarkActive),
+			pa("foo", "pull-slow"),
+			pullingPod("pull-slow"),
+			pullEvent("pull-slow", "Pulling", testClockTime.Add(-10*time.Minute)),
+			deploy(t, "foo", "pull-slow"),
+			image("foo", "pull-slow"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Revision("foo", "pull-slow",
+				WithLogURL, allUnknownConditions, func(r *v1.Revision) {
+					r.Status.MarkResourcesAvailableUnknown(v1.ReasonImagePullSlow,
+						v1.RevisionImagePullSlowMessage("busybox", 5*time.Minute))
+				}, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1)),
+		}},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: pa("foo", "pull-slow", WithReachabilityUnreachable),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){wantRequeued(0)},
+		Key:            "foo/pull-slow",
+	}, {
+		Name: "image pulled",
+		Objects: []runtime.Object{
+			Revision("foo", "pull-done",
+				WithK8sServiceName("a-pull-done"), WithLogURL, allUnknownConditions, MarkActive),
+			pa("foo", "pull-done"),
+			pullingPod("pull-done"),
+			pullEvent("pull-done", "Pulling", testClockTime.Add(-10*time.Minute)),
+			pullEvent("pull-done", "Pulled", testClockTime.Add(-time.Minute)),
+			deploy(t, "foo", "pull-done"),
+			image("foo", "pull-done"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Revision("foo", "pull-done",
+				WithLogURL, allUnknownConditions, withDefaultContainerStatuses(), WithRevisionObservedGeneration(1)),
+		}},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: pa("foo", "pull-done", WithReachabilityUnreachable),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){wantRequeued(0)},
+		Key:            "foo/pull-done",
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		requeued = 0
+		r := &Reconciler{
+			kubeclient:    kubeclient.Get(ctx),
+			client:        servingclient.Get(ctx),
+			cachingclient: cachingclient.Get(ctx),
+
+			podAutoscalerLister: listers.GetPodAutoscalerLister(),
+			imageLister:         listers.GetImageLister(),
+			deploymentLister:    listers.GetDeploymentLister(),
+			roleLister:          listers.GetRoleLister(),
+			roleBindingLister:   listers.GetRoleBindingLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			podLister:           listers.GetPodsLister(),
+			runtimeClassLister:  listers.GetRuntimeClassLister(),
+			resolver:            &nopResolver{},
+			clock:               clock.NewFakeClock(testClockTime),
+			enqueueAfter: func(_ interface{}, d time.Duration) {
+				requeued = d
+			},
+		}
+
+		cfg := ReconcilerTestConfig()
+		cfg.Deployment.ImagePullProgressDeadline = 5 * time.Minute
+		return revisionreconciler.NewReconciler(ctx, logging.FromContext(ctx), servingclient.Get(ctx),
+			listers.GetRevisionLister(), controller.GetEventRecorder(ctx), r,
+			controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}))
+}
+
+// pullEvent returns an event of the kubelet pulling the image of the user
+// container of the pod at the given time.
+func pullEvent(podName, reason string, t time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      podName + "." + reason,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "Pod",
+			Namespace: "foo",
+			Name:      podName,
+			FieldPath: "spec.containers{user-container}",
+		},
+		Reason:        reason,
+		LastTimestamp: metav1.NewTime(t),
+	}
+}
+
+var testClockTime = time.Date(2020, time.August, 18, 10, 0, 0, 0, time.UTC)
+
+func withPodScheduledTransitionTime(t time.Time) PodOption {
+	return func(pod *corev1.Pod) {
+		for i := range pod.Status.Conditions {
+			pod.Status.Conditions[i].LastTransitionTime = metav1.NewTime(t)
+		}
+	}
+}
+
+func withPodCreationTimestamp(t time.Time) PodOption {
+	return func(pod *corev1.Pod) {
+		pod.CreationTimestamp = metav1.NewTime(t)
+	}
+}
+
+func withPodName(name string) PodOption {
+	return func(pod *corev1.Pod) {
+		pod.Name = name
+	}
+}
+
+func withRestartedAt(deploy *appsv1.Deployment, t time.Time) *appsv1.Deployment {
+	deploy.Spec.Template.Annotations = kmeta.UnionMaps(deploy.Spec.Template.Annotations,
+		map[string]string{serving.RestartedAtAnnotationKey: t.UTC().Format(time.RFC3339)})
+	return deploy
+}
+
+func role(namespace, name, rules string) *rbacv1.Role {
+	rev := Revision(namespace, name, WithRevisionAnn(serving.WorkloadRBACAnnotationKey, rules))
+	parsed, _ := serving.ParseWorkloadRBAC(rules)
+	return resources.MakeRole(rev, parsed)
+}
+
+func roleBinding(namespace, name, rules string) *rbacv1.RoleBinding {
+	rev := Revision(namespace, name, WithRevisionAnn(serving.WorkloadRBACAnnotationKey, rules))
+	return resources.MakeRoleBinding(rev)
+}
+
+func withRules(role *rbacv1.Role, rules string) *rbacv1.Role {
+	role.Rules, _ = serving.ParseWorkloadRBAC(rules)
+	return role
+}
+
+func noRoleOwner(role *rbacv1.Role) *rbacv1.Role {
+	role.OwnerReferences = nil
+	return role
+}
+
 func readyDeploy(deploy *appsv1.Deployment) *appsv1.Deployment {
 	deploy.Status.Conditions = []appsv1.DeploymentCondition{{
 		Type:   appsv1.DeploymentProgressing,
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Knative Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// warmupLeaderAware wraps a LeaderAware to enqueue the Revisions of the
+// buckets it is promoted to lead at most rate() per second, so that the
+// initial reconcile of a large number of pre-existing Revisions is spread
+// over time rather than all at once.
+type warmupLeaderAware struct {
+	pkgreconciler.LeaderAware
+
+	clock clock.Clock
+	rate  func() float64
+
+	mu sync.Mutex
+	// stops holds, by bucket name, the channels stopping the warm-ups
+	// still enqueuing the keys of the buckets.
+	stops map[string]chan struct{}
+}
+
+func newWarmupLeaderAware(la pkgreconciler.LeaderAware, clock clock.Clock, rate func() float64) *warmupLeaderAware {
+	return &warmupLeaderAware{
+		LeaderAware: la,
+		clock:       clock,
+		rate:        rate,
+		stops:       make(map[string]chan struct{}),
+	}
+}
+
+// Promote implements pkgreconciler.LeaderAware
+func (w *warmupLeaderAware) Promote(b pkgreconciler.Bucket, enq func(pkgreconciler.Bucket, types.NamespacedName)) error {
+	rate := w.rate()
+	if rate <= 0 {
+		return w.LeaderAware.Promote(b, enq)
+	}
+	interval := time.Duration(float64(time.Second) / rate)
+	if interval <= 0 {
+		return w.LeaderAware.Promote(b, enq)
+	}
+
+	var keys []types.NamespacedName
+	if err := w.LeaderAware.Promote(b, func(_ pkgreconciler.Bucket, key types.NamespacedName) {
+		keys = append(keys, key)
+	}); err != nil {
+		return err
+	}
+
+	stop := make(chan struct{})
+	w.mu.Lock()
+	w.stopLocked(b.Name())
+	w.stops[b.Name()] = stop
+	w.mu.Unlock()
+
+	go w.enqueue(b, enq, keys, interval, stop)
+	return nil
+}
+
+// Demote implements pkgreconciler.LeaderAware
+func (w *warmupLeaderAware) Demote(b pkgreconciler.Bucket) {
+	w.mu.Lock()
+	w.stopLocked(b.Name())
+	w.mu.Unlock()
+	w.LeaderAware.Demote(b)
+}
+
+func (w *warmupLeaderAware) stopLocked(name string) {
+	if stop, ok := w.stops[name]; ok {
+		close(stop)
+		delete(w.stops, name)
+	}
+}
+
+// enqueue enqueues the keys one per interval, until all of them are enqueued
+// or stop is closed.
+func (w *warmupLeaderAware) enqueue(b pkgreconciler.Bucket, enq func(pkgreconciler.Bucket, types.NamespacedName),
+	keys []types.NamespacedName, interval time.Duration, stop <-chan struct{}) {
+	ticker := w.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for i, key := range keys {
+		if i > 0 {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C():
+			}
+		}
+		enq(b, key)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Knative Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// keyRecorder records the keys enqueued by the warm-up.
+type keyRecorder struct {
+	mu   sync.Mutex
+	keys []types.NamespacedName
+}
+
+func (r *keyRecorder) enqueue(_ pkgreconciler.Bucket, key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = append(r.keys, key)
+}
+
+func (r *keyRecorder) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.keys)
+}
+
+func newTestWarmup(fakeClock clock.Clock, rate float64, count int) *warmupLeaderAware {
+	return newWarmupLeaderAware(&pkgreconciler.LeaderAwareFuncs{
+		PromoteFunc: func(b pkgreconciler.Bucket, enq func(pkgreconciler.Bucket, types.NamespacedName)) error {
+			for i := 0; i < count; i++ {
+				enq(b, types.NamespacedName{Namespace: "foo", Name: string(rune('a' + i))})
+			}
+			return nil
+		},
+	}, fakeClock, func() float64 { return rate })
+}
+
+func TestWarmupDisabled(t *testing.T) {
+	w := newTestWarmup(clock.NewFakeClock(time.Now()), 0, 5)
+
+	rec := &keyRecorder{}
+	if err := w.Promote(pkgreconciler.UniversalBucket(), rec.enqueue); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+	if got, want := rec.len(), 5; got != want {
+		t.Errorf("Enqueued %d keys, want: %d", got, want)
+	}
+}
+
+func TestWarmupRate(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	// 2 revisions per second, i.e. one every 500ms.
+	w := newTestWarmup(fakeClock, 2, 4)
+
+	rec := &keyRecorder{}
+	if err := w.Promote(pkgreconciler.UniversalBucket(), rec.enqueue); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+	if err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	}); err != nil {
+		t.Fatal("The warm-up never started ticking")
+	}
+
+	waitForKeys := func(want int) {
+		t.Helper()
+		if err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+			return rec.len() == want, nil
+		}); err != nil {
+			t.Fatalf("Enqueued %d keys, want: %d", rec.len(), want)
+		}
+	}
+
+	// The first key is enqueued right away.
+	waitForKeys(1)
+
+	fakeClock.Step(499 * time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	if got, want := rec.len(), 1; got != want {
+		t.Fatalf("Enqueued %d keys before the interval, want: %d", got, want)
+	}
+
+	for want := 2; want <= 4; want++ {
+		fakeClock.Step(500 * time.Millisecond)
+		waitForKeys(want)
+	}
+
+	fakeClock.Step(time.Second)
+	time.Sleep(10 * time.Millisecond)
+	if got, want := rec.len(), 4; got != want {
+		t.Errorf("Enqueued %d keys after the warm-up, want: %d", got, want)
+	}
+}
+
+func TestWarmupDemote(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	w := newTestWarmup(fakeClock, 1, 3)
+
+	bkt := pkgreconciler.UniversalBucket()
+	rec := &keyRecorder{}
+	if err := w.Promote(bkt, rec.enqueue); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+	if err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters() && rec.len() == 1, nil
+	}); err != nil {
+		t.Fatal("The warm-up never started ticking")
+	}
+
+	w.Demote(bkt)
+	time.Sleep(10 * time.Millisecond)
+	fakeClock.Step(5 * time.Second)
+	time.Sleep(10 * time.Millisecond)
+	if got, want := rec.len(), 1; got != want {
+		t.Errorf("Enqueued %d keys after the demotion, want: %d", got, want)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	network "knative.dev/networking/pkg"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/logging"
+	apisconfig "knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/gc"
 )
 
@@ -29,9 +30,11 @@ type cfgKey struct{}
 
 // +k8s:deepcopy-gen=false
 type Config struct {
-	Domain  *Domain
-	GC      *gc.Config
-	Network *network.Config
+	Domain   *Domain
+	GC       *gc.Config
+	Network  *network.Config
+	Defaults *apisconfig.Defaults
+	Features *apisconfig.Features
 }
 
 func FromContext(ctx context.Context) *Config {
@@ -43,7 +46,7 @@ func ToContext(ctx context.Context, c *Config) context.Context {
 }
 
 // Store is based on configmap.UntypedStore and is used to store and watch for
-// updates to configuration related to routes (currently only config-domain).
+// updates to configuration related to routes.
 //
 // +k8s:deepcopy-gen=false
 type Store struct {
@@ -70,6 +73,9 @@ func NewStore(ctx context.Context, onAfterStore ...func(name string, value inter
 				DomainConfigName:   NewDomainFromConfigMap,
 				gc.ConfigName:      gc.NewConfigFromConfigMapFunc(ctx),
 				network.ConfigName: network.NewConfigFromConfigMap,
+
+				apisconfig.DefaultsConfigName: apisconfig.NewDefaultsConfigFromConfigMap,
+				apisconfig.FeaturesConfigName: apisconfig.NewFeaturesConfigFromConfigMap,
 			},
 			onAfterStore...,
 		),
@@ -83,9 +89,16 @@ func (s *Store) ToContext(ctx context.Context) context.Context {
 }
 
 func (s *Store) Load() *Config {
-	return &Config{
+	cfg := &Config{
 		Domain:  s.UntypedLoad(DomainConfigName).(*Domain).DeepCopy(),
 		GC:      s.UntypedLoad(gc.ConfigName).(*gc.Config).DeepCopy(),
 		Network: s.UntypedLoad(network.ConfigName).(*network.Config).DeepCopy(),
 	}
+	if def, ok := s.UntypedLoad(apisconfig.DefaultsConfigName).(*apisconfig.Defaults); ok {
+		cfg.Defaults = def.DeepCopy()
+	}
+	if features, ok := s.UntypedLoad(apisconfig.FeaturesConfigName).(*apisconfig.Features); ok {
+		cfg.Features = features.DeepCopy()
+	}
+	return cfg
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	network "knative.dev/networking/pkg"
 	logtesting "knative.dev/pkg/logging/testing"
+	apisconfig "knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/gc"
 
 	. "knative.dev/pkg/configmap/testing"
@@ -36,10 +37,14 @@ func TestStoreLoadWithContext(t *testing.T) {
 	domainConfig := ConfigMapFromTestFile(t, DomainConfigName)
 	gcConfig := ConfigMapFromTestFile(t, gc.ConfigName)
 	networkConfig := ConfigMapFromTestFile(t, network.ConfigName)
+	defaultsConfig := ConfigMapFromTestFile(t, apisconfig.DefaultsConfigName)
+	featuresConfig := ConfigMapFromTestFile(t, apisconfig.FeaturesConfigName)
 
 	store.OnConfigChanged(domainConfig)
 	store.OnConfigChanged(gcConfig)
 	store.OnConfigChanged(networkConfig)
+	store.OnConfigChanged(defaultsConfig)
+	store.OnConfigChanged(featuresConfig)
 
 	config := FromContext(store.ToContext(context.Background()))
 
@@ -60,6 +65,20 @@ func TestStoreLoadWithContext(t *testing.T) {
 		}
 	})
 
+	t.Run("defaults", func(t *testing.T) {
+		expected, _ := apisconfig.NewDefaultsConfigFromConfigMap(defaultsConfig)
+		if diff := cmp.Diff(expected, config.Defaults); diff != "" {
+			t.Errorf("Unexpected defaults config (-want, +got): %v", diff)
+		}
+	})
+
+	t.Run("features", func(t *testing.T) {
+		expected, _ := apisconfig.NewFeaturesConfigFromConfigMap(featuresConfig)
+		if diff := cmp.Diff(expected, config.Features); diff != "" {
+			t.Errorf("Unexpected features config (-want, +got): %v", diff)
+		}
+	})
+
 	t.Run("gc invalid timeout", func(t *testing.T) {
 		gcConfig.Data["stale-revision-timeout"] = "1h"
 		expected, err := gc.NewConfigFromConfigMapFunc(ctx)(gcConfig)
@@ -23,6 +23,8 @@ import (
 	certificateinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/certificate"
 	ingressinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	endpointsinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/endpoints"
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret"
 	serviceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/service"
 	servingclient "knative.dev/serving/pkg/client/injection/client"
 	configurationinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/configuration"
@@ -34,10 +36,15 @@ import (
 	network "knative.dev/networking/pkg"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
 	"knative.dev/pkg/tracker"
+	apisconfig "knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	listers "knative.dev/serving/pkg/client/listers/serving/v1"
 	servingreconciler "knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/route/config"
 )
@@ -69,6 +76,7 @@ func newControllerWithClock(
 	revisionInformer := revisioninformer.Get(ctx)
 	ingressInformer := ingressinformer.Get(ctx)
 	certificateInformer := certificateinformer.Get(ctx)
+	endpointsInformer := endpointsinformer.Get(ctx)
 
 	c := &Reconciler{
 		kubeclient:          kubeclient.Get(ctx),
@@ -77,23 +85,30 @@ func newControllerWithClock(
 		configurationLister: configInformer.Lister(),
 		revisionLister:      revisionInformer.Lister(),
 		serviceLister:       serviceInformer.Lister(),
+		secretLister:        secretinformer.Get(ctx).Lister(),
 		ingressLister:       ingressInformer.Lister(),
 		certificateLister:   certificateInformer.Lister(),
 		clock:               clock,
+		newResolver:         newHostResolver,
 	}
+	var configStore *config.Store
 	impl := routereconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 		configsToResync := []interface{}{
 			&network.Config{},
 			&config.Domain{},
+			// The per-tag-ingress feature changes the Ingresses of the Routes.
+			&apisconfig.Features{},
 		}
 		resync := configmap.TypeFilter(configsToResync...)(func(string, interface{}) {
 			impl.GlobalResync(routeInformer.Informer())
 		})
-		configStore := config.NewStore(logging.WithLogger(ctx, logger.Named("config-store")), resync)
+		configStore = config.NewStore(logging.WithLogger(ctx, logger.Named("config-store")), resync)
 		configStore.WatchConfigs(cmw)
 		return controller.Options{ConfigStore: configStore}
 	})
 
+	c.enqueueAfter = impl.EnqueueAfter
+
 	logger.Info("Setting up event handlers")
 	routeInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
 
@@ -122,18 +137,54 @@ func newControllerWithClock(
 		),
 	))
 
-	revisionInformer.Informer().AddEventHandler(controller.HandleAll(
-		// Call the tracker's OnChanged method, but we've seen the objects
-		// coming through this path missing TypeMeta, so ensure it is properly
-		// populated.
-		controller.EnsureTypeMeta(
-			c.tracker.OnChanged,
-			v1.SchemeGroupVersion.WithKind("Revision"),
+	// Call the tracker's OnChanged method, but we've seen the objects
+	// coming through this path missing TypeMeta, so ensure it is properly
+	// populated.
+	revisionChanged := controller.EnsureTypeMeta(
+		c.tracker.OnChanged,
+		v1.SchemeGroupVersion.WithKind("Revision"),
+	)
+	revisionInformer.Informer().AddEventHandler(controller.HandleAll(revisionChanged))
+
+	// When asked to, treat the changes to the endpoints of a Revision as
+	// changes to the Revision, so that the Routes don't wait for the Revision
+	// to report its readiness.
+	endpointsInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: pkgreconciler.ChainFilterFuncs(
+			pkgreconciler.LabelExistsFilterFunc(serving.RevisionLabelKey),
+			responsiveReadinessFilter(configStore),
 		),
-	))
+		Handler: controller.HandleAll(revisionOfEndpoints(revisionInformer.Lister(), revisionChanged)),
+	})
 
 	for _, opt := range opts {
 		opt(c)
 	}
 	return impl
 }
+
+// responsiveReadinessFilter returns a filter accepting the objects only while
+// the responsive-route-readiness feature is enabled.
+func responsiveReadinessFilter(configStore *config.Store) func(interface{}) bool {
+	return func(interface{}) bool {
+		// Endpoints change often, so don't copy the whole config for them.
+		features, ok := configStore.UntypedLoad(apisconfig.FeaturesConfigName).(*apisconfig.Features)
+		return ok && features.ResponsiveRouteReadiness == apisconfig.Enabled
+	}
+}
+
+// revisionOfEndpoints returns a handler calling f with the Revision labeled
+// on the Endpoints it is passed.
+func revisionOfEndpoints(lister listers.RevisionLister, f func(interface{})) func(interface{}) {
+	return func(obj interface{}) {
+		acc, err := kmeta.DeletionHandlingAccessor(obj)
+		if err != nil {
+			return
+		}
+		rev, err := lister.Revisions(acc.GetNamespace()).Get(acc.GetLabels()[serving.RevisionLabelKey])
+		if err != nil {
+			return
+		}
+		f(rev)
+	}
+}
@@ -37,6 +37,11 @@ import (
 // HTTPScheme is the string representation of http.
 const HTTPScheme string = "http"
 
+// clusterLocalDomainTemplate is the domain template of the cluster-local
+// Routes, parsed once since it is applied to each of their targets.
+var clusterLocalDomainTemplate = template.Must(template.New("domain-template").Parse(
+	network.DefaultDomainTemplate))
+
 // GetAllDomainsAndTags returns all of the domains and tags(including subdomains) associated with a Route
 func GetAllDomainsAndTags(ctx context.Context, r *v1.Route, names []string, visibility map[string]netv1alpha1.IngressVisibility) (map[string]string, error) {
 	domainTagMap := make(map[string]string)
@@ -85,8 +90,7 @@ func DomainNameFromTemplate(ctx context.Context, r metav1.ObjectMeta, name strin
 	// If the route is "cluster local" then don't use the user-defined
 	// domain template, use the default one
 	if rLabels[serving.VisibilityLabelKey] == serving.VisibilityClusterLocal {
-		templ = template.Must(template.New("domain-template").Parse(
-			network.DefaultDomainTemplate))
+		templ = clusterLocalDomainTemplate
 	} else {
 		templ = networkConfig.GetDomainTemplate()
 	}
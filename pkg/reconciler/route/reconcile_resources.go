@@ -19,6 +19,7 @@ package route
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
@@ -26,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 
@@ -33,6 +35,7 @@ import (
 	"knative.dev/pkg/apis/duck"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	"knative.dev/serving/pkg/reconciler/route/config"
 	"knative.dev/serving/pkg/reconciler/route/resources"
@@ -53,6 +56,10 @@ func (c *Reconciler) reconcileIngress(ctx context.Context, r *v1.Route, desired
 		return ingress, nil
 	} else if err != nil {
 		return nil, err
+	} else if !metav1.IsControlledBy(ingress, r) {
+		// Surface an error in the route's status, and return an error.
+		r.Status.MarkIngressNotOwned(desired.Name)
+		return nil, fmt.Errorf("route: %q does not own Ingress: %q", r.Name, desired.Name)
 	} else if !equality.Semantic.DeepEqual(ingress.Spec, desired.Spec) ||
 		!equality.Semantic.DeepEqual(ingress.Annotations, desired.Annotations) {
 		// It is notable that one reason for differences here may be defaulting.
@@ -74,6 +81,109 @@ func (c *Reconciler) reconcileIngress(ctx context.Context, r *v1.Route, desired
 	return ingress, err
 }
 
+// deleteOrphanedIngresses deletes the Ingresses of the Route other than the
+// desired ones, e.g. those of the tags it no longer has, or of all its tags
+// when it stops having an Ingress per tag.
+func (c *Reconciler) deleteOrphanedIngresses(r *v1.Route, desired []*netv1alpha1.Ingress) error {
+	existing, err := c.ingressLister.Ingresses(r.Namespace).List(labels.SelectorFromSet(labels.Set{
+		serving.RouteLabelKey: r.Name,
+	}))
+	if err != nil {
+		return err
+	}
+
+	names := sets.NewString()
+	for _, ingress := range desired {
+		names.Insert(ingress.Name)
+	}
+	for _, ingress := range existing {
+		if names.Has(ingress.Name) || !metav1.IsControlledBy(ingress, r) {
+			continue
+		}
+		if err := c.netclient.NetworkingV1alpha1().Ingresses(ingress.Namespace).Delete(ingress.Name, &metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("failed to delete Ingress: %w", err)
+		}
+	}
+	return nil
+}
+
+// drainingTargets returns the Revisions that left the traffic of the Route and
+// still drain their in-flight requests through the Ingress. The Revisions
+// without endpoints, i.e. that require activation, have no in-flight requests
+// going through the Ingress, so they stop draining early.
+func (c *Reconciler) drainingTargets(ctx context.Context, r *v1.Route, tc *traffic.Config) ([]resources.DrainingTarget, error) {
+	timeout := resources.DrainTimeout(r)
+	if timeout <= 0 {
+		return nil, nil
+	}
+	ingresses, err := c.ingressLister.Ingresses(r.Namespace).List(labels.SelectorFromSet(labels.Set{
+		serving.RouteLabelKey: r.Name,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	targeted := sets.NewString()
+	for _, targets := range tc.Targets {
+		for _, t := range targets {
+			targeted.Insert(t.RevisionName)
+		}
+	}
+
+	// The Revisions drain from any of the Ingresses of the Route, e.g. when
+	// they leave the traffic of a tag having an Ingress of its own.
+	now := c.clock.Now()
+	deadlines := make(map[string]time.Time)
+	for _, ingress := range ingresses {
+		if !metav1.IsControlledBy(ingress, r) {
+			continue
+		}
+		for name, deadline := range resources.DrainingRevisions(ingress) {
+			if d, ok := deadlines[name]; !ok || deadline.Before(d) {
+				deadlines[name] = deadline
+			}
+		}
+		for _, name := range resources.ServingRevisions(ingress).Difference(targeted).List() {
+			if _, ok := deadlines[name]; !ok {
+				deadlines[name] = now.Add(timeout)
+			}
+		}
+	}
+
+	var (
+		draining []resources.DrainingTarget
+		next     time.Duration
+	)
+	for name, deadline := range deadlines {
+		if targeted.Has(name) || !now.Before(deadline) {
+			continue
+		}
+		rev, err := c.revisionLister.Revisions(r.Namespace).Get(name)
+		if apierrs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		// Reconcile the Route when the Revision scales down.
+		if err := c.tracker.TrackReference(objectRef(rev), r); err != nil {
+			return nil, err
+		}
+		if rev.Status.IsActivationRequired() {
+			continue
+		}
+		draining = append(draining, resources.DrainingTarget{Revision: rev, Deadline: deadline})
+		if left := deadline.Sub(now); next == 0 || left < next {
+			next = left
+		}
+	}
+
+	if len(draining) > 0 {
+		// Stop draining the Revisions once their deadline passes.
+		c.enqueueAfter(r, next)
+	}
+	return draining, nil
+}
+
 func (c *Reconciler) deleteServices(namespace string, serviceNames sets.String) error {
 	for _, serviceName := range serviceNames.List() {
 		if err := c.kubeclient.CoreV1().Services(namespace).Delete(serviceName, nil); err != nil {
@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"knative.dev/networking/pkg/apis/networking"
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/activator"
+	"knative.dev/serving/pkg/apis/serving"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// DrainingRevisionsAnnotationKey is the annotation of the Ingress recording the
+// Revisions that left the traffic of the Route, and until when they keep being
+// programmed to drain their in-flight requests.
+const DrainingRevisionsAnnotationKey = serving.GroupName + "/drainingRevisions"
+
+// DrainingTarget is a Revision that left the traffic of the Route and keeps
+// being programmed, without receiving new requests, until Deadline.
+type DrainingTarget struct {
+	Revision *servingv1.Revision
+	Deadline time.Time
+}
+
+// DrainTimeout returns how long the Revisions that leave the traffic of the
+// Route keep being programmed. Zero disables draining.
+func DrainTimeout(r *servingv1.Route) time.Duration {
+	// Validated in the webhook.
+	d, _ := time.ParseDuration(r.Annotations[serving.DrainTimeoutAnnotationKey])
+	return d
+}
+
+// ServingRevisions returns the names of the Revisions receiving requests
+// through the Ingress, i.e. excluding the ones draining.
+func ServingRevisions(ing *netv1alpha1.Ingress) sets.String {
+	revisions := sets.NewString()
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			for _, split := range path.Splits {
+				if name, ok := split.AppendHeaders[activator.RevisionHeaderName]; ok && split.Percent > 0 {
+					revisions.Insert(name)
+				}
+			}
+		}
+	}
+	return revisions
+}
+
+// DrainingRevisions returns the deadlines of the draining Revisions recorded
+// on the Ingress.
+func DrainingRevisions(ing *netv1alpha1.Ingress) map[string]time.Time {
+	deadlines := make(map[string]time.Time)
+	for _, entry := range strings.Split(ing.Annotations[DrainingRevisionsAnnotationKey], ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		deadline, err := time.Parse(time.RFC3339, parts[1])
+		if err != nil {
+			continue
+		}
+		deadlines[parts[0]] = deadline
+	}
+	return deadlines
+}
+
+// AddDrainingTargets appends a split with no traffic for each draining target
+// to the paths of the Ingress routing to Revisions, so that the ingress
+// keeps the Revisions programmed while new requests go to the other splits.
+// The deadlines of the targets are recorded on the Ingress.
+func AddDrainingTargets(ing *netv1alpha1.Ingress, targets []DrainingTarget) {
+	if len(targets) == 0 {
+		return
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Revision.Name < targets[j].Revision.Name
+	})
+
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for i := range rule.HTTP.Paths {
+			path := &rule.HTTP.Paths[i]
+			if !routesToRevisions(path) {
+				continue
+			}
+			for _, t := range targets {
+				path.Splits = append(path.Splits, netv1alpha1.IngressBackendSplit{
+					IngressBackend: netv1alpha1.IngressBackend{
+						ServiceNamespace: t.Revision.Namespace,
+						ServiceName:      t.Revision.Status.ServiceName,
+						ServicePort:      intstr.FromInt(networking.ServicePort(t.Revision.GetProtocol())),
+					},
+					Percent: 0,
+					AppendHeaders: map[string]string{
+						activator.RevisionHeaderName:      t.Revision.Name,
+						activator.RevisionHeaderNamespace: t.Revision.Namespace,
+					},
+				})
+			}
+		}
+	}
+
+	entries := make([]string, 0, len(targets))
+	for _, t := range targets {
+		entries = append(entries, t.Revision.Name+"="+t.Deadline.UTC().Format(time.RFC3339))
+	}
+	if ing.Annotations == nil {
+		ing.Annotations = make(map[string]string, 1)
+	}
+	ing.Annotations[DrainingRevisionsAnnotationKey] = strings.Join(entries, ",")
+}
+
+func routesToRevisions(path *netv1alpha1.HTTPIngressPath) bool {
+	for _, split := range path.Splits {
+		if _, ok := split.AppendHeaders[activator.RevisionHeaderName]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	. "knative.dev/serving/pkg/testing/v1"
+)
+
+func TestDrainTimeout(t *testing.T) {
+	if got := DrainTimeout(Route(ns, testRouteName)); got != 0 {
+		t.Errorf("DrainTimeout() = %v, want: 0", got)
+	}
+	r := Route(ns, testRouteName, WithRouteAnnotation(map[string]string{
+		serving.DrainTimeoutAnnotationKey: "45s",
+	}))
+	if got, want := DrainTimeout(r), 45*time.Second; got != want {
+		t.Errorf("DrainTimeout() = %v, want: %v", got, want)
+	}
+}
+
+func TestDrainingTargets(t *testing.T) {
+	deadline := time.Unix(1e9, 0)
+	drained := &v1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+			Name:      "v1",
+		},
+		Status: v1.RevisionStatus{
+			ServiceName: "jobim",
+		},
+	}
+	split := func(rev string, percent int) netv1alpha1.IngressBackendSplit {
+		return netv1alpha1.IngressBackendSplit{
+			IngressBackend: netv1alpha1.IngressBackend{
+				ServiceNamespace: ns,
+				ServiceName:      rev + "-service",
+				ServicePort:      intstr.FromInt(80),
+			},
+			Percent: percent,
+			AppendHeaders: map[string]string{
+				"Knative-Serving-Revision":  rev,
+				"Knative-Serving-Namespace": ns,
+			},
+		}
+	}
+	acmePath := netv1alpha1.HTTPIngressPath{
+		Path: "/.well-known/acme-challenge/challenge-token",
+		Splits: []netv1alpha1.IngressBackendSplit{{
+			IngressBackend: netv1alpha1.IngressBackend{
+				ServiceNamespace: ns,
+				ServiceName:      "cm-solver",
+				ServicePort:      intstr.FromInt(8090),
+			},
+			Percent: 100,
+		}},
+	}
+	ing := &netv1alpha1.Ingress{
+		Spec: netv1alpha1.IngressSpec{
+			Rules: []netv1alpha1.IngressRule{{
+				HTTP: &netv1alpha1.HTTPIngressRuleValue{
+					Paths: []netv1alpha1.HTTPIngressPath{acmePath, {
+						Splits: []netv1alpha1.IngressBackendSplit{split("v2", 100)},
+					}},
+				},
+			}},
+		},
+	}
+
+	AddDrainingTargets(ing, []DrainingTarget{{Revision: drained, Deadline: deadline}})
+
+	// The draining split gets no requests, and the ACME challenges are left alone.
+	want := []netv1alpha1.HTTPIngressPath{acmePath, {
+		Splits: []netv1alpha1.IngressBackendSplit{split("v2", 100), {
+			IngressBackend: netv1alpha1.IngressBackend{
+				ServiceNamespace: ns,
+				ServiceName:      "jobim",
+				ServicePort:      intstr.FromInt(80),
+			},
+			Percent: 0,
+			AppendHeaders: map[string]string{
+				"Knative-Serving-Revision":  "v1",
+				"Knative-Serving-Namespace": ns,
+			},
+		}},
+	}}
+	if got := ing.Spec.Rules[0].HTTP.Paths; !cmp.Equal(got, want) {
+		t.Error("Paths (-want, +got):", cmp.Diff(want, got))
+	}
+	if got, want := ing.Annotations[DrainingRevisionsAnnotationKey], "v1=2001-09-09T01:46:40Z"; got != want {
+		t.Errorf("Annotation %s = %q, want: %q", DrainingRevisionsAnnotationKey, got, want)
+	}
+
+	// The draining revisions round trip, and don't count as serving.
+	if got, want := DrainingRevisions(ing), map[string]time.Time{"v1": deadline}; !cmp.Equal(got, want) {
+		t.Error("DrainingRevisions (-want, +got):", cmp.Diff(want, got))
+	}
+	if got, want := ServingRevisions(ing), sets.NewString("v2"); !got.Equal(want) {
+		t.Errorf("ServingRevisions() = %v, want: %v", got.List(), want.List())
+	}
+}
+
+func TestDrainingRevisionsMalformed(t *testing.T) {
+	ing := &netv1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				DrainingRevisionsAnnotationKey: "v1,v2=yesterday,v3=2001-09-09T01:46:40Z",
+			},
+		},
+	}
+	want := map[string]time.Time{"v3": time.Unix(1e9, 0)}
+	if got := DrainingRevisions(ing); !cmp.Equal(got, want) {
+		t.Error("DrainingRevisions (-want, +got):", cmp.Diff(want, got))
+	}
+}
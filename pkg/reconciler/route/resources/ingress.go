@@ -18,17 +18,22 @@ package resources
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"strconv"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	network "knative.dev/networking/pkg"
 	"knative.dev/networking/pkg/apis/networking"
 	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/ptr"
+	"knative.dev/pkg/system"
 	"knative.dev/serving/pkg/activator"
 	defaults "knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/serving"
@@ -40,6 +45,13 @@ import (
 	"knative.dev/serving/pkg/reconciler/route/traffic"
 )
 
+// IngressMinTLSVersionAnnotationKey is the annotation through which the
+// Ingresses terminating TLS for a Route are told the minimum TLS version to
+// accept, as requested with serving.MinTLSVersionAnnotationKey. The
+// IngressTLS has no field for it, so the Ingress implementations supporting
+// it read this annotation when configuring their listeners.
+const IngressMinTLSVersionAnnotationKey = networking.GroupName + "/minTLSVersion"
+
 // MakeIngressTLS creates IngressTLS to configure the ingress TLS.
 func MakeIngressTLS(cert *netv1alpha1.Certificate, hostNames []string) netv1alpha1.IngressTLS {
 	return netv1alpha1.IngressTLS{
@@ -63,23 +75,100 @@ func MakeIngress(
 	if err != nil {
 		return nil, err
 	}
+	return makeIngress(r, names.Ingress(r), ingressClass, spec), nil
+}
+
+// MakeIngresses creates the Ingresses setting up the routing rules of the
+// Route. Unless perTag, that is the single Ingress of MakeIngress. Otherwise
+// the rules for the hosts of each tag are in an Ingress of their own, and the
+// first Ingress, named like the one of MakeIngress, has the other rules.
+// Either way, the Ingresses route the same traffic.
+func MakeIngresses(
+	ctx context.Context,
+	r *servingv1.Route,
+	tc *traffic.Config,
+	tls []netv1alpha1.IngressTLS,
+	ingressClass string,
+	perTag bool,
+	acmeChallenges ...netv1alpha1.HTTP01Challenge,
+) ([]*netv1alpha1.Ingress, error) {
+	if !perTag {
+		ingress, err := MakeIngress(ctx, r, tc, tls, ingressClass, acmeChallenges...)
+		if err != nil {
+			return nil, err
+		}
+		return []*netv1alpha1.Ingress{ingress}, nil
+	}
+
+	tags := make([]string, 0, len(tc.Targets))
+	for name := range tc.Targets {
+		if name != traffic.DefaultTarget {
+			tags = append(tags, name)
+		}
+	}
+	sort.Strings(tags)
+
+	ingresses := make([]*netv1alpha1.Ingress, 0, len(tags)+1)
+	add := func(name, target string) error {
+		spec, err := makeIngressSpec(ctx, r, tls, tc.Targets, tc.Visibility, func(n string) bool {
+			return n == target
+		}, acmeChallenges...)
+		if err != nil {
+			return err
+		}
+		spec.TLS = tlsForRules(tls, spec.Rules)
+		ingresses = append(ingresses, makeIngress(r, name, ingressClass, spec))
+		return nil
+	}
+	if err := add(names.Ingress(r), traffic.DefaultTarget); err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if err := add(names.TagIngress(r, tag), tag); err != nil {
+			return nil, err
+		}
+	}
+	return ingresses, nil
+}
+
+func makeIngress(r *servingv1.Route, name, ingressClass string, spec netv1alpha1.IngressSpec) *netv1alpha1.Ingress {
+	annotations := kmeta.FilterMap(kmeta.UnionMaps(map[string]string{
+		networking.IngressClassAnnotationKey: ingressClass,
+	}, r.GetAnnotations()), func(key string) bool {
+		return key == corev1.LastAppliedConfigAnnotation || key == serving.MinTLSVersionAnnotationKey
+	})
+	// The minimum TLS version only matters to the Ingresses terminating TLS.
+	if v, ok := r.Annotations[serving.MinTLSVersionAnnotationKey]; ok && len(spec.TLS) > 0 {
+		annotations[IngressMinTLSVersionAnnotationKey] = v
+	}
 	return &netv1alpha1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      names.Ingress(r),
+			Name:      name,
 			Namespace: r.Namespace,
 			Labels: kmeta.UnionMaps(r.Labels, map[string]string{
 				serving.RouteLabelKey:          r.Name,
 				serving.RouteNamespaceLabelKey: r.Namespace,
 			}),
-			Annotations: kmeta.FilterMap(kmeta.UnionMaps(map[string]string{
-				networking.IngressClassAnnotationKey: ingressClass,
-			}, r.GetAnnotations()), func(key string) bool {
-				return key == corev1.LastAppliedConfigAnnotation
-			}),
+			Annotations:     annotations,
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(r)},
 		},
 		Spec: spec,
-	}, nil
+	}
+}
+
+// tlsForRules returns the entries of tls for the hosts of the rules.
+func tlsForRules(tls []netv1alpha1.IngressTLS, rules []netv1alpha1.IngressRule) []netv1alpha1.IngressTLS {
+	hosts := sets.NewString()
+	for _, rule := range rules {
+		hosts.Insert(rule.Hosts...)
+	}
+	ret := make([]netv1alpha1.IngressTLS, 0, len(tls))
+	for _, t := range tls {
+		if hosts.HasAny(t.Hosts...) {
+			ret = append(ret, t)
+		}
+	}
+	return ret
 }
 
 // MakeIngressSpec creates a new IngressSpec
@@ -90,6 +179,20 @@ func MakeIngressSpec(
 	targets map[string]traffic.RevisionTargets,
 	visibility map[string]netv1alpha1.IngressVisibility,
 	acmeChallenges ...netv1alpha1.HTTP01Challenge,
+) (netv1alpha1.IngressSpec, error) {
+	return makeIngressSpec(ctx, r, tls, targets, visibility, func(string) bool { return true }, acmeChallenges...)
+}
+
+// makeIngressSpec creates a new IngressSpec with the rules for the hosts of the
+// targets whose name is included.
+func makeIngressSpec(
+	ctx context.Context,
+	r *servingv1.Route,
+	tls []netv1alpha1.IngressTLS,
+	targets map[string]traffic.RevisionTargets,
+	visibility map[string]netv1alpha1.IngressVisibility,
+	include func(name string) bool,
+	acmeChallenges ...netv1alpha1.HTTP01Challenge,
 ) (netv1alpha1.IngressSpec, error) {
 	// Domain should have been specified in route status
 	// before calling this func.
@@ -105,14 +208,25 @@ func MakeIngressSpec(
 
 	networkConfig := config.FromContext(ctx).Network
 
+	// The domains are templated with the metadata of the Route for each
+	// visibility, which is set up once rather than for each of the possibly
+	// many targets.
+	metas := map[netv1alpha1.IngressVisibility]*metav1.ObjectMeta{
+		netv1alpha1.IngressVisibilityClusterLocal: visibilityMeta(r, true),
+		netv1alpha1.IngressVisibilityExternalIP:   visibilityMeta(r, false),
+	}
+
 	for _, name := range names {
+		if !include(name) {
+			continue
+		}
 		visibilities := []netv1alpha1.IngressVisibility{netv1alpha1.IngressVisibilityClusterLocal}
 		// If this is a public target (or not being marked as cluster-local), we also make public rule.
 		if v, ok := visibility[name]; !ok || v == netv1alpha1.IngressVisibilityExternalIP {
 			visibilities = append(visibilities, netv1alpha1.IngressVisibilityExternalIP)
 		}
 		for _, visibility := range visibilities {
-			domain, err := routeDomain(ctx, name, r, visibility)
+			domain, err := routeDomain(ctx, name, r.Name, metas[visibility])
 			if err != nil {
 				return netv1alpha1.IngressSpec{}, err
 			}
@@ -153,6 +267,23 @@ func MakeIngressSpec(
 		}
 	}
 
+	if name, ok := r.Annotations[serving.DefaultBackendAnnotationKey]; ok && include(traffic.DefaultTarget) {
+		rule, err := makeDefaultBackendRule(ctx, r.Namespace, name, targets, visibility)
+		if err != nil {
+			return netv1alpha1.IngressSpec{}, err
+		}
+		rules = append(rules, rule)
+	}
+
+	// Validated in the webhook.
+	if maintenance, _ := strconv.ParseBool(r.Annotations[serving.MaintenanceAnnotationKey]); maintenance {
+		for _, rule := range rules {
+			for _, path := range rule.HTTP.Paths {
+				makeMaintenanceSplits(path.Splits)
+			}
+		}
+	}
+
 	return netv1alpha1.IngressSpec{
 		Rules: rules,
 		TLS:   tls,
@@ -169,16 +300,19 @@ func getChallengeHosts(challenges []netv1alpha1.HTTP01Challenge) map[string]netv
 	return c
 }
 
-func routeDomain(ctx context.Context, targetName string, r *servingv1.Route, visibility netv1alpha1.IngressVisibility) (string, error) {
-	hostname, err := domains.HostnameFromTemplate(ctx, r.Name, targetName)
-	if err != nil {
-		return "", err
-	}
-
+// visibilityMeta returns a copy of the metadata of the Route, labeled with the
+// given visibility.
+func visibilityMeta(r *servingv1.Route, isClusterLocal bool) *metav1.ObjectMeta {
 	meta := r.ObjectMeta.DeepCopy()
-	isClusterLocal := visibility == netv1alpha1.IngressVisibilityClusterLocal
 	labels.SetVisibility(meta, isClusterLocal)
+	return meta
+}
 
+func routeDomain(ctx context.Context, targetName, routeName string, meta *metav1.ObjectMeta) (string, error) {
+	hostname, err := domains.HostnameFromTemplate(ctx, routeName, targetName)
+	if err != nil {
+		return "", err
+	}
 	return domains.DomainNameFromTemplate(ctx, *meta, hostname)
 }
 
@@ -219,6 +353,52 @@ func makeIngressRule(ctx context.Context,
 	}
 }
 
+// makeDefaultBackendRule creates a host-less rule routing all the traffic to the
+// named revision. Since the first matching rule takes precedence, it must come
+// last so that it only serves the requests no other rule matches.
+func makeDefaultBackendRule(ctx context.Context, ns, revisionName string,
+	targets map[string]traffic.RevisionTargets,
+	visibility map[string]netv1alpha1.IngressVisibility) (netv1alpha1.IngressRule, error) {
+	for _, rts := range targets {
+		for _, t := range rts {
+			if t.TrafficTarget.RevisionName != revisionName {
+				continue
+			}
+			// The default backend gets all the unmatched traffic.
+			t.Percent = ptr.Int64(100)
+
+			v := netv1alpha1.IngressVisibilityExternalIP
+			if vis, ok := visibility[traffic.DefaultTarget]; ok {
+				v = vis
+			}
+			return netv1alpha1.IngressRule{
+				Visibility: v,
+				HTTP: &netv1alpha1.HTTPIngressRuleValue{
+					Paths: []netv1alpha1.HTTPIngressPath{
+						*makeBaseIngressPath(ctx, ns, traffic.RevisionTargets{t}),
+					},
+				},
+			}, nil
+		}
+	}
+	return netv1alpha1.IngressRule{}, fmt.Errorf("default backend revision %q is not a traffic target of the route", revisionName)
+}
+
+// makeMaintenanceSplits routes the splits to the revisions to the activator,
+// which answers them with the maintenance response. The other splits, e.g.
+// for ACME challenges, are left untouched.
+func makeMaintenanceSplits(splits []netv1alpha1.IngressBackendSplit) {
+	for i := range splits {
+		split := &splits[i]
+		if _, ok := split.AppendHeaders[activator.RevisionHeaderName]; !ok {
+			continue
+		}
+		split.ServiceNamespace = system.Namespace()
+		split.ServiceName = networking.ActivatorServiceName
+		split.AppendHeaders[activator.MaintenanceHeaderName] = "true"
+	}
+}
+
 func makeTagBasedRoutingIngressPaths(
 	ctx context.Context, ns string, targets map[string]traffic.RevisionTargets, names []string) []netv1alpha1.HTTPIngressPath {
 	paths := make([]netv1alpha1.HTTPIngressPath, 0, len(names))
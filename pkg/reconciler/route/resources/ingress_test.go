@@ -18,6 +18,7 @@ package resources
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -27,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	network "knative.dev/networking/pkg"
 	"knative.dev/networking/pkg/apis/networking"
 	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
@@ -34,6 +36,7 @@ import (
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/ptr"
 	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/activator"
 	apiConfig "knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
@@ -228,6 +231,194 @@ func TestMakeIngressSpec_CorrectRules(t *testing.T) {
 	}
 }
 
+func TestMakeIngressSpec_DefaultBackend(t *testing.T) {
+	targets := map[string]traffic.RevisionTargets{
+		traffic.DefaultTarget: {{
+			TrafficTarget: v1.TrafficTarget{
+				ConfigurationName: "config",
+				RevisionName:      "v2",
+				Percent:           ptr.Int64(90),
+			},
+			ServiceName: "gilberto",
+			Active:      true,
+		}, {
+			TrafficTarget: v1.TrafficTarget{
+				ConfigurationName: "config",
+				RevisionName:      "v1",
+				Percent:           ptr.Int64(10),
+			},
+			ServiceName: "jobim",
+			Active:      true,
+		}},
+	}
+
+	r := Route(ns, "test-route", WithURL, WithRouteAnnotation(map[string]string{
+		serving.DefaultBackendAnnotationKey: "v1",
+	}))
+
+	// The default backend rule has no hosts, serves all the traffic from the
+	// default backend revision and comes last.
+	expected := netv1alpha1.IngressRule{
+		HTTP: &netv1alpha1.HTTPIngressRuleValue{
+			Paths: []netv1alpha1.HTTPIngressPath{{
+				Splits: []netv1alpha1.IngressBackendSplit{{
+					IngressBackend: netv1alpha1.IngressBackend{
+						ServiceNamespace: ns,
+						ServiceName:      "jobim",
+						ServicePort:      intstr.FromInt(80),
+					},
+					Percent: 100,
+					AppendHeaders: map[string]string{
+						"Knative-Serving-Revision":  "v1",
+						"Knative-Serving-Namespace": ns,
+					},
+				}},
+				Timeout: &metav1.Duration{Duration: 48 * time.Hour},
+			}},
+		},
+		Visibility: netv1alpha1.IngressVisibilityExternalIP,
+	}
+
+	ci, err := MakeIngressSpec(testContext(), r, nil, targets, nil /* visibility */)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if got, want := len(ci.Rules), 3; got != want {
+		t.Fatalf("len(Rules) = %d, want: %d", got, want)
+	}
+	if got := ci.Rules[len(ci.Rules)-1]; !cmp.Equal(expected, got) {
+		t.Error("Unexpected default backend rule (-want, +got):", cmp.Diff(expected, got))
+	}
+
+	// The default backend follows the visibility of the route.
+	ci, err = MakeIngressSpec(testContext(), r, nil, targets, map[string]netv1alpha1.IngressVisibility{
+		traffic.DefaultTarget: netv1alpha1.IngressVisibilityClusterLocal,
+	})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if got, want := ci.Rules[len(ci.Rules)-1].Visibility, netv1alpha1.IngressVisibilityClusterLocal; got != want {
+		t.Errorf("Default backend visibility = %v, want: %v", got, want)
+	}
+}
+
+func TestMakeIngressSpec_DefaultBackendNotATarget(t *testing.T) {
+	targets := map[string]traffic.RevisionTargets{
+		traffic.DefaultTarget: {{
+			TrafficTarget: v1.TrafficTarget{
+				ConfigurationName: "config",
+				RevisionName:      "v2",
+				Percent:           ptr.Int64(100),
+			},
+			ServiceName: "gilberto",
+			Active:      true,
+		}},
+	}
+	r := Route(ns, "test-route", WithURL, WithRouteAnnotation(map[string]string{
+		serving.DefaultBackendAnnotationKey: "v1",
+	}))
+
+	if _, err := MakeIngressSpec(testContext(), r, nil, targets, nil /* visibility */); err == nil {
+		t.Error("Expected error for a default backend that is not a traffic target")
+	}
+}
+
+func TestMakeIngressSpec_Maintenance(t *testing.T) {
+	targets := map[string]traffic.RevisionTargets{
+		traffic.DefaultTarget: {{
+			TrafficTarget: v1.TrafficTarget{
+				ConfigurationName: "config",
+				RevisionName:      "v1",
+				Percent:           ptr.Int64(100),
+			},
+			ServiceName: "jobim",
+			Active:      true,
+		}},
+	}
+	acmeChallenge := netv1alpha1.HTTP01Challenge{
+		ServiceNamespace: ns,
+		ServiceName:      "cm-solver",
+		ServicePort:      intstr.FromInt(8090),
+		URL: &apis.URL{
+			Scheme: "http",
+			Path:   "/.well-known/acme-challenge/challenge-token",
+			Host:   "test-route.test-ns.example.com",
+		},
+	}
+	revisionSplit := netv1alpha1.IngressBackendSplit{
+		IngressBackend: netv1alpha1.IngressBackend{
+			ServiceNamespace: ns,
+			ServiceName:      "jobim",
+			ServicePort:      intstr.FromInt(80),
+		},
+		Percent: 100,
+		AppendHeaders: map[string]string{
+			"Knative-Serving-Revision":  "v1",
+			"Knative-Serving-Namespace": ns,
+		},
+	}
+	maintenanceSplit := netv1alpha1.IngressBackendSplit{
+		IngressBackend: netv1alpha1.IngressBackend{
+			ServiceNamespace: system.Namespace(),
+			ServiceName:      "activator-service",
+			ServicePort:      intstr.FromInt(80),
+		},
+		Percent: 100,
+		AppendHeaders: map[string]string{
+			"Knative-Serving-Revision":    "v1",
+			"Knative-Serving-Namespace":   ns,
+			"Knative-Serving-Maintenance": "true",
+		},
+	}
+	acmeSplit := netv1alpha1.IngressBackendSplit{
+		IngressBackend: netv1alpha1.IngressBackend{
+			ServiceNamespace: ns,
+			ServiceName:      "cm-solver",
+			ServicePort:      intstr.FromInt(8090),
+		},
+		Percent: 100,
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        netv1alpha1.IngressBackendSplit
+	}{{
+		name: "no annotation",
+		want: revisionSplit,
+	}, {
+		name:        "entering maintenance",
+		annotations: map[string]string{serving.MaintenanceAnnotationKey: "true"},
+		want:        maintenanceSplit,
+	}, {
+		name:        "exiting maintenance",
+		annotations: map[string]string{serving.MaintenanceAnnotationKey: "false"},
+		want:        revisionSplit,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := Route(ns, "test-route", WithURL, WithRouteAnnotation(test.annotations))
+			ci, err := MakeIngressSpec(testContext(), r, nil, targets, nil /* visibility */, acmeChallenge)
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			for _, rule := range ci.Rules {
+				for _, path := range rule.HTTP.Paths {
+					want := []netv1alpha1.IngressBackendSplit{test.want}
+					if path.Path == acmeChallenge.URL.Path {
+						// ACME challenges are served regardless of maintenance.
+						want = []netv1alpha1.IngressBackendSplit{acmeSplit}
+					}
+					if !cmp.Equal(want, path.Splits) {
+						t.Errorf("Unexpected splits for %v (-want, +got): %s", rule.Hosts, cmp.Diff(want, path.Splits))
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestMakeIngressSpec_CorrectRuleVisibility(t *testing.T) {
 	cases := []struct {
 		name               string
@@ -490,6 +681,100 @@ func TestMakeIngressSpec_CorrectRulesWithTagBasedRouting(t *testing.T) {
 }
 
 // One active target.
+func TestMakeIngresses(t *testing.T) {
+	target := func(revision, service string) traffic.RevisionTargets {
+		return traffic.RevisionTargets{{
+			TrafficTarget: v1.TrafficTarget{
+				ConfigurationName: "config",
+				RevisionName:      revision,
+				Percent:           ptr.Int64(100),
+			},
+			ServiceName: service,
+			Active:      true,
+		}}
+	}
+	tc := &traffic.Config{
+		Targets: map[string]traffic.RevisionTargets{
+			traffic.DefaultTarget: target("v3", "caetano"),
+			"v1":                  target("v1", "jobim"),
+			"v2":                  target("v2", "gilberto"),
+		},
+		Visibility: map[string]netv1alpha1.IngressVisibility{
+			"v2": netv1alpha1.IngressVisibilityClusterLocal,
+		},
+	}
+	r := Route(ns, "test-route", WithRouteUID("1234-5678"), WithURL)
+
+	combined, err := MakeIngresses(testContext(), r, tc, nil, testIngressClass, false /*perTag*/)
+	if err != nil {
+		t.Fatal("MakeIngresses() =", err)
+	}
+	if len(combined) != 1 {
+		t.Fatalf("Got %d combined Ingresses, want: 1", len(combined))
+	}
+
+	// A certificate per rule, so that each Ingress only gets its own.
+	var tls []netv1alpha1.IngressTLS
+	for i, rule := range combined[0].Spec.Rules {
+		tls = append(tls, netv1alpha1.IngressTLS{
+			Hosts:      rule.Hosts,
+			SecretName: fmt.Sprint("secret-", i),
+		})
+	}
+	combined, err = MakeIngresses(testContext(), r, tc, tls, testIngressClass, false /*perTag*/)
+	if err != nil {
+		t.Fatal("MakeIngresses() =", err)
+	}
+	perTag, err := MakeIngresses(testContext(), r, tc, tls, testIngressClass, true /*perTag*/)
+	if err != nil {
+		t.Fatal("MakeIngresses() =", err)
+	}
+
+	if got, want := ingressNames(perTag), []string{"test-route", "test-route.v1", "test-route.v2"}; !cmp.Equal(got, want) {
+		t.Errorf("Per-tag Ingress names = %v, want: %v", got, want)
+	}
+	if got, want := ingressNames(combined), []string{"test-route"}; !cmp.Equal(got, want) {
+		t.Errorf("Combined Ingress names = %v, want: %v", got, want)
+	}
+
+	// Both strategies route the same traffic.
+	var (
+		rules    []netv1alpha1.IngressRule
+		gotTLS   []netv1alpha1.IngressTLS
+		perTagMD = perTag[0].ObjectMeta
+	)
+	for _, ing := range perTag {
+		rules = append(rules, ing.Spec.Rules...)
+		gotTLS = append(gotTLS, ing.Spec.TLS...)
+		if diff := cmp.Diff(perTagMD.Labels, ing.Labels); diff != "" {
+			t.Errorf("Unexpected labels of %s (-want, +got): %s", ing.Name, diff)
+		}
+		if diff := cmp.Diff(perTagMD.Annotations, ing.Annotations); diff != "" {
+			t.Errorf("Unexpected annotations of %s (-want, +got): %s", ing.Name, diff)
+		}
+		if diff := cmp.Diff(perTagMD.OwnerReferences, ing.OwnerReferences); diff != "" {
+			t.Errorf("Unexpected owner references of %s (-want, +got): %s", ing.Name, diff)
+		}
+	}
+	if diff := cmp.Diff(combined[0].Spec.Rules, rules); diff != "" {
+		t.Error("Unexpected per-tag rules (-combined, +per-tag):", diff)
+	}
+	if diff := cmp.Diff(combined[0].Spec.TLS, gotTLS); diff != "" {
+		t.Error("Unexpected per-tag TLS (-combined, +per-tag):", diff)
+	}
+	if diff := cmp.Diff(combined[0].ObjectMeta, perTagMD); diff != "" {
+		t.Error("Unexpected per-tag metadata (-combined, +per-tag):", diff)
+	}
+}
+
+func ingressNames(ingresses []*netv1alpha1.Ingress) []string {
+	names := make([]string, 0, len(ingresses))
+	for _, ing := range ingresses {
+		names = append(names, ing.Name)
+	}
+	return names
+}
+
 func TestMakeIngressRule_Vanilla(t *testing.T) {
 	targets := []traffic.RevisionTarget{{
 		TrafficTarget: v1.TrafficTarget{
@@ -916,6 +1201,46 @@ func TestMakeIngressWithTLS(t *testing.T) {
 	}
 }
 
+func TestMakeIngressWithMinTLSVersion(t *testing.T) {
+	r := Route(ns, "test-route", WithRouteUID("1234-5678"), WithURL, WithRouteAnnotation(map[string]string{
+		serving.MinTLSVersionAnnotationKey: "1.3",
+	}))
+	tls := []netv1alpha1.IngressTLS{{
+		Hosts:      []string{"test-route.test-ns.example.com"},
+		SecretName: "secret",
+	}}
+
+	tests := []struct {
+		name string
+		tls  []netv1alpha1.IngressTLS
+		want string
+	}{{
+		name: "with TLS",
+		tls:  tls,
+		want: "1.3",
+	}, {
+		name: "without TLS",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := MakeIngress(testContext(), r, &traffic.Config{}, test.tls, testIngressClass)
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			if v := got.Annotations[IngressMinTLSVersionAnnotationKey]; v != test.want {
+				t.Errorf("Annotation %s = %q, want: %q", IngressMinTLSVersionAnnotationKey, v, test.want)
+			}
+			if _, ok := got.Annotations[serving.MinTLSVersionAnnotationKey]; ok {
+				t.Errorf("Annotation %s was propagated, want it translated", serving.MinTLSVersionAnnotationKey)
+			}
+			if !cmp.Equal(got.Spec.TLS, test.tls, cmpopts.EquateEmpty()) {
+				t.Error("Unexpected TLS (-want, +got):", cmp.Diff(test.tls, got.Spec.TLS, cmpopts.EquateEmpty()))
+			}
+		})
+	}
+}
+
 func TestMakeIngressTLS(t *testing.T) {
 	cert := &netv1alpha1.Certificate{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1116,6 +1441,78 @@ func TestMakeIngressFailToGenerateTagHost(t *testing.T) {
 	}
 }
 
+func TestMakeIngressSpec_ManyTargets(t *testing.T) {
+	const numTags = 100
+	r := Route("default", "myroute", WithURL)
+	target := func(rev string) traffic.RevisionTargets {
+		return traffic.RevisionTargets{{
+			TrafficTarget: v1.TrafficTarget{
+				ConfigurationName: "config",
+				RevisionName:      rev,
+				Percent:           ptr.Int64(100),
+			},
+			ServiceName: rev + "-service",
+			Active:      true,
+		}}
+	}
+
+	type want struct {
+		visibility netv1alpha1.IngressVisibility
+		revision   string
+	}
+	targets := map[string]traffic.RevisionTargets{
+		traffic.DefaultTarget: target("default"),
+	}
+	visibility := map[string]netv1alpha1.IngressVisibility{}
+	wantRules := map[string]want{
+		"myroute.default.svc.cluster.local": {netv1alpha1.IngressVisibilityClusterLocal, "default"},
+		"myroute.default.example.com":       {netv1alpha1.IngressVisibilityExternalIP, "default"},
+	}
+	for i := 0; i < numTags; i++ {
+		tag := fmt.Sprintf("tag%03d", i)
+		rev := fmt.Sprintf("rev%03d", i)
+		targets[tag] = target(rev)
+		wantRules[tag+"-myroute.default.svc.cluster.local"] = want{netv1alpha1.IngressVisibilityClusterLocal, rev}
+		// Every other tag is cluster-local.
+		if i%2 == 0 {
+			visibility[tag] = netv1alpha1.IngressVisibilityClusterLocal
+		} else {
+			wantRules[tag+"-myroute.default.example.com"] = want{netv1alpha1.IngressVisibilityExternalIP, rev}
+		}
+	}
+
+	spec, err := MakeIngressSpec(testContext(), r, nil, targets, visibility)
+	if err != nil {
+		t.Fatal("MakeIngressSpec() =", err)
+	}
+
+	if got, want := len(spec.Rules), len(wantRules); got != want {
+		t.Fatalf("Got %d rules, want: %d", got, want)
+	}
+	for _, rule := range spec.Rules {
+		host := rule.Hosts[0]
+		w, ok := wantRules[host]
+		if !ok {
+			t.Errorf("Unexpected rule for host %q", host)
+			continue
+		}
+		delete(wantRules, host)
+		if rule.Visibility != w.visibility {
+			t.Errorf("Visibility of %q = %s, want: %s", host, rule.Visibility, w.visibility)
+		}
+		splits := rule.HTTP.Paths[0].Splits
+		if len(splits) != 1 || splits[0].ServiceName != w.revision+"-service" ||
+			splits[0].AppendHeaders[activator.RevisionHeaderName] != w.revision {
+			t.Errorf("Splits of %q = %#v, want a single split to %s", host, splits, w.revision)
+		}
+	}
+
+	// The metadata of the Route is left alone.
+	if _, ok := r.Labels[serving.VisibilityLabelKey]; ok {
+		t.Error("The visibility label leaked to the Route")
+	}
+}
+
 func testContext() context.Context {
 	ctx := context.Background()
 	cfg := testConfig()
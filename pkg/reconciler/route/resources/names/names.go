@@ -35,6 +35,14 @@ func Ingress(route kmeta.Accessor) string {
 	return kmeta.ChildName(route.GetName(), "")
 }
 
+// TagIngress returns the name for the Ingress child resource routing the
+// given tag of the Route, when each tag has an Ingress of its own. The names
+// of the Routes cannot contain dots, so it cannot be the name of the Ingress
+// of another Route.
+func TagIngress(route kmeta.Accessor, tag string) string {
+	return kmeta.ChildName(route.GetName(), "."+tag)
+}
+
 // Certificate returns the name for the Certificate
 // child resource for the given Route.
 func Certificate(route kmeta.Accessor) string {
@@ -64,6 +64,12 @@ func TestNamer(t *testing.T) {
 	}
 }
 
+func TestTagIngress(t *testing.T) {
+	if got, want := TagIngress(getRoute("bar", "default", ""), "blue"), "bar.blue"; got != want {
+		t.Errorf("TagIngress() = %v, wanted %v", got, want)
+	}
+}
+
 func getRoute(name, ns string, uid types.UID) *v1.Route {
 	return &v1.Route{
 		ObjectMeta: metav1.ObjectMeta{
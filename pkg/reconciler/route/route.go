@@ -18,8 +18,13 @@ package route
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
 	"sort"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	kubelabels "k8s.io/apimachinery/pkg/labels"
@@ -36,6 +41,7 @@ import (
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/logging"
+	pkgnet "knative.dev/pkg/network"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
 	"knative.dev/pkg/tracker"
@@ -65,11 +71,44 @@ type Reconciler struct {
 	configurationLister listers.ConfigurationLister
 	revisionLister      listers.RevisionLister
 	serviceLister       corev1listers.ServiceLister
+	secretLister        corev1listers.SecretLister
 	ingressLister       networkinglisters.IngressLister
 	certificateLister   networkinglisters.CertificateLister
 	tracker             tracker.Interface
 
-	clock system.Clock
+	clock        system.Clock
+	enqueueAfter func(interface{}, time.Duration)
+
+	// newResolver returns the resolver checking the hosts of the Routes,
+	// given the address of its DNS server.
+	newResolver func(address string) hostResolver
+}
+
+// dnsRecheckInterval is how long to wait before resolving the host of a
+// Route again, when it didn't resolve to its Ingress yet.
+const dnsRecheckInterval = 10 * time.Second
+
+// dnsLookupTimeout bounds the lookups of the hosts of a Route.
+const dnsLookupTimeout = 2 * time.Second
+
+// hostResolver looks up the addresses of hosts, as *net.Resolver does.
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// newHostResolver returns a resolver querying the DNS server at address, or
+// the system resolver when address is empty.
+func newHostResolver(address string) hostResolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, proto, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, proto, address)
+		},
+	}
 }
 
 // Check that our Reconciler implements routereconciler.Interface
@@ -107,6 +146,8 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, r *v1.Route) pkgreconcil
 	logger := logging.FromContext(ctx)
 	logger.Debugf("Reconciling route: %#v", r.Spec)
 
+	markTrafficTargetCount(ctx, r)
+
 	// Configure traffic based on the RouteSpec.
 	traffic, err := c.configureTraffic(ctx, r)
 	if traffic == nil || err != nil {
@@ -139,22 +180,22 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, r *v1.Route) pkgreconcil
 	if err != nil {
 		return err
 	}
+	c.checkCertificateExpiry(ctx, r, tls)
 
 	// Reconcile ingress and its children resources.
-	ingress, err := c.reconcileIngressResources(ctx, r, traffic, tls, ingressClassForRoute(ctx, r), acmeChallenges...)
+	ingresses, err := c.reconcileIngressResources(ctx, r, traffic, tls, ingressClassForRoute(ctx, r), acmeChallenges...)
 
 	if err != nil {
 		return err
 	}
 
-	if ingress.GetObjectMeta().GetGeneration() != ingress.Status.ObservedGeneration {
-		r.Status.MarkIngressNotConfigured()
-	} else {
-		r.Status.PropagateIngressStatus(ingress.Status)
-	}
+	propagateIngressStatus(r, ingresses)
+	c.checkDNSReadiness(ctx, r, ingresses[0])
 
+	// The Ingresses of the Route are all of the same class, so they share
+	// their load balancers.
 	logger.Info("Updating placeholder k8s services with ingress information")
-	if err := c.updatePlaceholderServices(ctx, r, services, ingress); err != nil {
+	if err := c.updatePlaceholderServices(ctx, r, services, ingresses[0]); err != nil {
 		return err
 	}
 
@@ -162,20 +203,193 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, r *v1.Route) pkgreconcil
 	return nil
 }
 
+// markTrafficTargetCount warns about the Routes with more traffic targets than
+// the configured threshold, since each target adds to the size of the Ingress.
+func markTrafficTargetCount(ctx context.Context, r *v1.Route) {
+	var threshold int64
+	if defaults := config.FromContext(ctx).Defaults; defaults != nil {
+		threshold = defaults.TrafficTargetsWarningThreshold
+	}
+	if count := len(r.Spec.Traffic); threshold > 0 && int64(count) > threshold {
+		r.Status.MarkTrafficTargetsExceedThreshold(count, int(threshold))
+	} else {
+		r.Status.MarkTrafficTargetsWithinThreshold()
+	}
+}
+
+// checkCertificateExpiry warns about the Routes served by a certificate which
+// expires within the configured threshold. The expiry is read from the
+// tls.crt of the secrets, so the externally provided certificates are
+// checked as well.
+func (c *Reconciler) checkCertificateExpiry(ctx context.Context, r *v1.Route, tls []netv1alpha1.IngressTLS) {
+	var threshold time.Duration
+	if defaults := config.FromContext(ctx).Defaults; defaults != nil {
+		threshold = defaults.CertificateExpiryWarningThreshold
+	}
+	if threshold <= 0 {
+		r.Status.MarkCertificatesNotExpiring()
+		return
+	}
+
+	var soonest time.Time
+	var soonestSecret string
+	for _, t := range tls {
+		secret, err := c.secretLister.Secrets(t.SecretNamespace).Get(t.SecretName)
+		if err != nil {
+			// The Ingress reports the secrets it can't use, the warning
+			// isn't worth failing the Route over.
+			logging.FromContext(ctx).Warnf("Failed to get the certificate secret %s/%s: %v", t.SecretNamespace, t.SecretName, err)
+			continue
+		}
+		notAfter, err := certificateNotAfter(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			r.Status.MarkCertificateInvalid(t.SecretName, err.Error())
+			return
+		}
+		if soonestSecret == "" || notAfter.Before(soonest) {
+			soonest, soonestSecret = notAfter, t.SecretName
+		}
+	}
+
+	now := c.clock.Now()
+	switch {
+	case soonestSecret == "":
+		r.Status.MarkCertificatesNotExpiring()
+	case soonest.Sub(now) <= threshold:
+		r.Status.MarkCertificateExpiring(soonestSecret, soonest, threshold)
+	default:
+		r.Status.MarkCertificatesNotExpiring()
+		// Nothing notifies us of the passing time, so check again once the
+		// certificate gets within the threshold.
+		c.enqueueAfter(r, soonest.Sub(now)-threshold)
+	}
+}
+
+// certificateNotAfter returns the expiry of the leaf certificate of the PEM
+// encoded chain.
+func certificateNotAfter(chain []byte) (time.Time, error) {
+	block, _ := pem.Decode(chain)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return time.Time{}, errors.New("no PEM encoded certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
+
+// propagateIngressStatus reflects the status of the Ingresses of the Route in
+// its status, which is only as ready as the least ready of them.
+func propagateIngressStatus(r *v1.Route, ingresses []*netv1alpha1.Ingress) {
+	for _, ingress := range ingresses {
+		if ingress.GetObjectMeta().GetGeneration() != ingress.Status.ObservedGeneration {
+			r.Status.MarkIngressNotConfigured()
+			return
+		}
+	}
+	for _, ingress := range ingresses {
+		if !ingress.IsReady() {
+			r.Status.PropagateIngressStatus(ingress.Status)
+			return
+		}
+	}
+	r.Status.PropagateIngressStatus(ingresses[0].Status)
+}
+
+// checkDNSReadiness keeps the Route from becoming Ready until its host and
+// the hosts of its tags resolve to the public load balancer of its Ingress,
+// for the external DNS programmed by something other than Knative.
+func (c *Reconciler) checkDNSReadiness(ctx context.Context, r *v1.Route, ingress *netv1alpha1.Ingress) {
+	features := config.FromContext(ctx).Features
+	if features == nil || features.RouteDNSReadiness != cfgmap.Enabled || !r.Status.IsReady() {
+		return
+	}
+	hosts := []string{r.Status.URL.Host}
+	for _, target := range r.Status.Traffic {
+		if target.Tag != "" && target.URL != nil {
+			hosts = append(hosts, target.URL.Host)
+		}
+	}
+
+	resolver := c.newResolver(features.RouteDNSResolver)
+	for _, host := range hosts {
+		// Cluster-local hosts aren't published in the external DNS.
+		if strings.HasSuffix(host, "."+pkgnet.GetClusterDomainName()) {
+			continue
+		}
+		// Don't hold the reconcile worker for long on a slow DNS server,
+		// we check again later anyway.
+		lookupCtx, cancel := context.WithTimeout(ctx, dnsLookupTimeout)
+		addrs, err := resolver.LookupHost(lookupCtx, host)
+		resolved := err == nil && resolvesToIngress(lookupCtx, resolver, addrs, ingress)
+		cancel()
+		if resolved {
+			continue
+		}
+		logging.FromContext(ctx).Infof("Host %q resolves to %v (err: %v), not to the Ingress yet", host, addrs, err)
+		r.Status.MarkDNSNotResolved(host)
+		// Nothing notifies us of the changes to the DNS, so check again later.
+		c.enqueueAfter(r, dnsRecheckInterval)
+		return
+	}
+}
+
+// resolvesToIngress returns whether the addresses include one of the public
+// load balancer of the Ingress, given by its IP or resolved from its domain.
+// Any address will do when the Ingress reports neither.
+func resolvesToIngress(ctx context.Context, resolver hostResolver, addrs []string, ingress *netv1alpha1.Ingress) bool {
+	expected, want := false, sets.NewString()
+	if lb := ingress.Status.PublicLoadBalancer; lb != nil {
+		for _, balancer := range lb.Ingress {
+			switch {
+			case balancer.IP != "":
+				expected = true
+				want.Insert(balancer.IP)
+			case balancer.Domain != "":
+				expected = true
+				if ips, err := resolver.LookupHost(ctx, balancer.Domain); err == nil {
+					want.Insert(ips...)
+				}
+			}
+		}
+	}
+	if !expected {
+		return len(addrs) > 0
+	}
+	return want.HasAny(addrs...)
+}
+
+// reconcileIngressResources reconciles the Ingresses of the Route, and returns
+// them with the one named after the Route first.
 func (c *Reconciler) reconcileIngressResources(ctx context.Context, r *v1.Route, tc *traffic.Config, tls []netv1alpha1.IngressTLS,
-	ingressClass string, acmeChallenges ...netv1alpha1.HTTP01Challenge) (*netv1alpha1.Ingress, error) {
+	ingressClass string, acmeChallenges ...netv1alpha1.HTTP01Challenge) ([]*netv1alpha1.Ingress, error) {
 
-	desired, err := resources.MakeIngress(ctx, r, tc, tls, ingressClass, acmeChallenges...)
+	features := config.FromContext(ctx).Features
+	perTag := features != nil && features.PerTagIngress == cfgmap.Enabled
+	desired, err := resources.MakeIngresses(ctx, r, tc, tls, ingressClass, perTag, acmeChallenges...)
 	if err != nil {
 		return nil, err
 	}
-
-	ingress, err := c.reconcileIngress(ctx, r, desired)
+	draining, err := c.drainingTargets(ctx, r, tc)
 	if err != nil {
 		return nil, err
 	}
+	ingresses := make([]*netv1alpha1.Ingress, 0, len(desired))
+	for _, d := range desired {
+		resources.AddDrainingTargets(d, draining)
+		ingress, err := c.reconcileIngress(ctx, r, d)
+		if err != nil {
+			return nil, err
+		}
+		ingresses = append(ingresses, ingress)
+	}
 
-	return ingress, nil
+	if err := c.deleteOrphanedIngresses(r, desired); err != nil {
+		return nil, err
+	}
+
+	return ingresses, nil
 }
 
 func (c *Reconciler) tls(ctx context.Context, host string, r *v1.Route, traffic *traffic.Config) ([]netv1alpha1.IngressTLS, []netv1alpha1.HTTP01Challenge, error) {
@@ -267,12 +481,22 @@ func (c *Reconciler) tls(ctx context.Context, host string, r *v1.Route, traffic
 //
 // If traffic is configured we update the RouteStatus with AllTrafficAssigned = True.  Otherwise we
 // mark AllTrafficAssigned = False, with a message referring to one of the missing target.
+//
+// With the tolerate-missing-revisions feature, the targets naming missing Revisions are dropped
+// instead, and the others configured with AllTrafficAssigned = False naming the missing Revisions.
 func (c *Reconciler) configureTraffic(ctx context.Context, r *v1.Route) (*traffic.Config, error) {
 	logger := logging.FromContext(ctx)
 	t, trafficErr := traffic.BuildTrafficConfiguration(c.configurationLister, c.revisionLister, r)
 	if t == nil {
 		return nil, trafficErr
 	}
+	var notFound []string
+	if features := config.FromContext(ctx).Features; trafficErr != nil &&
+		features != nil && features.TolerateMissingRevisions == cfgmap.Enabled {
+		if pruned, missing := c.withoutMissingRevisions(r, t); pruned != nil {
+			t, trafficErr, notFound = pruned, nil, missing
+		}
+	}
 	// Augment traffic configuration with visibility information.  Do not overwrite trafficErr,
 	// since we will use it later.
 	visibility, err := visibility.NewResolver(c.serviceLister).GetVisibility(ctx, r)
@@ -327,19 +551,85 @@ func (c *Reconciler) configureTraffic(ctx context.Context, r *v1.Route) (*traffi
 		return nil, nil
 	}
 
-	logger.Info("All referred targets are routable, marking AllTrafficAssigned with traffic information.")
-
 	// Domain should already be present
 	r.Status.Traffic, err = t.GetRevisionTrafficTargets(ctx, r)
 	if err != nil {
 		return nil, err
 	}
+	if features := config.FromContext(ctx).Features; features != nil &&
+		features.RouteSortedTraffic == cfgmap.Enabled {
+		sortTrafficTargets(r.Status.Traffic)
+	}
 
-	r.Status.MarkTrafficAssigned()
+	if len(notFound) > 0 {
+		logger.Info("Routing the traffic of the missing Revisions to the other targets: ", notFound)
+		r.Status.MarkTrafficTargetNotFound(notFound...)
+	} else {
+		logger.Info("All referred targets are routable, marking AllTrafficAssigned with traffic information.")
+		r.Status.MarkTrafficAssigned()
+	}
 
 	return t, nil
 }
 
+// withoutMissingRevisions builds the traffic configuration of the Route without
+// the targets naming the missing Revisions of t, whose percent is shared by the
+// other targets in proportion to theirs. It returns the configuration and the
+// names of the missing Revisions, or nil if the other targets aren't routable.
+func (c *Reconciler) withoutMissingRevisions(r *v1.Route, t *traffic.Config) (*traffic.Config, []string) {
+	missing := sets.NewString()
+	for _, obj := range t.MissingTargets {
+		if obj.Kind == "Revision" {
+			missing.Insert(obj.Name)
+		}
+	}
+	if missing.Len() == 0 {
+		return nil, nil
+	}
+
+	var kept int64
+	targets := make([]v1.TrafficTarget, 0, len(r.Spec.Traffic))
+	for _, tt := range r.Spec.Traffic {
+		if tt.RevisionName != "" && missing.Has(tt.RevisionName) {
+			continue
+		}
+		if tt.Percent != nil {
+			kept += *tt.Percent
+		}
+		targets = append(targets, *tt.DeepCopy())
+	}
+	if kept == 0 {
+		// No traffic left to serve.
+		return nil, nil
+	}
+	if kept < 100 {
+		var total int64
+		first := -1
+		for i := range targets {
+			if p := targets[i].Percent; p != nil && *p > 0 {
+				*p = *p * 100 / kept
+				total += *p
+				if first < 0 {
+					first = i
+				}
+			}
+		}
+		// The rounding remainder goes to the first target.
+		*targets[first].Percent += 100 - total
+	}
+
+	pruned := r.DeepCopy()
+	pruned.Spec.Traffic = targets
+	pt, err := traffic.BuildTrafficConfiguration(c.configurationLister, c.revisionLister, pruned)
+	if pt == nil || err != nil {
+		// Other targets aren't routable either.
+		return nil, nil
+	}
+	// Keep tracking the missing targets, to route to them once they appear.
+	pt.MissingTargets = t.MissingTargets
+	return pt, missing.List()
+}
+
 func (c *Reconciler) updateRouteStatusURL(ctx context.Context, route *v1.Route, visibility map[string]netv1alpha1.IngressVisibility) error {
 	isClusterLocal := visibility[traffic.DefaultTarget] == netv1alpha1.IngressVisibilityClusterLocal
 
@@ -398,6 +688,17 @@ func getTrafficNames(targets map[string]traffic.RevisionTargets) []string {
 	return names
 }
 
+// sortTrafficTargets orders the traffic targets by tag, the untagged first,
+// and then by Revision name, so the status is stable across reconciles.
+func sortTrafficTargets(targets []v1.TrafficTarget) {
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].Tag != targets[j].Tag {
+			return targets[i].Tag < targets[j].Tag
+		}
+		return targets[i].RevisionName < targets[j].RevisionName
+	})
+}
+
 // Sets the traffic URL scheme to scheme if the URL matches the dnsNames.
 // dnsNames are DNS names under a certificate for a particular domain, and so only change
 // the corresponding traffic under the route, rather than all traffic
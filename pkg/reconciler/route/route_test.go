@@ -18,7 +18,14 @@ package route
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"strings"
 	"testing"
 	"time"
@@ -27,6 +34,8 @@ import (
 	fakenetworkingclient "knative.dev/networking/pkg/client/injection/client/fake"
 	_ "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/certificate/fake"
 	fakeingressinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/endpoints/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/secret/fake"
 	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/service/fake"
 	fakeservingclient "knative.dev/serving/pkg/client/injection/client/fake"
 	fakecfginformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/configuration/fake"
@@ -43,15 +52,20 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/wait"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 
 	network "knative.dev/networking/pkg"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/apis"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/ptr"
 	"knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
+	"knative.dev/pkg/tracker"
+	apisconfig "knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	"knative.dev/serving/pkg/gc"
@@ -1269,6 +1283,86 @@ func TestCreateRouteWithNamedTargetsAndTagBasedRouting(t *testing.T) {
 	}
 }
 
+func TestCreateRouteWithSortedTraffic(t *testing.T) {
+	ctx, _, ctl, watcher, cf := newTestSetup(t)
+	defer cf()
+	watcher.OnChange(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      apisconfig.FeaturesConfigName,
+			Namespace: system.Namespace(),
+		},
+		Data: map[string]string{"route-sorted-traffic": "Enabled"},
+	})
+
+	for _, name := range []string{"rev-a", "rev-b"} {
+		rev := Revision(testNamespace, name, MarkRevisionReady, WithK8sServiceName(name))
+		fakeservingclient.Get(ctx).ServingV1().Revisions(testNamespace).Create(rev)
+		fakerevisioninformer.Get(ctx).Informer().GetIndexer().Add(rev)
+	}
+
+	targets := []v1.TrafficTarget{{
+		Tag:          "foo",
+		RevisionName: "rev-b",
+		Percent:      ptr.Int64(25),
+	}, {
+		RevisionName: "rev-b",
+		Percent:      ptr.Int64(25),
+	}, {
+		Tag:          "bar",
+		RevisionName: "rev-a",
+		Percent:      ptr.Int64(25),
+	}, {
+		RevisionName: "rev-a",
+		Percent:      ptr.Int64(25),
+	}}
+	route := Route(testNamespace, "test-route", WithSpecTraffic(targets...))
+	fakeservingclient.Get(ctx).ServingV1().Routes(testNamespace).Create(route)
+	fakerouteinformer.Get(ctx).Informer().GetIndexer().Add(route)
+
+	target := func(tag, revision string) v1.TrafficTarget {
+		tt := v1.TrafficTarget{
+			Tag:            tag,
+			RevisionName:   revision,
+			Percent:        ptr.Int64(25),
+			LatestRevision: ptr.Bool(false),
+		}
+		if tag != "" {
+			tt.URL = &apis.URL{
+				Scheme: "http",
+				Host:   fmt.Sprintf("%s-%s.%s.%s", tag, route.Name, route.Namespace, defaultDomainSuffix),
+			}
+		}
+		return tt
+	}
+	want := []v1.TrafficTarget{
+		target("", "rev-a"),
+		target("", "rev-b"),
+		target("bar", "rev-a"),
+		target("foo", "rev-b"),
+	}
+
+	// The status is sorted the same way whatever the order of the spec.
+	for i := 0; i < 2; i++ {
+		ctl.Reconciler.Reconcile(context.Background(), KeyOrDie(route))
+
+		got, err := fakeservingclient.Get(ctx).ServingV1().Routes(testNamespace).Get(route.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("Route.Get() =", err)
+		}
+		if !cmp.Equal(got.Status.Traffic, want) {
+			t.Errorf("Reconcile %d: Status.Traffic (-want, +got): %s", i, cmp.Diff(want, got.Status.Traffic))
+		}
+
+		// Reverse the targets of the spec for the next reconcile.
+		got.Spec.Traffic = nil
+		for j := len(targets) - 1; j >= 0; j-- {
+			got.Spec.Traffic = append(got.Spec.Traffic, targets[j])
+		}
+		fakeservingclient.Get(ctx).ServingV1().Routes(testNamespace).Update(got)
+		fakerouteinformer.Get(ctx).Informer().GetIndexer().Update(got)
+	}
+}
+
 func TestUpdateDomainConfigMap(t *testing.T) {
 	templateCM := corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1569,3 +1663,239 @@ func TestRouteDomain(t *testing.T) {
 		})
 	}
 }
+
+func TestMarkTrafficTargetCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		defaults  *apisconfig.Defaults
+		targets   int
+		wantExist bool
+	}{{
+		name:    "no defaults",
+		targets: 10,
+	}, {
+		name:     "threshold disabled",
+		defaults: &apisconfig.Defaults{},
+		targets:  10,
+	}, {
+		name:     "within threshold",
+		defaults: &apisconfig.Defaults{TrafficTargetsWarningThreshold: 10},
+		targets:  10,
+	}, {
+		name:      "exceeds threshold",
+		defaults:  &apisconfig.Defaults{TrafficTargetsWarningThreshold: 10},
+		targets:   11,
+		wantExist: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := Route(testNamespace, "many-targets")
+			r.Status.InitializeConditions()
+			// Start from a stale warning, to check it gets cleared.
+			r.Status.MarkTrafficTargetsExceedThreshold(1, 0)
+			for i := 0; i < test.targets; i++ {
+				r.Spec.Traffic = append(r.Spec.Traffic, v1.TrafficTarget{
+					Tag:          fmt.Sprint("tag-", i),
+					RevisionName: fmt.Sprint("rev-", i),
+				})
+			}
+
+			ctx := config.ToContext(context.Background(), &config.Config{Defaults: test.defaults})
+			markTrafficTargetCount(ctx, r)
+
+			cond := r.Status.GetCondition(v1.RouteConditionTrafficTargetsWithinThreshold)
+			if got := cond != nil; got != test.wantExist {
+				t.Fatalf("Condition exists = %v, want: %v", got, test.wantExist)
+			}
+			if cond != nil && (!cond.IsFalse() || cond.Severity != apis.ConditionSeverityWarning) {
+				t.Errorf("Condition = %#v, want a False warning", cond)
+			}
+		})
+	}
+}
+
+func TestCheckCertificateExpiry(t *testing.T) {
+	now := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	tls := []v1alpha1.IngressTLS{{
+		SecretName:      "soon",
+		SecretNamespace: testNamespace,
+	}, {
+		SecretName:      "later",
+		SecretNamespace: testNamespace,
+	}}
+
+	tests := []struct {
+		name        string
+		defaults    *apisconfig.Defaults
+		secrets     []*corev1.Secret
+		wantReason  string
+		wantRequeue time.Duration
+	}{{
+		name:    "no defaults",
+		secrets: []*corev1.Secret{certSecret(t, "soon", now.Add(time.Hour))},
+	}, {
+		name:     "threshold disabled",
+		defaults: &apisconfig.Defaults{},
+		secrets:  []*corev1.Secret{certSecret(t, "soon", now.Add(time.Hour))},
+	}, {
+		name:     "valid certificates",
+		defaults: &apisconfig.Defaults{CertificateExpiryWarningThreshold: 24 * time.Hour},
+		secrets: []*corev1.Secret{
+			certSecret(t, "soon", now.Add(72*time.Hour)),
+			certSecret(t, "later", now.Add(720*time.Hour)),
+		},
+		wantRequeue: 48 * time.Hour,
+	}, {
+		name:     "certificate near expiry",
+		defaults: &apisconfig.Defaults{CertificateExpiryWarningThreshold: 24 * time.Hour},
+		secrets: []*corev1.Secret{
+			certSecret(t, "soon", now.Add(time.Hour)),
+			certSecret(t, "later", now.Add(720*time.Hour)),
+		},
+		wantReason: "CertificateExpiring",
+	}, {
+		name:     "invalid certificate",
+		defaults: &apisconfig.Defaults{CertificateExpiryWarningThreshold: 24 * time.Hour},
+		secrets: []*corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{Name: "soon", Namespace: testNamespace},
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte("garbage")},
+		}},
+		wantReason: "CertificateInvalid",
+	}, {
+		name:     "missing secrets",
+		defaults: &apisconfig.Defaults{CertificateExpiryWarningThreshold: 24 * time.Hour},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, s := range test.secrets {
+				indexer.Add(s)
+			}
+			var requeue time.Duration
+			c := &Reconciler{
+				secretLister: corev1listers.NewSecretLister(indexer),
+				clock:        FakeClock{Time: now},
+				enqueueAfter: func(_ interface{}, d time.Duration) {
+					requeue = d
+				},
+			}
+
+			r := Route(testNamespace, "tls")
+			r.Status.InitializeConditions()
+			// Start from a stale warning, to check it gets cleared.
+			r.Status.MarkCertificateInvalid("stale", "stale")
+
+			ctx := config.ToContext(context.Background(), &config.Config{Defaults: test.defaults})
+			c.checkCertificateExpiry(ctx, r, tls)
+
+			cond := r.Status.GetCondition(v1.RouteConditionCertificateNotExpiring)
+			if test.wantReason == "" {
+				if cond != nil {
+					t.Errorf("Condition = %#v, want: nil", cond)
+				}
+			} else if cond == nil || !cond.IsFalse() || cond.Severity != apis.ConditionSeverityWarning ||
+				cond.Reason != test.wantReason {
+				t.Errorf("Condition = %#v, want a False warning with reason %s", cond, test.wantReason)
+			}
+			if requeue != test.wantRequeue {
+				t.Errorf("Requeued after %v, want: %v", requeue, test.wantRequeue)
+			}
+		})
+	}
+}
+
+// certSecret returns a TLS secret holding a self-signed certificate expiring
+// at notAfter.
+func certSecret(t *testing.T, name string, notAfter time.Time) *corev1.Secret {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("Failed to generate the key:", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal("Failed to create the certificate:", err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		},
+	}
+}
+
+func TestResponsiveRouteReadiness(t *testing.T) {
+	ctx, cancel, _ := SetupFakeContextWithCancel(t)
+	defer cancel()
+
+	store := config.NewStore(ctx)
+	filter := responsiveReadinessFilter(store)
+	if filter(nil) {
+		t.Error("Filter accepted the endpoints before the features were loaded")
+	}
+	for flag, want := range map[string]bool{"Enabled": true, "Disabled": false, "Allowed": false} {
+		store.OnConfigChanged(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apisconfig.FeaturesConfigName,
+				Namespace: system.Namespace(),
+			},
+			Data: map[string]string{"responsive-route-readiness": flag},
+		})
+		if got := filter(nil); got != want {
+			t.Errorf("Filter with the feature %s = %v, want: %v", flag, got, want)
+		}
+	}
+
+	rev := Revision(testNamespace, "ready-rev")
+	fakerevisioninformer.Get(ctx).Informer().GetIndexer().Add(rev)
+
+	var requeued []types.NamespacedName
+	tr := tracker.New(func(key types.NamespacedName) {
+		requeued = append(requeued, key)
+	}, time.Minute)
+	route := Route(testNamespace, "ready-route")
+	if err := tr.TrackReference(tracker.Reference{
+		APIVersion: v1.SchemeGroupVersion.String(),
+		Kind:       "Revision",
+		Namespace:  testNamespace,
+		Name:       rev.Name,
+	}, route); err != nil {
+		t.Fatal("TrackReference() =", err)
+	}
+	requeued = nil
+
+	handler := revisionOfEndpoints(fakerevisioninformer.Get(ctx).Lister(),
+		controller.EnsureTypeMeta(tr.OnChanged, v1.SchemeGroupVersion.WithKind("Revision")))
+	endpoints := func(revName string) *corev1.Endpoints {
+		return &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNamespace,
+				Name:      revName + "-private",
+				Labels:    map[string]string{serving.RevisionLabelKey: revName},
+			},
+		}
+	}
+
+	handler(endpoints("unknown-rev"))
+	if len(requeued) != 0 {
+		t.Errorf("Requeued = %v for the endpoints of an unknown Revision", requeued)
+	}
+
+	handler(endpoints(rev.Name))
+	want := []types.NamespacedName{{Namespace: testNamespace, Name: route.Name}}
+	if !cmp.Equal(requeued, want) {
+		t.Errorf("Requeued = %v, want: %v", requeued, want)
+	}
+}
@@ -19,6 +19,7 @@ package route
 import (
 	"context"
 	"fmt"
+	"net"
 	"testing"
 	"time"
 
@@ -27,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	clientgotesting "k8s.io/client-go/testing"
 
 	network "knative.dev/networking/pkg"
@@ -1800,6 +1802,7 @@ func TestReconcile(t *testing.T) {
 			configurationLister: listers.GetConfigurationLister(),
 			revisionLister:      listers.GetRevisionLister(),
 			serviceLister:       listers.GetK8sServiceLister(),
+			secretLister:        listers.GetSecretLister(),
 			ingressLister:       listers.GetIngressLister(),
 			tracker:             ctx.Value(TrackerKey).(tracker.Interface),
 			clock:               FakeClock{Time: fakeCurTime},
@@ -1811,6 +1814,153 @@ func TestReconcile(t *testing.T) {
 	}))
 }
 
+func TestReconcile_TolerateMissingRevisions(t *testing.T) {
+	partialTraffic := WithSpecTraffic(
+		v1.TrafficTarget{
+			ConfigurationName: "blue",
+			Percent:           ptr.Int64(30),
+		}, v1.TrafficTarget{
+			RevisionName: "green-00001",
+			Percent:      ptr.Int64(40),
+		}, v1.TrafficTarget{
+			RevisionName: "not-found",
+			Percent:      ptr.Int64(30),
+		})
+	table := TableTest{{
+		Name: "missing revision next to valid targets",
+		Objects: []runtime.Object{
+			Route("default", "partial", WithRouteGeneration(1), partialTraffic,
+				WithRouteUID("12-34"), WithRouteFinalizer),
+			cfg("default", "blue",
+				WithConfigGeneration(1), WithLatestCreated("blue-00001"), WithLatestReady("blue-00001")),
+			cfg("default", "green",
+				WithConfigGeneration(1), WithLatestCreated("green-00001"), WithLatestReady("green-00001")),
+			rev("default", "blue", 1, MarkRevisionReady, WithRevName("blue-00001"), WithServiceName("blue-ridge")),
+			rev("default", "green", 1, MarkRevisionReady, WithRevName("green-00001"), WithServiceName("green-lake")),
+		},
+		WantCreates: []runtime.Object{
+			simpleIngress(
+				Route("default", "partial", WithURL, WithRouteGeneration(1), partialTraffic, WithRouteUID("12-34")),
+				&traffic.Config{
+					Targets: map[string]traffic.RevisionTargets{
+						traffic.DefaultTarget: {{
+							TrafficTarget: v1.TrafficTarget{
+								// The 30% of the missing revision are shared
+								// by the others, the remainder going first.
+								RevisionName: "blue-00001",
+								Percent:      ptr.Int64(43),
+							},
+							ServiceName: "blue-ridge",
+							Active:      true,
+						}, {
+							TrafficTarget: v1.TrafficTarget{
+								RevisionName: "green-00001",
+								Percent:      ptr.Int64(57),
+							},
+							ServiceName: "green-lake",
+							Active:      true,
+						}},
+					},
+				},
+			),
+			simplePlaceholderK8sService(
+				getContext(),
+				Route("default", "partial", WithRouteGeneration(1), partialTraffic,
+					WithRouteUID("12-34"), WithRouteFinalizer),
+				"",
+			),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Route("default", "partial", WithRouteFinalizer,
+				WithRouteGeneration(1), WithRouteObservedGeneration,
+				partialTraffic, WithRouteUID("12-34"),
+				WithURL, WithAddress, WithRouteConditionsAutoTLSDisabled,
+				MarkTrafficTargetNotFound("not-found"), MarkIngressNotConfigured, WithStatusTraffic(
+					v1.TrafficTarget{
+						RevisionName:   "blue-00001",
+						Percent:        ptr.Int64(43),
+						LatestRevision: ptr.Bool(true),
+					}, v1.TrafficTarget{
+						RevisionName:   "green-00001",
+						Percent:        ptr.Int64(57),
+						LatestRevision: ptr.Bool(false),
+					})),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "partial"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created Ingress %q", "partial"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){
+			AssertTrackingRevision("default", "not-found"),
+		},
+		Key: "default/partial",
+	}, {
+		Name: "only missing revisions",
+		Objects: []runtime.Object{
+			Route("default", "missing-only", WithRevTarget("not-found"), WithRouteGeneration(1)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Route("default", "missing-only", WithRevTarget("not-found"), WithURL,
+				WithRouteGeneration(1), WithRouteObservedGeneration,
+				WithInitRouteConditions, MarkMissingTrafficTarget("Revision", "not-found")),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){
+			AssertTrackingRevision("default", "not-found"),
+		},
+		Key: "default/missing-only",
+	}, {
+		Name: "missing configuration is not tolerated",
+		Objects: []runtime.Object{
+			Route("default", "missing-config", WithRouteGeneration(1), WithSpecTraffic(
+				v1.TrafficTarget{
+					ConfigurationName: "not-found",
+					Percent:           ptr.Int64(50),
+				}, v1.TrafficTarget{
+					RevisionName: "green-00001",
+					Percent:      ptr.Int64(50),
+				})),
+			cfg("default", "green",
+				WithConfigGeneration(1), WithLatestCreated("green-00001"), WithLatestReady("green-00001")),
+			rev("default", "green", 1, MarkRevisionReady, WithRevName("green-00001"), WithServiceName("green-lake")),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Route("default", "missing-config", WithURL,
+				WithRouteGeneration(1), WithRouteObservedGeneration, WithSpecTraffic(
+					v1.TrafficTarget{
+						ConfigurationName: "not-found",
+						Percent:           ptr.Int64(50),
+					}, v1.TrafficTarget{
+						RevisionName: "green-00001",
+						Percent:      ptr.Int64(50),
+					}),
+				WithInitRouteConditions, MarkMissingTrafficTarget("Configuration", "not-found")),
+		}},
+		Key: "default/missing-config",
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		cfg := ReconcilerTestConfig(false)
+		cfg.Features = &cfgmap.Features{TolerateMissingRevisions: cfgmap.Enabled}
+		r := &Reconciler{
+			kubeclient:          kubeclient.Get(ctx),
+			client:              servingclient.Get(ctx),
+			netclient:           networkingclient.Get(ctx),
+			configurationLister: listers.GetConfigurationLister(),
+			revisionLister:      listers.GetRevisionLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			secretLister:        listers.GetSecretLister(),
+			ingressLister:       listers.GetIngressLister(),
+			tracker:             ctx.Value(TrackerKey).(tracker.Interface),
+			clock:               FakeClock{Time: fakeCurTime},
+			enqueueAfter:        func(interface{}, time.Duration) {},
+		}
+
+		return routereconciler.NewReconciler(ctx, logging.FromContext(ctx), servingclient.Get(ctx),
+			listers.GetRouteLister(), controller.GetEventRecorder(ctx), r,
+			controller.Options{ConfigStore: &testConfigStore{config: cfg}})
+	}))
+}
+
 func TestReconcile_ResponsiveGC(t *testing.T) {
 	table := TableTest{{
 		Name: "Update stale lastPinned",
@@ -1906,9 +2056,624 @@ func TestReconcile_ResponsiveGC(t *testing.T) {
 			configurationLister: listers.GetConfigurationLister(),
 			revisionLister:      listers.GetRevisionLister(),
 			serviceLister:       listers.GetK8sServiceLister(),
+			secretLister:        listers.GetSecretLister(),
+			ingressLister:       listers.GetIngressLister(),
+			tracker:             ctx.Value(TrackerKey).(tracker.Interface),
+			clock:               FakeClock{Time: fakeCurTime},
+		}
+
+		return routereconciler.NewReconciler(ctx, logging.FromContext(ctx), servingclient.Get(ctx),
+			listers.GetRouteLister(), controller.GetEventRecorder(ctx), r,
+			controller.Options{ConfigStore: &testConfigStore{config: ReconcilerTestConfig(false)}})
+	}))
+}
+
+func TestReconcile_PerTagIngress(t *testing.T) {
+	withIngressName := func(name string) IngressOption {
+		return func(ing *netv1alpha1.Ingress) {
+			ing.Name = name
+		}
+	}
+	steadyTraffic := &traffic.Config{
+		Targets: map[string]traffic.RevisionTargets{
+			traffic.DefaultTarget: {{
+				TrafficTarget: v1.TrafficTarget{
+					// Use the Revision name from the config.
+					RevisionName: "config-00001",
+					Percent:      ptr.Int64(100),
+				},
+				Active: true,
+			}},
+		},
+	}
+	taggedTraffic := v1.TrafficTarget{
+		Tag:          "blue",
+		RevisionName: "config-00001",
+		Percent:      ptr.Int64(100),
+	}
+	taggedTargets := traffic.RevisionTargets{{
+		TrafficTarget: v1.TrafficTarget{
+			RevisionName: "config-00001",
+			Percent:      ptr.Int64(100),
+		},
+		ServiceName: "mcgee",
+		Active:      true,
+	}}
+	taggedIngresses, _ := resources.MakeIngresses(getContext(),
+		Route("default", "tagged", WithSpecTraffic(taggedTraffic), WithURL, WithRouteUID("1-2")),
+		&traffic.Config{
+			Targets: map[string]traffic.RevisionTargets{
+				traffic.DefaultTarget: taggedTargets,
+				"blue":                taggedTargets,
+			},
+		}, nil, TestIngressClass, true /*perTag*/)
+	drainingRoute := Route("default", "tagged", WithSpecTraffic(v1.TrafficTarget{
+		Tag:          "blue",
+		RevisionName: "config-00002",
+		Percent:      ptr.Int64(100),
+	}), WithRouteUID("1-2"), WithRouteAnnotation(map[string]string{
+		serving.DrainTimeoutAnnotationKey: "1m",
+	}), WithURL, WithAddress, WithRouteConditionsAutoTLSDisabled,
+		MarkTrafficAssigned, MarkIngressReady, WithStatusTraffic(v1.TrafficTarget{
+			Tag:            "blue",
+			RevisionName:   "config-00002",
+			Percent:        ptr.Int64(100),
+			LatestRevision: ptr.Bool(false),
+			URL: &apis.URL{
+				Scheme: "http",
+				Host:   "blue-tagged.default.example.com",
+			},
+		}))
+	drainingTagService, _ := resources.MakeK8sService(getContext(), drainingRoute, "blue",
+		&netv1alpha1.Ingress{Status: readyIngressStatus()}, false, "")
+	drainingIngresses := func(blue string, draining ...resources.DrainingTarget) []runtime.Object {
+		targets := func(name, serviceName string) traffic.RevisionTargets {
+			return traffic.RevisionTargets{{
+				TrafficTarget: v1.TrafficTarget{
+					RevisionName: name,
+					Percent:      ptr.Int64(100),
+				},
+				ServiceName: serviceName,
+				Active:      true,
+			}}
+		}
+		blueTargets := targets("config-00002", "belltown")
+		if blue == "config-00001" {
+			blueTargets = targets("config-00001", "magnolia")
+		}
+		ingresses, _ := resources.MakeIngresses(getContext(), drainingRoute, &traffic.Config{
+			Targets: map[string]traffic.RevisionTargets{
+				traffic.DefaultTarget: targets("config-00002", "belltown"),
+				"blue":                blueTargets,
+			},
+		}, nil, TestIngressClass, true /*perTag*/)
+		objs := make([]runtime.Object, 0, len(ingresses))
+		for _, ing := range ingresses {
+			ing.Status = readyIngressStatus()
+			resources.AddDrainingTargets(ing, draining)
+			objs = append(objs, ing)
+		}
+		return objs
+	}
+	drainingRev := rev("default", "config", 1, MarkRevisionReady, WithRevName("config-00001"), WithServiceName("magnolia"))
+
+	table := TableTest{{
+		Name: "orphaned tag Ingress is deleted",
+		Objects: []runtime.Object{
+			Route("default", "per-tag", WithConfigTarget("config"),
+				WithURL, WithAddress, WithRouteConditionsAutoTLSDisabled,
+				MarkTrafficAssigned, MarkIngressReady, WithRouteGeneration(1), WithRouteObservedGeneration,
+				WithRouteFinalizer, WithStatusTraffic(
+					v1.TrafficTarget{
+						RevisionName:   "config-00001",
+						Percent:        ptr.Int64(100),
+						LatestRevision: ptr.Bool(true),
+					})),
+			cfg("default", "config",
+				WithConfigGeneration(1), WithLatestCreated("config-00001"), WithLatestReady("config-00001"),
+				// The Route controller attaches our label to this Configuration.
+				WithConfigLabel("serving.knative.dev/route", "per-tag"),
+			),
+			rev("default", "config", 1, MarkRevisionReady, WithRevName("config-00001")),
+			simpleReadyIngress(Route("default", "per-tag", WithConfigTarget("config"), WithURL), steadyTraffic),
+			simpleReadyIngress(Route("default", "per-tag", WithConfigTarget("config"), WithURL), steadyTraffic,
+				withIngressName("per-tag.gone")),
+			simpleK8sService(Route("default", "per-tag", WithConfigTarget("config"))),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "default",
+				Verb:      "delete",
+				Resource: schema.GroupVersionResource{
+					Group:    "networking.internal.knative.dev",
+					Version:  "v1alpha1",
+					Resource: "ingresses",
+				},
+			},
+			Name: "per-tag.gone",
+		}},
+		Key: "default/per-tag",
+	}, {
+		Name:    "unhappy about ownership of tag Ingress",
+		WantErr: true,
+		Objects: []runtime.Object{
+			Route("default", "tagged", WithSpecTraffic(taggedTraffic), WithRouteGeneration(1),
+				WithRouteUID("1-2"), WithRouteFinalizer),
+			cfg("default", "config",
+				WithConfigGeneration(1), WithLatestCreated("config-00001"), WithLatestReady("config-00001")),
+			rev("default", "config", 1, MarkRevisionReady, WithRevName("config-00001"), WithServiceName("mcgee")),
+			simpleIngress(Route("default", "someone-else", WithURL), steadyTraffic,
+				withIngressName("tagged.blue")),
+		},
+		WantCreates: []runtime.Object{
+			taggedIngresses[0],
+			simplePlaceholderK8sService(getContext(), Route("default", "tagged",
+				WithSpecTraffic(taggedTraffic), WithRouteUID("1-2")), ""),
+			simplePlaceholderK8sService(getContext(), Route("default", "tagged",
+				WithSpecTraffic(taggedTraffic), WithRouteUID("1-2")), "blue"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Route("default", "tagged", WithSpecTraffic(taggedTraffic), WithRouteGeneration(1),
+				WithRouteObservedGeneration, WithRouteUID("1-2"), WithRouteFinalizer,
+				WithURL, WithAddress, WithRouteConditionsAutoTLSDisabled,
+				MarkTrafficAssigned, MarkIngressNotOwned("tagged.blue"), WithStatusTraffic(
+					v1.TrafficTarget{
+						Tag:            "blue",
+						RevisionName:   "config-00001",
+						Percent:        ptr.Int64(100),
+						LatestRevision: ptr.Bool(false),
+						URL: &apis.URL{
+							Scheme: "http",
+							Host:   "blue-tagged.default.example.com",
+						},
+					})),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "tagged"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "blue-tagged"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created Ingress %q", "tagged"),
+			Eventf(corev1.EventTypeWarning, "InternalError", `route: "tagged" does not own Ingress: "tagged.blue"`),
+		},
+		Key: "default/tagged",
+	}, {
+		Name:    "unhappy about ownership of Ingress",
+		WantErr: true,
+		Objects: []runtime.Object{
+			Route("default", "tagged", WithSpecTraffic(taggedTraffic), WithRouteGeneration(1),
+				WithRouteUID("1-2"), WithRouteFinalizer),
+			cfg("default", "config",
+				WithConfigGeneration(1), WithLatestCreated("config-00001"), WithLatestReady("config-00001")),
+			rev("default", "config", 1, MarkRevisionReady, WithRevName("config-00001"), WithServiceName("mcgee")),
+			simpleIngress(Route("default", "someone-else", WithURL), steadyTraffic,
+				withIngressName("tagged")),
+		},
+		WantCreates: []runtime.Object{
+			simplePlaceholderK8sService(getContext(), Route("default", "tagged",
+				WithSpecTraffic(taggedTraffic), WithRouteUID("1-2")), ""),
+			simplePlaceholderK8sService(getContext(), Route("default", "tagged",
+				WithSpecTraffic(taggedTraffic), WithRouteUID("1-2")), "blue"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Route("default", "tagged", WithSpecTraffic(taggedTraffic), WithRouteGeneration(1),
+				WithRouteObservedGeneration, WithRouteUID("1-2"), WithRouteFinalizer,
+				WithURL, WithAddress, WithRouteConditionsAutoTLSDisabled,
+				MarkTrafficAssigned, MarkIngressNotOwned("tagged"), WithStatusTraffic(
+					v1.TrafficTarget{
+						Tag:            "blue",
+						RevisionName:   "config-00001",
+						Percent:        ptr.Int64(100),
+						LatestRevision: ptr.Bool(false),
+						URL: &apis.URL{
+							Scheme: "http",
+							Host:   "blue-tagged.default.example.com",
+						},
+					})),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "tagged"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "blue-tagged"),
+			Eventf(corev1.EventTypeWarning, "InternalError", `route: "tagged" does not own Ingress: "tagged"`),
+		},
+		Key: "default/tagged",
+	}, {
+		Name: "revision leaving the traffic of a tag drains through all the Ingresses",
+		Objects: append([]runtime.Object{
+			drainingRoute,
+			cfg("default", "config",
+				WithConfigGeneration(2), WithLatestCreated("config-00002"), WithLatestReady("config-00002")),
+			drainingRev,
+			rev("default", "config", 2, MarkRevisionReady, WithRevName("config-00002"), WithServiceName("belltown")),
+			simpleK8sService(drainingRoute),
+			drainingTagService,
+		}, drainingIngresses("config-00001")...),
+		WantUpdates: func() []clientgotesting.UpdateActionImpl {
+			var updates []clientgotesting.UpdateActionImpl
+			for _, ing := range drainingIngresses("config-00002", resources.DrainingTarget{
+				Revision: drainingRev,
+				Deadline: fakeCurTime.Add(time.Minute),
+			}) {
+				updates = append(updates, clientgotesting.UpdateActionImpl{Object: ing})
+			}
+			return updates
+		}(),
+		Key: "default/tagged",
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		cfg := ReconcilerTestConfig(false)
+		cfg.Features = &cfgmap.Features{PerTagIngress: cfgmap.Enabled}
+		r := &Reconciler{
+			kubeclient:          kubeclient.Get(ctx),
+			client:              servingclient.Get(ctx),
+			netclient:           networkingclient.Get(ctx),
+			configurationLister: listers.GetConfigurationLister(),
+			revisionLister:      listers.GetRevisionLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			secretLister:        listers.GetSecretLister(),
+			ingressLister:       listers.GetIngressLister(),
+			tracker:             ctx.Value(TrackerKey).(tracker.Interface),
+			clock:               FakeClock{Time: fakeCurTime},
+			enqueueAfter:        func(interface{}, time.Duration) {},
+		}
+
+		return routereconciler.NewReconciler(ctx, logging.FromContext(ctx), servingclient.Get(ctx),
+			listers.GetRouteLister(), controller.GetEventRecorder(ctx), r,
+			controller.Options{ConfigStore: &testConfigStore{config: cfg}})
+	}))
+}
+
+// fakeResolver resolves the hosts to their addresses in the map, and fails
+// to resolve the others.
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if addrs, ok := f[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestReconcile_DNSReadiness(t *testing.T) {
+	readyRoute := func(name string, ro ...RouteOption) *v1.Route {
+		return Route("default", name, append([]RouteOption{WithConfigTarget("config"),
+			WithURL, WithAddress, WithRouteConditionsAutoTLSDisabled,
+			MarkTrafficAssigned, MarkIngressReady, WithRouteGeneration(1), WithRouteObservedGeneration,
+			WithRouteFinalizer, WithStatusTraffic(
+				v1.TrafficTarget{
+					RevisionName:   "config-00001",
+					Percent:        ptr.Int64(100),
+					LatestRevision: ptr.Bool(true),
+				})}, ro...)...)
+	}
+	objects := func(name string, lb netv1alpha1.LoadBalancerIngressStatus) []runtime.Object {
+		return []runtime.Object{
+			readyRoute(name),
+			cfg("default", "config",
+				WithConfigGeneration(1), WithLatestCreated("config-00001"), WithLatestReady("config-00001"),
+				WithConfigLabel("serving.knative.dev/route", name),
+			),
+			rev("default", "config", 1, MarkRevisionReady, WithRevName("config-00001")),
+			simpleReadyIngress(
+				Route("default", name, WithConfigTarget("config"), WithURL),
+				&traffic.Config{
+					Targets: map[string]traffic.RevisionTargets{
+						traffic.DefaultTarget: {{
+							TrafficTarget: v1.TrafficTarget{
+								RevisionName: "config-00001",
+								Percent:      ptr.Int64(100),
+							},
+							Active: true,
+						}},
+					},
+				},
+				func(ing *netv1alpha1.Ingress) {
+					ing.Status.PublicLoadBalancer = &netv1alpha1.LoadBalancerStatus{
+						Ingress: []netv1alpha1.LoadBalancerIngressStatus{lb},
+					}
+				},
+			),
+			simpleK8sService(Route("default", name, WithConfigTarget("config"))),
+		}
+	}
+
+	resolver := fakeResolver{
+		"resolved.default.example.com":  {"10.0.0.1", "1.2.3.4"},
+		"elsewhere.default.example.com": {"5.6.7.8"},
+		"lb-domain.default.example.com": {"1.2.3.4"},
+		"lb.example.com":                {"1.2.3.4"},
+	}
+	requeued := sets.NewString()
+
+	table := TableTest{{
+		Name:    "host resolves to the ingress",
+		Objects: objects("resolved", netv1alpha1.LoadBalancerIngressStatus{IP: "1.2.3.4"}),
+		Key:     "default/resolved",
+	}, {
+		Name:    "host resolves to the domain of the ingress",
+		Objects: objects("lb-domain", netv1alpha1.LoadBalancerIngressStatus{Domain: "lb.example.com"}),
+		Key:     "default/lb-domain",
+	}, {
+		Name:    "host resolves elsewhere",
+		Objects: objects("elsewhere", netv1alpha1.LoadBalancerIngressStatus{IP: "1.2.3.4"}),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: readyRoute("elsewhere", MarkDNSNotResolved("elsewhere.default.example.com")),
+		}},
+		PostConditions: []func(*testing.T, *TableRow){
+			func(t *testing.T, _ *TableRow) {
+				if !requeued.Has("default/elsewhere") {
+					t.Error("The Route wasn't requeued to resolve its host again")
+				}
+			},
+		},
+		Key: "default/elsewhere",
+	}, {
+		Name:    "host does not resolve",
+		Objects: objects("unresolved", netv1alpha1.LoadBalancerIngressStatus{IP: "1.2.3.4"}),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: readyRoute("unresolved", MarkDNSNotResolved("unresolved.default.example.com")),
+		}},
+		Key: "default/unresolved",
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		cfg := ReconcilerTestConfig(false)
+		cfg.Features = &cfgmap.Features{RouteDNSReadiness: cfgmap.Enabled}
+		r := &Reconciler{
+			kubeclient:          kubeclient.Get(ctx),
+			client:              servingclient.Get(ctx),
+			netclient:           networkingclient.Get(ctx),
+			configurationLister: listers.GetConfigurationLister(),
+			revisionLister:      listers.GetRevisionLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			secretLister:        listers.GetSecretLister(),
+			ingressLister:       listers.GetIngressLister(),
+			tracker:             ctx.Value(TrackerKey).(tracker.Interface),
+			clock:               FakeClock{Time: fakeCurTime},
+			enqueueAfter: func(obj interface{}, _ time.Duration) {
+				route := obj.(*v1.Route)
+				requeued.Insert(route.Namespace + "/" + route.Name)
+			},
+			newResolver: func(string) hostResolver {
+				return resolver
+			},
+		}
+
+		return routereconciler.NewReconciler(ctx, logging.FromContext(ctx), servingclient.Get(ctx),
+			listers.GetRouteLister(), controller.GetEventRecorder(ctx), r,
+			controller.Options{ConfigStore: &testConfigStore{config: cfg}})
+	}))
+}
+
+func TestCheckDNSReadinessTagHosts(t *testing.T) {
+	taggedRoute := func(ro ...RouteOption) *v1.Route {
+		return Route("default", "tagged", append([]RouteOption{WithURL, WithRouteConditionsAutoTLSDisabled,
+			MarkTrafficAssigned, MarkIngressReady, WithStatusTraffic(v1.TrafficTarget{
+				Tag:          "blue",
+				RevisionName: "config-00001",
+				Percent:      ptr.Int64(100),
+				URL: &apis.URL{
+					Scheme: "http",
+					Host:   "blue-tagged.default.example.com",
+				},
+			})}, ro...)...)
+	}
+	ingress := &netv1alpha1.Ingress{
+		Status: netv1alpha1.IngressStatus{
+			PublicLoadBalancer: &netv1alpha1.LoadBalancerStatus{
+				Ingress: []netv1alpha1.LoadBalancerIngressStatus{{IP: "1.2.3.4"}},
+			},
+		},
+	}
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Features: &cfgmap.Features{RouteDNSReadiness: cfgmap.Enabled},
+	})
+
+	tests := []struct {
+		name     string
+		resolver fakeResolver
+		// wantUnresolved is the host expected not to resolve, if any.
+		wantUnresolved string
+	}{{
+		name: "all hosts resolve to the ingress",
+		resolver: fakeResolver{
+			"tagged.default.example.com":      {"1.2.3.4"},
+			"blue-tagged.default.example.com": {"1.2.3.4"},
+		},
+	}, {
+		name: "tag host does not resolve",
+		resolver: fakeResolver{
+			"tagged.default.example.com": {"1.2.3.4"},
+		},
+		wantUnresolved: "blue-tagged.default.example.com",
+	}, {
+		name: "tag host resolves elsewhere",
+		resolver: fakeResolver{
+			"tagged.default.example.com":      {"1.2.3.4"},
+			"blue-tagged.default.example.com": {"5.6.7.8"},
+		},
+		wantUnresolved: "blue-tagged.default.example.com",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requeued := false
+			c := &Reconciler{
+				enqueueAfter: func(interface{}, time.Duration) {
+					requeued = true
+				},
+				newResolver: func(string) hostResolver {
+					return test.resolver
+				},
+			}
+			r := taggedRoute()
+			c.checkDNSReadiness(ctx, r, ingress)
+			want := taggedRoute()
+			if test.wantUnresolved != "" {
+				want.Status.MarkDNSNotResolved(test.wantUnresolved)
+			}
+			got, wantCond := r.Status.GetCondition(v1.RouteConditionIngressReady), want.Status.GetCondition(v1.RouteConditionIngressReady)
+			if got.Status != wantCond.Status || got.Reason != wantCond.Reason || got.Message != wantCond.Message {
+				t.Errorf("IngressReady = %#v, want: %#v", got, wantCond)
+			}
+			if got, want := requeued, test.wantUnresolved != ""; got != want {
+				t.Errorf("requeued = %v, want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestReconcile_Draining(t *testing.T) {
+	drainTimeout := WithRouteAnnotation(map[string]string{
+		serving.DrainTimeoutAnnotationKey: "1m",
+	})
+	oldRev := func(ro ...RevisionOption) *v1.Revision {
+		return rev("default", "config", 1, append([]RevisionOption{MarkRevisionReady,
+			WithRevName("config-00001"), WithServiceName("magnolia")}, ro...)...)
+	}
+	newTraffic := &traffic.Config{
+		Targets: map[string]traffic.RevisionTargets{
+			traffic.DefaultTarget: {{
+				TrafficTarget: v1.TrafficTarget{
+					RevisionName: "config-00002",
+					Percent:      ptr.Int64(100),
+				},
+				ServiceName: "belltown",
+				Active:      true,
+			}},
+		},
+	}
+	withDraining := func(deadline time.Time) IngressOption {
+		return func(ing *netv1alpha1.Ingress) {
+			resources.AddDrainingTargets(ing, []resources.DrainingTarget{{
+				Revision: oldRev(),
+				Deadline: deadline,
+			}})
+		}
+	}
+	routeStatus := []RouteOption{
+		drainTimeout, WithConfigTarget("config"),
+		WithURL, WithAddress, WithRouteConditionsAutoTLSDisabled, WithRouteGeneration(1),
+		MarkTrafficAssigned, MarkIngressReady, WithRouteObservedGeneration, WithRouteFinalizer,
+	}
+	drainedRoute := Route("default", "draining", append(routeStatus, WithStatusTraffic(
+		v1.TrafficTarget{
+			RevisionName:   "config-00002",
+			Percent:        ptr.Int64(100),
+			LatestRevision: ptr.Bool(true),
+		}))...)
+	objects := func(rev1 *v1.Revision, ing *netv1alpha1.Ingress) []runtime.Object {
+		return []runtime.Object{
+			drainedRoute,
+			cfg("default", "config",
+				WithConfigGeneration(2), WithLatestCreated("config-00002"), WithLatestReady("config-00002"),
+				WithConfigLabel("serving.knative.dev/route", "draining"),
+			),
+			rev1,
+			rev("default", "config", 2, MarkRevisionReady, WithRevName("config-00002"), WithServiceName("belltown")),
+			ing,
+			simpleK8sService(Route("default", "draining", WithConfigTarget("config"))),
+		}
+	}
+	ingressRoute := Route("default", "draining", drainTimeout, WithConfigTarget("config"), WithURL)
+
+	table := TableTest{{
+		Name: "traffic shifts off a revision, which starts draining",
+		Objects: []runtime.Object{
+			Route("default", "draining", append(routeStatus, WithStatusTraffic(
+				v1.TrafficTarget{
+					RevisionName: "config-00001",
+					Percent:      ptr.Int64(100),
+				}))...),
+			cfg("default", "config",
+				WithConfigGeneration(2), WithLatestCreated("config-00002"), WithLatestReady("config-00002"),
+				WithConfigLabel("serving.knative.dev/route", "draining"),
+			),
+			oldRev(),
+			rev("default", "config", 2, MarkRevisionReady, WithRevName("config-00002"), WithServiceName("belltown")),
+			simpleReadyIngress(ingressRoute, &traffic.Config{
+				Targets: map[string]traffic.RevisionTargets{
+					traffic.DefaultTarget: {{
+						TrafficTarget: v1.TrafficTarget{
+							RevisionName: "config-00001",
+							Percent:      ptr.Int64(100),
+						},
+						ServiceName: "magnolia",
+						Active:      true,
+					}},
+				},
+			}),
+			simpleK8sService(Route("default", "draining", WithConfigTarget("config"))),
+		},
+		// The new revision gets all the requests, while the old one stays
+		// programmed to complete its in-flight requests.
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: simpleReadyIngress(ingressRoute, newTraffic, withDraining(fakeCurTime.Add(time.Minute))),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: drainedRoute,
+		}},
+		Key: "default/draining",
+	}, {
+		Name: "revision draining, steady state",
+		Objects: objects(oldRev(),
+			simpleReadyIngress(ingressRoute, newTraffic, withDraining(fakeCurTime.Add(30*time.Second)))),
+		Key: "default/draining",
+	}, {
+		Name: "revision draining past the deadline",
+		Objects: objects(oldRev(),
+			simpleReadyIngress(ingressRoute, newTraffic, withDraining(fakeCurTime.Add(-time.Second)))),
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: simpleReadyIngress(ingressRoute, newTraffic),
+		}},
+		Key: "default/draining",
+	}, {
+		Name: "draining revision without endpoints",
+		Objects: objects(oldRev(MarkInactive("NoTraffic", "no message")),
+			simpleReadyIngress(ingressRoute, newTraffic, withDraining(fakeCurTime.Add(30*time.Second)))),
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: simpleReadyIngress(ingressRoute, newTraffic),
+		}},
+		Key: "default/draining",
+	}, {
+		Name: "draining disabled",
+		Objects: []runtime.Object{
+			Route("default", "draining", WithConfigTarget("config"),
+				WithURL, WithAddress, WithRouteConditionsAutoTLSDisabled, WithRouteGeneration(1),
+				MarkTrafficAssigned, MarkIngressReady, WithRouteObservedGeneration, WithRouteFinalizer,
+				WithStatusTraffic(v1.TrafficTarget{
+					RevisionName:   "config-00002",
+					Percent:        ptr.Int64(100),
+					LatestRevision: ptr.Bool(true),
+				})),
+			cfg("default", "config",
+				WithConfigGeneration(2), WithLatestCreated("config-00002"), WithLatestReady("config-00002"),
+				WithConfigLabel("serving.knative.dev/route", "draining"),
+			),
+			oldRev(),
+			rev("default", "config", 2, MarkRevisionReady, WithRevName("config-00002"), WithServiceName("belltown")),
+			simpleReadyIngress(Route("default", "draining", WithConfigTarget("config"), WithURL),
+				newTraffic, withDraining(fakeCurTime.Add(30*time.Second))),
+			simpleK8sService(Route("default", "draining", WithConfigTarget("config"))),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: simpleReadyIngress(Route("default", "draining", WithConfigTarget("config"), WithURL), newTraffic),
+		}},
+		Key: "default/draining",
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:          kubeclient.Get(ctx),
+			client:              servingclient.Get(ctx),
+			netclient:           networkingclient.Get(ctx),
+			configurationLister: listers.GetConfigurationLister(),
+			revisionLister:      listers.GetRevisionLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			secretLister:        listers.GetSecretLister(),
 			ingressLister:       listers.GetIngressLister(),
 			tracker:             ctx.Value(TrackerKey).(tracker.Interface),
 			clock:               FakeClock{Time: fakeCurTime},
+			enqueueAfter:        func(interface{}, time.Duration) {},
 		}
 
 		return routereconciler.NewReconciler(ctx, logging.FromContext(ctx), servingclient.Get(ctx),
@@ -2461,6 +3226,7 @@ func TestReconcile_EnableAutoTLS(t *testing.T) {
 			configurationLister: listers.GetConfigurationLister(),
 			revisionLister:      listers.GetRevisionLister(),
 			serviceLister:       listers.GetK8sServiceLister(),
+			secretLister:        listers.GetSecretLister(),
 			ingressLister:       listers.GetIngressLister(),
 			certificateLister:   listers.GetCertificateLister(),
 			tracker:             &NullTracker{},
@@ -2581,6 +3347,7 @@ func TestReconcile_EnableAutoTLS_HTTPDisabled(t *testing.T) {
 			configurationLister: listers.GetConfigurationLister(),
 			revisionLister:      listers.GetRevisionLister(),
 			serviceLister:       listers.GetK8sServiceLister(),
+			secretLister:        listers.GetSecretLister(),
 			ingressLister:       listers.GetIngressLister(),
 			certificateLister:   listers.GetCertificateLister(),
 			tracker:             &NullTracker{},
@@ -103,6 +103,11 @@ func (b *Resolver) GetVisibility(ctx context.Context, route *v1.Route) (map[stri
 				ttVisibility = netv1alpha1.IngressVisibilityClusterLocal
 			}
 		}
+		// Or one for the tag on the Route?
+		if tt != traffic.DefaultTarget &&
+			route.Labels[serving.TagVisibilityLabelKeyPrefix+tt] == serving.VisibilityClusterLocal {
+			ttVisibility = netv1alpha1.IngressVisibilityClusterLocal
+		}
 
 		// Now, choose the lowest visibility.
 		m[tt] = minVisibility(ttVisibility, defaultVisibility)
@@ -203,6 +203,60 @@ func TestVisibility(t *testing.T) {
 			"blue":                netv1alpha1.IngressVisibilityExternalIP,
 			"green":               netv1alpha1.IngressVisibilityExternalIP,
 		},
+	}, {
+		name: "two tags, tag marked local on the route",
+		route: &v1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "foo",
+				Labels: map[string]string{
+					serving.TagVisibilityLabelKeyPrefix + "blue": serving.VisibilityClusterLocal,
+				},
+			},
+			Spec: v1.RouteSpec{
+				Traffic: []v1.TrafficTarget{
+					{Tag: "blue"},
+					{Tag: "green"},
+				},
+			},
+		},
+		expected: map[string]netv1alpha1.IngressVisibility{
+			traffic.DefaultTarget: netv1alpha1.IngressVisibilityExternalIP,
+			"blue":                netv1alpha1.IngressVisibilityClusterLocal,
+			"green":               netv1alpha1.IngressVisibilityExternalIP,
+		},
+	}, {
+		name: "three tags, marked local on the route and on the svc",
+		route: &v1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "foo",
+				Labels: map[string]string{
+					serving.TagVisibilityLabelKeyPrefix + "blue":   serving.VisibilityClusterLocal,
+					serving.TagVisibilityLabelKeyPrefix + "absent": serving.VisibilityClusterLocal,
+				},
+			},
+			Spec: v1.RouteSpec{
+				Traffic: []v1.TrafficTarget{
+					{Tag: "blue"},
+					{Tag: "green"},
+					{Tag: "red"},
+				},
+			},
+		},
+		services: []*corev1.Service{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "green-foo",
+				Labels: map[string]string{
+					serving.RouteLabelKey:      "foo",
+					serving.VisibilityLabelKey: serving.VisibilityClusterLocal,
+				},
+			},
+		}},
+		expected: map[string]netv1alpha1.IngressVisibility{
+			traffic.DefaultTarget: netv1alpha1.IngressVisibilityExternalIP,
+			"blue":                netv1alpha1.IngressVisibilityClusterLocal,
+			"green":               netv1alpha1.IngressVisibilityClusterLocal,
+			"red":                 netv1alpha1.IngressVisibilityExternalIP,
+		},
 	}, {
 		name: "two tags initial default with .svc.cluster.local domain suffix",
 		route: &v1.Route{
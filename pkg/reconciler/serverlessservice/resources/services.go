@@ -17,9 +17,12 @@ limitations under the License.
 package resources
 
 import (
+	"strconv"
+
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/kmeta"
+	"knative.dev/serving/pkg/apis/serving"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 
 	corev1 "k8s.io/api/core/v1"
@@ -27,6 +30,10 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// topologyAwareHintsAnnotationKey is the K8s Service annotation having the
+// EndpointSlice controller populate topology aware hints for its endpoints.
+const topologyAwareHintsAnnotationKey = "service.kubernetes.io/topology-aware-hints"
+
 // targetPort chooses the target (pod) port for the public and private service.
 func targetPort(sks *v1alpha1.ServerlessService) intstr.IntOrString {
 	if sks.Spec.ProtocolType == networking.ProtocolH2C {
@@ -112,6 +119,12 @@ func filterSubsetPorts(targetPort int32, subsets []corev1.EndpointSubset) []core
 // MakePrivateService constructs a K8s service, that is backed by the pod selector
 // matching pods created by the revision.
 func MakePrivateService(sks *v1alpha1.ServerlessService, selector map[string]string) *corev1.Service {
+	annotations := kmeta.CopyMap(sks.GetAnnotations())
+	// Only the EndpointSlices of the Services with a selector get hints, so
+	// they are requested on the private Service alone.
+	if hints, _ := strconv.ParseBool(annotations[serving.TopologyAwareHintsAnnotationKey]); hints {
+		annotations[topologyAwareHintsAnnotationKey] = "auto"
+	}
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      kmeta.ChildName(sks.Name, "-private"),
@@ -121,7 +134,7 @@ func MakePrivateService(sks *v1alpha1.ServerlessService, selector map[string]str
 				networking.SKSLabelKey:    sks.Name,
 				networking.ServiceTypeKey: string(networking.ServiceTypePrivate),
 			}),
-			Annotations:     kmeta.CopyMap(sks.GetAnnotations()),
+			Annotations:     annotations,
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(sks)},
 		},
 		Spec: corev1.ServiceSpec{
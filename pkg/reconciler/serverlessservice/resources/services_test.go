@@ -414,6 +414,33 @@ func TestMakePrivateService(t *testing.T) {
 				TargetPort: intstr.FromInt(networking.BackendHTTP2Port),
 			}
 		}),
+	}, {
+		name: "topology aware hints",
+		sks: sks(func(s *v1alpha1.ServerlessService) {
+			s.Annotations[serving.TopologyAwareHintsAnnotationKey] = "true"
+		}),
+		selector: map[string]string{
+			"app": "sadness",
+		},
+		want: svc(networking.ServiceTypePrivate, func(s *corev1.Service) {
+			s.Annotations = map[string]string{
+				serving.TopologyAwareHintsAnnotationKey: "true",
+				topologyAwareHintsAnnotationKey:         "auto",
+			}
+		}, privateSvcMod),
+	}, {
+		name: "topology aware hints disabled",
+		sks: sks(func(s *v1alpha1.ServerlessService) {
+			s.Annotations[serving.TopologyAwareHintsAnnotationKey] = "false"
+		}),
+		selector: map[string]string{
+			"app": "sadness",
+		},
+		want: svc(networking.ServiceTypePrivate, func(s *corev1.Service) {
+			s.Annotations = map[string]string{
+				serving.TopologyAwareHintsAnnotationKey: "false",
+			}
+		}, privateSvcMod),
 	}}
 
 	for _, test := range tests {
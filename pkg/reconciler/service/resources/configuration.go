@@ -37,6 +37,16 @@ func MakeConfiguration(service *v1.Service) (*v1.Configuration, error) {
 
 // MakeConfigurationFromExisting creates a Configuration from a Service object given an existing Configuration.
 func MakeConfigurationFromExisting(service *v1.Service, existing *v1.Configuration, gc cfgmap.Flag) (*v1.Configuration, error) {
+	return makeConfiguration(service, names.Configuration(service), service.Spec.ConfigurationSpec, existing, gc)
+}
+
+// MakeVariantConfigurationFromExisting creates the Configuration of a variant of a Service
+// object given an existing Configuration.
+func MakeVariantConfigurationFromExisting(service *v1.Service, variant *v1.ServiceVariant, existing *v1.Configuration, gc cfgmap.Flag) (*v1.Configuration, error) {
+	return makeConfiguration(service, names.VariantConfiguration(service, variant.Name), variant.ConfigurationSpec, existing, gc)
+}
+
+func makeConfiguration(service *v1.Service, name string, spec v1.ConfigurationSpec, existing *v1.Configuration, gc cfgmap.Flag) (*v1.Configuration, error) {
 	labels := map[string]string{serving.ServiceLabelKey: service.Name}
 	anns := kmeta.FilterMap(service.GetAnnotations(), func(key string) bool {
 		return key == corev1.LastAppliedConfigAnnotation
@@ -57,7 +67,7 @@ func MakeConfigurationFromExisting(service *v1.Service, existing *v1.Configurati
 
 	return &v1.Configuration{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      names.Configuration(service),
+			Name:      name,
 			Namespace: service.Namespace,
 			OwnerReferences: []metav1.OwnerReference{
 				*kmeta.NewControllerRef(service),
@@ -65,6 +75,6 @@ func MakeConfigurationFromExisting(service *v1.Service, existing *v1.Configurati
 			Labels:      kmeta.UnionMaps(service.GetLabels(), labels),
 			Annotations: anns,
 		},
-		Spec: service.Spec.ConfigurationSpec,
+		Spec: spec,
 	}, nil
 }
@@ -84,3 +84,32 @@ func TestConfigurationHasNoKubectlAnnotation(t *testing.T) {
 		t.Errorf("Annotation %s = %q, want empty", corev1.LastAppliedConfigAnnotation, v)
 	}
 }
+
+func TestVariantConfigurationSpec(t *testing.T) {
+	s := createService()
+	variant := &v1.ServiceVariant{
+		Name:              "canary",
+		ConfigurationSpec: *createConfiguration("canary-container"),
+	}
+	c, err := MakeVariantConfigurationFromExisting(s, variant, &v1.Configuration{}, cfgmap.Disabled)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if got, want := c.Name, testServiceName+"-canary"; got != want {
+		t.Errorf("expected %q for configuration name got %q", want, got)
+	}
+	if got, want := c.Namespace, testServiceNamespace; got != want {
+		t.Errorf("expected %q for service namespace got %q", want, got)
+	}
+	if got, want := c.Spec.GetTemplate().Spec.GetContainer().Name, "canary-container"; got != want {
+		t.Errorf("expected %q for container name got %q", want, got)
+	}
+	expectOwnerReferencesSetCorrectly(t, c.OwnerReferences)
+
+	if got, want := c.Labels[serving.ServiceLabelKey], testServiceName; got != want {
+		t.Errorf("expected %q labels got %q", want, got)
+	}
+	if got, want := c.Labels[serving.RouteLabelKey], names.Route(s); got != want {
+		t.Errorf("expected %q route label got %q", want, got)
+	}
+}
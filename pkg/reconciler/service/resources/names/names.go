@@ -16,12 +16,21 @@ limitations under the License.
 
 package names
 
-import "knative.dev/pkg/kmeta"
+import (
+	"knative.dev/pkg/kmeta"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
 
 func Configuration(service kmeta.Accessor) string {
 	return service.GetName()
 }
 
+// VariantConfiguration returns the name of the Configuration of the given
+// variant of the service.
+func VariantConfiguration(service kmeta.Accessor, variant string) string {
+	return v1.VariantConfigurationName(service.GetName(), variant)
+}
+
 func Route(service kmeta.Accessor) string {
 	return service.GetName()
 }
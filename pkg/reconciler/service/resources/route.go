@@ -21,6 +21,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/ptr"
 	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	"knative.dev/serving/pkg/reconciler/service/resources/names"
@@ -46,12 +47,39 @@ func MakeRoute(service *v1.Service) (*v1.Route, error) {
 		Spec: *service.Spec.RouteSpec.DeepCopy(),
 	}
 
+	variants := make(map[string]bool, len(service.Spec.Variants))
+	for _, v := range service.Spec.Variants {
+		variants[v.Name] = false
+	}
+
 	// Fill in any missing ConfigurationName fields when translating
-	// from Service to Route.
+	// from Service to Route. The targets tagged with the name of a
+	// variant reference the Configuration of the variant.
 	for idx := range c.Spec.Traffic {
-		if c.Spec.Traffic[idx].RevisionName == "" {
-			c.Spec.Traffic[idx].ConfigurationName = names.Configuration(service)
+		tt := &c.Spec.Traffic[idx]
+		_, isVariant := variants[tt.Tag]
+		if isVariant {
+			variants[tt.Tag] = true
+		}
+		switch {
+		case tt.RevisionName != "":
+		case isVariant:
+			tt.ConfigurationName = names.VariantConfiguration(service, tt.Tag)
+		default:
+			tt.ConfigurationName = names.Configuration(service)
+		}
+	}
+
+	// Make the variants without traffic reachable through their tag.
+	for _, v := range service.Spec.Variants {
+		if variants[v.Name] {
+			continue
 		}
+		c.Spec.Traffic = append(c.Spec.Traffic, v1.TrafficTarget{
+			Tag:               v.Name,
+			ConfigurationName: names.VariantConfiguration(service, v.Name),
+			LatestRevision:    ptr.Bool(true),
+		})
 	}
 
 	return c, nil
@@ -62,6 +62,98 @@ func TestRouteSpec(t *testing.T) {
 	}
 }
 
+func TestRouteSpecVariants(t *testing.T) {
+	canary := v1.ServiceVariant{Name: "canary", ConfigurationSpec: *createConfiguration("canary")}
+	beta := v1.ServiceVariant{Name: "beta", ConfigurationSpec: *createConfiguration("beta")}
+
+	tests := []struct {
+		name    string
+		traffic []v1.TrafficTarget
+		want    []v1.TrafficTarget
+	}{{
+		name: "variants without traffic",
+		traffic: []v1.TrafficTarget{{
+			Percent:        ptr.Int64(100),
+			LatestRevision: ptr.Bool(true),
+		}},
+		want: []v1.TrafficTarget{{
+			Percent:           ptr.Int64(100),
+			ConfigurationName: testServiceName,
+			LatestRevision:    ptr.Bool(true),
+		}, {
+			Tag:               "canary",
+			ConfigurationName: testServiceName + "-canary",
+			LatestRevision:    ptr.Bool(true),
+		}, {
+			Tag:               "beta",
+			ConfigurationName: testServiceName + "-beta",
+			LatestRevision:    ptr.Bool(true),
+		}},
+	}, {
+		name: "traffic split with a variant",
+		traffic: []v1.TrafficTarget{{
+			Percent:        ptr.Int64(90),
+			LatestRevision: ptr.Bool(true),
+		}, {
+			Tag:            "canary",
+			Percent:        ptr.Int64(10),
+			LatestRevision: ptr.Bool(true),
+		}},
+		want: []v1.TrafficTarget{{
+			Percent:           ptr.Int64(90),
+			ConfigurationName: testServiceName,
+			LatestRevision:    ptr.Bool(true),
+		}, {
+			Tag:               "canary",
+			Percent:           ptr.Int64(10),
+			ConfigurationName: testServiceName + "-canary",
+			LatestRevision:    ptr.Bool(true),
+		}, {
+			Tag:               "beta",
+			ConfigurationName: testServiceName + "-beta",
+			LatestRevision:    ptr.Bool(true),
+		}},
+	}, {
+		name: "variant tag pinned to a revision",
+		traffic: []v1.TrafficTarget{{
+			Percent:        ptr.Int64(100),
+			LatestRevision: ptr.Bool(true),
+		}, {
+			Tag:            "canary",
+			RevisionName:   "test-service-canary-00001",
+			LatestRevision: ptr.Bool(false),
+		}},
+		want: []v1.TrafficTarget{{
+			Percent:           ptr.Int64(100),
+			ConfigurationName: testServiceName,
+			LatestRevision:    ptr.Bool(true),
+		}, {
+			Tag:            "canary",
+			RevisionName:   "test-service-canary-00001",
+			LatestRevision: ptr.Bool(false),
+		}, {
+			Tag:               "beta",
+			ConfigurationName: testServiceName + "-beta",
+			LatestRevision:    ptr.Bool(true),
+		}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := createService()
+			s.Spec.Traffic = test.traffic
+			s.Spec.Variants = []v1.ServiceVariant{canary, beta}
+			r, err := MakeRoute(s)
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			if !cmp.Equal(r.Spec.Traffic, test.want) {
+				t.Error("Traffic mismatch (-want, +got):", cmp.Diff(test.want, r.Spec.Traffic))
+			}
+		})
+	}
+}
+
 func TestRouteHasNoKubectlAnnotation(t *testing.T) {
 	s := createServiceWithKubectlAnnotation()
 	r, err := MakeRoute(s)
@@ -27,6 +27,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	clientset "knative.dev/serving/pkg/client/clientset/versioned"
 	ksvcreconciler "knative.dev/serving/pkg/client/injection/reconciler/serving/v1/service"
 
@@ -85,14 +87,29 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, service *v1.Service) pkg
 		service.Status.PropagateConfigurationStatus(&config.Status)
 	}
 
+	variants, err := c.variantConfigs(ctx, logger, service)
+	if err != nil {
+		return err
+	}
+	for _, variant := range variants {
+		// As above, serialize reconciling the Configuration of a variant
+		// using BYO-Revision name and the Route.
+		if variant.Generation != variant.Status.ObservedGeneration &&
+			variant.Spec.GetTemplate().Name != "" {
+			return nil
+		}
+	}
+
 	// When the Configuration names a Revision, check that the named Revision is owned
 	// by our Configuration and matches its generation before reprogramming the Route,
 	// otherwise a bad patch could lead to folks inadvertently routing traffic to a
 	// pre-existing Revision (possibly for another Configuration).
-	if err := CheckNameAvailability(config, c.revisionLister); err != nil &&
-		!apierrs.IsNotFound(err) {
-		service.Status.MarkRevisionNameTaken(config.Spec.GetTemplate().Name)
-		return nil
+	for _, cfg := range append([]*v1.Configuration{config}, variants...) {
+		if err := CheckNameAvailability(cfg, c.revisionLister); err != nil &&
+			!apierrs.IsNotFound(err) {
+			service.Status.MarkRevisionNameTaken(cfg.Spec.GetTemplate().Name)
+			return nil
+		}
 	}
 
 	route, err := c.route(ctx, logger, service)
@@ -111,16 +128,85 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, service *v1.Service) pkg
 		ss.PropagateRouteStatus(&route.Status)
 	}
 
-	c.checkRoutesNotReady(config, logger, route, service)
+	// The Route no longer references the Configurations of the removed
+	// variants, so they can go.
+	if err := c.pruneVariantConfigs(ctx, service); err != nil {
+		return err
+	}
+
+	c.checkRoutesNotReady(append([]*v1.Configuration{config}, variants...), logger, route, service)
 	return nil
 }
 
+// pruneVariantConfigs deletes the Configurations of the variants removed from
+// the Service. Only the Configurations the Service controls are considered.
+func (c *Reconciler) pruneVariantConfigs(ctx context.Context, service *v1.Service) error {
+	want := sets.NewString(resourcenames.Configuration(service))
+	for _, variant := range service.Spec.Variants {
+		want.Insert(resourcenames.VariantConfiguration(service, variant.Name))
+	}
+
+	configs, err := c.configurationLister.Configurations(service.Namespace).List(labels.SelectorFromSet(labels.Set{
+		serving.ServiceLabelKey: service.Name,
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to list Configurations: %w", err)
+	}
+	recorder := controller.GetEventRecorder(ctx)
+	for _, config := range configs {
+		if want.Has(config.Name) || !metav1.IsControlledBy(config, service) {
+			continue
+		}
+		err := c.client.ServingV1().Configurations(config.Namespace).Delete(config.Name, &metav1.DeleteOptions{})
+		if err != nil && !apierrs.IsNotFound(err) {
+			recorder.Eventf(service, corev1.EventTypeWarning, "DeleteFailed", "Failed to delete Configuration %q: %v", config.Name, err)
+			return fmt.Errorf("failed to delete Configuration: %w", err)
+		}
+		recorder.Eventf(service, corev1.EventTypeNormal, "Deleted", "Deleted Configuration %q", config.Name)
+	}
+	return nil
+}
+
+// configMaker makes the desired Configuration of a Service given the existing one.
+type configMaker func(existing *v1.Configuration, gc cfgmap.Flag) (*v1.Configuration, error)
+
 func (c *Reconciler) config(ctx context.Context, logger *zap.SugaredLogger, service *v1.Service) (*v1.Configuration, error) {
+	return c.reconcileConfigurationNamed(ctx, service, resourcenames.Configuration(service),
+		func(existing *v1.Configuration, gc cfgmap.Flag) (*v1.Configuration, error) {
+			return resources.MakeConfigurationFromExisting(service, existing, gc)
+		})
+}
+
+// variantConfigs reconciles the Configurations of the variants of the Service, and
+// propagates their status.
+func (c *Reconciler) variantConfigs(ctx context.Context, logger *zap.SugaredLogger, service *v1.Service) ([]*v1.Configuration, error) {
+	configs := make([]*v1.Configuration, 0, len(service.Spec.Variants))
+	for i := range service.Spec.Variants {
+		variant := &service.Spec.Variants[i]
+		config, err := c.reconcileConfigurationNamed(ctx, service, resourcenames.VariantConfiguration(service, variant.Name),
+			func(existing *v1.Configuration, gc cfgmap.Flag) (*v1.Configuration, error) {
+				return resources.MakeVariantConfigurationFromExisting(service, variant, existing, gc)
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		if config.Generation != config.Status.ObservedGeneration {
+			service.Status.MarkConfigurationNotReconciled()
+		} else {
+			logger.Debugf("Configuration %q Conditions = %#v", config.Name, config.Status.Conditions)
+			service.Status.PropagateVariantConfigurationStatus(&config.Status)
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+func (c *Reconciler) reconcileConfigurationNamed(ctx context.Context, service *v1.Service, configName string, makeConfig configMaker) (*v1.Configuration, error) {
 	recorder := controller.GetEventRecorder(ctx)
-	configName := resourcenames.Configuration(service)
 	config, err := c.configurationLister.Configurations(service.Namespace).Get(configName)
 	if apierrs.IsNotFound(err) {
-		config, err = c.createConfiguration(ctx, service)
+		config, err = c.createConfiguration(ctx, service, makeConfig)
 		if err != nil {
 			recorder.Eventf(service, corev1.EventTypeWarning, "CreationFailed", "Failed to create Configuration %q: %v", configName, err)
 			return nil, fmt.Errorf("failed to create Configuration: %w", err)
@@ -132,7 +218,7 @@ func (c *Reconciler) config(ctx context.Context, logger *zap.SugaredLogger, serv
 		// Surface an error in the service's status,and return an error.
 		service.Status.MarkConfigurationNotOwned(configName)
 		return nil, fmt.Errorf("service: %q does not own configuration: %q", service.Name, configName)
-	} else if config, err = c.reconcileConfiguration(ctx, service, config); err != nil {
+	} else if config, err = c.reconcileConfiguration(ctx, service, config, makeConfig); err != nil {
 		return nil, fmt.Errorf("failed to reconcile Configuration: %w", err)
 	}
 	return config, nil
@@ -161,7 +247,7 @@ func (c *Reconciler) route(ctx context.Context, logger *zap.SugaredLogger, servi
 	return route, nil
 }
 
-func (c *Reconciler) checkRoutesNotReady(config *v1.Configuration, logger *zap.SugaredLogger, route *v1.Route, service *v1.Service) {
+func (c *Reconciler) checkRoutesNotReady(configs []*v1.Configuration, logger *zap.SugaredLogger, route *v1.Route, service *v1.Service) {
 	// `manual` is not reconciled.
 	rc := service.Status.GetCondition(v1.ServiceConditionRoutesReady)
 	if rc == nil || rc.Status != corev1.ConditionTrue {
@@ -173,11 +259,15 @@ func (c *Reconciler) checkRoutesNotReady(config *v1.Configuration, logger *zap.S
 		return
 	}
 
+	latestReady := make(map[string]string, len(configs))
+	for _, config := range configs {
+		latestReady[config.Name] = config.Status.LatestReadyRevisionName
+	}
 	want, got := route.Spec.DeepCopy().Traffic, route.Status.DeepCopy().Traffic
 	// Replace `configuration` target with its latest ready revision.
 	for idx := range want {
-		if want[idx].ConfigurationName == config.Name {
-			want[idx].RevisionName = config.Status.LatestReadyRevisionName
+		if rev, ok := latestReady[want[idx].ConfigurationName]; ok {
+			want[idx].RevisionName = rev
 			want[idx].ConfigurationName = ""
 		}
 	}
@@ -188,9 +278,9 @@ func (c *Reconciler) checkRoutesNotReady(config *v1.Configuration, logger *zap.S
 	}
 }
 
-func (c *Reconciler) createConfiguration(ctx context.Context, service *v1.Service) (*v1.Configuration, error) {
+func (c *Reconciler) createConfiguration(ctx context.Context, service *v1.Service, makeConfig configMaker) (*v1.Configuration, error) {
 	gc := cfgmap.FromContextOrDefaults(ctx).Features.ResponsiveRevisionGC
-	cfg, err := resources.MakeConfigurationFromExisting(service, &v1.Configuration{}, gc)
+	cfg, err := makeConfig(&v1.Configuration{}, gc)
 	if err != nil {
 		return nil, err
 	}
@@ -212,14 +302,14 @@ func configSemanticEquals(ctx context.Context, desiredConfig, config *v1.Configu
 		specDiff == "", nil
 }
 
-func (c *Reconciler) reconcileConfiguration(ctx context.Context, service *v1.Service, config *v1.Configuration) (*v1.Configuration, error) {
+func (c *Reconciler) reconcileConfiguration(ctx context.Context, service *v1.Service, config *v1.Configuration, makeConfig configMaker) (*v1.Configuration, error) {
 	existing := config.DeepCopy()
 	// In the case of an upgrade, there can be default values set that don't exist pre-upgrade.
 	// We are setting the up-to-date default values here so an update won't be triggered if the only
 	// diff is the new default values.
 	existing.SetDefaults(ctx)
 	gc := cfgmap.FromContextOrDefaults(ctx).Features.ResponsiveRevisionGC
-	desiredConfig, err := resources.MakeConfigurationFromExisting(service, existing, gc)
+	desiredConfig, err := makeConfig(existing, gc)
 	if err != nil {
 		return nil, err
 	}
@@ -28,6 +28,7 @@ import (
 	_ "knative.dev/serving/pkg/client/injection/informers/serving/v1/route/fake"
 	_ "knative.dev/serving/pkg/client/injection/informers/serving/v1/service/fake"
 
+	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -639,6 +640,172 @@ func TestReconcile(t *testing.T) {
 				WithServiceStatusRouteNotReady, WithFailedConfig(
 					"config-fails-00001", "RevisionFailed", "blah")),
 		}},
+	}, {
+		Name: "create route and configurations of the variants",
+		Objects: []runtime.Object{
+			DefaultService("variants", "foo", withCanaryVariant, WithServiceGeneration(1)),
+		},
+		Key: "foo/variants",
+		WantCreates: []runtime.Object{
+			config("variants", "foo", withCanaryVariant),
+			variantConfig("variants", "foo", "canary", withCanaryVariant),
+			route("variants", "foo", withCanaryVariant),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: DefaultService("variants", "foo", withCanaryVariant,
+				// The first reconciliation will initialize the status conditions.
+				WithInitSvcConditions, WithServiceObservedGenFailure,
+				WithServiceGeneration(1), WithServiceObservedGeneration),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Created", "Created Configuration %q", "variants"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created Configuration %q", "variants-canary"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created Route %q", "variants"),
+		},
+	}, {
+		Name: "all ready with variants",
+		Objects: []runtime.Object{
+			DefaultService("variants", "foo", withCanaryVariant, WithInitSvcConditions, WithServiceGeneration(1)),
+			route("variants", "foo", withCanaryVariant, RouteReady,
+				WithURL, WithAddress, WithInitRouteConditions,
+				WithStatusTraffic(v1.TrafficTarget{
+					RevisionName: "variants-00001",
+					Percent:      ptr.Int64(100),
+				}, v1.TrafficTarget{
+					Tag:          "canary",
+					RevisionName: "variants-canary-00001",
+					URL:          canaryURL,
+				}), MarkTrafficAssigned, MarkIngressReady),
+			config("variants", "foo", withCanaryVariant,
+				WithConfigGeneration(1), WithConfigObservedGen,
+				WithLatestCreated("variants-00001"), WithLatestReady("variants-00001")),
+			variantConfig("variants", "foo", "canary", withCanaryVariant,
+				WithConfigGeneration(1), WithConfigObservedGen,
+				WithLatestCreated("variants-canary-00001"), WithLatestReady("variants-canary-00001")),
+		},
+		Key: "foo/variants",
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: DefaultService("variants", "foo", withCanaryVariant,
+				WithReadyConfig("variants-00001"),
+				WithReadyRoute, WithSvcStatusDomain, WithSvcStatusAddress,
+				WithSvcStatusTraffic(v1.TrafficTarget{
+					RevisionName: "variants-00001",
+					Percent:      ptr.Int64(100),
+				}, v1.TrafficTarget{
+					Tag:          "canary",
+					RevisionName: "variants-canary-00001",
+					URL:          canaryURL,
+				})),
+		}},
+	}, {
+		Name: "prune the configuration of a removed variant",
+		Objects: []runtime.Object{
+			DefaultService("variants", "foo", withCanaryVariant, WithInitSvcConditions, WithServiceGeneration(1)),
+			route("variants", "foo", withCanaryVariant, RouteReady,
+				WithURL, WithAddress, WithInitRouteConditions,
+				WithStatusTraffic(v1.TrafficTarget{
+					RevisionName: "variants-00001",
+					Percent:      ptr.Int64(100),
+				}, v1.TrafficTarget{
+					Tag:          "canary",
+					RevisionName: "variants-canary-00001",
+					URL:          canaryURL,
+				}), MarkTrafficAssigned, MarkIngressReady),
+			config("variants", "foo", withCanaryVariant,
+				WithConfigGeneration(1), WithConfigObservedGen,
+				WithLatestCreated("variants-00001"), WithLatestReady("variants-00001")),
+			variantConfig("variants", "foo", "canary", withCanaryVariant,
+				WithConfigGeneration(1), WithConfigObservedGen,
+				WithLatestCreated("variants-canary-00001"), WithLatestReady("variants-canary-00001")),
+			variantConfig("variants", "foo", "canary", withCanaryVariant, withConfigName("variants-old")),
+		},
+		Key: "foo/variants",
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: DefaultService("variants", "foo", withCanaryVariant,
+				WithReadyConfig("variants-00001"),
+				WithReadyRoute, WithSvcStatusDomain, WithSvcStatusAddress,
+				WithSvcStatusTraffic(v1.TrafficTarget{
+					RevisionName: "variants-00001",
+					Percent:      ptr.Int64(100),
+				}, v1.TrafficTarget{
+					Tag:          "canary",
+					RevisionName: "variants-canary-00001",
+					URL:          canaryURL,
+				})),
+		}},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{Namespace: "foo"},
+			Name:       "variants-old",
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Deleted", "Deleted Configuration %q", "variants-old"),
+		},
+	}, {
+		Name: "configurations not controlled are not pruned",
+		Objects: []runtime.Object{
+			DefaultService("variants", "foo", withCanaryVariant, WithInitSvcConditions, WithServiceGeneration(1)),
+			route("variants", "foo", withCanaryVariant, RouteReady,
+				WithURL, WithAddress, WithInitRouteConditions,
+				WithStatusTraffic(v1.TrafficTarget{
+					RevisionName: "variants-00001",
+					Percent:      ptr.Int64(100),
+				}, v1.TrafficTarget{
+					Tag:          "canary",
+					RevisionName: "variants-canary-00001",
+					URL:          canaryURL,
+				}), MarkTrafficAssigned, MarkIngressReady),
+			config("variants", "foo", withCanaryVariant,
+				WithConfigGeneration(1), WithConfigObservedGen,
+				WithLatestCreated("variants-00001"), WithLatestReady("variants-00001")),
+			variantConfig("variants", "foo", "canary", withCanaryVariant,
+				WithConfigGeneration(1), WithConfigObservedGen,
+				WithLatestCreated("variants-canary-00001"), WithLatestReady("variants-canary-00001")),
+			variantConfig("variants", "foo", "canary", withCanaryVariant, withConfigName("variants-old"),
+				WithConfigOwnersRemoved),
+		},
+		Key: "foo/variants",
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: DefaultService("variants", "foo", withCanaryVariant,
+				WithReadyConfig("variants-00001"),
+				WithReadyRoute, WithSvcStatusDomain, WithSvcStatusAddress,
+				WithSvcStatusTraffic(v1.TrafficTarget{
+					RevisionName: "variants-00001",
+					Percent:      ptr.Int64(100),
+				}, v1.TrafficTarget{
+					Tag:          "canary",
+					RevisionName: "variants-canary-00001",
+					URL:          canaryURL,
+				})),
+		}},
+	}, {
+		Name: "variant configuration failure is propagated",
+		Objects: []runtime.Object{
+			DefaultService("variants", "foo", withCanaryVariant, WithInitSvcConditions, WithServiceGeneration(1)),
+			route("variants", "foo", withCanaryVariant, RouteReady),
+			config("variants", "foo", withCanaryVariant,
+				WithConfigGeneration(1), WithConfigObservedGen,
+				WithLatestCreated("variants-00001"), WithLatestReady("variants-00001")),
+			variantConfig("variants", "foo", "canary", withCanaryVariant,
+				WithConfigGeneration(1), WithConfigObservedGen,
+				WithLatestCreated("variants-canary-00001"), MarkLatestCreatedFailed("blah")),
+		},
+		Key: "foo/variants",
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: DefaultService("variants", "foo", withCanaryVariant, WithInitSvcConditions,
+				WithReadyConfig("variants-00001"), WithServiceStatusRouteNotReady,
+				func(s *v1.Service) {
+					s.Status.PropagateVariantConfigurationStatus(&v1.ConfigurationStatus{
+						Status: duckv1.Status{
+							Conditions: duckv1.Conditions{{
+								Type:    v1.ConfigurationConditionReady,
+								Status:  corev1.ConditionFalse,
+								Reason:  "RevisionFailed",
+								Message: `Revision "variants-canary-00001" failed with message: blah.`,
+							}},
+						},
+					})
+				}),
+		}},
 	}, {
 		Name: "route failure is propagated",
 		// When route fails, the service should fail.
@@ -833,6 +1000,56 @@ func config(name, namespace string, so ServiceOption, co ...ConfigOption) *v1.Co
 	return cfg
 }
 
+func variantConfig(name, namespace, variant string, so ServiceOption, co ...ConfigOption) *v1.Configuration {
+	s := DefaultService(name, namespace, so)
+	s.SetDefaults(context.Background())
+	for i := range s.Spec.Variants {
+		if s.Spec.Variants[i].Name != variant {
+			continue
+		}
+		cfg, err := resources.MakeVariantConfigurationFromExisting(s, &s.Spec.Variants[i], &v1.Configuration{}, cfgmap.Disabled)
+		if err != nil {
+			panic(fmt.Sprint("MakeVariantConfigurationFromExisting() = ", err))
+		}
+		for _, opt := range co {
+			opt(cfg)
+		}
+		return cfg
+	}
+	panic(fmt.Sprintf("no variant %q", variant))
+}
+
+func withConfigName(name string) ConfigOption {
+	return func(cfg *v1.Configuration) {
+		cfg.Name = name
+	}
+}
+
+var canaryURL = &apis.URL{
+	Scheme: "http",
+	Host:   "canary-variants.foo.example.com",
+}
+
+// withCanaryVariant is WithRunLatestRollout with a canary variant running
+// another image.
+func withCanaryVariant(s *v1.Service) {
+	WithRunLatestRollout(s)
+	WithServiceVariants(v1.ServiceVariant{
+		Name: "canary",
+		ConfigurationSpec: v1.ConfigurationSpec{
+			Template: v1.RevisionTemplateSpec{
+				Spec: v1.RevisionSpec{
+					PodSpec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Image: "busybox:canary",
+						}},
+					},
+				},
+			},
+		},
+	})(s)
+}
+
 func route(name, namespace string, so ServiceOption, ro ...RouteOption) *v1.Route {
 	s := DefaultService(name, namespace, so)
 	s.SetDefaults(context.Background())
@@ -21,11 +21,15 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
 	corev1 "k8s.io/api/core/v1"
+	nodev1beta1 "k8s.io/api/node/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	autoscalingv2beta1listers "k8s.io/client-go/listers/autoscaling/v2beta1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	nodev1beta1listers "k8s.io/client-go/listers/node/v1beta1"
+	rbacv1listers "k8s.io/client-go/listers/rbac/v1"
 	"k8s.io/client-go/tools/cache"
 	cachingv1alpha1 "knative.dev/caching/pkg/apis/caching/v1alpha1"
 	fakecachingclientset "knative.dev/caching/pkg/client/clientset/versioned/fake"
@@ -186,6 +190,21 @@ func (l *Listers) GetPodsLister() corev1listers.PodLister {
 	return corev1listers.NewPodLister(l.IndexerFor(&corev1.Pod{}))
 }
 
+// GetRoleLister gets lister for Role resource.
+func (l *Listers) GetRoleLister() rbacv1listers.RoleLister {
+	return rbacv1listers.NewRoleLister(l.IndexerFor(&rbacv1.Role{}))
+}
+
+// GetRoleBindingLister gets lister for RoleBinding resource.
+func (l *Listers) GetRoleBindingLister() rbacv1listers.RoleBindingLister {
+	return rbacv1listers.NewRoleBindingLister(l.IndexerFor(&rbacv1.RoleBinding{}))
+}
+
+// GetRuntimeClassLister gets lister for RuntimeClass resource.
+func (l *Listers) GetRuntimeClassLister() nodev1beta1listers.RuntimeClassLister {
+	return nodev1beta1listers.NewRuntimeClassLister(l.IndexerFor(&nodev1beta1.RuntimeClass{}))
+}
+
 func (l *Listers) GetSecretLister() corev1listers.SecretLister {
 	return corev1listers.NewSecretLister(l.IndexerFor(&corev1.Secret{}))
 }
@@ -85,6 +85,13 @@ func WithLatestReady(name string) ConfigOption {
 	}
 }
 
+// WithRevisionSummary sets the .status.revisions of the Configuration.
+func WithRevisionSummary(revs ...v1.ConfigurationRevisionSummary) ConfigOption {
+	return func(cfg *v1.Configuration) {
+		cfg.Status.Revisions = revs
+	}
+}
+
 // MarkRevisionCreationFailed calls .Status.MarkRevisionCreationFailed.
 func MarkRevisionCreationFailed(msg string) ConfigOption {
 	return func(cfg *v1.Configuration) {
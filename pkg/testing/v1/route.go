@@ -113,6 +113,13 @@ func MarkServiceNotOwned(r *v1.Route) {
 	r.Status.MarkServiceNotOwned(routenames.K8sService(r))
 }
 
+// MarkIngressNotOwned calls the function of the same name on the Route's status.
+func MarkIngressNotOwned(name string) RouteOption {
+	return func(r *v1.Route) {
+		r.Status.MarkIngressNotOwned(name)
+	}
+}
+
 // WithURL sets the .Status.Domain field to the prototypical domain.
 func WithURL(r *v1.Route) {
 	r.Status.URL = &apis.URL{
@@ -225,6 +232,13 @@ func MarkIngressNotConfigured(r *v1.Route) {
 	r.Status.MarkIngressNotConfigured()
 }
 
+// MarkDNSNotResolved calls the method of the same name on .Status
+func MarkDNSNotResolved(host string) RouteOption {
+	return func(r *v1.Route) {
+		r.Status.MarkDNSNotResolved(host)
+	}
+}
+
 // WithPropagatedStatus propagates the given IngressStatus into the routes status.
 func WithPropagatedStatus(status netv1alpha1.IngressStatus) RouteOption {
 	return func(r *v1.Route) {
@@ -239,6 +253,13 @@ func MarkMissingTrafficTarget(kind, revision string) RouteOption {
 	}
 }
 
+// MarkTrafficTargetNotFound calls the method of the same name on .Status
+func MarkTrafficTargetNotFound(names ...string) RouteOption {
+	return func(r *v1.Route) {
+		r.Status.MarkTrafficTargetNotFound(names...)
+	}
+}
+
 // MarkConfigurationNotReady calls the method of the same name on .Status
 func MarkConfigurationNotReady(name string) RouteOption {
 	return func(r *v1.Route) {
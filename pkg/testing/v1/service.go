@@ -94,6 +94,13 @@ func WithRouteSpec(route v1.RouteSpec) ServiceOption {
 	}
 }
 
+// WithServiceVariants sets the variants of the Service.
+func WithServiceVariants(variants ...v1.ServiceVariant) ServiceOption {
+	return func(svc *v1.Service) {
+		svc.Spec.Variants = variants
+	}
+}
+
 // WithNamedPort sets the name on the Service's port to the provided name
 func WithNamedPort(name string) ServiceOption {
 	return func(svc *v1.Service) {
@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/config"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// PodSpecEnvFromValidationAnnotation gates the envFrom validation when the
+// feature is "allowed", and runs it with the value 'enabled'.
+const PodSpecEnvFromValidationAnnotation = "features.knative.dev/podspec-envfrom-validation"
+
+// envFromValidationEnabled returns whether the envFrom sources must be
+// validated given the feature flag and the annotations of the resource.
+func envFromValidationEnabled(flag config.Flag, annotations map[string]string) bool {
+	switch flag {
+	case config.Enabled:
+		return true
+	case config.Allowed:
+		return annotations[PodSpecEnvFromValidationAnnotation] == "enabled"
+	default:
+		return false
+	}
+}
+
+// validateEnvFromSources checks that the ConfigMaps and Secrets referenced by
+// the envFrom of the containers exist, unless they are optional. Failures to
+// check other than the source not existing are only logged, so that the
+// admission doesn't depend on the API server beyond this opt-in check.
+func validateEnvFromSources(ctx context.Context, rs v1.RevisionSpec, namespace string) *apis.FieldError {
+	logger := logging.FromContext(ctx)
+	client := kubeclient.Get(ctx)
+
+	var errs *apis.FieldError
+	for i, container := range rs.Containers {
+		for j, envFrom := range container.EnvFrom {
+			var err *apis.FieldError
+			switch {
+			case envFrom.ConfigMapRef != nil && !isOptional(envFrom.ConfigMapRef.Optional):
+				name := envFrom.ConfigMapRef.Name
+				_, getErr := client.CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+				err = missingSourceError(logger, "ConfigMap", namespace, name, getErr).ViaField("configMapRef")
+			case envFrom.SecretRef != nil && !isOptional(envFrom.SecretRef.Optional):
+				name := envFrom.SecretRef.Name
+				_, getErr := client.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+				err = missingSourceError(logger, "Secret", namespace, name, getErr).ViaField("secretRef")
+			}
+			errs = errs.Also(err.ViaFieldIndex("envFrom", j).ViaFieldIndex("containers", i))
+		}
+	}
+	return errs.ViaField("spec.template.spec")
+}
+
+func missingSourceError(logger *zap.SugaredLogger, kind, namespace, name string, err error) *apis.FieldError {
+	switch {
+	case err == nil:
+		return nil
+	case apierrs.IsNotFound(err):
+		return &apis.FieldError{
+			Message: fmt.Sprintf("%s %q referenced by envFrom does not exist in namespace %q", kind, name, namespace),
+			Paths:   []string{"name"},
+			Details: "Create it before the revision, or mark the envFrom source optional.",
+		}
+	default:
+		logger.Warnw(fmt.Sprintf("Failed to check that %s %s/%s exists", kind, namespace, name), zap.Error(err))
+		return nil
+	}
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/pkg/logging"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/config"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestEnvFromValidation(t *testing.T) {
+	configMapRef := func(name string, optional *bool) corev1.EnvFromSource {
+		return corev1.EnvFromSource{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Optional:             optional,
+			},
+		}
+	}
+	secretRef := func(name string, optional *bool) corev1.EnvFromSource {
+		return corev1.EnvFromSource{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Optional:             optional,
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		flag        config.Flag
+		annotations map[string]string
+		envFrom     []corev1.EnvFromSource
+		getErr      error
+		want        string
+	}{{
+		name:    "sources present",
+		flag:    config.Enabled,
+		envFrom: []corev1.EnvFromSource{configMapRef("present", nil), secretRef("present", nil)},
+	}, {
+		name:    "configmap missing",
+		flag:    config.Enabled,
+		envFrom: []corev1.EnvFromSource{configMapRef("present", nil), configMapRef("missing", nil)},
+		want: `ConfigMap "missing" referenced by envFrom does not exist in namespace "foo": ` +
+			"spec.template.spec.containers[0].envFrom[1].configMapRef.name\n" +
+			"Create it before the revision, or mark the envFrom source optional.",
+	}, {
+		name:    "secret missing",
+		flag:    config.Enabled,
+		envFrom: []corev1.EnvFromSource{secretRef("missing", ptr.Bool(false))},
+		want: `Secret "missing" referenced by envFrom does not exist in namespace "foo": ` +
+			"spec.template.spec.containers[0].envFrom[0].secretRef.name\n" +
+			"Create it before the revision, or mark the envFrom source optional.",
+	}, {
+		name:    "optional sources missing",
+		flag:    config.Enabled,
+		envFrom: []corev1.EnvFromSource{configMapRef("missing", ptr.Bool(true)), secretRef("missing", ptr.Bool(true))},
+	}, {
+		name:    "failure to check",
+		flag:    config.Enabled,
+		envFrom: []corev1.EnvFromSource{configMapRef("missing", nil)},
+		getErr:  errors.New("connection refused"),
+	}, {
+		name:    "disabled",
+		flag:    config.Disabled,
+		envFrom: []corev1.EnvFromSource{configMapRef("missing", nil)},
+		annotations: map[string]string{
+			PodSpecEnvFromValidationAnnotation: "enabled",
+		},
+	}, {
+		name:    "allowed without annotation",
+		flag:    config.Allowed,
+		envFrom: []corev1.EnvFromSource{configMapRef("missing", nil)},
+	}, {
+		name:    "allowed with annotation",
+		flag:    config.Allowed,
+		envFrom: []corev1.EnvFromSource{configMapRef("missing", nil)},
+		annotations: map[string]string{
+			PodSpecEnvFromValidationAnnotation: "enabled",
+		},
+		want: `ConfigMap "missing" referenced by envFrom does not exist in namespace "foo": ` +
+			"spec.template.spec.containers[0].envFrom[0].configMapRef.name\n" +
+			"Create it before the revision, or mark the envFrom source optional.",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, client := fakekubeclient.With(context.Background(),
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "present"}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "present"}},
+			)
+			if test.getErr != nil {
+				client.PrependReactor("get", "*", func(clientgotesting.Action) (bool, runtime.Object, error) {
+					return true, nil, test.getErr
+				})
+			}
+			ctx = logging.WithLogger(ctx, logtesting.TestLogger(t))
+			ctx = config.ToContext(ctx, &config.Config{
+				Features: &config.Features{
+					PodSpecDryRun:            config.Disabled,
+					PodSpecEnvFromValidation: test.flag,
+				},
+			})
+
+			svc := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "foo",
+					Name:        "valid",
+					Annotations: test.annotations,
+				},
+				Spec: v1.ServiceSpec{
+					ConfigurationSpec: v1.ConfigurationSpec{
+						Template: v1.RevisionTemplateSpec{
+							Spec: v1.RevisionSpec{
+								PodSpec: corev1.PodSpec{
+									Containers: []corev1.Container{{
+										Image:   "busybox",
+										EnvFrom: test.envFrom,
+									}},
+								},
+							},
+						},
+					},
+				},
+			}
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(svc)
+			if err != nil {
+				t.Fatal("ToUnstructured() =", err)
+			}
+			unstruct := &unstructured.Unstructured{}
+			unstruct.SetUnstructuredContent(content)
+
+			got := ValidateService(ctx, unstruct)
+			if got == nil {
+				if test.want != "" {
+					t.Errorf("Validate got=nil, want=%q", test.want)
+				}
+			} else if got.Error() != test.want {
+				t.Errorf("Validate got=%q, want=%q", got.Error(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	schedulinglisters "k8s.io/client-go/listers/scheduling/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/logging"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+type priorityClassListerKey struct{}
+
+// WithPriorityClassLister attaches the lister used to check that the
+// PriorityClasses referenced by revisions exist to the context.
+func WithPriorityClassLister(ctx context.Context, lister schedulinglisters.PriorityClassLister) context.Context {
+	return context.WithValue(ctx, priorityClassListerKey{}, lister)
+}
+
+func getPriorityClassLister(ctx context.Context) schedulinglisters.PriorityClassLister {
+	if lister, ok := ctx.Value(priorityClassListerKey{}).(schedulinglisters.PriorityClassLister); ok {
+		return lister
+	}
+	return nil
+}
+
+// validatePriorityClass checks that the PriorityClass referenced by the
+// priorityClassName exists. The check is skipped when there's no lister in
+// the context, and failures to check other than the class not existing are
+// only logged.
+func validatePriorityClass(ctx context.Context, rs v1.RevisionSpec) *apis.FieldError {
+	name := rs.PriorityClassName
+	lister := getPriorityClassLister(ctx)
+	if name == "" || lister == nil {
+		return nil
+	}
+
+	_, err := lister.Get(name)
+	switch {
+	case err == nil:
+		return nil
+	case apierrs.IsNotFound(err):
+		return (&apis.FieldError{
+			Message: fmt.Sprintf("PriorityClass %q does not exist", name),
+			Paths:   []string{"priorityClassName"},
+			Details: "Create it before the revision.",
+		}).ViaField("spec.template.spec")
+	default:
+		logging.FromContext(ctx).Warnw("Failed to check that PriorityClass "+name+" exists", zap.Error(err))
+		return nil
+	}
+}
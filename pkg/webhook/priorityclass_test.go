@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	schedulinglisters "k8s.io/client-go/listers/scheduling/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/logging"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/apis/config"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// failingPriorityClassLister fails all the lookups.
+type failingPriorityClassLister struct{}
+
+func (failingPriorityClassLister) List(labels.Selector) ([]*schedulingv1.PriorityClass, error) {
+	return nil, errors.New("informer not synced")
+}
+
+func (failingPriorityClassLister) Get(string) (*schedulingv1.PriorityClass, error) {
+	return nil, errors.New("informer not synced")
+}
+
+func TestPriorityClassValidation(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "critical"}})
+	present := schedulinglisters.NewPriorityClassLister(indexer)
+
+	tests := []struct {
+		name   string
+		flag   config.Flag
+		pcn    string
+		lister schedulinglisters.PriorityClassLister
+		want   string
+	}{{
+		name:   "class present",
+		flag:   config.Enabled,
+		pcn:    "critical",
+		lister: present,
+	}, {
+		name:   "class missing",
+		flag:   config.Enabled,
+		pcn:    "missing",
+		lister: present,
+		want: `PriorityClass "missing" does not exist: spec.template.spec.priorityClassName` +
+			"\nCreate it before the revision.",
+	}, {
+		name:   "no class",
+		flag:   config.Enabled,
+		lister: present,
+	}, {
+		name:   "failure to check",
+		flag:   config.Enabled,
+		pcn:    "missing",
+		lister: failingPriorityClassLister{},
+	}, {
+		name: "no lister",
+		flag: config.Enabled,
+		pcn:  "missing",
+	}, {
+		name:   "disabled",
+		flag:   config.Disabled,
+		pcn:    "missing",
+		lister: present,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := logging.WithLogger(context.Background(), logtesting.TestLogger(t))
+			ctx = config.ToContext(ctx, &config.Config{
+				Features: &config.Features{
+					PodSpecDryRun:                  config.Disabled,
+					PodSpecPriorityClassName:       config.Enabled,
+					PodSpecPriorityClassValidation: test.flag,
+				},
+			})
+			if test.lister != nil {
+				ctx = WithPriorityClassLister(ctx, test.lister)
+			}
+
+			svc := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "valid",
+				},
+				Spec: v1.ServiceSpec{
+					ConfigurationSpec: v1.ConfigurationSpec{
+						Template: v1.RevisionTemplateSpec{
+							Spec: v1.RevisionSpec{
+								PodSpec: corev1.PodSpec{
+									PriorityClassName: test.pcn,
+									Containers: []corev1.Container{{
+										Image: "busybox",
+									}},
+								},
+							},
+						},
+					},
+				},
+			}
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(svc)
+			if err != nil {
+				t.Fatal("ToUnstructured() =", err)
+			}
+			unstruct := &unstructured.Unstructured{}
+			unstruct.SetUnstructuredContent(content)
+
+			got := ValidateService(ctx, unstruct)
+			if got == nil {
+				if test.want != "" {
+					t.Errorf("Validate got=nil, want=%q", test.want)
+				}
+			} else if got.Error() != test.want {
+				t.Errorf("Validate got=%q, want=%q", got.Error(), test.want)
+			}
+		})
+	}
+}
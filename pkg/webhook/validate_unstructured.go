@@ -62,21 +62,11 @@ func ValidateConfiguration(ctx context.Context, uns *unstructured.Unstructured)
 func validateRevisionTemplate(ctx context.Context, uns *unstructured.Unstructured) error {
 	content := uns.UnstructuredContent()
 
-	mode := DryRunMode(uns.GetAnnotations()[PodSpecDryRunAnnotation])
 	features := config.FromContextOrDefaults(ctx).Features
-	switch features.PodSpecDryRun {
-	case config.Enabled:
-		if mode != DryRunStrict {
-			mode = DryRunEnabled
-		}
-	case config.Disabled:
-		return nil
-	}
-
-	// TODO(https://github.com/knative/serving/issues/3425): remove this guard once variations
-	// of this are well-tested. Only run extra validation for the dry-run test.
-	// This will be in place to while the feature is tested for compatibility and later removed.
-	if mode != DryRunStrict && mode != DryRunEnabled {
+	mode := dryRunMode(features.PodSpecDryRun, uns.GetAnnotations())
+	envFrom := envFromValidationEnabled(features.PodSpecEnvFromValidation, uns.GetAnnotations())
+	priorityClass := features.PodSpecPriorityClassValidation == config.Enabled
+	if mode == "" && !envFrom && !priorityClass {
 		return nil
 	}
 
@@ -110,8 +100,42 @@ func validateRevisionTemplate(ctx context.Context, uns *unstructured.Unstructure
 		}
 	}
 
-	if err := validatePodSpec(ctx, templ.Spec, namespace, mode); err != nil {
-		return err
+	if envFrom {
+		if err := validateEnvFromSources(ctx, templ.Spec, namespace); err != nil {
+			return err
+		}
+	}
+	if priorityClass {
+		if err := validatePriorityClass(ctx, templ.Spec); err != nil {
+			return err
+		}
+	}
+	if mode != "" {
+		if err := validatePodSpec(ctx, templ.Spec, namespace, mode); err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+// dryRunMode returns the mode the dry-run runs with given the feature flag and
+// the annotations of the resource, or "" if it must not run.
+func dryRunMode(flag config.Flag, annotations map[string]string) DryRunMode {
+	mode := DryRunMode(annotations[PodSpecDryRunAnnotation])
+	switch flag {
+	case config.Enabled:
+		if mode != DryRunStrict {
+			mode = DryRunEnabled
+		}
+	case config.Disabled:
+		return ""
+	}
+
+	// TODO(https://github.com/knative/serving/issues/3425): remove this guard once variations
+	// of this are well-tested. Only run extra validation for the dry-run test.
+	// This will be in place to while the feature is tested for compatibility and later removed.
+	if mode != DryRunStrict && mode != DryRunEnabled {
+		return ""
+	}
+	return mode
+}